@@ -0,0 +1,132 @@
+package blade
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// AccessibilityIssue is a problem found by AccessibilityValidator.
+type AccessibilityIssue struct {
+	Line    int
+	Message string
+}
+
+// AccessibilityError is returned by AccessibilityValidator with the issues of an output.
+type AccessibilityError struct {
+	Entry  string
+	Issues []AccessibilityIssue
+}
+
+func (e *AccessibilityError) Error() string {
+	messages := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		messages[i] = fmt.Sprintf("line %d: %s", issue.Line, issue.Message)
+	}
+	return fmt.Sprintf("[%s] accessibility issues: %s", e.Entry, strings.Join(messages, "; "))
+}
+
+// AccessibilityValidator flags common accessibility regressions in HTML output: images without an alt
+// attribute, form controls without a label, and duplicate ids. Use it in development, or in CI with
+// Engine.Warmup to check every view.
+type AccessibilityValidator struct{}
+
+// unlabeledInputTypes are the input types that do not need a label.
+var unlabeledInputTypes = map[string]struct{}{
+	"hidden": {}, "submit": {}, "reset": {}, "button": {}, "image": {},
+}
+
+// formControl is a form control waiting for a label, which may come after it.
+type formControl struct {
+	tag  string
+	id   string
+	line int
+}
+
+// Validate implements Validator.
+func (AccessibilityValidator) Validate(entry string, output []byte) error {
+	z := html.NewTokenizer(bytes.NewReader(output))
+	line := 1
+	var issues []AccessibilityIssue
+	ids := map[string]int{}
+	labelFor := map[string]struct{}{}
+	var controls []formControl
+	openLabels := 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				return z.Err()
+			}
+			break
+		}
+		tokenLine := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+		if tt == html.EndTagToken {
+			if name, _ := z.TagName(); string(name) == "label" && openLabels > 0 {
+				openLabels--
+			}
+			continue
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		name, hasAttr := z.TagName()
+		tag := string(name)
+		attrs := map[string]string{}
+		for hasAttr {
+			var key, value []byte
+			key, value, hasAttr = z.TagAttr()
+			attrs[string(key)] = string(value)
+		}
+
+		if id, ok := attrs["id"]; ok && id != "" {
+			if first, ok := ids[id]; ok {
+				issues = append(issues, AccessibilityIssue{Line: tokenLine, Message: fmt.Sprintf(`duplicate id "%s", first used at line %d`, id, first)})
+			} else {
+				ids[id] = tokenLine
+			}
+		}
+
+		switch tag {
+		case "img":
+			if _, ok := attrs["alt"]; !ok {
+				issues = append(issues, AccessibilityIssue{Line: tokenLine, Message: fmt.Sprintf(`<img src="%s"> has no alt attribute`, attrs["src"])})
+			}
+		case "label":
+			if id := attrs["for"]; id != "" {
+				labelFor[id] = struct{}{}
+			}
+			if tt == html.StartTagToken {
+				openLabels++
+			}
+		case "input", "select", "textarea":
+			if _, ok := unlabeledInputTypes[strings.ToLower(attrs["type"])]; ok && tag == "input" {
+				continue
+			}
+			if openLabels > 0 || attrs["aria-label"] != "" || attrs["aria-labelledby"] != "" || attrs["title"] != "" {
+				continue
+			}
+			controls = append(controls, formControl{tag: tag, id: attrs["id"], line: tokenLine})
+		}
+	}
+
+	for _, control := range controls {
+		if control.id == "" {
+			issues = append(issues, AccessibilityIssue{Line: control.line, Message: fmt.Sprintf("<%s> has no id or label", control.tag)})
+		} else if _, ok := labelFor[control.id]; !ok {
+			issues = append(issues, AccessibilityIssue{Line: control.line, Message: fmt.Sprintf(`<%s id="%s"> has no label`, control.tag, control.id)})
+		}
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return &AccessibilityError{Entry: entry, Issues: issues}
+}
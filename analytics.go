@@ -0,0 +1,108 @@
+package blade
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"maps"
+	"strings"
+)
+
+// DefaultAnalyticsPrefix is the default name prefix of the attributes of @track.
+const DefaultAnalyticsPrefix = "data-analytics"
+
+// AnalyticsSchema standardizes the events tracked by templates, see Engine.Analytics.
+type AnalyticsSchema struct {
+	// Prefix is the name prefix of the attributes of @track, DefaultAnalyticsPrefix when empty
+	Prefix string
+	// Events are the events templates can track by name, tracking another event fails the render.
+	// Any event can be tracked when it is empty.
+	Events map[string]AnalyticsEvent
+	// Properties are added to the properties of every event, like the version of the application
+	Properties map[string]any
+}
+
+// AnalyticsEvent describes an event of an AnalyticsSchema.
+type AnalyticsEvent struct {
+	// Required are the properties every tracking of the event must set, a missing or nil one fails the render
+	Required []string
+	// Properties are fixed properties of the event, like its category
+	Properties map[string]any
+}
+
+// trackedEvent returns the properties of event from key and value pairs, validated by the schema.
+func (s *renderState) trackedEvent(name string, event string, pairs []any) (map[string]any, error) {
+	schema := s.e.Analytics
+	definition, ok := schema.Events[event]
+	if !ok && len(schema.Events) > 0 {
+		return nil, fmt.Errorf(`%s: unknown event "%s"`, name, event)
+	}
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("%s: expected property name and value pairs", name)
+	}
+	properties := maps.Clone(schema.Properties)
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	maps.Copy(properties, definition.Properties)
+	for i := 0; i < len(pairs); i += 2 {
+		properties[fmt.Sprint(pairs[i])] = pairs[i+1]
+	}
+	for _, required := range definition.Required {
+		if properties[required] == nil {
+			return nil, fmt.Errorf(`%s: event "%s" is missing the required property "%s"`, name, event, required)
+		}
+	}
+	return properties, nil
+}
+
+// track returns the tracking attributes of an event and its properties:
+// <button {{ track "signup_click" "plan" .Plan }}> renders
+// <button data-analytics-event="signup_click" data-analytics-properties="{&#34;plan&#34;:&#34;pro&#34;}">.
+func (s *renderState) track(event string, pairs ...any) (template.HTMLAttr, error) {
+	properties, err := s.trackedEvent("track", event, pairs)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(properties)
+	if err != nil {
+		return "", fmt.Errorf("track: %w", err)
+	}
+	prefix := s.e.Analytics.Prefix
+	if prefix == "" {
+		prefix = DefaultAnalyticsPrefix
+	}
+	prefix = template.HTMLEscapeString(prefix)
+	return template.HTMLAttr(fmt.Sprintf(`%s-event="%s" %s-properties="%s"`,
+		prefix, template.HTMLEscapeString(event), prefix, template.HTMLEscapeString(string(encoded)))), nil
+}
+
+// trackEvent returns an event and its properties as a map of "event" and "properties", encoded to JSON in
+// scripts: <script>analytics.push({{ trackEvent "purchase" "total" .Total }})</script>.
+func (s *renderState) trackEvent(event string, pairs ...any) (map[string]any, error) {
+	properties, err := s.trackedEvent("trackEvent", event, pairs)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"event": event, "properties": properties}, nil
+}
+
+// parseTrackDirective converts @track('signup_click', plan: .Plan) to {{ track "signup_click" "plan" (.Plan) }}.
+func parseTrackDirective(args []string) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	event, ok := unquoteDirectiveString(args[0])
+	if !ok || event == "" {
+		return "", false
+	}
+	var properties strings.Builder
+	for _, arg := range args[1:] {
+		key, value, ok := parseNamedDirectiveArg(arg)
+		if !ok {
+			return "", false
+		}
+		fmt.Fprintf(&properties, " %q (%s)", key, value)
+	}
+	return fmt.Sprintf(`{{ track %q%s }}`, event, properties.String()), true
+}
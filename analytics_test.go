@@ -0,0 +1,49 @@
+package blade
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTrack(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"pricing.blade": `<button @track('signup_click', plan: .Plan, seats: 3)>Sign up</button>` +
+			`<a {{ track "docs_click" }} href="/docs">Docs</a>` +
+			`<script>analytics.push({{ trackEvent "signup_click" "plan" .Plan }})</script>`,
+		"broken.blade":  `<button @track('signup_click')>Sign up</button>`,
+		"unknown.blade": `<button @track('newsletter')>Subscribe</button>`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.Analytics = AnalyticsSchema{
+		Properties: map[string]any{"app": "shop"},
+		Events: map[string]AnalyticsEvent{
+			"signup_click": {Required: []string{"plan"}, Properties: map[string]any{"category": "conversion"}},
+			"docs_click":   {},
+		},
+	}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "pricing", map[string]any{"Plan": "pro"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<button data-analytics-event="signup_click" data-analytics-properties="{&#34;app&#34;:&#34;shop&#34;,&#34;category&#34;:&#34;conversion&#34;,&#34;plan&#34;:&#34;pro&#34;,&#34;seats&#34;:3}">Sign up</button>` +
+		`<a data-analytics-event="docs_click" data-analytics-properties="{&#34;app&#34;:&#34;shop&#34;}" href="/docs">Docs</a>` +
+		`<script>analytics.push({"event":"signup_click","properties":{"app":"shop","category":"conversion","plan":"pro"}})</script>`
+	if buf.String() != expected {
+		t.Errorf("Track mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	for name, message := range map[string]string{
+		"broken":  `event "signup_click" is missing the required property "plan"`,
+		"unknown": `unknown event "newsletter"`,
+	} {
+		err := engine.Render(&bytes.Buffer{}, name, nil)
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Expected %q rendering %s, got %v", message, name, err)
+		}
+	}
+}
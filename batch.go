@@ -0,0 +1,81 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// RenderJob is a render of RenderBatch.
+type RenderJob struct {
+	Entry string
+	Data  any
+	// Context is the render context of the job, the batch context when nil
+	Context context.Context
+}
+
+// RenderResult is the output of a RenderJob.
+type RenderResult struct {
+	Output []byte
+	Err    error
+}
+
+// RenderBatch renders jobs concurrently, like the emails of a newsletter campaign, see RenderBatchContext.
+func (e *Engine) RenderBatch(jobs []RenderJob) ([]RenderResult, error) {
+	return e.RenderBatchContext(context.Background(), jobs)
+}
+
+// RenderBatchContext renders jobs with at most Engine.BatchWorkers concurrent renders, GOMAXPROCS when zero,
+// reusing pooled buffers. Results are in the order of jobs, the error joins the errors of the failed jobs.
+// Once ctx is done, the remaining jobs fail with its error.
+func (e *Engine) RenderBatchContext(ctx context.Context, jobs []RenderJob) ([]RenderResult, error) {
+	results := make([]RenderResult, len(jobs))
+	workers := e.BatchWorkers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(jobs))
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = e.renderJob(ctx, jobs[i])
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var errs []error
+	for i, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("job %d [%s]: %w", i, normalizeName(jobs[i].Entry), result.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+func (e *Engine) renderJob(ctx context.Context, job RenderJob) RenderResult {
+	if job.Context != nil {
+		ctx = job.Context
+	}
+	if err := ctx.Err(); err != nil {
+		return RenderResult{Err: err}
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := e.execute(ctx, buf, job.Entry, job.Data); err != nil {
+		return RenderResult{Err: err}
+	}
+	return RenderResult{Output: bytes.Clone(buf.Bytes())}
+}
@@ -0,0 +1,47 @@
+package blade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRenderBatch(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"email.blade": `Hello {{ .Name }}`,
+	}))
+	engine.BatchWorkers = 4
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	jobs := make([]RenderJob, 100)
+	for i := range jobs {
+		jobs[i] = RenderJob{Entry: "email", Data: map[string]any{"Name": fmt.Sprint("user", i)}}
+	}
+	jobs[42].Entry = "missing"
+	results, err := engine.RenderBatch(jobs)
+	if err == nil || !strings.Contains(err.Error(), "job 42 [missing]") {
+		t.Errorf("expected the error of job 42, got %v", err)
+	}
+	for i, result := range results {
+		if i == 42 {
+			if result.Err == nil {
+				t.Errorf("expected job 42 to fail")
+			}
+			continue
+		}
+		if expected := fmt.Sprint("Hello user", i); string(result.Output) != expected || result.Err != nil {
+			t.Errorf("job %d: expected %q, got %q, %v", i, expected, result.Output, result.Err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err = engine.RenderBatchContext(ctx, jobs[:3])
+	if !errors.Is(err, context.Canceled) || !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("expected the jobs to be canceled, got %v", err)
+	}
+}
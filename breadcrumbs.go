@@ -0,0 +1,30 @@
+package blade
+
+// Breadcrumb is an item of the built-in blade/breadcrumbs component, the current page has no URL.
+type Breadcrumb struct {
+	Name string
+	URL  string
+}
+
+// breadcrumbListJSON is the schema.org BreadcrumbList of breadcrumbs.
+type breadcrumbListJSON struct {
+	Context string               `json:"@context"`
+	Type    string               `json:"@type"`
+	Items   []breadcrumbItemJSON `json:"itemListElement"`
+}
+
+type breadcrumbItemJSON struct {
+	Type     string `json:"@type"`
+	Position int    `json:"position"`
+	Name     string `json:"name"`
+	Item     string `json:"item,omitempty"`
+}
+
+// breadcrumbList returns the schema.org BreadcrumbList of breadcrumbs, encoded to JSON-LD in a script element.
+func breadcrumbList(breadcrumbs []Breadcrumb) breadcrumbListJSON {
+	list := breadcrumbListJSON{Context: "https://schema.org", Type: "BreadcrumbList", Items: make([]breadcrumbItemJSON, len(breadcrumbs))}
+	for i, b := range breadcrumbs {
+		list.Items[i] = breadcrumbItemJSON{Type: "ListItem", Position: i + 1, Name: b.Name, Item: b.URL}
+	}
+	return list
+}
@@ -0,0 +1,45 @@
+package blade
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBreadcrumbsComponent(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"page.blade": `@include('blade/breadcrumbs', .Breadcrumbs)`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{"Breadcrumbs": []Breadcrumb{
+		{Name: "Home", URL: "/"},
+		{Name: "Books & Comics", URL: "/books"},
+		{Name: "</script>"},
+	}}
+	if err := engine.Render(&buf, "page", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<nav aria-label="Breadcrumb">
+<ol class="breadcrumbs">
+<li><a href="/">Home</a></li>
+<li><a href="/books">Books &amp; Comics</a></li>
+<li><span aria-current="page">&lt;/script&gt;</span></li>
+</ol>
+</nav>
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"BreadcrumbList","itemListElement":[{"@type":"ListItem","position":1,"name":"Home","item":"/"},{"@type":"ListItem","position":2,"name":"Books \u0026 Comics","item":"/books"},{"@type":"ListItem","position":3,"name":"\u003c/script\u003e"}]}</script>`
+	if buf.String() != expected {
+		t.Errorf("Breadcrumbs mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := engine.Render(&buf, "page", map[string]any{"Breadcrumbs": []Breadcrumb(nil)}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if buf.String() != "" {
+		t.Errorf("Expected no output without breadcrumbs, got %q", buf.String())
+	}
+}
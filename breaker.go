@@ -0,0 +1,130 @@
+package blade
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of BreakerOptions.
+const (
+	DefaultBreakerFailures = 5
+	DefaultBreakerWindow   = time.Minute
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// BreakerOptions configures the circuit breakers of the partials included with a fallback, like
+// @include('widgets/weather', .Weather, fallback: 'Weather unavailable'). A partial failing Failures times
+// within Window renders its fallback without being executed for Cooldown, then it is tried again: a success
+// closes the breaker, a failure opens it for another Cooldown. Failures before the breaker opens fail the render.
+type BreakerOptions struct {
+	// Failures is the number of failures opening the breaker, DefaultBreakerFailures when 0
+	Failures int
+	// Window is the period failures are counted in, DefaultBreakerWindow when 0
+	Window time.Duration
+	// Cooldown is how long an open breaker renders the fallback, DefaultBreakerCooldown when 0
+	Cooldown time.Duration
+	// OnOpen is called when the breaker of a partial opens, with the error opening it
+	OnOpen func(partial string, err error)
+}
+
+// breaker counts the recent failures of a partial.
+type breaker struct {
+	mu        sync.Mutex
+	failures  []time.Time
+	openUntil time.Time
+}
+
+// partialBreaker returns the breaker of the partial name.
+func (e *Engine) partialBreaker(name string) *breaker {
+	b, _ := e.breakers.LoadOrStore(name, &breaker{})
+	return b.(*breaker)
+}
+
+// allow reports whether the partial can render, false while the breaker is open.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !now.Before(b.openUntil)
+}
+
+// success closes a half-open breaker. The failures of a closed breaker are kept, so a partial failing
+// intermittently opens it once it failed Failures times within Window.
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return
+	}
+	b.failures = b.failures[:0]
+	b.openUntil = time.Time{}
+}
+
+// failure records a failure and reports whether it opened the breaker.
+func (b *breaker) failure(now time.Time, opts BreakerOptions) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	failures, window, cooldown := opts.Failures, opts.Window, opts.Cooldown
+	if failures <= 0 {
+		failures = DefaultBreakerFailures
+	}
+	if window <= 0 {
+		window = DefaultBreakerWindow
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+
+	// a half-open breaker failing again opens at once
+	halfOpen := !b.openUntil.IsZero()
+	recent := b.failures[:0]
+	for _, t := range b.failures {
+		if now.Sub(t) < window {
+			recent = append(recent, t)
+		}
+	}
+	b.failures = append(recent, now)
+	if !halfOpen && len(b.failures) < failures {
+		return false
+	}
+	b.failures = b.failures[:0]
+	b.openUntil = now.Add(cooldown)
+	return true
+}
+
+// guardedInclude converts an include with a fallback to a call rendering the partial through its breaker:
+// {{ $__guarded := __guardedInclude "__partial_weather" (.Weather) false }}
+// {{ if $__guarded.OK }}{{ $__guarded.Output }}{{ else }}fallback{{ end }}
+func (p *ParsedFile) guardedInclude(partialName string, pipeline string, memo bool, fallback string) string {
+	p.Includes[partialName] = struct{}{}
+	return fmt.Sprintf(`{{ $__guarded := __guardedInclude "%s%s" (%s) %t }}{{ if $__guarded.OK }}{{ $__guarded.Output }}{{ else }}%s{{ end }}`,
+		partialNamePrefix, partialName, pipeline, memo, fallback)
+}
+
+// guardedInclude renders the partial name unless its breaker is open. A failure of the partial opening the
+// breaker renders the fallback, other failures fail the render.
+func (s *renderState) guardedInclude(name string, data any, memo bool) (blockOutput, error) {
+	b := s.e.partialBreaker(strings.TrimPrefix(name, partialNamePrefix))
+	if !b.allow(time.Now()) {
+		return blockOutput{}, nil
+	}
+	var out any
+	var err error
+	if memo {
+		out, err = s.memoInclude(name, data)
+	} else {
+		out, err = s.executeDefine(name, data)
+	}
+	if err == nil {
+		b.success()
+		return blockOutput{OK: true, Output: out}, nil
+	}
+	if !b.failure(time.Now(), s.e.Breaker) {
+		return blockOutput{}, err
+	}
+	if s.e.Breaker.OnOpen != nil {
+		s.e.Breaker.OnOpen(strings.TrimPrefix(name, partialNamePrefix), err)
+	}
+	return blockOutput{}, nil
+}
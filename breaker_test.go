@@ -0,0 +1,91 @@
+package blade
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIncludeBreaker(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"widgets/weather.blade": `{{ forecast }}`,
+		"page.blade":            `<h1>Home</h1>@include('widgets/weather', ., fallback: 'Weather unavailable')`,
+	})
+	engine := NewEngineFS(mockFS)
+	failing := true
+	engine.FuncMap["forecast"] = func() (string, error) {
+		if failing {
+			return "", errors.New("weather service down")
+		}
+		return "Sunny", nil
+	}
+	var opened []string
+	engine.Breaker = BreakerOptions{Failures: 2, Cooldown: 50 * time.Millisecond, OnOpen: func(partial string, err error) {
+		opened = append(opened, partial)
+	}}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	render := func() (string, error) {
+		var buf bytes.Buffer
+		err := engine.Render(&buf, "page", nil)
+		return buf.String(), err
+	}
+	if _, err := render(); err == nil {
+		t.Fatal("Expected the first failure to fail the render")
+	}
+	for range 2 {
+		out, err := render()
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if out != "<h1>Home</h1>Weather unavailable" {
+			t.Errorf("Unexpected output: %s", out)
+		}
+	}
+	if len(opened) != 1 || opened[0] != "widgets/weather" {
+		t.Errorf("Unexpected opened breakers: %v", opened)
+	}
+
+	failing = false
+	time.Sleep(60 * time.Millisecond)
+	if out, err := render(); err != nil || out != "<h1>Home</h1>Sunny" {
+		t.Errorf("Expected the breaker to close after the cooldown, got %q %v", out, err)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := &breaker{}
+	opts := BreakerOptions{Failures: 3, Window: time.Second, Cooldown: time.Minute}
+	now := time.Now()
+	if b.failure(now, opts) || b.failure(now.Add(2*time.Second), opts) || b.failure(now.Add(2*time.Second), opts) {
+		t.Fatal("Expected failures outside of the window not to open the breaker")
+	}
+	if !b.failure(now.Add(2*time.Second), opts) || b.allow(now.Add(time.Minute)) {
+		t.Fatal("Expected the breaker to open")
+	}
+	if !b.allow(now.Add(2*time.Minute)) || !b.failure(now.Add(2*time.Minute), opts) {
+		t.Fatal("Expected a failure of the half-open breaker to open it again")
+	}
+}
+
+func TestBreakerIntermittentFailures(t *testing.T) {
+	b := &breaker{}
+	opts := BreakerOptions{Failures: 3, Window: time.Minute, Cooldown: time.Minute}
+	now := time.Now()
+	for i := range 2 {
+		if b.failure(now.Add(time.Duration(i)*time.Second), opts) {
+			t.Fatal("Expected the breaker to stay closed")
+		}
+		b.success()
+	}
+	if !b.failure(now.Add(3*time.Second), opts) {
+		t.Fatal("Expected failures within the window to open the breaker despite the successes between them")
+	}
+	b.success()
+	if !b.allow(now.Add(3*time.Second)) || b.failure(now.Add(4*time.Second), opts) {
+		t.Fatal("Expected a success of the half-open breaker to close it")
+	}
+}
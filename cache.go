@@ -0,0 +1,190 @@
+package blade
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// cacheVersion is the format version of the artifacts written by Export.
+const cacheVersion = 3
+
+// cacheArtifact is the gob encoded content of a blade.cache artifact.
+type cacheArtifact struct {
+	Version int
+	Files   []cachedFile
+	Views   []cachedView
+}
+
+// cachedView is the compiled template text of a view.
+type cachedView struct {
+	Name string
+	Text string
+	XML  bool
+}
+
+// cachedFile is a ParsedFile, sets are encoded as slices since gob cannot encode struct{} values.
+type cachedFile struct {
+	Name            string
+	Path            string
+	Raw             string
+	Extends         string
+	Includes        []string
+	Yields          map[string]string
+	Sections        map[string]string
+	SectionPolicies map[string]SectionPolicy
+	Stacks          []string
+	PushStacks      map[string][]StackPush
+	Snippets        map[string]string
+	Macros          map[string]string
+	Imports         []string
+	Deferred        map[string]string
+	Params          []Param
+	StandaloneBody  string
+	XML             bool
+	ParsedAt        int64
+}
+
+// Export writes the compiled template texts of the loaded views and the parsed files they depend on to w,
+// as an artifact that LoadCache reads instead of parsing the Blade sources, like a blade.cache file built
+// at deploy time with "blade export". It fails when views failed to compile in DevMode.
+func (e *Engine) Export(w io.Writer) error {
+	set := e.set.Load()
+	if len(set.brokenFiles) > 0 {
+		return errors.New("export: templates failed to parse")
+	}
+	artifact := cacheArtifact{Version: cacheVersion}
+	for _, name := range sortedKeys(set.parsedFiles) {
+		artifact.Files = append(artifact.Files, toCachedFile(set.parsedFiles[name]))
+	}
+	for _, name := range sortedKeys(set.templates) {
+		if err := set.templates[name].err; err != nil {
+			return fmt.Errorf("export: [%s] %w", name, err)
+		}
+		f := set.parsedFiles[name]
+		text, err := e.buildTemplateText(set.parsedFiles, f)
+		if err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+		artifact.Views = append(artifact.Views, cachedView{Name: name, Text: text, XML: f.XML})
+	}
+	if err := gob.NewEncoder(w).Encode(artifact); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	return nil
+}
+
+// LoadCache loads the views of an artifact written by Export, in place of Load. The views are compiled from
+// their template texts, so Engine.FuncMap must hold the funcs of the exporting engine. The engine fs is not
+// read, a later Load only reads the files modified after LoadCache.
+func (e *Engine) LoadCache(r io.Reader) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	err := e.loadCache(r)
+	e.setLoadError(err)
+	return err
+}
+
+func (e *Engine) loadCache(r io.Reader) error {
+	startedAt := time.Now().UnixMilli()
+	var artifact cacheArtifact
+	if err := gob.NewDecoder(r).Decode(&artifact); err != nil {
+		return fmt.Errorf("load cache: %w", err)
+	}
+	if artifact.Version != cacheVersion {
+		return fmt.Errorf("load cache: unsupported version %d", artifact.Version)
+	}
+
+	set := newCompiledSet()
+	for _, f := range artifact.Files {
+		set.parsedFiles[f.Name] = f.parsedFile()
+	}
+	set.warnings = e.compositionWarnings(set.parsedFiles)
+	for _, view := range artifact.Views {
+		if !e.DisableDebugTemplates {
+			set.debugTemplates[view.Name] = view.Text
+		}
+		tmpl, err := e.compileTemplate(view.Name, view.Text, view.XML)
+		if err != nil {
+			return fmt.Errorf("load cache: %w", err)
+		}
+		tmpl.textSize = len(view.Text)
+		set.templates[view.Name] = tmpl
+		if f, ok := set.parsedFiles[view.Name]; ok {
+			set.warnings = append(set.warnings, e.paramWarnings(set.parsedFiles, f, tmpl.proto)...)
+		}
+	}
+
+	e.storeGeneration(set)
+	e.manifest = nil
+	e.lastCompileTime = startedAt - 1
+	return nil
+}
+
+func toCachedFile(f *ParsedFile) cachedFile {
+	return cachedFile{
+		Name:            f.Name,
+		Path:            f.Path,
+		Raw:             f.Raw,
+		Extends:         f.Extends,
+		Includes:        sortedKeys(f.Includes),
+		Yields:          f.Yields,
+		Sections:        f.Sections,
+		SectionPolicies: f.SectionPolicies,
+		Stacks:          sortedKeys(f.Stacks),
+		PushStacks:      f.PushStacks,
+		Snippets:        f.Snippets,
+		Macros:          f.Macros,
+		Imports:         sortedKeys(f.Imports),
+		Deferred:        f.Deferred,
+		Params:          f.Params,
+		StandaloneBody:  f.StandaloneBody,
+		XML:             f.XML,
+		ParsedAt:        f.ParsedAt,
+	}
+}
+
+func (c cachedFile) parsedFile() *ParsedFile {
+	toSet := func(names []string) map[string]struct{} {
+		set := make(map[string]struct{}, len(names))
+		for _, name := range names {
+			set[name] = struct{}{}
+		}
+		return set
+	}
+	orEmpty := func(m map[string]string) map[string]string {
+		if m == nil {
+			return map[string]string{}
+		}
+		return m
+	}
+	pushStacks := c.PushStacks
+	if pushStacks == nil {
+		pushStacks = map[string][]StackPush{}
+	}
+	return &ParsedFile{
+		Name:            c.Name,
+		Path:            c.Path,
+		Raw:             c.Raw,
+		Extends:         c.Extends,
+		Includes:        toSet(c.Includes),
+		Yields:          orEmpty(c.Yields),
+		Sections:        orEmpty(c.Sections),
+		SectionPolicies: c.SectionPolicies,
+		Stacks:          toSet(c.Stacks),
+		PushStacks:      pushStacks,
+		Snippets:        orEmpty(c.Snippets),
+		Macros:          orEmpty(c.Macros),
+		Imports:         toSet(c.Imports),
+		Deferred:        orEmpty(c.Deferred),
+		Params:          slices.Clone(c.Params),
+		Directives:      locateDirectives(c.Raw),
+		StandaloneBody:  c.StandaloneBody,
+		XML:             c.XML,
+		ParsedAt:        c.ParsedAt,
+	}
+}
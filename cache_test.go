@@ -0,0 +1,66 @@
+package blade
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExportLoadCache(t *testing.T) {
+	source := NewEngineFS(createMockFS(map[string]string{
+		"layout.blade":        `<title>@yield('title')</title>@stack('scripts')@include('partials/nav')@yield('content')`,
+		"partials/nav.blade":  `<nav>{{ shout "nav" }}</nav>`,
+		"home.blade":          "@extends('layout')@section('title', 'Home')@section('content')@call('badge', \"new\")@endsection@push('scripts')<script></script>@endpush@macro('badge', 'label')<b>{{ $label }}</b>@endmacro",
+		"feed.xml":            `<feed>{{ .Title }}</feed>`,
+		"partials/logo.blade": `Logo`,
+	}))
+	shout := func(s string) string { return s + "!" }
+	source.FuncMap["shout"] = shout
+	if err := source.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var artifact bytes.Buffer
+	if err := source.Export(&artifact); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	cached := NewEngineFS(fstest.MapFS{})
+	cached.FuncMap["shout"] = shout
+	if err := cached.LoadCache(bytes.NewReader(artifact.Bytes())); err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	if err := cached.Ready(); err != nil {
+		t.Errorf("expected the engine to be ready, got %v", err)
+	}
+	for _, entry := range []string{"home", "feed"} {
+		var want, got bytes.Buffer
+		data := map[string]any{"Title": "A & B"}
+		if err := source.Render(&want, entry, data); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if err := cached.Render(&got, entry, data); err != nil {
+			t.Fatalf("Render from cache failed: %v", err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("Render %s from cache mismatch.\nExp: %s\nGot: %s", entry, want.String(), got.String())
+		}
+	}
+	info, err := cached.Describe("home")
+	if err != nil || len(info.Layouts) != 1 || info.Layouts[0] != "layout" {
+		t.Errorf("expected the dependency graph to be restored, got %+v, %v", info, err)
+	}
+	// a Load of the empty fs keeps the cached views
+	if err := cached.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, ok := cached.set.Load().templates["home"]; !ok {
+		t.Errorf("expected Load to keep the cached views")
+	}
+
+	var old bytes.Buffer
+	gob.NewEncoder(&old).Encode(cacheArtifact{Version: 0})
+	if err := NewEngineFS(fstest.MapFS{}).LoadCache(&old); err == nil {
+		t.Errorf("expected an error for an unsupported version")
+	}
+}
@@ -0,0 +1,46 @@
+package blade
+
+import (
+	"errors"
+	"fmt"
+)
+
+// captureSnippetPrefix prefixes the snippet names holding the content of @capture blocks.
+const captureSnippetPrefix = "capture:"
+
+// parseCaptureBlock records the content of a @capture block as a snippet and returns the assignment of its
+// rendered content: @capture('banner') ... @endcapture => {{ $banner := __capture "banner" "__snippet_file:capture:banner" . }}
+func (p *ParsedFile) parseCaptureBlock(args []string, content string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("invalid @capture directive")
+	}
+	name, ok := parseQuotedDirectiveName(args[0])
+	if !ok || !isIdentifier(name) {
+		return "", fmt.Errorf("invalid @capture name %s", args[0])
+	}
+	snippetName := captureSnippetPrefix + name
+	if _, ok := p.Snippets[snippetName]; ok {
+		return "", fmt.Errorf(`duplicate @capture name "%s"`, name)
+	}
+	p.Snippets[snippetName] = content
+	return fmt.Sprintf(`{{ $%s := __capture %q %q . }}`, name, name, snippetTemplateName(p.Name, snippetName)), nil
+}
+
+// capture renders the @capture block define with data and keeps its output for captured.
+func (s *renderState) capture(name string, define string, data any) (any, error) {
+	if s.tmpl == nil {
+		return nil, errors.New("capture: block rendered outside of a render")
+	}
+	out, err := s.executeDefine(define, data)
+	if err != nil {
+		return nil, err
+	}
+	s.captures[name] = out
+	return out, nil
+}
+
+// captured returns the output of the @capture block name rendered earlier in the render, like in a layout
+// rendering content captured by a view, or nil when it was not rendered.
+func (s *renderState) captured(name string) any {
+	return s.captures[name]
+}
@@ -0,0 +1,36 @@
+package blade
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCapture(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `<header>{{ captured "banner" }}</header>@yield('content')<footer>{{ captured "banner" }}{{ captured "missing" }}</footer>`,
+		"page.blade": `@extends('layout')
+@section('content')@capture('banner')<strong>{{ .Title }}</strong>@endcapture{{ if gt (len $banner) 20 }}long{{ else }}short{{ end }}:{{ $banner }}{{ $banner }}@endsection`,
+		"list.blade": `{{ range .Items }}@capture('item')<li>{{ . }}</li>@endcapture{{ $item }}{{ end }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", map[string]string{"Title": "Tom & Jerry"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<header></header>long:<strong>Tom &amp; Jerry</strong><strong>Tom &amp; Jerry</strong><footer><strong>Tom &amp; Jerry</strong></footer>`
+	if buf.String() != expected {
+		t.Errorf("Capture mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := engine.Render(&buf, "list", map[string][]string{"Items": {"a", "<b>"}}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `<li>a</li><li>&lt;b&gt;</li>`; buf.String() != expected {
+		t.Errorf("Capture in range mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
@@ -0,0 +1,313 @@
+// Command blade checks go-blade templates and generates typed render functions.
+//
+// Usage:
+//
+//	blade check [-json] [-funcs name,...] [dir]
+//	blade audit [-json] [-funcs name,...] [dir]
+//	blade gen [-pkg name] [-o file] [-import name=path ...] [dir]
+//	blade export [-o file] [-funcs name,...] [dir]
+//	blade export -site site.json [-o dir] [-funcs name,...] [dir]
+//
+// check prints the diagnostics of the templates in dir (default "."), with -json as an array of
+// LSP-style diagnostics for editor integrations. Functions registered by the application in
+// Engine.FuncMap are declared with -funcs. It exits with status 1 when an error is found.
+//
+// audit prints the actions printing values html/template does not escape, like the template.HTML returned
+// by nl2br, for security reviews. Functions declared with -funcs are not known to return such values, use
+// Engine.EscapeReport with the application funcs and @param types for a complete report.
+//
+// gen writes a Go file with a typed render function for every view declaring @param, like
+// RenderPagesHome(w io.Writer, user models.User) error. The import paths of the packages used in
+// @param types are given with -import, like -import models=example.com/app/models.
+//
+// export compiles the templates in dir and writes an artifact (default blade.cache) that production
+// instances load with Engine.LoadCache instead of parsing the templates. With -site, it renders the pages
+// of a site configuration to static HTML files in the -o directory (default public) instead:
+//
+//	{
+//	  "assets": "static", "assetsDir": "static",
+//	  "pages": [
+//	    {"path": "index.html", "entry": "pages/home", "data": {"title": "Home"}},
+//	    {"path": "blog/{slug}/index.html", "entry": "blog/post", "dataFile": "content/posts.json"}
+//	  ]
+//	}
+//
+// A page with a dataFile holding a JSON array is exported once per item, {key} in its path is replaced by the
+// value of the key of the item. The assets directory is copied to assetsDir in the output directory. With a
+// "baseURL", like "https://example.com", a sitemap.xml of the pages is written too.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	blade "github.com/dangdungcntt/go-blade"
+)
+
+const usage = `usage:
+  blade check [-json] [-funcs name,...] [dir]
+  blade audit [-json] [-funcs name,...] [dir]
+  blade gen [-pkg name] [-o file] [-import name=path ...] [dir]
+  blade export [-o file] [-funcs name,...] [dir]
+  blade export -site site.json [-o dir] [-funcs name,...] [dir]`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "check":
+		os.Exit(check(os.Args[2:]))
+	case "audit":
+		os.Exit(audit(os.Args[2:]))
+	case "gen":
+		os.Exit(gen(os.Args[2:]))
+	case "export":
+		os.Exit(export(os.Args[2:]))
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func check(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print diagnostics as JSON")
+	funcs := flags.String("funcs", "", "comma separated names of functions registered by the application")
+	_ = flags.Parse(args)
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	eng := blade.NewEngine(dir)
+	declareFuncs(eng, *funcs)
+
+	diagnostics, err := eng.Diagnose()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if *asJSON {
+		if diagnostics == nil {
+			diagnostics = []blade.Diagnostic{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diagnostics); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	} else {
+		for _, d := range diagnostics {
+			fmt.Println(d)
+		}
+	}
+
+	if slices.ContainsFunc(diagnostics, func(d blade.Diagnostic) bool { return d.Severity == blade.SeverityError }) {
+		return 1
+	}
+	return 0
+}
+
+func audit(args []string) int {
+	flags := flag.NewFlagSet("audit", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	funcs := flags.String("funcs", "", "comma separated names of functions registered by the application")
+	_ = flags.Parse(args)
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	eng := blade.NewEngine(dir)
+	declareFuncs(eng, *funcs)
+	if err := eng.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	report := eng.EscapeReport()
+	if *asJSON {
+		if report == nil {
+			report = []blade.UnescapedOutput{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		return 0
+	}
+	for _, output := range report {
+		fmt.Println(output)
+	}
+	return 0
+}
+
+func gen(args []string) int {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	pkg := flags.String("pkg", "views", "package name of the generated file")
+	out := flags.String("o", "", "output file, stdout when empty")
+	imports := map[string]string{}
+	flags.Func("import", "import path of a package used in @param types, as name=path", func(value string) error {
+		name, path, ok := strings.Cut(value, "=")
+		if !ok || name == "" || path == "" {
+			return fmt.Errorf("expected name=path, got %q", value)
+		}
+		imports[name] = path
+		return nil
+	})
+	_ = flags.Parse(args)
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	src, err := blade.NewEngine(dir).GenerateRenderers(blade.CodegenOptions{Package: *pkg, Imports: imports})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *out == "" {
+		_, _ = os.Stdout.Write(src)
+		return 0
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func export(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	out := flags.String("o", "", "output file, blade.cache by default, or output directory with -site, public by default")
+	site := flags.String("site", "", "site configuration of a static site export")
+	funcs := flags.String("funcs", "", "comma separated names of functions registered by the application")
+	_ = flags.Parse(args)
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	eng := blade.NewEngine(dir)
+	declareFuncs(eng, *funcs)
+	if err := eng.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *site != "" {
+		if *out == "" {
+			*out = "public"
+		}
+		return exportSite(eng, *site, *out)
+	}
+	if *out == "" {
+		*out = "blade.cache"
+	}
+	var buf bytes.Buffer
+	if err := eng.Export(&buf); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// siteConfig is the site configuration of export -site.
+type siteConfig struct {
+	Assets    string `json:"assets"`
+	AssetsDir string `json:"assetsDir"`
+	BaseURL   string `json:"baseURL"`
+	Pages     []struct {
+		Path     string `json:"path"`
+		Entry    string `json:"entry"`
+		Data     any    `json:"data"`
+		DataFile string `json:"dataFile"`
+	} `json:"pages"`
+}
+
+var rePathKey = regexp.MustCompile(`\{(\w+)\}`)
+
+func exportSite(eng *blade.Engine, configFile string, dir string) int {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var config siteConfig
+	if err := json.Unmarshal(content, &config); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", configFile, err)
+		return 1
+	}
+
+	opts := blade.SiteOptions{Dir: dir, AssetsDir: config.AssetsDir, BaseURL: config.BaseURL}
+	if config.Assets != "" {
+		opts.Assets = os.DirFS(config.Assets)
+	}
+	for _, page := range config.Pages {
+		if page.DataFile == "" {
+			opts.Pages = append(opts.Pages, blade.Page{Path: page.Path, Entry: page.Entry, Data: page.Data})
+			continue
+		}
+		content, err := os.ReadFile(page.DataFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		var data any
+		if err := json.Unmarshal(content, &data); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", page.DataFile, err)
+			return 1
+		}
+		items, ok := data.([]any)
+		if !ok {
+			items = []any{data}
+		}
+		for _, item := range items {
+			fields, _ := item.(map[string]any)
+			pagePath := rePathKey.ReplaceAllStringFunc(page.Path, func(key string) string {
+				return fmt.Sprint(fields[key[1:len(key)-1]])
+			})
+			opts.Pages = append(opts.Pages, blade.Page{Path: pagePath, Entry: page.Entry, Data: item})
+		}
+	}
+
+	written, err := eng.ExportSite(context.Background(), opts)
+	for _, name := range written {
+		fmt.Println(filepath.Join(dir, name))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// declareFuncs registers placeholders for the comma separated names of functions registered by the application.
+func declareFuncs(eng *blade.Engine, names string) {
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			eng.FuncMap[name] = func(...any) any { return nil }
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package blade
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CodegenOptions configures GenerateRenderers.
+type CodegenOptions struct {
+	// Package is the package name of the generated file
+	Package string
+	// Imports maps the package qualifiers used in @param types to import paths, like "models" to "example.com/app/models"
+	Imports map[string]string
+}
+
+// reTypeQualifier matches the package qualifiers of a Go type expression, like models in []*models.User.
+var reTypeQualifier = regexp.MustCompile(`\b([A-Za-z_]\w*)\.[A-Za-z_]`)
+
+// GenerateRenderers returns the Go source of a typed render function for every view declaring @param,
+// like RenderPagesHome(w io.Writer, user models.User) error wrapping Engine.Render with
+// map[string]any{"User": user}. The functions render with the package level Engine variable of the generated file.
+func (e *Engine) GenerateRenderers(opts CodegenOptions) ([]byte, error) {
+	var parseErr error
+	files, err := e.parseAll(func(path string, raw string, err error) {
+		if parseErr == nil {
+			parseErr = err
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "views"
+	}
+
+	imports := map[string]string{"blade": "github.com/dangdungcntt/go-blade"}
+	var funcs bytes.Buffer
+	funcNames := map[string]string{}
+	for _, name := range sortedKeys(files) {
+		f := files[name]
+		if !e.EntryFilter(f) {
+			continue
+		}
+		params := entryParams(files, f)
+		if len(params) == 0 {
+			continue
+		}
+
+		funcName := "Render" + exportedIdentifier(name)
+		if other, ok := funcNames[funcName]; ok {
+			return nil, fmt.Errorf(`[%s] render function %s already generated for "%s"`, name, funcName, other)
+		}
+		funcNames[funcName] = name
+		imports["io"] = "io"
+
+		var args, fields []string
+		for _, param := range params {
+			for _, m := range reTypeQualifier.FindAllStringSubmatch(param.Type, -1) {
+				path, ok := opts.Imports[m[1]]
+				if !ok {
+					return nil, fmt.Errorf(`[%s] unknown package "%s" in type %s of param %s`, name, m[1], param.Type, param.Name)
+				}
+				imports[m[1]] = path
+			}
+			argName := paramArgName(param.Name)
+			if _, ok := opts.Imports[argName]; ok {
+				argName += "Param"
+			}
+			args = append(args, argName+" "+param.Type)
+			fields = append(fields, fmt.Sprintf("%q: %s", param.Name, argName))
+		}
+
+		fmt.Fprintf(&funcs, "\n// %s renders the %s view.\n", funcName, name)
+		fmt.Fprintf(&funcs, "func %s(w io.Writer, %s) error {\n", funcName, strings.Join(args, ", "))
+		fmt.Fprintf(&funcs, "\treturn Engine.Render(w, %q, map[string]any{%s})\n}\n", name, strings.Join(fields, ", "))
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by blade gen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	for _, qualifier := range sortedKeys(imports) {
+		path := imports[qualifier]
+		if path == qualifier || strings.HasSuffix(path, "/"+qualifier) {
+			fmt.Fprintf(&buf, "\t%s\n", strconv.Quote(path))
+		} else {
+			fmt.Fprintf(&buf, "\t%s %s\n", qualifier, strconv.Quote(path))
+		}
+	}
+	buf.WriteString(")\n\n// Engine renders the generated functions, it must be set before rendering.\nvar Engine *blade.Engine\n")
+	buf.Write(funcs.Bytes())
+
+	// format also sorts the imports
+	return format.Source(buf.Bytes())
+}
+
+// exportedIdentifier converts a view name like pages/user-profile to PagesUserProfile.
+func exportedIdentifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// paramArgName converts a param name like UserID to an argument name like userID.
+func paramArgName(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		// lower the leading upper case run, keeping the last letter of an acronym followed by a word
+		if !unicode.IsUpper(runes[i]) || (i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	arg := string(runes)
+	if token.IsKeyword(arg) || arg == "w" || arg == "blade" || arg == "io" || arg == "Engine" {
+		arg += "Param"
+	}
+	return arg
+}
@@ -0,0 +1,65 @@
+package blade
+
+import "testing"
+
+func TestGenerateRenderers(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layouts/app.blade":        "@param('Title', 'string')@yield('content')",
+		"pages/user-profile.blade": "@extends('layouts/app')@param('User', '*models.User')@param('Items', '[]Item')@section('content'){{ .User.Name }}@endsection",
+		"pages/about.blade":        "@extends('layouts/app')@section('content')About@endsection",
+		"_partials/nav.blade":      "@param('Links', '[]string')",
+	})
+	engine := NewEngineFS(mockFS)
+
+	src, err := engine.GenerateRenderers(CodegenOptions{
+		Package: "views",
+		Imports: map[string]string{"models": "example.com/app/models"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateRenderers failed: %v", err)
+	}
+
+	expected := `// Code generated by blade gen. DO NOT EDIT.
+
+package views
+
+import (
+	"example.com/app/models"
+	blade "github.com/dangdungcntt/go-blade"
+	"io"
+)
+
+// Engine renders the generated functions, it must be set before rendering.
+var Engine *blade.Engine
+
+// RenderLayoutsApp renders the layouts/app view.
+func RenderLayoutsApp(w io.Writer, title string) error {
+	return Engine.Render(w, "layouts/app", map[string]any{"Title": title})
+}
+
+// RenderPagesAbout renders the pages/about view.
+func RenderPagesAbout(w io.Writer, title string) error {
+	return Engine.Render(w, "pages/about", map[string]any{"Title": title})
+}
+
+// RenderPagesUserProfile renders the pages/user-profile view.
+func RenderPagesUserProfile(w io.Writer, user *models.User, items []Item, title string) error {
+	return Engine.Render(w, "pages/user-profile", map[string]any{"User": user, "Items": items, "Title": title})
+}
+`
+	if string(src) != expected {
+		t.Errorf("Source mismatch.\nExp:\n%s\nGot:\n%s", expected, src)
+	}
+
+	if _, err := engine.GenerateRenderers(CodegenOptions{}); err == nil {
+		t.Errorf("expected an error for an unknown package")
+	}
+}
+
+func TestParamArgName(t *testing.T) {
+	for name, expected := range map[string]string{"User": "user", "UserID": "userID", "ID": "id", "HTMLTitle": "htmlTitle", "Type": "typeParam"} {
+		if got := paramArgName(name); got != expected {
+			t.Errorf("paramArgName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
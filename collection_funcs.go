@@ -0,0 +1,190 @@
+package blade
+
+import (
+	"cmp"
+	"fmt"
+	"html/template"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Group is a group of items returned by the groupBy helper.
+type Group struct {
+	Key   any
+	Items []any
+}
+
+// collectionItems returns the items of a slice or array, nil returns no items.
+func collectionItems(name string, list any) ([]any, error) {
+	rv := indirectValue(reflect.ValueOf(list))
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%s: expected a slice, got %T", name, list)
+	}
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// itemValue returns the value of the dotted path of fields, map keys or methods of item, like "Customer.Name".
+func itemValue(item any, path string) any {
+	return optional(item, strings.Split(path, ".")...)
+}
+
+// where returns the items whose value at path equals value, or is not empty when value is omitted:
+// where .Orders "Status" "paid", where .Users "Active".
+func where(list any, path string, value ...any) ([]any, error) {
+	if len(value) > 1 {
+		return nil, fmt.Errorf("where: expected at most one value, got %d", len(value))
+	}
+	items, err := collectionItems("where", list)
+	if err != nil {
+		return nil, err
+	}
+	matched := []any{}
+	for _, item := range items {
+		v := itemValue(item, path)
+		if len(value) == 0 {
+			if truth, _ := template.IsTrue(v); truth {
+				matched = append(matched, item)
+			}
+		} else if valuesEqual(v, value[0]) {
+			matched = append(matched, item)
+		}
+	}
+	return matched, nil
+}
+
+// sortBy returns the items sorted by their value at path, in descending order with "desc":
+// sortBy .Products "Price" "desc". The sort is stable.
+func sortBy(list any, path string, order ...string) ([]any, error) {
+	items, err := collectionItems("sortBy", list)
+	if err != nil {
+		return nil, err
+	}
+	desc := false
+	if len(order) > 0 {
+		switch order[0] {
+		case "asc":
+		case "desc":
+			desc = true
+		default:
+			return nil, fmt.Errorf(`sortBy: unknown order %q, expected "asc" or "desc"`, order[0])
+		}
+	}
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b any) int {
+		c := compareValues(itemValue(a, path), itemValue(b, path))
+		if desc {
+			return -c
+		}
+		return c
+	})
+	return sorted, nil
+}
+
+// groupBy groups the items by their value at path, groups are in the order their key first appears:
+// {{ range groupBy .Orders "Date" }}{{ .Key }}: {{ len .Items }}{{ end }}.
+func groupBy(list any, path string) ([]Group, error) {
+	items, err := collectionItems("groupBy", list)
+	if err != nil {
+		return nil, err
+	}
+	groups := []Group{}
+	for _, item := range items {
+		key := itemValue(item, path)
+		i := slices.IndexFunc(groups, func(g Group) bool { return valuesEqual(g.Key, key) })
+		if i == -1 {
+			groups = append(groups, Group{Key: key})
+			i = len(groups) - 1
+		}
+		groups[i].Items = append(groups[i].Items, item)
+	}
+	return groups, nil
+}
+
+// pluck returns the values at path of the items: pluck .Users "Email".
+func pluck(list any, path string) ([]any, error) {
+	items, err := collectionItems("pluck", list)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(items))
+	for i, item := range items {
+		values[i] = itemValue(item, path)
+	}
+	return values, nil
+}
+
+// chunk splits the items into rows of size items, the last row may be shorter: chunk .Cards 3.
+func chunk(list any, size any) ([][]any, error) {
+	n, err := toInt(size)
+	if err != nil {
+		return nil, fmt.Errorf("chunk: %w", err)
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("chunk: size must be positive, got %d", n)
+	}
+	items, err := collectionItems("chunk", list)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Collect(slices.Chunk(items, n)), nil
+}
+
+// valuesEqual reports whether a and b are equal, comparing numbers by value and strings of named string
+// types by content.
+func valuesEqual(a any, b any) bool {
+	if isNumberValue(a) && isNumberValue(b) {
+		c, _ := compare(a, b)
+		return c == 0
+	}
+	av, bv := indirectValue(reflect.ValueOf(a)), indirectValue(reflect.ValueOf(b))
+	if av.Kind() == reflect.String && bv.Kind() == reflect.String {
+		return av.String() == bv.String()
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compareValues orders numbers by value, times chronologically and other values by their string form.
+// Missing values sort first.
+func compareValues(a any, b any) int {
+	switch {
+	case a == nil || b == nil:
+		return cmp.Compare(boolRank(a != nil), boolRank(b != nil))
+	case isNumberValue(a) && isNumberValue(b):
+		c, _ := compare(a, b)
+		return c
+	}
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// isNumberValue reports whether v is an integer or a float, numeric strings are not numbers.
+func isNumberValue(v any) bool {
+	switch indirectValue(reflect.ValueOf(v)).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// boolRank returns 1 for true and 0 for false.
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
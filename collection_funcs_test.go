@@ -0,0 +1,105 @@
+package blade
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type collectionStatus string
+
+type collectionOrder struct {
+	ID       int
+	Status   collectionStatus
+	Total    float64
+	Customer *optionalProfile
+	Date     time.Time
+}
+
+func TestCollectionFuncs(t *testing.T) {
+	day := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	orders := []collectionOrder{
+		{ID: 1, Status: "paid", Total: 30, Customer: &optionalProfile{Name: "Ann"}, Date: day},
+		{ID: 2, Status: "open", Total: 10, Date: day.AddDate(0, 0, 1)},
+		{ID: 3, Status: "paid", Total: 20, Customer: &optionalProfile{Name: "Bob"}, Date: day},
+	}
+	ids := func(items []any) []int {
+		var result []int
+		for _, item := range items {
+			result = append(result, item.(collectionOrder).ID)
+		}
+		return result
+	}
+
+	paid, err := where(orders, "Status", "paid")
+	if err != nil || !reflect.DeepEqual(ids(paid), []int{1, 3}) {
+		t.Errorf("where mismatch: %v, %v", ids(paid), err)
+	}
+	withCustomer, err := where(orders, "Customer.Name")
+	if err != nil || !reflect.DeepEqual(ids(withCustomer), []int{1, 3}) {
+		t.Errorf("where truthy mismatch: %v, %v", ids(withCustomer), err)
+	}
+	byTotal, err := where(orders, "Total", 20)
+	if err != nil || !reflect.DeepEqual(ids(byTotal), []int{3}) {
+		t.Errorf("where number mismatch: %v, %v", ids(byTotal), err)
+	}
+
+	sorted, err := sortBy(orders, "Total")
+	if err != nil || !reflect.DeepEqual(ids(sorted), []int{2, 3, 1}) {
+		t.Errorf("sortBy mismatch: %v, %v", ids(sorted), err)
+	}
+	sorted, err = sortBy(orders, "Date", "desc")
+	if err != nil || !reflect.DeepEqual(ids(sorted), []int{2, 1, 3}) {
+		t.Errorf("sortBy desc mismatch: %v, %v", ids(sorted), err)
+	}
+	sorted, err = sortBy(orders, "Customer.Name")
+	if err != nil || !reflect.DeepEqual(ids(sorted), []int{2, 1, 3}) {
+		t.Errorf("sortBy missing values mismatch: %v, %v", ids(sorted), err)
+	}
+
+	groups, err := groupBy(orders, "Date")
+	if err != nil || len(groups) != 2 || groups[0].Key != day || !reflect.DeepEqual(ids(groups[0].Items), []int{1, 3}) {
+		t.Errorf("groupBy mismatch: %+v, %v", groups, err)
+	}
+
+	names, err := pluck(orders, "Customer.Name")
+	if err != nil || !reflect.DeepEqual(names, []any{"Ann", nil, "Bob"}) {
+		t.Errorf("pluck mismatch: %v, %v", names, err)
+	}
+
+	rows, err := chunk([]int{1, 2, 3, 4, 5}, 2)
+	if err != nil || !reflect.DeepEqual(rows, [][]any{{1, 2}, {3, 4}, {5}}) {
+		t.Errorf("chunk mismatch: %v, %v", rows, err)
+	}
+
+	if _, err := where("orders", "Status", "paid"); err == nil {
+		t.Error("expected an error for a value that is not a slice")
+	}
+	if _, err := chunk(orders, 0); err == nil {
+		t.Error("expected an error for a chunk size of 0")
+	}
+	if _, err := sortBy(orders, "Total", "up"); err == nil {
+		t.Error("expected an error for an unknown order")
+	}
+}
+
+func TestCollectionFuncsInTemplates(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"orders.blade": `{{ range groupBy (sortBy .Orders "ID" "desc") "Status" }}{{ .Key }}:{{ range .Items }}{{ .ID }}{{ end }} {{ end }}` +
+			`|{{ range chunk (pluck .Orders "ID") 2 }}[{{ range . }}{{ . }}{{ end }}]{{ end }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	orders := []collectionOrder{{ID: 1, Status: "paid"}, {ID: 2, Status: "open"}, {ID: 3, Status: "paid"}}
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "orders", map[string]any{"Orders": orders}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := "paid:31 open:2 |[12][3]"; buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
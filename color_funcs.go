@@ -0,0 +1,143 @@
+package blade
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// rgb is a color with channels from 0 to 1.
+type rgb struct {
+	r, g, b float64
+}
+
+// parseColor parses hex colors like #0af or #00aaff, the # is optional.
+func parseColor(v any) (rgb, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(fmt.Sprint(v)), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	n, err := strconv.ParseUint(s, 16, 32)
+	if len(s) != 6 || err != nil {
+		return rgb{}, fmt.Errorf("invalid color %q, expected #rgb or #rrggbb", fmt.Sprint(v))
+	}
+	return rgb{float64(n>>16) / 255, float64(n>>8&0xff) / 255, float64(n&0xff) / 255}, nil
+}
+
+// String returns the color as #rrggbb.
+func (c rgb) String() string {
+	channel := func(f float64) int { return int(math.Round(math.Max(0, math.Min(1, f)) * 255)) }
+	return fmt.Sprintf("#%02x%02x%02x", channel(c.r), channel(c.g), channel(c.b))
+}
+
+// hsl returns the hue from 0 to 360, the saturation and the lightness of the color.
+func (c rgb) hsl() (h, s, l float64) {
+	high, low := max(c.r, c.g, c.b), min(c.r, c.g, c.b)
+	l = (high + low) / 2
+	if high == low {
+		return 0, 0, l
+	}
+	d := high - low
+	if l > 0.5 {
+		s = d / (2 - high - low)
+	} else {
+		s = d / (high + low)
+	}
+	switch high {
+	case c.r:
+		h = math.Mod((c.g-c.b)/d+6, 6)
+	case c.g:
+		h = (c.b-c.r)/d + 2
+	default:
+		h = (c.r-c.g)/d + 4
+	}
+	return h * 60, s, l
+}
+
+// fromHSL returns the color of a hue from 0 to 360, a saturation and a lightness.
+func fromHSL(h, s, l float64) rgb {
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - chroma/2
+	var c rgb
+	switch {
+	case h < 60:
+		c = rgb{chroma, x, 0}
+	case h < 120:
+		c = rgb{x, chroma, 0}
+	case h < 180:
+		c = rgb{0, chroma, x}
+	case h < 240:
+		c = rgb{0, x, chroma}
+	case h < 300:
+		c = rgb{x, 0, chroma}
+	default:
+		c = rgb{chroma, 0, x}
+	}
+	return rgb{c.r + m, c.g + m, c.b + m}
+}
+
+// luminance returns the relative luminance of the color, as defined by WCAG.
+func (c rgb) luminance() float64 {
+	linear := func(f float64) float64 {
+		if f <= 0.04045 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(c.r) + 0.7152*linear(c.g) + 0.0722*linear(c.b)
+}
+
+// contrastRatio returns the WCAG contrast ratio of two colors, from 1 to 21.
+func contrastRatio(a rgb, b rgb) float64 {
+	la, lb := a.luminance(), b.luminance()
+	return (max(la, lb) + 0.05) / (min(la, lb) + 0.05)
+}
+
+// adjustLightness adds amount percentage points to the lightness of the color, like the functions of Sass.
+func adjustLightness(name string, color any, amount any, sign float64) (string, error) {
+	c, err := parseColor(color)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	points, err := toFloat(amount)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	h, s, l := c.hsl()
+	return fromHSL(h, s, math.Max(0, math.Min(1, l+sign*points/100))).String(), nil
+}
+
+// lighten returns the color with its lightness increased by amount percentage points: lighten .Brand 20.
+func lighten(color any, amount any) (string, error) {
+	return adjustLightness("lighten", color, amount, 1)
+}
+
+// darken returns the color with its lightness decreased by amount percentage points: darken .Brand 10.
+func darken(color any, amount any) (string, error) {
+	return adjustLightness("darken", color, amount, -1)
+}
+
+// contrastText returns the text color with the best contrast on a background color, #000 or #fff by default:
+// contrastText .Brand, contrastText .Brand "#1e293b" "#f8fafc".
+func contrastText(background any, candidates ...any) (string, error) {
+	bg, err := parseColor(background)
+	if err != nil {
+		return "", fmt.Errorf("contrastText: %w", err)
+	}
+	if len(candidates) == 0 {
+		candidates = []any{"#000000", "#ffffff"}
+	}
+	best, bestRatio := "", 0.0
+	for _, candidate := range candidates {
+		c, err := parseColor(candidate)
+		if err != nil {
+			return "", fmt.Errorf("contrastText: %w", err)
+		}
+		if ratio := contrastRatio(bg, c); ratio > bestRatio {
+			best, bestRatio = c.String(), ratio
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,58 @@
+package blade
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestColorFuncs(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func() (string, error)
+		expected string
+	}{
+		{"Lighten", func() (string, error) { return lighten("#336699", 20) }, "#6699cc"},
+		{"Darken", func() (string, error) { return darken("#336699", 10) }, "#264d73"},
+		{"Short hex", func() (string, error) { return darken("0AF", "100") }, "#000000"},
+		{"Lighten clamps", func() (string, error) { return lighten("#808080", 80) }, "#ffffff"},
+		{"Grey", func() (string, error) { return lighten("#808080", 10) }, "#9a9a9a"},
+		{"Contrast on dark", func() (string, error) { return contrastText("#1e3a8a") }, "#ffffff"},
+		{"Contrast on light", func() (string, error) { return contrastText("#facc15") }, "#000000"},
+		{"Contrast candidates", func() (string, error) { return contrastText("#0f172a", "#334155", "#f8fafc") }, "#f8fafc"},
+	}
+	for _, test := range tests {
+		got, err := test.fn()
+		if err != nil || got != test.expected {
+			t.Errorf("%s: expected %s, got %s (%v)", test.name, test.expected, got, err)
+		}
+	}
+
+	for _, color := range []any{"blue", "#12345", nil} {
+		if _, err := lighten(color, 10); err == nil {
+			t.Errorf("Expected an error for %v", color)
+		}
+	}
+	if _, err := darken("#fff", "a lot"); err == nil {
+		t.Error("Expected an error for an amount that is not a number")
+	}
+}
+
+func TestColorFuncsInTemplates(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"email.blade": `<style>.btn { background: {{ .Brand }}; color: {{ contrastText .Brand }} }</style>` +
+			`<td style="border-color: {{ darken .Brand 10 }}" bgcolor="{{ lighten .Brand 40 }}"></td>`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "email", map[string]any{"Brand": "#336699"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<style>.btn { background: #336699; color: #ffffff }</style><td style="border-color: #264d73" bgcolor="#b3cce6"></td>`
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
@@ -0,0 +1,70 @@
+package blade
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+// componentNamePrefix is the directory of the built-in components, like "blade/meta".
+// A template with the same name in the engine fs overrides a built-in component.
+const componentNamePrefix = "blade/"
+
+//go:embed components
+var componentsFS embed.FS
+
+// builtinComponents parses the built-in components once, by template name.
+var builtinComponents = sync.OnceValue(func() map[string]*ParsedFile {
+	files := map[string]*ParsedFile{}
+	entries, err := fs.ReadDir(componentsFS, "components")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		raw, err := componentsFS.ReadFile("components/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		ext := path.Ext(entry.Name())
+		name := componentNamePrefix + strings.TrimSuffix(entry.Name(), ext)
+		f, err := (&Engine{}).parseFile(name, string(raw))
+		if err != nil {
+			panic(err)
+		}
+		f.XML = ext == ".xml"
+		files[name] = f
+	}
+	return files
+})
+
+// lookupFile returns the file name of files, falling back to the built-in components.
+func lookupFile(files map[string]*ParsedFile, name string) (*ParsedFile, bool) {
+	if f, ok := files[name]; ok {
+		return f, true
+	}
+	f, ok := builtinComponents()[name]
+	return f, ok
+}
+
+// builtinTemplate returns the built-in component name compiled as a view of set, so components like
+// blade/sitemap render with Render when the templates do not override them. It is compiled once per set.
+func (e *Engine) builtinTemplate(set *compiledSet, name string) (*compiledTemplate, bool) {
+	if tmpl, ok := set.builtins.Load(name); ok {
+		return tmpl.(*compiledTemplate), true
+	}
+	f, ok := builtinComponents()[name]
+	if !ok {
+		return nil, false
+	}
+	tmplText, err := e.buildTemplateText(set.parsedFiles, f)
+	tmpl := &compiledTemplate{err: err, xml: f.XML}
+	if err == nil {
+		if tmpl, err = e.compileTemplate(name, tmplText, f.XML); err != nil {
+			tmpl = &compiledTemplate{err: err, xml: f.XML}
+		}
+	}
+	actual, _ := set.builtins.LoadOrStore(name, tmpl)
+	return actual.(*compiledTemplate), true
+}
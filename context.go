@@ -4,6 +4,9 @@
 	sectionNamePrefix = "__section_"
 	stackNamePrefix   = "__stack_"
 	partialNamePrefix = "__partial_"
+	snippetNamePrefix = "__snippet_"
+	macroNamePrefix   = "__macro_"
+	deferNamePrefix   = "__defer_"
 )
 
 type CompileContext struct {
@@ -12,13 +15,30 @@ type CompileContext struct {
 	Yields map[string]YieldInfo
 	// FilledSections is a map of section names, it prevents override section content from parent layout
 	FilledSections map[string]struct{}
+	// SectionContents maps section names to the contents filled by the layout chain, from the child to the root layout
+	SectionContents map[string][]SectionContent
+	// SectionPolicies maps section names to their policy when @section does not give one
+	SectionPolicies map[string]SectionPolicy
+	// DefaultSectionPolicy is the policy of the other sections, SectionOverride when empty
+	DefaultSectionPolicy SectionPolicy
 	// FilledIncludes is a map of partial names, it prevents duplicate partial names
 	FilledIncludes map[string]struct{}
 	// Stacks is a map of stack names to a template file, it prevents duplicate stack names and provides friendly error messages
 	Stacks map[string]string
 	// PushStacks is a map of stack names to values to push
 	// In the array, the last value is popped first
-	PushStacks map[string][]string
+	PushStacks map[string][]StackPush
+	// Macros is a map of macro names to a template file, it prevents duplicate macro names
+	Macros map[string]string
+	// Imports is a map of imported file names, it prevents importing the same macros twice
+	Imports map[string]struct{}
+	// cycles finds the partials on include cycles, guarded with a depth limit
+	cycles includeCycles
+}
+
+// snippetTemplateName returns the template name of a @define snippet, scoped to the file declaring it.
+func snippetTemplateName(fileName string, snippetName string) string {
+	return snippetNamePrefix + fileName + ":" + snippetName
 }
 
 // YieldInfo contains information about a yield
@@ -0,0 +1,127 @@
+package blade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// FragmentParam is the query parameter of the requests rendering a @defer block, see Fragment.
+const FragmentParam = "_fragment"
+
+// Placeholder styles of @defer, see Engine.DeferStyle.
+const (
+	// DeferHTMX renders placeholders loading their block with htmx once displayed
+	DeferHTMX = "htmx"
+	// DeferTurbo renders placeholders as lazy loaded Turbo frames, blocks are rendered in the matching frame
+	DeferTurbo = "turbo"
+)
+
+// reDeferName matches the names of @defer blocks, they are part of URLs and element ids.
+var reDeferName = regexp.MustCompile(`^[\w\-]+$`)
+
+type fragmentKey struct{}
+
+// Fragment returns the name of the @defer block requested by r, empty for a page request. Handlers can skip
+// loading the slow data of deferred blocks for page requests, and load only the data of the block otherwise.
+func Fragment(r *http.Request) string {
+	return r.URL.Query().Get(FragmentParam)
+}
+
+// RenderFragment renders the @defer block named fragment of entry with data, for the follow-up request of its
+// placeholder. Respond renders the block of requests with a fragment parameter by itself.
+func (e *Engine) RenderFragment(ctx context.Context, w io.Writer, entry string, fragment string, data any) error {
+	return e.execute(context.WithValue(ctx, fragmentKey{}, fragment), w, entry, data)
+}
+
+// parseDeferBlock records the content of a @defer block and returns its placeholder:
+// @defer('comments', placeholder: 'Loading...') => {{ __deferOpen "comments" }}Loading...{{ __deferClose }}
+func (p *ParsedFile) parseDeferBlock(args []string, content string) (string, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return "", errors.New("invalid @defer directive")
+	}
+	name, ok := parseQuotedDirectiveName(args[0])
+	if !ok || !reDeferName.MatchString(name) {
+		return "", fmt.Errorf("invalid @defer name %s", args[0])
+	}
+	if _, ok := p.Deferred[name]; ok {
+		return "", fmt.Errorf(`duplicate @defer name "%s"`, name)
+	}
+	placeholder := ""
+	if len(args) == 2 {
+		argName, value, ok := parseNamedDirectiveArg(args[1])
+		if !ok || argName != "placeholder" {
+			return "", fmt.Errorf("unknown @defer argument %s", args[1])
+		}
+		placeholder = directiveValueToTemplate(value)
+	}
+	p.Deferred[name] = content
+	return fmt.Sprintf(`{{ __deferOpen %q }}%s{{ __deferClose }}`, name, placeholder), nil
+}
+
+// deferOpen returns the opening tag of the placeholder of the @defer block name, loading the block from the
+// URL of the request being rendered with the fragment parameter.
+func (s *renderState) deferOpen(name string) template.HTML {
+	src := fragmentURL(RequestURL(s.ctx), name)
+	if s.e.DeferStyle == DeferTurbo {
+		return template.HTML(fmt.Sprintf(`<turbo-frame id="%s" src="%s" loading="lazy">`, deferFrameID(name), template.HTMLEscapeString(src)))
+	}
+	return template.HTML(fmt.Sprintf(`<div hx-get="%s" hx-trigger="load" hx-swap="outerHTML">`, template.HTMLEscapeString(src)))
+}
+
+// deferClose returns the closing tag of a @defer placeholder.
+func (s *renderState) deferClose() template.HTML {
+	if s.e.DeferStyle == DeferTurbo {
+		return "</turbo-frame>"
+	}
+	return "</div>"
+}
+
+// fragmentURL returns the path and query of u with the fragment parameter set to name, a relative query
+// without request URL.
+func fragmentURL(u *url.URL, name string) string {
+	if u == nil {
+		return "?" + url.Values{FragmentParam: {name}}.Encode()
+	}
+	query := u.Query()
+	query.Set(FragmentParam, name)
+	return (&url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: query.Encode()}).RequestURI()
+}
+
+// deferFrameID returns the id of the Turbo frame of the @defer block name.
+func deferFrameID(name string) string {
+	return "blade-defer-" + name
+}
+
+// executeFragment executes the @defer block name of t, in its Turbo frame with DeferTurbo.
+func (e *Engine) executeFragment(w io.Writer, t templateSet, name string, data any) error {
+	if !reDeferName.MatchString(name) || !hasTree(t, deferNamePrefix+name) {
+		return fmt.Errorf(`deferred block "%s" not found`, name)
+	}
+	if e.DeferStyle != DeferTurbo {
+		return t.ExecuteTemplate(w, deferNamePrefix+name, data)
+	}
+	if _, err := fmt.Fprintf(w, `<turbo-frame id="%s">`, deferFrameID(name)); err != nil {
+		return err
+	}
+	if err := t.ExecuteTemplate(w, deferNamePrefix+name, data); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "</turbo-frame>")
+	return err
+}
+
+// hasTree reports whether t has an associated template named name.
+func hasTree(t templateSet, name string) bool {
+	for _, tree := range t.trees() {
+		if tree.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDefer(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `<main>@yield('content')</main>`,
+		"post.blade": `@extends('layout')
+@section('content')<h1>{{ .Title }}</h1>@defer('comments', placeholder: 'Loading...'){{ range .Comments }}<p>{{ . }}</p>{{ end }}@enddefer@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	respond := func(target string) string {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+		data := map[string]any{"Title": "Hello"}
+		if Fragment(req) == "comments" {
+			data["Comments"] = []string{"First", "Second"}
+		}
+		if err := engine.Respond(w, req, http.StatusOK, "post", data); err != nil {
+			t.Fatalf("Respond failed: %v", err)
+		}
+		return w.Body.String()
+	}
+
+	expected := `<main><h1>Hello</h1><div hx-get="/posts/1?_fragment=comments&amp;lang=en" hx-trigger="load" hx-swap="outerHTML">Loading...</div></main>`
+	if got := respond("/posts/1?lang=en"); got != expected {
+		t.Errorf("Page mismatch.\nExp: %s\nGot: %s", expected, got)
+	}
+	if got, expected := respond("/posts/1?lang=en&_fragment=comments"), `<p>First</p><p>Second</p>`; got != expected {
+		t.Errorf("Fragment mismatch.\nExp: %s\nGot: %s", expected, got)
+	}
+
+	engine.DeferStyle = DeferTurbo
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "post", map[string]any{"Title": "Hello"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected = `<main><h1>Hello</h1><turbo-frame id="blade-defer-comments" src="?_fragment=comments" loading="lazy">Loading...</turbo-frame></main>`
+	if buf.String() != expected {
+		t.Errorf("Turbo page mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+	buf.Reset()
+	if err := engine.RenderFragment(context.Background(), &buf, "post", "comments", map[string]any{"Comments": []string{"First"}}); err != nil {
+		t.Fatalf("RenderFragment failed: %v", err)
+	}
+	if expected := `<turbo-frame id="blade-defer-comments"><p>First</p></turbo-frame>`; buf.String() != expected {
+		t.Errorf("Turbo fragment mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	if err := engine.RenderFragment(context.Background(), &buf, "post", "missing", nil); err == nil {
+		t.Error("Expected an error for a missing deferred block")
+	}
+}
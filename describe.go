@@ -0,0 +1,120 @@
+package blade
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// TemplateInfo describes how a view is composed, see Engine.Describe.
+type TemplateInfo struct {
+	Name string
+	// Layouts is the layout chain, from the direct parent to the root layout
+	Layouts []string
+	// Yields are the sections expected by the view, its layouts and partials, with their default content
+	Yields []YieldInfo
+	// Sections maps the sections filled by the view and its layouts to the file filling them
+	Sections map[string]string
+	// Stacks maps stack names to the file declaring them
+	Stacks map[string]string
+	// Pushes maps stack names to the files pushing to them, from child to parent
+	Pushes map[string][]string
+	// Includes are the partials rendered by the view, including nested ones
+	Includes []string
+}
+
+// Describe returns the layout chain, yields, sections, stacks and includes of the loaded view name,
+// for documentation tooling and editor integrations.
+func (e *Engine) Describe(name string) (*TemplateInfo, error) {
+	return describe(e.set.Load().parsedFiles, normalizeName(name))
+}
+
+// describe builds the TemplateInfo of name from the parsed files.
+func describe(files map[string]*ParsedFile, name string) (*TemplateInfo, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("template %s not loaded", name)
+	}
+
+	info := &TemplateInfo{
+		Name:     name,
+		Sections: map[string]string{},
+		Stacks:   map[string]string{},
+		Pushes:   map[string][]string{},
+	}
+	yields := map[string]YieldInfo{}
+	visited := map[string]struct{}{}
+
+	var describeFile func(f *ParsedFile) error
+	describeFile = func(f *ParsedFile) error {
+		if _, ok := visited[f.Name]; ok {
+			return nil
+		}
+		visited[f.Name] = struct{}{}
+
+		for sectionName := range f.Sections {
+			// the child fills a section first, like in ToTemplateString
+			if _, ok := info.Sections[sectionName]; !ok {
+				info.Sections[sectionName] = f.Name
+			}
+		}
+		for yieldName, defaultValue := range f.Yields {
+			if _, ok := yields[yieldName]; !ok {
+				yields[yieldName] = YieldInfo{Name: yieldName, FileName: f.Name, Default: defaultValue}
+			}
+		}
+		for stackName := range f.Stacks {
+			info.Stacks[stackName] = f.Name
+		}
+		for stackName := range f.PushStacks {
+			info.Pushes[stackName] = append(info.Pushes[stackName], f.Name)
+		}
+		for _, partialName := range sortedKeys(f.Includes) {
+			partial, ok := lookupFile(files, partialName)
+			if !ok {
+				return fmt.Errorf(`[%s] template "%s" not found to include`, f.Name, partialName)
+			}
+			if !slices.Contains(info.Includes, partialName) {
+				info.Includes = append(info.Includes, partialName)
+			}
+			if err := describeFile(partial); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for {
+		if err := describeFile(f); err != nil {
+			return nil, err
+		}
+		if f.Extends == "" {
+			break
+		}
+		parent, ok := lookupFile(files, f.Extends)
+		if !ok {
+			return nil, fmt.Errorf(`[%s] template "%s" not found to extends`, f.Name, f.Extends)
+		}
+		if slices.Contains(info.Layouts, parent.Name) || parent.Name == name {
+			return nil, fmt.Errorf(`[%s] circular extends of template "%s"`, f.Name, parent.Name)
+		}
+		info.Layouts = append(info.Layouts, parent.Name)
+		f = parent
+	}
+
+	for _, yieldName := range sortedKeys(yields) {
+		info.Yields = append(info.Yields, yields[yieldName])
+	}
+	sort.Strings(info.Includes)
+	return info, nil
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
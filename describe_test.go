@@ -0,0 +1,65 @@
+package blade
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layouts/base.blade": `<title>@yield('title', 'Home')</title>@yield('content')@stack('scripts')`,
+		"layouts/app.blade":  `@extends('layouts/base')@section('content')<main>@yield('main')</main>@endsection@push('scripts')<script src="app.js"></script>@endpush`,
+		"partials/nav.blade": `<nav>@yield('nav')</nav>`,
+		"page.blade":         `@extends('layouts/app')@section('main')@include('partials/nav')Page@endsection@push('scripts')<script src="page.js"></script>@endpush`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	info, err := engine.Describe("page")
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+
+	expected := &TemplateInfo{
+		Name:    "page",
+		Layouts: []string{"layouts/app", "layouts/base"},
+		Yields: []YieldInfo{
+			{Name: "content", FileName: "layouts/base"},
+			{Name: "main", FileName: "layouts/app"},
+			{Name: "nav", FileName: "partials/nav"},
+			{Name: "title", FileName: "layouts/base", Default: "Home"},
+		},
+		Sections: map[string]string{"main": "page", "content": "layouts/app"},
+		Stacks:   map[string]string{"scripts": "layouts/base"},
+		Pushes:   map[string][]string{"scripts": {"page", "layouts/app"}},
+		Includes: []string{"partials/nav"},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("Describe mismatch.\nExp: %+v\nGot: %+v", expected, info)
+	}
+
+	if _, err := engine.Describe("missing"); err == nil {
+		t.Errorf("expected an error for a missing template")
+	}
+}
+
+func TestDescribeComponents(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `<head>@stack('meta')</head>@yield('content')`,
+		"page.blade":   `@extends('layout')@meta(title: .Title)@section('content')Page@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	info, err := engine.Describe("page")
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	if !reflect.DeepEqual(info.Includes, []string{"blade/meta"}) || !reflect.DeepEqual(info.Pushes["meta"], []string{"page"}) {
+		t.Errorf("Expected the built-in meta component, got %+v", info)
+	}
+}
@@ -0,0 +1,131 @@
+package blade
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithDevOverlay shadows the templates of the engine fs with the files of dir, like
+// NewEngineFS(embedded, "views").WithDevOverlay(os.DirFS("./views")). Paths in dir are relative to the
+// engine prefix. When dir is missing, as in a deployed binary, the engine fs is used alone, so the same code
+// serves embedded templates in production and edited files from disk during development, reloaded by Watch.
+func (e *Engine) WithDevOverlay(dir fs.FS) *Engine {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fs = &overlayFS{base: e.fs, upper: dir, prefix: e.dirPrefix}
+	e.lastCompileTime = -1
+	e.manifest = nil
+	return e
+}
+
+// overlayFS is an fs.FS whose files in upper shadow the files in base under prefix.
+type overlayFS struct {
+	base   fs.FS
+	upper  fs.FS
+	prefix string
+	// shadowed holds the base paths shadowed by upper on the last ReadDir, a base file is reported modified
+	// when its shadowing file is removed, so Load reads it again
+	shadowed sync.Map
+}
+
+// upperPath returns the path of name in upper, false when name is outside of the prefix.
+func (o *overlayFS) upperPath(name string) (string, bool) {
+	if o.prefix == "" || o.prefix == "." {
+		return name, true
+	}
+	if name == o.prefix {
+		return ".", true
+	}
+	return strings.CutPrefix(name, o.prefix+"/")
+}
+
+func (o *overlayFS) Open(name string) (fs.File, error) {
+	if p, ok := o.upperPath(name); ok {
+		if f, err := o.upper.Open(p); err == nil {
+			if stat, err := f.Stat(); err == nil && !stat.IsDir() {
+				return f, nil
+			}
+			f.Close()
+		}
+	}
+	f, err := o.base.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		if p, ok := o.upperPath(name); ok {
+			// directories only present in upper
+			return o.upper.Open(p)
+		}
+	}
+	return f, err
+}
+
+func (o *overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	baseEntries, baseErr := fs.ReadDir(o.base, name)
+	var upperEntries []fs.DirEntry
+	upperErr := fs.ErrNotExist
+	if p, ok := o.upperPath(name); ok {
+		upperEntries, upperErr = fs.ReadDir(o.upper, p)
+	}
+	if baseErr != nil && upperErr != nil {
+		return nil, baseErr
+	}
+
+	entries := map[string]fs.DirEntry{}
+	for _, entry := range baseEntries {
+		filePath := path.Join(name, entry.Name())
+		if _, ok := o.shadowed.LoadAndDelete(filePath); ok && !entry.IsDir() {
+			entry = unshadowedEntry{DirEntry: entry, modTime: time.Now()}
+		}
+		entries[entry.Name()] = entry
+	}
+	for _, entry := range upperEntries {
+		if old, ok := entries[entry.Name()]; ok && old.IsDir() != entry.IsDir() {
+			continue
+		}
+		if !entry.IsDir() {
+			o.shadowed.Store(path.Join(name, entry.Name()), struct{}{})
+		}
+		entries[entry.Name()] = entry
+	}
+
+	result := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+	return result, nil
+}
+
+// Refresh refreshes the base fs when it is a RefreshableFS.
+func (o *overlayFS) Refresh(ctx context.Context) error {
+	if r, ok := o.base.(RefreshableFS); ok {
+		return r.Refresh(ctx)
+	}
+	return nil
+}
+
+// unshadowedEntry is a base file whose shadowing file was removed, dated when it reappeared.
+type unshadowedEntry struct {
+	fs.DirEntry
+	modTime time.Time
+}
+
+func (u unshadowedEntry) Info() (fs.FileInfo, error) {
+	info, err := u.DirEntry.Info()
+	if err != nil {
+		return nil, err
+	}
+	return unshadowedInfo{FileInfo: info, modTime: u.modTime}, nil
+}
+
+type unshadowedInfo struct {
+	fs.FileInfo
+	modTime time.Time
+}
+
+func (u unshadowedInfo) ModTime() time.Time { return u.modTime }
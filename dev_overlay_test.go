@@ -0,0 +1,62 @@
+package blade
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestWithDevOverlay(t *testing.T) {
+	embedded := createMockFS(map[string]string{
+		"views/layouts/app.blade": `<main>@yield('content')</main>`,
+		"views/pages/home.blade":  `@extends('layouts/app')@section('content')Embedded@endsection`,
+	})
+	embedded["views/layouts/app.blade"].ModTime = time.Time{}
+	embedded["views/pages/home.blade"].ModTime = time.Time{}
+	disk := createMockFS(map[string]string{
+		"pages/home.blade":  `@extends('layouts/app')@section('content')Disk@endsection`,
+		"pages/about.blade": `About`,
+	})
+	engine := NewEngineFS(embedded, "views").WithDevOverlay(disk)
+
+	render := func(entry string) string {
+		t.Helper()
+		if err := engine.Load(); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, entry, nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	if got := render("pages/home"); got != "<main>Disk</main>" {
+		t.Errorf("expected the disk file to shadow the embedded one, got %q", got)
+	}
+	if got := render("pages/about"); got != "About" {
+		t.Errorf("expected files only on disk to be served, got %q", got)
+	}
+
+	disk["pages/home.blade"].Data = []byte(`@extends('layouts/app')@section('content')Edited@endsection`)
+	disk["pages/home.blade"].ModTime = time.Now().Add(time.Second)
+	if got := render("pages/home"); got != "<main>Edited</main>" {
+		t.Errorf("expected the edited disk file to be reloaded, got %q", got)
+	}
+
+	delete(disk, "pages/home.blade")
+	if got := render("pages/home"); got != "<main>Embedded</main>" {
+		t.Errorf("expected the embedded file after removing the disk one, got %q", got)
+	}
+
+	// without the overlay directory, the embedded templates are served
+	production := NewEngineFS(embedded, "views").WithDevOverlay(fstest.MapFS{})
+	if err := production.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := production.Render(&buf, "pages/home", nil); err != nil || buf.String() != "<main>Embedded</main>" {
+		t.Errorf("unexpected output %q, %v", buf.String(), err)
+	}
+}
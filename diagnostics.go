@@ -0,0 +1,358 @@
+package blade
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// DiagnosticSeverity follows the Language Server Protocol severities.
+type DiagnosticSeverity int
+
+const (
+	SeverityError       DiagnosticSeverity = 1
+	SeverityWarning     DiagnosticSeverity = 2
+	SeverityInformation DiagnosticSeverity = 3
+	SeverityHint        DiagnosticSeverity = 4
+)
+
+func (s DiagnosticSeverity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInformation:
+		return "info"
+	default:
+		return "hint"
+	}
+}
+
+// Position is a zero based line and UTF-16 character offset, like LSP positions.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a range in a source file, the end is exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Diagnostic is a problem found in a template file, it encodes to JSON like an LSP diagnostic with the file path.
+type Diagnostic struct {
+	// File is the path of the file in the engine fs
+	File     string             `json:"file"`
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity"`
+	Code     string             `json:"code"`
+	Source   string             `json:"source"`
+	Message  string             `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", d.File, d.Range.Start.Line+1, d.Range.Start.Character+1, d.Severity, d.Message)
+}
+
+// Diagnostic codes.
+const (
+	DiagnosticParseError        = "parse-error"
+	DiagnosticCompileError      = "compile-error"
+	DiagnosticUnknownDirective  = "unknown-directive"
+	DiagnosticUnresolvedInclude = "unresolved-include"
+	DiagnosticUnusedSection     = "unused-section"
+	DiagnosticUnfilledYield     = "unfilled-yield"
+	DiagnosticOverriddenSection = "overridden-section"
+	DiagnosticUnknownField      = "unknown-field"
+)
+
+var (
+	reDirectiveToken = regexp.MustCompile(`(?:^|[^\w@.:/-])@(\w+)`)
+	reFileReference  = regexp.MustCompile(`@(extends|includeData|include|import|island)\(\s*(['"])([^'"]+)['"]`)
+	reSectionName    = regexp.MustCompile(`@section\(\s*['"]([^'"]+)['"]`)
+	reYieldName      = regexp.MustCompile(`@yield\(\s*['"]([^'"]+)['"]`)
+	reErrorFile      = regexp.MustCompile(`^\[([^\]]+)\] `)
+	// reCompileErrorName matches the name quoted by a compile error: function "money" not defined
+	reCompileErrorName = regexp.MustCompile(`"([$.]?\w[\w.]*)"`)
+)
+
+// cssAtRules are not reported as unknown directives in inline styles.
+var cssAtRules = map[string]struct{}{
+	"media": {}, "font": {}, "keyframes": {}, "supports": {}, "page": {}, "charset": {}, "namespace": {},
+	"layer": {}, "container": {}, "property": {}, "counter": {}, "scope": {}, "starting": {}, "tailwind": {}, "apply": {},
+}
+
+// Diagnose parses and compiles every template file of the engine fs independently of Load,
+// returning all problems found instead of stopping at the first one.
+func (e *Engine) Diagnose() ([]Diagnostic, error) {
+	var diagnostics []Diagnostic
+	add := func(f *ParsedFile, start int, end int, severity DiagnosticSeverity, code string, message string) {
+		diagnostics = append(diagnostics, newDiagnostic(f, start, end, severity, code, message))
+	}
+
+	files, err := e.parseAll(func(path string, raw string, err error) {
+		// report blocks missing their end directive at the block
+		start, end := 0, 0
+		var missing *missingEndError
+		if errors.As(err, &missing) {
+			if d, ok := unclosedBlock(locateDirectives(raw), missing.directive); ok {
+				start, end = d.Span.Start, d.Span.End
+			}
+		}
+		add(&ParsedFile{Path: path, Raw: raw}, start, end, SeverityError, DiagnosticParseError, errorMessage(err))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := sortedKeys(files)
+	for _, name := range names {
+		f := files[name]
+		for _, m := range reDirectiveToken.FindAllStringSubmatchIndex(f.Raw, -1) {
+			directive := f.Raw[m[2]:m[3]]
+			if _, ok := knownDirectives[directive]; ok {
+				continue
+			}
+			if _, ok := cssAtRules[directive]; ok {
+				continue
+			}
+			add(f, m[2]-1, m[3], SeverityWarning, DiagnosticUnknownDirective, fmt.Sprintf(`unknown directive "@%s"`, directive))
+		}
+		for _, m := range reFileReference.FindAllStringSubmatchIndex(f.Raw, -1) {
+			target := normalizeName(f.Raw[m[6]:m[7]])
+			if _, ok := lookupFile(files, target); !ok {
+				add(f, m[6], m[7], SeverityError, DiagnosticUnresolvedInclude, fmt.Sprintf(`template "%s" not found to %s`, target, f.Raw[m[2]:m[3]]))
+			}
+		}
+	}
+	if slices.ContainsFunc(diagnostics, func(d Diagnostic) bool { return d.Severity == SeverityError }) {
+		// compiling would report the same problems without ranges
+		return sortDiagnostics(diagnostics), nil
+	}
+
+	diagnostics = append(diagnostics, e.compositionWarnings(files)...)
+	for _, name := range names {
+		f := files[name]
+		if !e.EntryFilter(f) {
+			continue
+		}
+		tmplText, err := e.buildTemplateText(files, f)
+		if err == nil {
+			var tmpl *compiledTemplate
+			if tmpl, err = e.compileTemplate(name, tmplText, f.XML); err == nil {
+				diagnostics = append(diagnostics, e.paramWarnings(files, f, tmpl.proto)...)
+			}
+		}
+		if err != nil {
+			errFile := f
+			if m := reErrorFile.FindStringSubmatch(err.Error()); m != nil && files[m[1]] != nil {
+				errFile = files[m[1]]
+			}
+			// the positions of template errors are in the compiled text, report the error at the name it quotes
+			start, end := 0, 0
+			if m := reCompileErrorName.FindStringSubmatch(err.Error()); m != nil {
+				if located, span, ok := locateSnippet(files, []string{errFile.Name, name}, m[1]); ok {
+					errFile, start, end = located, span.Start, span.End
+				}
+			}
+			add(errFile, start, end, SeverityError, DiagnosticCompileError, errorMessage(err))
+		}
+	}
+
+	return sortDiagnostics(slices.CompactFunc(sortDiagnostics(diagnostics), func(a, b Diagnostic) bool { return a == b })), nil
+}
+
+// parseAll parses every template file of the engine fs without compiling them, independently of Load.
+// Files failing to parse are passed to onError and skipped.
+func (e *Engine) parseAll(onError func(path string, raw string, err error)) (map[string]*ParsedFile, error) {
+	return e.parseFS(e.fs, e.dirPrefix, onError)
+}
+
+// parseFS is like parseAll for the template files of fsys, named relative to dirPrefix.
+func (e *Engine) parseFS(fsys fs.FS, dirPrefix string, onError func(path string, raw string, err error)) (map[string]*ParsedFile, error) {
+	files := map[string]*ParsedFile{}
+	err := fs.WalkDir(fsys, ".", func(path string, info fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		isTemplate, isXML := e.templateFileKind(path)
+		if !isTemplate {
+			return nil
+		}
+		f, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		raw, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		name := nameFromPathIn(dirPrefix, path)
+		parsedFile, err := e.parseFile(name, string(raw))
+		if err != nil {
+			onError(path, string(raw), err)
+			return nil
+		}
+		parsedFile.Path = path
+		parsedFile.XML = isXML
+		files[name] = parsedFile
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// compositionWarnings reports sections that no layout of their file yields,
+// and yields without default that no child view extending their layout fills.
+func (e *Engine) compositionWarnings(files map[string]*ParsedFile) []Diagnostic {
+	var warnings []Diagnostic
+	// yields of the layout chains of child views, by file, with whether a child filled them
+	filledYields := map[string]map[string]bool{}
+
+	for _, name := range sortedKeys(files) {
+		f := files[name]
+		if f.Extends == "" {
+			continue
+		}
+		info, err := describe(files, name)
+		if err != nil {
+			continue
+		}
+		for _, m := range reSectionName.FindAllStringSubmatchIndex(f.Raw, -1) {
+			sectionName := normalizeName(f.Raw[m[2]:m[3]])
+			if !slices.ContainsFunc(info.Yields, func(y YieldInfo) bool { return y.Name == sectionName }) {
+				warnings = append(warnings, newDiagnostic(f, m[2], m[3], SeverityWarning, DiagnosticUnusedSection,
+					orphanSectionMessage(sectionName, info.Layouts)))
+			}
+		}
+		if parent := files[f.Extends]; parent != nil {
+			warnings = append(warnings, e.overriddenSections(files, f, parent)...)
+		}
+		if !e.EntryFilter(f) {
+			continue
+		}
+		for _, y := range info.Yields {
+			if y.Default != "" {
+				continue
+			}
+			if filledYields[y.FileName] == nil {
+				filledYields[y.FileName] = map[string]bool{}
+			}
+			_, filled := info.Sections[y.Name]
+			filledYields[y.FileName][y.Name] = filledYields[y.FileName][y.Name] || filled
+		}
+	}
+
+	for _, fileName := range sortedKeys(filledYields) {
+		f := files[fileName]
+		for _, m := range reYieldName.FindAllStringSubmatchIndex(f.Raw, -1) {
+			yieldName := normalizeName(f.Raw[m[2]:m[3]])
+			if filled, ok := filledYields[fileName][yieldName]; ok && !filled {
+				warnings = append(warnings, newDiagnostic(f, m[2], m[3], SeverityWarning, DiagnosticUnfilledYield,
+					fmt.Sprintf(`yield "%s" has no default and is not filled by any view`, yieldName)))
+			}
+		}
+	}
+	return warnings
+}
+
+// overriddenSections reports the sections of f also defined by one of its layouts, starting at parent.
+// The child section wins, which hides the content of the layout, unless its policy appends or prepends it.
+func (e *Engine) overriddenSections(files map[string]*ParsedFile, f *ParsedFile, parent *ParsedFile) []Diagnostic {
+	var warnings []Diagnostic
+	policies := &CompileContext{SectionPolicies: e.SectionPolicies, DefaultSectionPolicy: e.SectionPolicy}
+	for _, m := range reSectionName.FindAllStringSubmatchIndex(f.Raw, -1) {
+		sectionName := normalizeName(f.Raw[m[2]:m[3]])
+		if policies.sectionPolicy(f, sectionName) != SectionOverride {
+			continue
+		}
+		visited := map[string]struct{}{f.Name: {}}
+		for layout := parent; layout != nil; layout = files[layout.Extends] {
+			if _, ok := visited[layout.Name]; ok {
+				break
+			}
+			visited[layout.Name] = struct{}{}
+			if _, ok := layout.Sections[sectionName]; !ok {
+				continue
+			}
+			line := 0
+			if lines := layout.directiveLines("section", sectionName); len(lines) > 0 {
+				line = lines[0]
+			}
+			warnings = append(warnings, newDiagnostic(f, m[2], m[3], SeverityWarning, DiagnosticOverriddenSection,
+				fmt.Sprintf(`section "%s" overrides the section defined in %s:%d`, sectionName, layout.Path, line)))
+			break
+		}
+	}
+	return warnings
+}
+
+// newDiagnostic returns a diagnostic of f for the bytes from start to end of its raw content.
+func newDiagnostic(f *ParsedFile, start int, end int, severity DiagnosticSeverity, code string, message string) Diagnostic {
+	return Diagnostic{
+		File:     f.Path,
+		Range:    Range{Start: offsetPosition(f.Raw, start), End: offsetPosition(f.Raw, end)},
+		Severity: severity,
+		Code:     code,
+		Source:   "blade",
+		Message:  message,
+	}
+}
+
+// orphanSectionMessage describes a section no layout of the chain yields, so its content is dropped.
+func orphanSectionMessage(name string, layouts []string) string {
+	return fmt.Sprintf(`section "%s" is not yielded by any layout, searched %s`, name, strings.Join(layouts, ", "))
+}
+
+// errorMessage strips the [file] prefix of engine errors, the file is already part of the diagnostic.
+func errorMessage(err error) string {
+	return reErrorFile.ReplaceAllString(err.Error(), "")
+}
+
+// sortDiagnostics sorts diagnostics by file and position.
+func sortDiagnostics(diagnostics []Diagnostic) []Diagnostic {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Range.Start.Line != b.Range.Start.Line {
+			return a.Range.Start.Line < b.Range.Start.Line
+		}
+		if a.Range.Start.Character != b.Range.Start.Character {
+			return a.Range.Start.Character < b.Range.Start.Character
+		}
+		return a.Message < b.Message
+	})
+	return diagnostics
+}
+
+// offsetPosition converts a byte offset of text to a Position.
+func offsetPosition(text string, offset int) Position {
+	offset = min(offset, len(text))
+	lineStart := strings.LastIndexByte(text[:offset], '\n') + 1
+	character := 0
+	for _, r := range text[lineStart:offset] {
+		if r == utf8.RuneError {
+			character++
+			continue
+		}
+		character += utf16.RuneLen(r)
+	}
+	return Position{Line: strings.Count(text[:offset], "\n"), Character: character}
+}
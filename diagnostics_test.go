@@ -0,0 +1,136 @@
+package blade
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layouts/app.blade": "<style>@media print { a { color: red } }</style>\n@yield('content')",
+		"page.blade":        "@extends('layouts/app')\n@section('content')Mail me at a@b.com @lang('x')@endsection\n@section('sidebar', 'unused')",
+		"broken.blade":      "@extends('layouts/app')\n@section('content')  @include('partials/missing')@endsection",
+	})
+	engine := NewEngineFS(mockFS)
+
+	diagnostics, err := engine.Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+
+	expected := []Diagnostic{
+		{
+			File:     "broken.blade",
+			Range:    Range{Start: Position{Line: 1, Character: 31}, End: Position{Line: 1, Character: 47}},
+			Severity: SeverityError,
+			Code:     DiagnosticUnresolvedInclude,
+			Source:   "blade",
+			Message:  `template "partials/missing" not found to include`,
+		},
+		{
+			File:     "page.blade",
+			Range:    Range{Start: Position{Line: 1, Character: 38}, End: Position{Line: 1, Character: 43}},
+			Severity: SeverityWarning,
+			Code:     DiagnosticUnknownDirective,
+			Source:   "blade",
+			Message:  `unknown directive "@lang"`,
+		},
+	}
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %v", len(expected), diagnostics)
+	}
+	for i := range expected {
+		if diagnostics[i] != expected[i] {
+			t.Errorf("diagnostic %d mismatch.\nExp: %+v\nGot: %+v", i, expected[i], diagnostics[i])
+		}
+	}
+
+	// once errors are fixed, warnings from the compile step are reported
+	mockFS["broken.blade"].Data = []byte("@extends('layouts/app')")
+	diagnostics, err = engine.Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	var codes []string
+	for _, d := range diagnostics {
+		codes = append(codes, d.Code)
+	}
+	if len(codes) != 2 || codes[0] != DiagnosticUnknownDirective || codes[1] != DiagnosticUnusedSection {
+		t.Errorf("unexpected diagnostics %v", diagnostics)
+	}
+
+	data, err := json.Marshal(diagnostics[1])
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expectedJSON := `{"file":"page.blade","range":{"start":{"line":2,"character":10},"end":{"line":2,"character":17}},"severity":2,"code":"unused-section","source":"blade","message":"section \"sidebar\" is not yielded by any layout, searched layouts/app"}`
+	if string(data) != expectedJSON {
+		t.Errorf("JSON mismatch.\nExp: %s\nGot: %s", expectedJSON, data)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layouts/app.blade": "<title>@yield('title', 'Home')</title>\n@yield('content') @yield('sidebar')",
+		"home.blade":        "@extends('layouts/app')@section('content')Home@endsection@section('footer', 'x')",
+		"about.blade":       "@extends('layouts/app')@section('content')About@endsection",
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	warnings := engine.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %v", warnings)
+	}
+	if warnings[0].Code != DiagnosticUnusedSection || warnings[0].File != "home.blade" {
+		t.Errorf("unexpected warning %v", warnings[0])
+	}
+	if warnings[1].Code != DiagnosticUnfilledYield || warnings[1].Message != `yield "sidebar" has no default and is not filled by any view` ||
+		warnings[1].Range.Start != (Position{Line: 1, Character: 26}) {
+		t.Errorf("unexpected warning %v", warnings[1])
+	}
+}
+
+func TestOverriddenSectionWarning(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layouts/base.blade": `@yield('content')`,
+		"layouts/app.blade":  "@extends('layouts/base')\n@section('content')Default@endsection",
+		"page.blade":         "@extends('layouts/app')@section('content')Page@endsection",
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	warnings := engine.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+	expected := `section "content" overrides the section defined in layouts/app.blade:2`
+	if warnings[0].Code != DiagnosticOverriddenSection || warnings[0].File != "page.blade" || warnings[0].Message != expected {
+		t.Errorf("unexpected warning %v", warnings[0])
+	}
+}
+
+func TestDiagnoseCompileErrorRange(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"page.blade":           "<main>\n@include('partials/price', .)\n</main>",
+		"partials/price.blade": "<p>\n  {{ moneyy .Price }}</p>",
+	})
+	engine := NewEngineFS(mockFS)
+
+	diagnostics, err := engine.Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	if len(diagnostics) == 0 {
+		t.Fatal("expected a compile error")
+	}
+	d := diagnostics[0]
+	expected := Range{Start: Position{Line: 1, Character: 5}, End: Position{Line: 1, Character: 11}}
+	if d.Code != DiagnosticCompileError || d.File != "partials/price.blade" || d.Range != expected {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+}
@@ -0,0 +1,32 @@
+package blade
+
+import "golang.org/x/text/language"
+
+// rtlScripts are the ISO 15924 codes of the scripts written right to left.
+var rtlScripts = map[string]struct{}{
+	"Adlm": {}, "Arab": {}, "Hebr": {}, "Mand": {}, "Mend": {}, "Nkoo": {}, "Rohg": {}, "Samr": {}, "Syrc": {}, "Thaa": {}, "Yezi": {},
+}
+
+// isRTL reports whether the render locale is written right to left, like Arabic, Hebrew or Persian.
+func (s *renderState) isRTL() bool {
+	return isRTLLocale(s.locale())
+}
+
+// dir returns the direction of the render locale for a dir attribute, "rtl" or "ltr".
+func (s *renderState) dir() string {
+	if s.isRTL() {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// isRTLLocale reports whether the likely script of locale is written right to left.
+func isRTLLocale(locale string) bool {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return false
+	}
+	script, _ := tag.Script()
+	_, ok := rtlScripts[script.String()]
+	return ok
+}
@@ -0,0 +1,43 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestDirection(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"page.blade": `<html dir="{{ dir }}">@rtl<link href="rtl.css">@endrtl@ltr<link href="ltr.css">@endltr{{ if isRTL }}!{{ end }}</html>`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{"", `<html dir="ltr"><link href="ltr.css"></html>`},
+		{"ar-EG", `<html dir="rtl"><link href="rtl.css">!</html>`},
+		{"he", `<html dir="rtl"><link href="rtl.css">!</html>`},
+		{"fa", `<html dir="rtl"><link href="rtl.css">!</html>`},
+		{"az-Arab", `<html dir="rtl"><link href="rtl.css">!</html>`},
+		{"az", `<html dir="ltr"><link href="ltr.css"></html>`},
+		{"invalid locale", `<html dir="ltr"><link href="ltr.css"></html>`},
+	}
+	for _, tc := range tests {
+		ctx := context.Background()
+		if tc.locale != "" {
+			ctx = WithLocale(ctx, tc.locale)
+		}
+		var buf bytes.Buffer
+		if err := engine.RenderContext(ctx, &buf, "page", nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("Direction mismatch for locale %q.\nExp: %s\nGot: %s", tc.locale, tc.expected, buf.String())
+		}
+	}
+}
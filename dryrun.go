@@ -0,0 +1,294 @@
+package blade
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"text/template/parse"
+)
+
+// DryRunReport is the result of Engine.DryRun.
+type DryRunReport struct {
+	Entry string
+	// Bytes is the size of the output
+	Bytes int64
+	// MissingData are the data paths the view references that the data lacks, like ".User.Email", or
+	// ".Items[].Price" for the items of a range. Paths under nil values are not checked.
+	MissingData []string
+	// MissingFuncs are the funcs the view calls that are not registered, they return nil in the dry run
+	MissingFuncs []string
+	// Err is the error of the render, nil when it succeeded
+	Err error
+}
+
+// OK reports whether the render succeeded without missing data or funcs.
+func (r *DryRunReport) OK() bool {
+	return r.Err == nil && len(r.MissingData) == 0 && len(r.MissingFuncs) == 0
+}
+
+// templateBuiltins are the funcs predefined by text/template and html/template.
+var templateBuiltins = []string{
+	"and", "call", "html", "index", "slice", "js", "len", "not", "or", "print", "printf", "println", "urlquery",
+	"eq", "ge", "gt", "le", "lt", "ne",
+}
+
+// DryRun renders entry with data into a discard writer and reports the data paths the view and its layouts
+// and partials reference that data lacks, and the funcs they call that are not registered, for smoke tests of
+// handler and data changes against the views before a deploy:
+//
+//	report, err := eng.DryRun("pages/checkout", checkoutData(fixtureOrder))
+//	if err != nil || !report.OK() {
+//		t.Errorf("checkout: %v %v %v", report.MissingData, report.MissingFuncs, report.Err)
+//	}
+//
+// The entry is compiled again from its sources, so views calling funcs missing from Engine.FuncMap are run
+// too. The returned error is only set when the entry cannot be run.
+func (e *Engine) DryRun(entry string, data any) (*DryRunReport, error) {
+	return e.DryRunContext(context.Background(), entry, data)
+}
+
+// DryRunContext is like DryRun with a render context.
+func (e *Engine) DryRunContext(ctx context.Context, entry string, data any) (*DryRunReport, error) {
+	name := normalizeName(entry)
+	set := e.set.Load()
+	f, ok := lookupFile(set.parsedFiles, name)
+	if !ok {
+		return nil, fmt.Errorf("template %s not loaded", entry)
+	}
+	text, err := e.buildTemplateText(set.parsedFiles, f)
+	if err != nil {
+		return nil, err
+	}
+
+	var funcs template.FuncMap
+	data, funcs, ctx = unwrapData(ctx, data)
+	state := e.newRenderState(ctx, nil)
+	known := []template.FuncMap{state.funcs(), builtinFuncs(), e.FuncMap, funcs}
+
+	trees, err := parseTreesUnchecked(name, text)
+	if err != nil {
+		return nil, err
+	}
+	report := &DryRunReport{Entry: name}
+	stubs := template.FuncMap{}
+	for _, tree := range trees {
+		walkTree(tree.Root, func(node parse.Node) bool {
+			ident, ok := node.(*parse.IdentifierNode)
+			if !ok || slices.Contains(templateBuiltins, ident.Ident) || stubs[ident.Ident] != nil {
+				return true
+			}
+			if !slices.ContainsFunc(known, func(m template.FuncMap) bool { return m[ident.Ident] != nil }) {
+				stubs[ident.Ident] = func(...any) any { return nil }
+				report.MissingFuncs = append(report.MissingFuncs, ident.Ident)
+			}
+			return true
+		})
+	}
+	slices.Sort(report.MissingFuncs)
+
+	var tmpl templateSet
+	if f.XML {
+		tmpl, err = parseXMLTemplate(name, text, append(known, stubs)...)
+	} else {
+		tmpl, err = parseHTMLTemplate(name, text, append(known, stubs)...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d := &dryRun{trees: trees, missing: map[string]struct{}{}, calls: map[string]int{}}
+	root := dryValue{values: []reflect.Value{reflect.ValueOf(data)}}
+	d.walk(trees[name].Root, root, map[string]dryValue{"$": root})
+	for path := range d.missing {
+		report.MissingData = append(report.MissingData, path)
+	}
+	slices.Sort(report.MissingData)
+
+	w := &countingWriter{w: io.Discard}
+	state.w = w
+	state.tmpl = tmpl
+	report.Err = tmpl.Execute(w, data)
+	report.Bytes = w.n
+	return report, nil
+}
+
+// parseTreesUnchecked parses the trees of a template text without checking that its funcs are defined.
+func parseTreesUnchecked(name string, text string) (map[string]*parse.Tree, error) {
+	trees := map[string]*parse.Tree{}
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(text, "", "", trees); err != nil {
+		return nil, err
+	}
+	return trees, nil
+}
+
+// dryValue is the possible values of a data path during a dry run, like the items of a range.
+// A value without values is unknown, its fields are not checked.
+type dryValue struct {
+	path   string
+	values []reflect.Value
+}
+
+// dryRun follows the data paths of the trees of a template against the data of a dry run.
+type dryRun struct {
+	trees   map[string]*parse.Tree
+	missing map[string]struct{}
+	// calls counts the templates being walked, to stop recursive partials
+	calls map[string]int
+}
+
+func (d *dryRun) walk(node parse.Node, dot dryValue, vars map[string]dryValue) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			d.walk(child, dot, vars)
+		}
+	case *parse.ActionNode:
+		value := d.pipe(n.Pipe, dot, vars)
+		for _, decl := range n.Pipe.Decl {
+			vars[decl.Ident[0]] = value
+		}
+	case *parse.IfNode:
+		d.pipe(n.Pipe, dot, vars)
+		d.walk(n.List, dot, maps.Clone(vars))
+		d.walk(n.ElseList, dot, maps.Clone(vars))
+	case *parse.WithNode:
+		value := d.pipe(n.Pipe, dot, vars)
+		d.walk(n.List, value, maps.Clone(vars))
+		d.walk(n.ElseList, dot, maps.Clone(vars))
+	case *parse.RangeNode:
+		items := d.items(d.pipe(n.Pipe, dot, vars))
+		scope := maps.Clone(vars)
+		if len(n.Pipe.Decl) > 0 {
+			scope[n.Pipe.Decl[len(n.Pipe.Decl)-1].Ident[0]] = items
+		}
+		d.walk(n.List, items, scope)
+		d.walk(n.ElseList, dot, maps.Clone(vars))
+	case *parse.TemplateNode:
+		tree, ok := d.trees[n.Name]
+		if !ok || d.calls[n.Name] > 0 {
+			return
+		}
+		value := dot
+		if n.Pipe != nil {
+			value = d.pipe(n.Pipe, dot, vars)
+		}
+		d.calls[n.Name]++
+		d.walk(tree.Root, value, map[string]dryValue{"$": value})
+		d.calls[n.Name]--
+	}
+}
+
+// pipe checks the data paths of a pipeline and returns its value, unknown unless it is a single field chain.
+func (d *dryRun) pipe(pipe *parse.PipeNode, dot dryValue, vars map[string]dryValue) dryValue {
+	if pipe == nil {
+		return dryValue{}
+	}
+	var value dryValue
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			value = d.arg(arg, dot, vars)
+		}
+	}
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return dryValue{}
+	}
+	return value
+}
+
+// arg checks the data paths of a command argument and returns its value.
+func (d *dryRun) arg(arg parse.Node, dot dryValue, vars map[string]dryValue) dryValue {
+	switch a := arg.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return d.field(dot, a.Ident)
+	case *parse.VariableNode:
+		return d.field(vars[a.Ident[0]], a.Ident[1:])
+	case *parse.PipeNode:
+		d.pipe(a, dot, vars)
+	case *parse.ChainNode:
+		d.arg(a.Node, dot, vars)
+	}
+	return dryValue{}
+}
+
+// field follows the names from value, recording the first name missing from one of its values.
+func (d *dryRun) field(value dryValue, names []string) dryValue {
+	for _, name := range names {
+		path := value.path + "." + name
+		var next []reflect.Value
+		for _, v := range value.values {
+			result, found, known := dryField(v, name)
+			if !found {
+				d.missing[path] = struct{}{}
+				return dryValue{}
+			}
+			if known {
+				next = append(next, result)
+			}
+		}
+		value = dryValue{path: path, values: next}
+	}
+	return value
+}
+
+// dryField looks name up in v like text/template does. Methods and nil values are not followed.
+func dryField(v reflect.Value, name string) (result reflect.Value, found bool, known bool) {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return reflect.Value{}, true, false
+	}
+	method := v
+	if method.CanAddr() {
+		method = method.Addr()
+	}
+	if method.MethodByName(name).IsValid() {
+		return reflect.Value{}, true, false
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		field, ok := v.Type().FieldByName(name)
+		if !ok || !field.IsExported() {
+			return reflect.Value{}, false, false
+		}
+		return v.FieldByIndex(field.Index), true, true
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, true, false
+		}
+		item := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !item.IsValid() {
+			return reflect.Value{}, false, false
+		}
+		return item, true, true
+	}
+	return reflect.Value{}, false, false
+}
+
+// items returns the items ranged over of a value, unknown for ranges over funcs and integers.
+func (d *dryRun) items(value dryValue) dryValue {
+	items := dryValue{path: value.path + "[]"}
+	for _, v := range value.values {
+		switch v = indirectValue(v); v.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := range v.Len() {
+				items.values = append(items.values, v.Index(i))
+			}
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				items.values = append(items.values, iter.Value())
+			}
+		}
+	}
+	return items
+}
@@ -0,0 +1,66 @@
+package blade
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type dryRunUser struct {
+	Name string
+}
+
+func (u dryRunUser) Greeting() string { return "Hi " + u.Name }
+
+func TestDryRun(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade":        `<title>{{ .Title }}</title>@yield('main')`,
+		"partials/user.blade": `{{ .Name }} {{ .Greeting }} {{ .Email }}`,
+		"page.blade": `@extends('layout')@section('main')@include('partials/user', .User)` +
+			`{{ range .Items }}{{ .Name }}: {{ money .Price }}{{ end }}{{ with .Coupon }}{{ .Code }}{{ end }}{{ shout .Footer }}@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.FuncMap["shout"] = func(s any) string { return "" }
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	delete(engine.FuncMap, "shout")
+
+	report, err := engine.DryRun("page", map[string]any{
+		"Title":  "Shop",
+		"User":   dryRunUser{Name: "Ada"},
+		"Items":  []map[string]any{{"Name": "Pen", "Price": 2}, {"Name": "Ink"}},
+		"Coupon": nil,
+	})
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	if expected := []string{".Footer", ".Items[].Price", ".User.Email"}; !reflect.DeepEqual(report.MissingData, expected) {
+		t.Errorf("Missing data mismatch.\nExp: %v\nGot: %v", expected, report.MissingData)
+	}
+	if expected := []string{"shout"}; !reflect.DeepEqual(report.MissingFuncs, expected) {
+		t.Errorf("Missing funcs mismatch.\nExp: %v\nGot: %v", expected, report.MissingFuncs)
+	}
+	if report.Err == nil {
+		t.Error("Expected the render error of the missing User.Email field")
+	}
+	if report.OK() {
+		t.Error("Expected the report not to be OK")
+	}
+
+	report, err = engine.DryRun("layout", map[string]any{"Title": "Shop"})
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "layout", map[string]any{"Title": "Shop"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !report.OK() || report.Bytes != int64(buf.Len()) {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	if _, err := engine.DryRun("missing", nil); err == nil {
+		t.Error("Expected an error for a view not loaded")
+	}
+}
@@ -1,21 +1,45 @@
 package blade
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"hash/maphash"
 	"html/template"
 	"io"
 	"io/fs"
+	"maps"
+	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 )
 
+// DefaultMaxIncludeDepth is the default limit of recursive partial includes
+const DefaultMaxIncludeDepth = 64
+
+// DefaultMaxWhileIterations is the default limit of the iterations of a @while loop without bound
+const DefaultMaxWhileIterations = 10000
+
 var DefaultValidFileExtensions = []string{".blade", ".tmpl", ".html", ".gohtml"}
 
+// DefaultXMLFileExtensions are the extensions of files compiled with XML escaping, for sitemaps and feeds.
+var DefaultXMLFileExtensions = []string{".xml"}
+
+// RefreshableFS is a template source refreshed by Load before it looks for modified files,
+// like the database and remote sources of the loader package.
+type RefreshableFS interface {
+	fs.FS
+	Refresh(ctx context.Context) error
+}
+
 // EntryFilter is a function that determines whether a parsed file should be available as a view
 type EntryFilter func(file *ParsedFile) bool
 
@@ -28,15 +52,133 @@
 type Engine struct {
 	dirPrefix              string
 	fs                     fs.FS
-	parsedFiles            map[string]*ParsedFile
-	debugTemplates         map[string]string
-	templates              map[string]*template.Template
+	set                    atomic.Pointer[compiledSet]
+	health                 atomic.Pointer[engineHealth]
+	tenants                tenantCache
+	themes                 sync.Map
+	drafts                 *overrideSet
+	generations            atomic.Pointer[[]*compiledSet]
+	manifest               *loadManifest
+	honeypotKey            []byte
+	now                    func() time.Time
+	breakers               sync.Map
+	asyncLoad              atomic.Int32
+	reloadSnapshot         map[string]time.Time
 	lastCompileTime        int64
 	mu                     sync.Mutex
 	ValidFileExtensions    []string
+	XMLFileExtensions      []string
 	FuncMap                template.FuncMap
 	EntryFilter            EntryFilter
 	IgnoreInvalidPushStack bool
+	// CompatibilityMode enables Blade flavoured expressions inside actions, like the null-safe {{ .User?.Name }}
+	CompatibilityMode bool
+	// Locale is the default BCP 47 locale used by formatting helpers, it can be overridden per render with WithLocale
+	Locale string
+	// Currency is the default ISO 4217 currency code used by the money helper
+	Currency string
+	// RelativeTimeLocales holds the words of the timeAgo and dateDiff helpers by language
+	RelativeTimeLocales map[string]RelativeTimeLocale
+	// MaxIncludeDepth limits how deep a partial can recursively @include itself within a render
+	MaxIncludeDepth int
+	// MaxWhileIterations fails the renders of @while loops without bound iterating more, like a condition
+	// never becoming false
+	MaxWhileIterations int
+	// ParamTypes maps the type names used by @param declarations to Go types, like
+	// ParamTypes["models.User"] = reflect.TypeFor[models.User](), to validate field references
+	ParamTypes map[string]reflect.Type
+	// DisableDebugTemplates stops keeping the compiled template texts returned by GetDebugTemplates, saving memory
+	DisableDebugTemplates bool
+	// TenantFS provides the templates of each tenant for Engine.Tenant, see TenantDirs
+	TenantFS TenantFSFunc
+	// MaxTenants is the number of tenants whose compiled templates are kept, DefaultMaxTenants when zero
+	MaxTenants int
+	// Themes are the skins rendered by Engine.Theme, by name
+	Themes map[string]ThemeSource
+	// DraftFS holds unpublished templates, overlaid over the engine templates by renders with a preview
+	// token, see WithPreview
+	DraftFS fs.FS
+	// PreviewKey signs the preview tokens of SignPreview
+	PreviewKey []byte
+	// BatchWorkers limits the concurrent renders of RenderBatch, GOMAXPROCS when zero
+	BatchWorkers int
+	// KeepGenerations is the number of compiled generations kept for Rollback and WithGeneration
+	KeepGenerations int
+	// ReloadDebounce is how long the files must stay unchanged before LoadAsync and Watch compile them, so an
+	// editor saving many files or a git checkout is compiled in a single pass. DefaultReloadDebounce when zero,
+	// negative compiles at once.
+	ReloadDebounce time.Duration
+	// ReloadMaxDelay bounds the wait of ReloadDebounce while files keep changing, DefaultReloadMaxDelay when zero
+	ReloadMaxDelay time.Duration
+	// BufferOutput renders into a buffer written at once when the render succeeds, so a failing render writes
+	// nothing, like a half rendered page. @flush does not stream while it is enabled.
+	BufferOutput bool
+	// Minify collapses the whitespace of the HTML text of templates when compiling them, except in pre, textarea,
+	// script and style elements
+	Minify bool
+	// StrictVariables fails renders reading a missing key of a map, instead of printing "<no value>"
+	StrictVariables bool
+	// Sandbox fails the compile of views calling funcs of FuncMap returning types html/template does not escape,
+	// like a raw helper casting strings to template.HTML, so untrusted values go through safeHTML, safeURL or
+	// safeJS. SandboxTrustedFuncs lists the funcs allowed anyway, like helpers sanitizing their output.
+	Sandbox             bool
+	SandboxTrustedFuncs []string
+	// IsolateIncludes passes an empty map to partials included without data instead of the data of the view,
+	// like @includeData, so partials cannot depend on the shape of the data of the pages including them
+	IsolateIncludes bool
+	// StrictSections fails the compile of views filling a section that no layout yields, instead of the
+	// unused-section warning of Warnings, since the content of the section is silently dropped
+	StrictSections bool
+	// SectionPolicy decides how the sections of child views combine with the same sections of their layouts,
+	// SectionOverride when empty. A policy given to @section('scripts', policy: 'append') takes precedence.
+	SectionPolicy SectionPolicy
+	// SectionPolicies overrides SectionPolicy for sections by name
+	SectionPolicies map[string]SectionPolicy
+	// OnSectionTimeout is called when a section with a timeout, like @section('report', timeout: '2s'), renders
+	// its fallback, a warning is logged with slog when it is nil
+	OnSectionTimeout func(ctx context.Context, section string, timeout time.Duration)
+	// Breaker configures the circuit breakers of the partials included with a fallback, like
+	// @include('widgets/weather', .Weather, fallback: 'Weather unavailable'), or with breaker: true
+	Breaker BreakerOptions
+	// DevMode keeps the views that compile renderable when others fail to parse or compile,
+	// the broken views render an error overlay instead of failing Load
+	DevMode bool
+	// Picture configures the breakpoint widths, formats and resized URLs of the images of @picture
+	Picture PictureOptions
+	// OnRender is called after every render with its duration and compile cache status, to feed metrics
+	OnRender func(RenderMetrics)
+	// ServerTiming adds a Server-Timing header with the render duration and compile cache status to the responses
+	// of Respond and the gin HTMLRender. The output is buffered to send the header after rendering.
+	ServerTiming bool
+	// DeferStyle is the markup of the placeholders of @defer, DeferHTMX or DeferTurbo, DeferHTMX when empty
+	DeferStyle string
+	// HoneypotField is the name prefix of the fields rendered by @honeypot, DefaultHoneypotField when empty
+	HoneypotField string
+	// HoneypotKey signs the timestamps rendered by @honeypot, a random key of the engine when empty.
+	// Set it when forms are validated by another instance than the one rendering them.
+	HoneypotKey []byte
+	// HoneypotMinAge is the time a form with @honeypot must be displayed before ValidateHoneypot accepts it,
+	// DefaultHoneypotMinAge when zero
+	HoneypotMinAge time.Duration
+	// HoneypotMaxAge is the time after rendering a form with @honeypot when ValidateHoneypot stops accepting it,
+	// DefaultHoneypotMaxAge when zero, so captured fields cannot be replayed forever
+	HoneypotMaxAge time.Duration
+	// Security configures the CSRF field of @csrf, the nonce of @nonce and the headers of the middleware of the
+	// security package providing them
+	Security SecurityOptions
+	// Features decides the feature flags of @feature blocks, it can be overridden per render with WithFeatures.
+	// Flags are disabled when it is nil.
+	Features FeatureChecker
+	// Experiments assigns the variants of @experiment blocks, the first variant renders when it is nil
+	Experiments ExperimentAssigner
+	// OnExposure is called the first time a render shows a variant of each experiment, to log the exposure
+	// with the analytics of the experiment
+	OnExposure func(ctx context.Context, exposure Exposure)
+	// Analytics standardizes the events and attributes of @track and the track and trackEvent helpers
+	Analytics AnalyticsSchema
+	// Validators check the output of HTML renders, a failing validator fails the render.
+	// The output is buffered while validating, so they are meant for development.
+	Validators []Validator
 }
 
 // NewEngine creates a new engine pointing to a directory with files.
@@ -54,41 +196,80 @@ func NewEngineFS(fs fs.FS, prefix ...string) *Engine {
 
 	validExts := make([]string, len(DefaultValidFileExtensions))
 	copy(validExts, DefaultValidFileExtensions)
+	xmlExts := make([]string, len(DefaultXMLFileExtensions))
+	copy(xmlExts, DefaultXMLFileExtensions)
 
-	return &Engine{
+	e := &Engine{
 		dirPrefix:              dirPrefix,
 		fs:                     fs,
-		parsedFiles:            map[string]*ParsedFile{},
-		debugTemplates:         map[string]string{},
-		templates:              make(map[string]*template.Template),
 		lastCompileTime:        -1,
+		honeypotKey:            newHoneypotKey(),
+		now:                    time.Now,
 		ValidFileExtensions:    validExts,
+		XMLFileExtensions:      xmlExts,
 		FuncMap:                template.FuncMap{},
 		EntryFilter:            DefaultEntryFilter,
 		IgnoreInvalidPushStack: false,
+		Locale:                 "en",
+		Currency:               "USD",
+		RelativeTimeLocales:    maps.Clone(DefaultRelativeTimeLocales),
+		MaxIncludeDepth:        DefaultMaxIncludeDepth,
+		MaxWhileIterations:     DefaultMaxWhileIterations,
+		ParamTypes:             map[string]reflect.Type{},
+		Themes:                 map[string]ThemeSource{},
+		KeepGenerations:        DefaultKeepGenerations,
 	}
+	e.drafts = e.newDraftSet()
+	e.set.Store(newCompiledSet())
+	e.generations.Store(&[]*compiledSet{})
+	e.health.Store(&engineHealth{loadErr: errNotLoaded})
+	return e
 }
 
 // Load reads all files with .blade or .tmpl extension from the fs.
 // It will only recompile if the files have been modified since last compile.
+// The loaded templates are replaced at once when the compile succeeds, a failing Load keeps the previous ones.
 func (e *Engine) Load() error {
 	e.mu.Lock()
-	defer func() {
-		e.lastCompileTime = time.Now().UnixMilli()
-		e.mu.Unlock()
-	}()
+	defer e.mu.Unlock()
+
+	err := e.load()
+	e.setLoadError(err)
+	return err
+}
+
+func (e *Engine) load() (err error) {
+	startedAt := time.Now().UnixMilli()
+	if r, ok := e.fs.(RefreshableFS); ok {
+		if err := r.Refresh(context.Background()); err != nil {
+			return err
+		}
+	}
+	if e.manifest != nil && e.manifest.unchanged(e.fs) {
+		return nil
+	}
+	e.manifest = nil
+	manifest := newLoadManifest()
+	useManifest := true
 
+	current := e.set.Load()
+	parsedFiles := maps.Clone(current.parsedFiles)
+	brokenFiles := maps.Clone(current.brokenFiles)
 	needCompile := false
 
-	err := fs.WalkDir(e.fs, ".", func(path string, info fs.DirEntry, err error) error {
+	err = fs.WalkDir(e.fs, ".", func(path string, info fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		if info.IsDir() {
+			if useManifest {
+				stats, err := info.Info()
+				useManifest = err == nil && manifest.add(path, stats)
+			}
 			return nil
 		}
-		ext := strings.ToLower(filepath.Ext(path))
-		if !slices.Contains(e.ValidFileExtensions, ext) {
+		isTemplate, isXML := e.templateFileKind(path)
+		if !isTemplate {
 			return nil
 		}
 
@@ -96,8 +277,13 @@ func (e *Engine) Load() error {
 		if err != nil {
 			return err
 		}
+		manifest.add(path, stats)
 
-		if stats.ModTime().UnixMilli() <= e.lastCompileTime {
+		// new files are read whatever their modification time, like copied files or embed.FS ones without one
+		name := e.nameFromPath(path)
+		_, parsed := parsedFiles[name]
+		_, broken := brokenFiles[name]
+		if (parsed || broken) && stats.ModTime().UnixMilli() <= e.lastCompileTime {
 			return nil
 		}
 
@@ -111,121 +297,255 @@ func (e *Engine) Load() error {
 		if err != nil {
 			return err
 		}
-		name := e.nameFromPath(path)
 		parsedFile, err := e.parseFile(name, string(raw))
 		if err != nil {
-			return err
+			if !e.DevMode {
+				return err
+			}
+			// the views depending on the file fail to compile and render the error overlay
+			delete(parsedFiles, name)
+			brokenFiles[name] = brokenFile{file: &ParsedFile{Name: name, Path: path, Raw: string(raw), XML: isXML}, err: err}
+			return nil
 		}
-		e.parsedFiles[name] = parsedFile
+		parsedFile.Path = path
+		parsedFile.XML = isXML
+		parsedFiles[name] = parsedFile
+		delete(brokenFiles, name)
 		return nil
 	})
 	if err != nil {
 		return err
 	}
 
+	if useManifest {
+		defer func() {
+			if err == nil {
+				e.manifest = manifest
+			}
+		}()
+	}
+
 	if !needCompile {
 		return nil
 	}
 
-	// TODO: compile only changed files and dependencies
+	// entries whose template text did not change, like the views not depending on the changed files, keep their
+	// compiled template
+	set := newCompiledSet()
+	set.parsedFiles = parsedFiles
+	set.warnings = e.compositionWarnings(parsedFiles)
+
+	set.brokenFiles = brokenFiles
+	for name, broken := range brokenFiles {
+		if e.EntryFilter(broken.file) {
+			set.templates[name] = &compiledTemplate{err: broken.err, xml: broken.file.XML}
+		}
+	}
 
-	for name, f := range e.parsedFiles {
+	for name, f := range parsedFiles {
 		if !e.EntryFilter(f) {
 			continue
 		}
-		ctx := &CompileContext{
-			Files:          e.parsedFiles,
-			Yields:         map[string]YieldInfo{},
-			FilledSections: map[string]struct{}{},
-			FilledIncludes: map[string]struct{}{},
-			Stacks:         map[string]string{},
-			PushStacks:     map[string][]string{},
-		}
-		bodyText, defText, err := f.ToTemplateString(ctx)
+		tmplText, err := e.buildTemplateText(parsedFiles, f)
 		if err != nil {
+			if e.DevMode {
+				set.templates[name] = &compiledTemplate{err: err, xml: f.XML}
+				continue
+			}
 			return err
 		}
-
-		if !e.IgnoreInvalidPushStack {
-			for stackName := range ctx.PushStacks {
-				if _, ok := ctx.Stacks[stackName]; !ok {
-					return fmt.Errorf(`[%s] missing stack "%s"`, f.Name, stackName)
+		if !e.DisableDebugTemplates {
+			set.debugTemplates[name] = tmplText
+		}
+		textHash := maphash.String(templateTextSeed, tmplText)
+		tmpl := current.templates[name]
+		if tmpl == nil || tmpl.err != nil || tmpl.xml != f.XML || tmpl.textHash != textHash {
+			tmpl, err = e.compileTemplate(name, tmplText, f.XML)
+			if err != nil {
+				if e.DevMode {
+					set.templates[name] = &compiledTemplate{err: err, xml: f.XML}
+					continue
 				}
+				// TODO: parse template error to point to the debug template content
+				return err
 			}
+			tmpl.textSize = len(tmplText)
+			tmpl.textHash = textHash
 		}
-
-		defText += e.buildDefaultYieldContent(ctx)
-		tmplText := defText + bodyText
-		e.debugTemplates[name] = tmplText
-		e.templates[name], err = template.New(name).Funcs(e.FuncMap).Parse(tmplText)
-		if err != nil {
-			// TODO: parse template error to point to the debug template content
-			return err
-		}
+		set.templates[name] = tmpl
+		set.warnings = append(set.warnings, e.paramWarnings(parsedFiles, f, tmpl.proto)...)
 	}
 
+	e.storeGeneration(set)
+	// files modified while loading are picked up by the next Load
+	e.lastCompileTime = startedAt - 1
 	return nil
 }
 
 // Render executes the template identified by entry (e.g., "pages/home") into io.Writer with data.
 func (e *Engine) Render(w io.Writer, entry string, data any) error {
-	tmpl, ok := e.GetTemplate(entry)
-	if !ok {
-		return fmt.Errorf("template %s not loaded", entry)
-	}
-	return tmpl.Execute(w, data)
+	return e.execute(context.Background(), w, entry, data)
+}
+
+// RenderContext is like Render, the context provides request scoped values to helpers like currentURL.
+func (e *Engine) RenderContext(ctx context.Context, w io.Writer, entry string, data any) error {
+	return e.execute(ctx, w, entry, data)
 }
 
 // GetTemplate returns the template identified by entry.
 func (e *Engine) GetTemplate(entry string) (*template.Template, bool) {
 	entry = normalizeName(entry)
-	tmpl, ok := e.templates[entry]
-	return tmpl, ok
+	tmpl, ok := e.set.Load().templates[entry]
+	if !ok {
+		return nil, false
+	}
+	exec, ok := tmpl.exec.(htmlTemplateSet)
+	if !ok {
+		return nil, false
+	}
+	return exec.Template, true
+}
+
+// Warnings returns the sections no layout yields and the yields no view fills, found by the last compile.
+// Both are almost always authoring mistakes, but do not fail Load.
+func (e *Engine) Warnings() []Diagnostic {
+	return e.set.Load().warnings
 }
 
 // GetDebugTemplates returns a map of all loaded templates and their content.
 func (e *Engine) GetDebugTemplates() map[string]string {
-	return e.debugTemplates
+	return e.set.Load().debugTemplates
 }
 
+// reWithAs matches a binding of @with: .Order.Customer as customer
+var reWithAs = regexp.MustCompile(`^\s*(.+?)\s+as\s+\$?(\w+)\s*$`)
+
+// reForLoop matches the arguments of a @for loop: $i = 0; $i < 5; $i++, or $i = 10; $i >= 0; $i -= 2
+var reForLoop = regexp.MustCompile(`^\s*\$(\w+)\s*=\s*(.+?)\s*;\s*\$(\w+)\s*(<=|<|>=|>)\s*(.+?)\s*(?:;\s*\$(\w+)\s*(\+\+|--|\+=\s*.+?|-=\s*.+?))?\s*;?\s*$`)
+
+// reIssetPath matches the arguments of @isset walked by isset: .User.Name, $user.Name or $.User
+var reIssetPath = regexp.MustCompile(`^(\$\w*|)((?:\.\w+)*)$`)
+
+// reForAs matches the last argument of a short @for loop binding a variable: 10 as $i
+var reForAs = regexp.MustCompile(`^\s*(.+?)\s+as\s+\$(\w+)\s*$`)
+
 var (
-	reExtend     = regexp.MustCompile(`@extends\(['"]([\w\-/. ]+)['"]\)`)                    // allow slashes for dirs
-	reYield      = regexp.MustCompile(`@yield\(['"]([\w\-]+)['"](?:,\s*['"]([^)]*)['"])?\)`) //	@yield('name',	'default')
-	reSectionEnd = regexp.MustCompile(`@endsection`)                                         //	@endsection
-	reStack      = regexp.MustCompile(`@stack\(['"]([\w\-]+)['"]\)`)                         //	@stack('name')
-	rePushStart  = regexp.MustCompile(`@push\(['"]([\w\-]+)['"]\)`)                          //	@push('stack_name')
-	rePushEnd    = regexp.MustCompile(`@endpush`)                                            //	@endpush
+	reExtend        = regexp.MustCompile(`@extends\(['"]([\w\-/. ]+)['"]\)`) // allow slashes for dirs
+	reSectionEnd    = regexp.MustCompile(`@endsection`)                      //	@endsection
+	reStack         = regexp.MustCompile(`@stack\(['"]([\w\-]+)['"]\)`)      //	@stack('name')
+	reOnce          = regexp.MustCompile(`@once\b`)                          //	@once
+	reOnceEnd       = regexp.MustCompile(`@endonce\b`)                       //	@endonce
+	reForEnd        = regexp.MustCompile(`@endfor\b`)                        //	@endfor
+	reWhileEnd      = regexp.MustCompile(`@endwhile\b`)                      //	@endwhile
+	reWithEnd       = regexp.MustCompile(`@endwith\b`)                       //	@endwith
+	reUnlessEnd     = regexp.MustCompile(`@endunless\b`)                     //	@endunless
+	reSessionEnd    = regexp.MustCompile(`@endsession\b`)                    //	@endsession
+	reStepEnd       = regexp.MustCompile(`@endstep\b`)                       //	@endstep
+	reIssetEnd      = regexp.MustCompile(`@endisset\b`)                      //	@endisset
+	reEmptyEnd      = regexp.MustCompile(`@endempty\b`)                      //	@endempty
+	reFeatureEnd    = regexp.MustCompile(`@endfeature\b`)                    //	@endfeature
+	reExperimentEnd = regexp.MustCompile(`@endexperiment\b`)                 //	@endexperiment
+	reFlush         = regexp.MustCompile(`@flush\b`)                         //	@flush
+	reDirection     = regexp.MustCompile(`@(rtl|ltr|endrtl|endltr)\b`)       //	@rtl ... @endrtl
+	reHoneypot      = regexp.MustCompile(`@honeypot\b`)                      //	@honeypot
+	reCSRF          = regexp.MustCompile(`@csrf\b`)                          //	@csrf
+	reNonce         = regexp.MustCompile(`@nonce\b`)                         //	@nonce
 )
 
+// knownDirectives are the directives handled by parseFile, other @name tokens are reported by Diagnose.
+var knownDirectives = map[string]struct{}{
+	"extends": {}, "section": {}, "endsection": {}, "yield": {}, "include": {}, "stack": {}, "push": {}, "endpush": {},
+	"prependOnce": {}, "endprependOnce": {},
+	"once": {}, "endonce": {}, "for": {}, "endfor": {}, "while": {}, "endwhile": {}, "state": {}, "import": {}, "call": {}, "macro": {}, "endmacro": {},
+	"define": {}, "enddefine": {}, "use": {}, "param": {}, "flush": {}, "plural": {},
+	"rtl": {}, "endrtl": {}, "ltr": {}, "endltr": {}, "meta": {},
+	"honeypot": {}, "csrf": {}, "nonce": {}, "picture": {}, "defer": {}, "enddefer": {},
+	"capture": {}, "endcapture": {}, "yieldIf": {}, "includeData": {},
+	"with": {}, "endwith": {}, "set": {}, "feature": {}, "else": {}, "endfeature": {}, "unless": {}, "endunless": {}, "isset": {}, "endisset": {},
+	"session": {}, "endsession": {}, "wizard": {}, "step": {}, "endstep": {},
+	"foreach": {}, "endforeach": {}, "forelse": {}, "empty": {}, "endforelse": {}, "endempty": {},
+	"experiment": {}, "variant": {}, "endexperiment": {}, "track": {}, "island": {},
+}
+
 // parseFile parses Blade-like directives
 func (e *Engine) parseFile(name string, raw string) (*ParsedFile, error) {
 	p := &ParsedFile{
-		Name:       name,
-		Raw:        raw,
-		Includes:   map[string]struct{}{},
-		Yields:     map[string]string{},
-		Sections:   map[string]string{},
-		Stacks:     map[string]struct{}{},
-		PushStacks: map[string][]string{},
-		ParsedAt:   time.Now().UnixMilli(),
-	}
-	rest := raw
-
-	if loc := reExtend.FindStringSubmatchIndex(raw); loc != nil {
+		Name:            name,
+		Raw:             raw,
+		Includes:        map[string]struct{}{},
+		Yields:          map[string]string{},
+		Sections:        map[string]string{},
+		SectionPolicies: map[string]SectionPolicy{},
+		Stacks:          map[string]struct{}{},
+		PushStacks:      map[string][]StackPush{},
+		Snippets:        map[string]string{},
+		Macros:          map[string]string{},
+		Imports:         map[string]struct{}{},
+		Deferred:        map[string]string{},
+		Directives:      locateDirectives(raw),
+		ParsedAt:        time.Now().UnixMilli(),
+	}
+	// convert the @else branches of blocks first, as their blocks are converted separately:
+	// @feature('beta') ... @else ... @endfeature => @feature('beta') ... {{ else }} ... @endfeature
+	rest := parseElseDirectives(raw, p.Directives)
+
+	if loc := reExtend.FindStringSubmatchIndex(rest); loc != nil {
 		parentName := rest[loc[2]:loc[3]]
 		p.Extends = normalizeName(parentName)
 		rest = rest[:loc[0]] + rest[loc[1]:]
 	}
 
-	// convert @yield to template inclusion: @yield('name') => {{ template "__section_name" . }}
-	rest = reYield.ReplaceAllStringFunc(rest, func(m string) string {
-		sm := reYield.FindStringSubmatch(m)
-		if len(sm) >= 3 {
-			yieldName := normalizeName(sm[1])
-			p.Yields[yieldName] = sm[2]
-			return fmt.Sprintf(`{{ template "%s%s" . }}`, sectionNamePrefix, yieldName)
+	// collect data contract declarations: @param('User', 'models.User')
+	rest = replaceDirectiveCalls(rest, "param", func(args []string) (string, bool) {
+		if len(args) != 2 {
+			return "", false
 		}
-		return m
+		paramName, ok := unquoteDirectiveString(strings.TrimSpace(args[0]))
+		if !ok || !isIdentifier(paramName) {
+			return "", false
+		}
+		paramType, ok := unquoteDirectiveString(strings.TrimSpace(args[1]))
+		if !ok || paramType == "" {
+			return "", false
+		}
+		p.Params = append(p.Params, Param{Name: paramName, Type: paramType})
+		return "", true
+	})
+
+	// convert @yield to template inclusion: @yield('name', 'default') => {{ template "__section_name" . }}
+	// the default can be a quoted text or a pipeline: @yield('name', .Title | upper)
+	rest = replaceDirectiveCalls(rest, "yield", func(args []string) (string, bool) {
+		if len(args) == 0 || len(args) > 2 {
+			return "", false
+		}
+		yieldName, ok := parseQuotedDirectiveName(args[0])
+		if !ok {
+			return "", false
+		}
+		p.Yields[yieldName] = ""
+		if len(args) > 1 {
+			p.Yields[yieldName] = directiveValueToTemplate(args[1])
+		}
+		return fmt.Sprintf(`{{ template "%s%s" . }}`, sectionNamePrefix, yieldName), true
+	})
+
+	// convert @yieldIf to a conditional template inclusion: @yieldIf('sidebar', .ShowSidebar) =>
+	// {{ if (.ShowSidebar) }}{{ template "__section_sidebar" . }}{{ end }}, the third argument is the default content
+	rest = replaceDirectiveCalls(rest, "yieldIf", func(args []string) (string, bool) {
+		if len(args) < 2 || len(args) > 3 || strings.TrimSpace(args[1]) == "" {
+			return "", false
+		}
+		yieldName, ok := parseQuotedDirectiveName(args[0])
+		if !ok {
+			return "", false
+		}
+		if _, ok := p.Yields[yieldName]; !ok || len(args) > 2 {
+			p.Yields[yieldName] = ""
+			if len(args) > 2 {
+				p.Yields[yieldName] = directiveValueToTemplate(args[2])
+			}
+		}
+		return fmt.Sprintf(`{{ if (%s) }}{{ template "%s%s" . }}{{ end }}`, strings.TrimSpace(args[1]), sectionNamePrefix, yieldName), true
 	})
 
 	// convert @stack to template inclusion: @stack('name') => {{ template "__stack_name" . }}
@@ -239,6 +559,15 @@ func (e *Engine) parseFile(name string, raw string) (*ParsedFile, error) {
 		return m
 	})
 
+	// convert @meta to a push of the built-in meta component to the meta stack
+	rest = replaceDirectiveCalls(rest, "meta", parseMetaDirective)
+
+	// convert @picture to an include of the built-in picture component
+	rest = replaceDirectiveCalls(rest, "picture", parsePictureDirective)
+
+	// convert @wizard to an include of the built-in wizard component
+	rest = replaceDirectiveCalls(rest, "wizard", parseWizardDirective)
+
 	// process includes: @include('partial') -> {{ template "__include_partial" . }}
 	rest = replaceDirectiveCalls(rest, "include", func(args []string) (string, bool) {
 		if len(args) == 0 {
@@ -248,17 +577,346 @@ func (e *Engine) parseFile(name string, raw string) (*ParsedFile, error) {
 		if !ok {
 			return "", false
 		}
+		// @include('badge', .Status, memo: true) renders the partial once per distinct data within a render,
+		// @include('header', esi: '/fragments/header') renders an ESI tag instead in the ESI mode of WithESI,
+		// @include('weather', fallback: 'Unavailable') renders the fallback while the breaker of the partial is open
+		memo, esi, guarded, fallback := false, "", false, ""
+		for len(args) > 1 {
+			argName, value, ok := parseNamedDirectiveArg(args[len(args)-1])
+			if !ok {
+				break
+			}
+			switch {
+			case argName == "memo" && (value == "true" || value == "false"):
+				memo = value == "true"
+			case argName == "esi" && value != "":
+				esi = value
+			case argName == "breaker" && (value == "true" || value == "false"):
+				guarded = value == "true"
+			case argName == "fallback" && value != "":
+				guarded, fallback = true, directiveValueToTemplate(value)
+			default:
+				return "", false
+			}
+			args = args[:len(args)-1]
+		}
+		if len(args) > 2 {
+			return "", false
+		}
 		pipeline := "."
-		if len(args) > 1 {
+		if e.IsolateIncludes {
+			// partials only receive the data passed explicitly
+			pipeline = "(__includeData)"
+		}
+		if len(args) > 1 && strings.TrimSpace(args[1]) != "" {
 			pipeline = strings.TrimSpace(args[1])
-			if pipeline == "" {
-				pipeline = "."
+		}
+		include := p.includeTemplate(partialName, pipeline, memo)
+		if guarded {
+			include = p.guardedInclude(partialName, pipeline, memo, fallback)
+		}
+		if esi != "" {
+			return esiInclude(esi, include), true
+		}
+		return include, true
+	})
+
+	// convert islands: @island('cart-widget', .Cart) renders the partial in a marker with its JSON props for hydration
+	rest = replaceDirectiveCalls(rest, "island", p.parseIslandDirective)
+
+	// process isolated includes: @includeData('card', title: .Title) passes only the given data to the partial
+	rest = replaceDirectiveCalls(rest, "includeData", p.parseIncludeDataDirective)
+
+	// convert @once blocks: @once(.Key) ... @endonce => {{ if __once "name:1" (.Key) }} ... {{ end }}
+	onceCount := 0
+	rest = replaceDirectiveCalls(rest, "once", func(args []string) (string, bool) {
+		if len(args) > 1 {
+			return "", false
+		}
+		onceCount++
+		onceID := fmt.Sprintf("%s:%d", p.Name, onceCount)
+		if len(args) == 0 {
+			return fmt.Sprintf(`{{ if __once %q }}`, onceID), true
+		}
+		return fmt.Sprintf(`{{ if __once %q (%s) }}`, onceID, args[0]), true
+	})
+	rest = replaceDirectiveTokens(rest, reOnce, func(string) string {
+		onceCount++
+		return fmt.Sprintf(`{{ if __once %q }}`, fmt.Sprintf("%s:%d", p.Name, onceCount))
+	})
+	rest = reOnceEnd.ReplaceAllString(rest, "{{ end }}")
+
+	// convert @use to template inclusion: @use('snippet', data) => {{ template "__snippet_file:snippet" data }}
+	rest = replaceDirectiveCalls(rest, "use", func(args []string) (string, bool) {
+		if len(args) == 0 || len(args) > 2 {
+			return "", false
+		}
+		snippetName, ok := parseQuotedDirectiveName(args[0])
+		if !ok {
+			return "", false
+		}
+		pipeline := "."
+		if len(args) > 1 {
+			pipeline = args[1]
+		}
+		return fmt.Sprintf(`{{ template "%s" %s }}`, snippetTemplateName(p.Name, snippetName), pipeline), true
+	})
+
+	// Parse inline sub-templates: @define('snippet') ... @enddefine
+	rest, err := extractBlocks(rest, "define", "@enddefine", func(args []string, content string) error {
+		if len(args) != 1 {
+			return errors.New("invalid @define directive")
+		}
+		snippetName, ok := parseQuotedDirectiveName(args[0])
+		if !ok {
+			return fmt.Errorf("invalid @define name %s", args[0])
+		}
+		if _, ok := p.Snippets[snippetName]; ok {
+			return fmt.Errorf(`duplicate @define name "%s"`, snippetName)
+		}
+		p.Snippets[snippetName] = content
+		return nil
+	})
+	if err != nil {
+		return nil, p.locateError(err)
+	}
+
+	// convert @for loops: @for($i = 0; $i < 5; $i++) ... @endfor => {{ range $i, $__ := __for . (0) (5) 1 false }} ...
+	// {{ end }} and the short form @for(0, 5) or @for(0, 10, 2 as $i), the range keeps the dot
+	rest = replaceDirectiveCalls(rest, "for", func(args []string) (string, bool) {
+		switch len(args) {
+		case 1:
+			return forLoopRange(args[0])
+		case 2, 3:
+			return shortForLoopRange(args)
+		}
+		return "", false
+	})
+	rest = reForEnd.ReplaceAllString(rest, "{{ end }}")
+
+	// convert @while loops: @while(.Rows.Next) ... @endwhile => {{ range $__while1, $__ := __while . }}{{ if not
+	// (__whileCheck $__while1 (.Rows.Next)) }}{{ break }}{{ end }} ... {{ end }}, the condition is evaluated
+	// before each iteration, and the range keeps the dot. @while(.Rows.Next, 50) stops after 50 iterations, loops without bound fail the
+	// render after Engine.MaxWhileIterations.
+	whiles := 0
+	rest = replaceDirectiveCalls(rest, "while", func(args []string) (string, bool) {
+		if len(args) == 0 || len(args) > 2 || strings.TrimSpace(args[0]) == "" {
+			return "", false
+		}
+		whiles++
+		counter := fmt.Sprintf("$__while%d", whiles)
+		cond := strings.TrimSpace(args[0])
+		if len(args) == 2 {
+			return fmt.Sprintf(`{{ range %s, $__ := __while . (%s) }}{{ if not (%s) }}{{ break }}{{ end }}`, counter, strings.TrimSpace(args[1]), cond), true
+		}
+		return fmt.Sprintf(`{{ range %s, $__ := __while . }}{{ if not (__whileCheck %s (%s)) }}{{ break }}{{ end }}`, counter, counter, cond), true
+	})
+	rest = reWhileEnd.ReplaceAllString(rest, "{{ end }}")
+
+	// convert @foreach loops: @foreach(.Items as $item) ... @endforeach ranges over the items binding $item and
+	// $loop, @forelse(.Items as $item) ... @empty ... @endforelse renders the @empty branch without items, see
+	// parseForeachDirectives
+	rest = parseForeachDirectives(rest)
+
+	// convert @empty blocks, after the @empty branches of @forelse: @empty(.Orders) ... @else ... @endempty =>
+	// {{ if empty (.Orders) }} ... {{ else }} ... {{ end }}
+	rest = replaceDirectiveCalls(rest, "empty", func(args []string) (string, bool) {
+		if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+			return "", false
+		}
+		return fmt.Sprintf(`{{ if empty (%s) }}`, strings.TrimSpace(args[0])), true
+	})
+	rest = reEmptyEnd.ReplaceAllString(rest, "{{ end }}")
+
+	// convert @set to a variable assignment: @set('total', .Price | mul .Qty) => {{ $total := (.Price | mul .Qty) }},
+	// later @set of a name declared in the same or an enclosing scope assign the variable, see parseSetDirectives
+	rest = parseSetDirectives(rest)
+
+	// convert @with blocks: @with(.Order.Customer as customer) ... @endwith binds $customer within the block,
+	// keeping the dot, while @with(.Order.Customer) ... @endwith renders the block with the value as the dot
+	// when it is not empty
+	rest = replaceDirectiveCalls(rest, "with", parseWithDirective)
+	rest = reWithEnd.ReplaceAllString(rest, "{{ end }}")
+
+	// convert @unless blocks: @unless(.User.Verified) ... @else ... @endunless =>
+	// {{ if not (.User.Verified) }} ... {{ else }} ... {{ end }}
+	rest = replaceDirectiveCalls(rest, "unless", func(args []string) (string, bool) {
+		if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+			return "", false
+		}
+		return fmt.Sprintf(`{{ if not (%s) }}`, strings.TrimSpace(args[0])), true
+	})
+	rest = reUnlessEnd.ReplaceAllString(rest, "{{ end }}")
+
+	// convert @isset blocks: @isset(.User.Nickname) ... @else ... @endisset =>
+	// {{ if __isset . "User" "Nickname" }} ... {{ else }} ... {{ end }}, true when the values are not nil or
+	// missing, even when they are zero. The paths are walked by __isset, so missing keys fail no render.
+	rest = replaceDirectiveCalls(rest, "isset", parseIssetDirective)
+	rest = reIssetEnd.ReplaceAllString(rest, "{{ end }}")
+
+	// convert @session blocks: @session('status') ... @endsession => {{ if $value := session "status" }} ... {{ end }},
+	// rendered when the session or the flash messages hold the key
+	rest = replaceDirectiveCalls(rest, "session", func(args []string) (string, bool) {
+		if len(args) != 1 {
+			return "", false
+		}
+		key, ok := unquoteDirectiveString(strings.TrimSpace(args[0]))
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf(`{{ if $value := session %q }}`, key), true
+	})
+	rest = reSessionEnd.ReplaceAllString(rest, "{{ end }}")
+
+	// convert @step blocks: @step(.Wizard, 'address') ... @endstep => {{ if (.Wizard).Is "address" }} ... {{ end }}
+	rest = replaceDirectiveCalls(rest, "step", parseStepDirective)
+	rest = reStepEnd.ReplaceAllString(rest, "{{ end }}")
+
+	// convert feature flag blocks: @feature('new-checkout') ... @else ... @endfeature =>
+	// {{ if feature "new-checkout" }} ... {{ else }} ... {{ end }}, evaluated per render by Engine.Features
+	rest = replaceDirectiveCalls(rest, "feature", parseFeatureDirective)
+	rest = reFeatureEnd.ReplaceAllString(rest, "{{ end }}")
+
+	// convert experiments: @experiment('hero', ['a', 'b']) @variant('a') ... @variant('b') ... @endexperiment renders
+	// the block of the variant assigned by Engine.Experiments, see parseExperimentDirective
+	rest = replaceDirectiveCalls(rest, "experiment", parseExperimentDirective)
+	rest = replaceDirectiveCalls(rest, "variant", parseVariantDirective)
+	rest = reExperimentEnd.ReplaceAllString(rest, "{{ end }}{{ end }}")
+
+	// convert @flush: @flush(100) => {{ __flush "name:1" 100 }}, flushing the output every 100th time it is reached
+	flushCount := 0
+	rest = replaceDirectiveCalls(rest, "flush", func(args []string) (string, bool) {
+		if len(args) != 1 {
+			return "", false
+		}
+		flushCount++
+		return fmt.Sprintf(`{{ __flush "%s:%d" (%s) }}`, p.Name, flushCount, args[0]), true
+	})
+	rest = replaceDirectiveTokens(rest, reFlush, func(string) string {
+		flushCount++
+		return fmt.Sprintf(`{{ __flush "%s:%d" }}`, p.Name, flushCount)
+	})
+
+	// convert direction conditionals: @rtl ... @endrtl => {{ if isRTL }} ... {{ end }}, @ltr => {{ if not isRTL }}
+	rest = replaceDirectiveTokens(rest, reDirection, func(directive string) string {
+		switch directive {
+		case "@rtl":
+			return "{{ if isRTL }}"
+		case "@ltr":
+			return "{{ if not isRTL }}"
+		}
+		return "{{ end }}"
+	})
+
+	// convert @honeypot to the spam protection fields validated by Engine.ValidateHoneypot
+	rest = replaceDirectiveTokens(rest, reHoneypot, func(string) string { return "{{ __honeypot }}" })
+
+	// convert @csrf to the hidden CSRF token field and @nonce to the nonce attribute of inline scripts and
+	// styles, <script @nonce>, both read from the render context, see SecurityOptions
+	rest = replaceDirectiveTokens(rest, reCSRF, func(string) string { return "{{ __csrf }}" })
+	rest = replaceDirectiveTokens(rest, reNonce, func(string) string { return "{{ __nonce }}" })
+
+	// convert @track to the tracking attributes of an event of Engine.Analytics:
+	// @track('signup_click', plan: .Plan) => {{ track "signup_click" "plan" (.Plan) }}
+	rest = replaceDirectiveCalls(rest, "track", parseTrackDirective)
+
+	// convert @plural to the form of the plural category of the count in the render locale
+	rest = replaceDirectiveCalls(rest, "plural", parsePluralDirective)
+
+	// convert @state to a script assigning JSON encoded data, html/template escapes it for the script context:
+	// @state('appConfig', .ClientState) => <script>window.appConfig = {{ .ClientState }};</script>
+	// @state('appConfig', .ClientState, type: 'json') => <script type="application/json" id="appConfig">{{ .ClientState }}</script>
+	rest = replaceDirectiveCalls(rest, "state", func(args []string) (string, bool) {
+		if len(args) < 2 || len(args) > 3 {
+			return "", false
+		}
+		stateName, ok := unquoteDirectiveString(strings.TrimSpace(args[0]))
+		if !ok || !isIdentifier(stateName) {
+			return "", false
+		}
+		if len(args) == 3 {
+			argName, value, ok := parseNamedDirectiveArg(args[2])
+			if !ok || argName != "type" || strings.Trim(value, `'"`) != "json" {
+				return "", false
 			}
+			return fmt.Sprintf(`<script type="application/json" id="%s">{{ %s }}</script>`, stateName, args[1]), true
 		}
-		p.Includes[partialName] = struct{}{}
-		return fmt.Sprintf(`{{ template "%s%s" %s }}`, partialNamePrefix, partialName, pipeline), true
+		return fmt.Sprintf(`<script>window.%s = {{ %s }};</script>`, stateName, args[1]), true
 	})
 
+	// process imports: @import('macros/forms') makes the macros of another file callable
+	rest = replaceDirectiveCalls(rest, "import", func(args []string) (string, bool) {
+		if len(args) != 1 {
+			return "", false
+		}
+		importName, ok := parseQuotedDirectiveName(args[0])
+		if !ok {
+			return "", false
+		}
+		p.Imports[importName] = struct{}{}
+		return "", true
+	})
+
+	// process macro calls: @call('input', "email", .Email) => {{ template "__macro_input" (__args "email" .Email) }}
+	rest = replaceDirectiveCalls(rest, "call", func(args []string) (string, bool) {
+		if len(args) == 0 {
+			return "", false
+		}
+		macroName, ok := parseQuotedDirectiveName(args[0])
+		if !ok {
+			return "", false
+		}
+		var callArgs strings.Builder
+		for _, arg := range args[1:] {
+			callArgs.WriteString(" (")
+			callArgs.WriteString(arg)
+			callArgs.WriteString(")")
+		}
+		return fmt.Sprintf(`{{ template "%s%s" (__args%s) }}`, macroNamePrefix, macroName, callArgs.String()), true
+	})
+
+	// Parse macros: @macro('input', 'name', 'value') ... @endmacro
+	rest, err = extractBlocks(rest, "macro", "@endmacro", func(args []string, content string) error {
+		if len(args) == 0 {
+			return errors.New("invalid @macro directive")
+		}
+		macroName, ok := parseQuotedDirectiveName(args[0])
+		if !ok {
+			return fmt.Errorf("invalid @macro name %s", args[0])
+		}
+		if _, ok := p.Macros[macroName]; ok {
+			return fmt.Errorf(`duplicate @macro name "%s"`, macroName)
+		}
+		// bind positional arguments to variables: {{ $name := __arg . 0 }}
+		var body strings.Builder
+		for i, arg := range args[1:] {
+			argName, ok := parseQuotedDirectiveName(arg)
+			if !ok || !isIdentifier(argName) {
+				return fmt.Errorf("invalid @macro argument %s", arg)
+			}
+			fmt.Fprintf(&body, "{{ $%s := __arg . %d }}", argName, i)
+		}
+		body.WriteString(content)
+		p.Macros[macroName] = body.String()
+		return nil
+	})
+	if err != nil {
+		return nil, p.locateError(err)
+	}
+
+	// Parse captured blocks: @capture('banner') ... @endcapture assigns the rendered block to $banner
+	rest, err = replaceBlocks(rest, "capture", "@endcapture", p.parseCaptureBlock)
+	if err != nil {
+		return nil, p.locateError(err)
+	}
+
+	// Parse deferred blocks: @defer('comments', placeholder: 'Loading...') ... @enddefer renders the placeholder,
+	// replaced by the block rendered by a follow-up request
+	rest, err = replaceBlocks(rest, "defer", "@enddefer", p.parseDeferBlock)
+	if err != nil {
+		return nil, p.locateError(err)
+	}
+
 	// Parse sections
 	for {
 		start := strings.Index(rest, "@section(")
@@ -281,9 +939,32 @@ func (e *Engine) parseFile(name string, raw string) (*ParsedFile, error) {
 			continue
 		}
 
+		args, timeout, fallback, err := parseSectionTimeout(args)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] %w", p.Name, err)
+		}
+		args, policy, err := parseSectionPolicy(args)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] %w", p.Name, err)
+		}
+		if policy != "" {
+			p.SectionPolicies[sectionName] = policy
+		}
+
+		if _, ok := p.Sections[sectionName]; ok {
+			lines := p.directiveLines("section", sectionName)
+			if len(lines) >= 2 {
+				return nil, fmt.Errorf(`[%s] duplicate section "%s" at line %d, already defined at line %d`, p.Name, sectionName, lines[1], lines[0])
+			}
+			return nil, fmt.Errorf(`[%s] duplicate section "%s"`, p.Name, sectionName)
+		}
+
 		if len(args) > 1 {
-			//	@section('name',	content expression)
-			p.Sections[sectionName] = strings.TrimSpace(args[1])
+			//	@section('name',	'content') or @section('name',	content pipeline)
+			p.Sections[sectionName] = directiveValueToTemplate(args[1])
+			if timeout > 0 {
+				p.Sections[sectionName] = p.timedSection(sectionName, p.Sections[sectionName], timeout, fallback)
+			}
 			rest = rest[:start] + rest[callEnd:]
 			continue
 		}
@@ -291,43 +972,513 @@ func (e *Engine) parseFile(name string, raw string) (*ParsedFile, error) {
 		// find end
 		endIdx := reSectionEnd.FindStringIndex(rest[callEnd:])
 		if endIdx == nil {
-			return nil, fmt.Errorf("[%s] missing @endsection", p.Name)
+			return nil, p.locateError(&missingEndError{directive: "section"})
 		}
 		contentStart := callEnd
 		contentEnd := callEnd + endIdx[0]
 		p.Sections[sectionName] = strings.TrimSpace(rest[contentStart:contentEnd])
+		if timeout > 0 {
+			p.Sections[sectionName] = p.timedSection(sectionName, p.Sections[sectionName], timeout, fallback)
+		}
 		// remove the section from rest by replacing with empty string
 		rest = rest[:start] + rest[contentEnd+len("@endsection"):] // remove tail including @endsection
 	}
 
-	// Parse push stacks
+	// Parse push stacks: @push('scripts') ... @endpush, and @prependOnce('scripts') ... @endprependOnce
+	// rendering the content before the pushes of the stack, once per key
+	if rest, err = p.parsePushes(rest, "push", false); err != nil {
+		return nil, err
+	}
+	if rest, err = p.parsePushes(rest, "prependOnce", true); err != nil {
+		return nil, err
+	}
+
+	p.StandaloneBody = strings.TrimSpace(rest)
+
+	return p, nil
+}
+
+// parsePushes removes the blocks of the push directive, @push or @prependOnce, from rest and records their
+// content in PushStacks. A @prependOnce without key is keyed by its content.
+func (p *ParsedFile) parsePushes(rest string, directive string, prepend bool) (string, error) {
+	marker, endMarker := "@"+directive+"(", "@"+blockEndDirectives[directive]
 	for {
-		loc := rePushStart.FindStringSubmatchIndex(rest)
-		if loc == nil {
-			break
+		start := strings.Index(rest, marker)
+		if start == -1 {
+			return rest, nil
+		}
+		callEnd, args, ok := parseDirectiveCall(rest, start, directive)
+		if !ok || len(args) == 0 {
+			return "", fmt.Errorf("[%s] invalid @%s directive", p.Name, directive)
+		}
+		stackName, ok := parseQuotedDirectiveName(args[0])
+		if !ok {
+			return "", fmt.Errorf("[%s] invalid @%s stack name %s", p.Name, directive, args[0])
+		}
+		push := StackPush{Prepend: prepend}
+		for _, arg := range args[1:] {
+			//	@push('stack_name', key: 'unique_key')
+			argName, value, ok := parseNamedDirectiveArg(arg)
+			if !ok || argName != "key" {
+				return "", fmt.Errorf("[%s] unknown @%s argument %s", p.Name, directive, arg)
+			}
+			if push.Key, ok = parseQuotedDirectiveName(value); !ok {
+				return "", fmt.Errorf("[%s] invalid @%s key %s", p.Name, directive, value)
+			}
 		}
-		// extract section name
-		stackName := rest[loc[2]:loc[3]] // matched name
 		// find end
-		endIdx := rePushEnd.FindStringIndex(rest[loc[1]:])
-		if endIdx == nil {
-			return nil, fmt.Errorf("[%s] missing @endpush", p.Name)
+		endIdx := strings.Index(rest[callEnd:], endMarker)
+		if endIdx == -1 {
+			return "", p.locateError(&missingEndError{directive: directive})
 		}
-		contentStart := loc[1]
-		contentEnd := loc[1] + endIdx[0]
-		p.PushStacks[stackName] = append(p.PushStacks[stackName], strings.TrimSpace(rest[contentStart:contentEnd]))
-		// remove the section from rest by replacing with empty string
-		rest = rest[:loc[0]] + rest[contentEnd+len("@endpush"):] // remove tail including @endpush
+		contentEnd := callEnd + endIdx
+		push.Content = strings.TrimSpace(rest[callEnd:contentEnd])
+		if prepend && push.Key == "" {
+			push.Key = push.Content
+		}
+		p.PushStacks[stackName] = append(p.PushStacks[stackName], push)
+		// remove the push from rest by replacing with empty string
+		rest = rest[:start] + rest[contentEnd+len(endMarker):] // remove tail including the end directive
 	}
+}
 
-	p.StandaloneBody = strings.TrimSpace(rest)
+// reSetScopeToken matches the tokens opening, splitting and closing the scopes of variables tracked by
+// parseSetDirectives: the block actions and the directives
+var reSetScopeToken = regexp.MustCompile(`\{\{-?\s*(if|range|with|define|block|else|end)\b|@(\w+)`)
 
-	return p, nil
+// setIsolatedBlocks are the blocks compiled to their own template, which do not see the variables of the file.
+var setIsolatedBlocks = map[string]struct{}{
+	"section": {}, "push": {}, "prependOnce": {}, "macro": {}, "define": {}, "capture": {}, "defer": {},
+}
+
+// parseSetDirectives converts the @set directives of rest to variable declarations, or to assignments when the
+// variable is already declared in the scope of the @set or an enclosing one. The scopes are the block actions
+// and directives, their else branches, and the blocks compiled to their own template like @section.
+func parseSetDirectives(rest string) string {
+	type scope struct {
+		// name is the action or directive opening the scope
+		name     string
+		declared map[string]struct{}
+		isolated bool
+	}
+	scopes := []scope{{declared: map[string]struct{}{}, isolated: true}}
+	isDeclared := func(name string) bool {
+		for i := len(scopes) - 1; i >= 0; i-- {
+			if _, ok := scopes[i].declared[name]; ok {
+				return true
+			}
+			if scopes[i].isolated {
+				return false
+			}
+		}
+		return false
+	}
+	push := func(name string, isolated bool) {
+		scopes = append(scopes, scope{name: name, declared: map[string]struct{}{}, isolated: isolated})
+	}
+	pop := func() scope {
+		last := scopes[len(scopes)-1]
+		if len(scopes) > 1 {
+			scopes = scopes[:len(scopes)-1]
+		}
+		return last
+	}
+	// branch starts the else branch of the innermost scope, which does not see the variables of the first branch
+	branch := func() {
+		last := pop()
+		push(last.name, last.isolated)
+	}
+
+	var out strings.Builder
+	cursor := 0
+	for _, loc := range reSetScopeToken.FindAllStringSubmatchIndex(rest, -1) {
+		if loc[0] < cursor {
+			continue
+		}
+		if loc[2] >= 0 {
+			switch action := rest[loc[2]:loc[3]]; action {
+			case "if", "range", "with":
+				push(action, false)
+			case "define", "block":
+				push(action, true)
+			case "else":
+				branch()
+			case "end":
+				pop()
+			}
+			continue
+		}
+		if followsWord(rest, loc[0]) {
+			continue
+		}
+		name := rest[loc[4]:loc[5]]
+		d := DirectiveSpan{Name: name}
+		end := loc[1]
+		if end < len(rest) && rest[end] == '(' {
+			callEnd, args, ok := parseDirectiveCall(rest, loc[0], name)
+			if !ok {
+				continue
+			}
+			end, d.Args = callEnd, args
+		}
+
+		switch {
+		case name == "set":
+			replacement, ok := setAssignment(d.Args, isDeclared)
+			if !ok {
+				continue
+			}
+			scopes[len(scopes)-1].declared[setVarName(d.Args)] = struct{}{}
+			out.WriteString(rest[cursor:loc[0]])
+			out.WriteString(replacement)
+			cursor = end
+		case name == "else" || name == "variant":
+			branch()
+		case strings.HasPrefix(name, "end") && blockEndDirectives[strings.TrimPrefix(name, "end")] == name:
+			// close the block, with the scopes left open inside it
+			opener := "@" + strings.TrimPrefix(name, "end")
+			for i := len(scopes) - 1; i > 0; i-- {
+				if scopes[i].name == opener {
+					scopes = scopes[:i]
+					break
+				}
+			}
+		case d.isBlock():
+			_, isolated := setIsolatedBlocks[name]
+			push("@"+name, isolated)
+		}
+	}
+	out.WriteString(rest[cursor:])
+	return out.String()
+}
+
+// setVarName returns the variable name of the arguments of @set, without $.
+func setVarName(args []string) string {
+	if len(args) != 2 {
+		return ""
+	}
+	varName, _ := unquoteDirectiveString(strings.TrimSpace(args[0]))
+	return strings.TrimPrefix(varName, "$")
+}
+
+// setAssignment returns the action of a @set, declaring its variable unless isDeclared reports it is declared.
+func setAssignment(args []string, isDeclared func(name string) bool) (string, bool) {
+	if len(args) != 2 || strings.TrimSpace(args[1]) == "" {
+		return "", false
+	}
+	if _, ok := unquoteDirectiveString(strings.TrimSpace(args[0])); !ok {
+		return "", false
+	}
+	varName := setVarName(args)
+	if !isIdentifier(varName) {
+		return "", false
+	}
+	assign := ":="
+	if isDeclared(varName) {
+		assign = "="
+	}
+	return fmt.Sprintf(`{{ $%s %s (%s) }}`, varName, assign, strings.TrimSpace(args[1])), true
+}
+
+// parseWithDirective converts the arguments of @with to the start of a block:
+// @with(.Order.Customer as customer, .Order.Total as total) => {{ if true }}{{ $customer := (.Order.Customer) }}{{ $total := (.Order.Total) }}
+// @with(.Order.Customer) => {{ with (.Order.Customer) }}
+func parseWithDirective(args []string) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	if len(args) == 1 && !reWithAs.MatchString(args[0]) {
+		return fmt.Sprintf(`{{ with (%s) }}`, strings.TrimSpace(args[0])), true
+	}
+	var block strings.Builder
+	block.WriteString("{{ if true }}")
+	for _, arg := range args {
+		sm := reWithAs.FindStringSubmatch(arg)
+		if sm == nil {
+			return "", false
+		}
+		fmt.Fprintf(&block, "{{ $%s := (%s) }}", sm[2], sm[1])
+	}
+	return block.String(), true
+}
+
+// buildTemplateText resolves the layouts, sections, stacks and includes of the entry f into a single template text.
+func (e *Engine) buildTemplateText(files map[string]*ParsedFile, f *ParsedFile) (string, error) {
+	ctx := &CompileContext{
+		Files:                files,
+		Yields:               map[string]YieldInfo{},
+		FilledSections:       map[string]struct{}{},
+		SectionContents:      map[string][]SectionContent{},
+		SectionPolicies:      e.SectionPolicies,
+		DefaultSectionPolicy: e.SectionPolicy,
+		FilledIncludes:       map[string]struct{}{},
+		Stacks:               map[string]string{},
+		PushStacks:           map[string][]StackPush{},
+		Macros:               map[string]string{},
+		Imports:              map[string]struct{}{},
+	}
+	bodyText, defText, err := f.ToTemplateString(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if e.StrictSections {
+		if err := orphanSections(ctx, f); err != nil {
+			return "", err
+		}
+	}
+
+	if !e.IgnoreInvalidPushStack {
+		for stackName := range ctx.PushStacks {
+			if _, ok := ctx.Stacks[stackName]; !ok {
+				return "", fmt.Errorf(`[%s] missing stack "%s"`, f.Name, stackName)
+			}
+		}
+	}
+
+	defText += e.buildDefaultYieldContent(ctx)
+	text := e.rewriteExpressions(defText + bodyText)
+	if e.Minify && !f.XML {
+		text = minifyHTML(text)
+	}
+	return text, nil
+}
+
+// compileTemplate parses the template text of an entry.
+func (e *Engine) compileTemplate(name string, tmplText string, xml bool) (*compiledTemplate, error) {
+	renderFuncs := e.newRenderState(context.Background(), nil).funcs()
+	proto, err := e.parseTemplateSet(name, tmplText, xml, renderFuncs)
+	if err != nil {
+		return nil, err
+	}
+	foldStaticIncludes(proto)
+	exec, err := proto.clone()
+	if err != nil {
+		return nil, err
+	}
+	return &compiledTemplate{
+		proto:    proto,
+		exec:     exec,
+		stateful: callsFuncs(proto, renderFuncs),
+		xml:      xml,
+	}, nil
+}
+
+// parseTemplateSet parses the template text of an entry with the options of the engine, without folding its
+// static includes.
+func (e *Engine) parseTemplateSet(name string, tmplText string, xml bool, renderFuncs template.FuncMap) (templateSet, error) {
+	var proto templateSet
+	var err error
+	if xml {
+		proto, err = parseXMLTemplate(name, tmplText, renderFuncs, builtinFuncs(), e.FuncMap)
+	} else {
+		proto, err = parseHTMLTemplate(name, tmplText, renderFuncs, builtinFuncs(), e.FuncMap)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if e.StrictVariables {
+		// clones keep the option of the prototype
+		switch t := proto.(type) {
+		case htmlTemplateSet:
+			t.Option("missingkey=error")
+		case textTemplateSet:
+			t.Option("missingkey=error")
+		}
+	}
+	if e.Sandbox {
+		if err := e.checkSandbox(proto); err != nil {
+			return nil, err
+		}
+	}
+	return proto, nil
+}
+
+// execute renders entry into w, binding render scoped funcs and funcs supplied with data.
+func (e *Engine) execute(ctx context.Context, w io.Writer, entry string, data any) error {
+	return e.executeIn(ctx, nil, w, entry, data)
+}
+
+// executeSet renders entry from the compiled set, nil resolves the set of the drafts of a preview render,
+// of the tenant of the render context, then of its theme, or the generation of the context or the current one.
+// The compile cache status of the render is reported to m when not nil.
+func (e *Engine) executeSet(ctx context.Context, set *compiledSet, w io.Writer, entry string, data any, m *RenderMetrics) (err error) {
+	var funcs template.FuncMap
+	data, funcs, ctx = unwrapData(ctx, data)
+
+	name := normalizeName(entry)
+	if set == nil {
+		set = e.set.Load()
+		var err error
+		if version, ok := ctx.Value(generationKey{}).(string); ok {
+			if set, err = e.generation(version); err != nil {
+				return err
+			}
+		}
+		if token, ok := ctx.Value(previewKey{}).(string); ok {
+			if err := e.VerifyPreview(token); err != nil {
+				return err
+			}
+			if set, err = e.drafts.resolve(name); err != nil {
+				return err
+			}
+		} else if tenant := TenantName(ctx); tenant != "" {
+			if set, err = e.Tenant(tenant).overrides.resolve(name); err != nil {
+				return err
+			}
+		} else if theme := ThemeName(ctx); theme != "" {
+			if set, err = e.Theme(theme).overrides.resolve(name); err != nil {
+				return err
+			}
+		}
+	}
+	tmpl, ok := set.templates[name]
+	if !ok {
+		if tmpl, ok = e.builtinTemplate(set, name); !ok {
+			return fmt.Errorf("template %s not loaded", entry)
+		}
+	}
+
+	if tmpl.err != nil {
+		if m != nil {
+			m.Cache = CacheError
+		}
+		// the overlay replaces the status of responses not written yet, like the responses of gin and Respond
+		if hw, ok := w.(http.ResponseWriter); ok {
+			hw.WriteHeader(http.StatusInternalServerError)
+		}
+		if !tmpl.xml {
+			if err := renderErrorOverlay(w, name, tmpl.err, set.debugTemplates[name]); err != nil {
+				return err
+			}
+		}
+		return &CompileError{Entry: name, Err: tmpl.err}
+	}
+	// funcs failing or panicking are reported with their location, panics escaping the execution are recovered
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicError(name, r)
+		} else if err != nil {
+			err = renderError(set, name, err)
+		}
+	}()
+	if m != nil {
+		m.Cache = CacheHit
+		if tmpl.stateful || funcs != nil {
+			m.Cache = CacheClone
+		}
+	}
+
+	if e.BufferOutput || (len(e.Validators) > 0 && !tmpl.xml) {
+		// Buffer the output so nothing is written when the render or a validator fails.
+		buf := getBuffer()
+		defer putBuffer(buf)
+		if err := e.executeTemplate(ctx, buf, tmpl, data, funcs); err != nil {
+			return err
+		}
+		if !tmpl.xml {
+			for _, v := range e.Validators {
+				if err := v.Validate(entry, buf.Bytes()); err != nil {
+					return err
+				}
+			}
+		}
+		_, err := buf.WriteTo(w)
+		return err
+	}
+	return e.executeTemplate(ctx, w, tmpl, data, funcs)
+}
+
+// executeTemplate executes tmpl, with a pooled clone of the prototype when the render needs its own state, or a
+// new clone when it needs the funcs supplied with the data.
+// A fragment render of the context executes the deferred block instead of the whole template.
+func (e *Engine) executeTemplate(ctx context.Context, w io.Writer, tmpl *compiledTemplate, data any, funcs template.FuncMap) error {
+	run := func(t templateSet) error { return t.Execute(w, data) }
+	if fragment, ok := ctx.Value(fragmentKey{}).(string); ok {
+		run = func(t templateSet) error { return e.executeFragment(w, t, fragment, data) }
+	}
+	if !tmpl.stateful && funcs == nil {
+		return run(tmpl.exec)
+	}
+	if funcs == nil {
+		c, err := e.renderClone(ctx, w, tmpl)
+		if err != nil {
+			return err
+		}
+		err = run(c.tmpl)
+		// the pooled state must not keep the request alive
+		c.state.reset(nil, nil)
+		tmpl.clones.Put(c)
+		return err
+	}
+
+	// The prototype is never executed, so it can be cloned to bind funcs for this render only.
+	var bindFuncs []template.FuncMap
+	var state *renderState
+	if tmpl.stateful {
+		state = e.newRenderState(ctx, w)
+		bindFuncs = append(bindFuncs, state.funcs())
+	}
+	if funcs != nil {
+		bindFuncs = append(bindFuncs, funcs)
+	}
+	cloneTmpl, err := tmpl.proto.clone(bindFuncs...)
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		state.tmpl, state.proto = cloneTmpl, tmpl.proto
+		if funcs != nil {
+			state.dataFuncs = []template.FuncMap{funcs}
+		}
+	}
+	return run(cloneTmpl)
+}
+
+// renderClone returns a pooled clone of the prototype of tmpl with its state reset for the render, or a new one
+// escaped by its first render.
+func (e *Engine) renderClone(ctx context.Context, w io.Writer, tmpl *compiledTemplate) (*renderClone, error) {
+	if c, ok := tmpl.clones.Get().(*renderClone); ok {
+		c.state.reset(ctx, w)
+		return c, nil
+	}
+	state := e.newRenderState(ctx, w)
+	clone, err := tmpl.proto.clone(state.funcs())
+	if err != nil {
+		return nil, err
+	}
+	state.tmpl, state.proto = clone, tmpl.proto
+	return &renderClone{tmpl: clone, state: state}, nil
+}
+
+// ContentType returns the content type of the output of entry.
+func (e *Engine) ContentType(entry string) string {
+	set, name := e.set.Load(), normalizeName(entry)
+	tmpl, ok := set.templates[name]
+	if !ok {
+		tmpl, ok = e.builtinTemplate(set, name)
+	}
+	if ok && tmpl.xml && tmpl.err == nil {
+		return "application/xml; charset=utf-8"
+	}
+	return "text/html; charset=utf-8"
+}
+
+// templateFileKind reports whether path has a template extension and whether it is an XML template.
+func (e *Engine) templateFileKind(path string) (isTemplate bool, isXML bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	isXML = slices.Contains(e.XMLFileExtensions, ext)
+	return isXML || slices.Contains(e.ValidFileExtensions, ext), isXML
 }
 
 // nameFromPath converts a filesystem path to a template name, relative to engine dir.
 func (e *Engine) nameFromPath(path string) string {
-	rel, err := filepath.Rel(e.dirPrefix, path)
+	return nameFromPathIn(e.dirPrefix, path)
+}
+
+// nameFromPathIn converts a filesystem path to a template name, relative to dirPrefix.
+func nameFromPathIn(dirPrefix string, path string) string {
+	rel, err := filepath.Rel(dirPrefix, path)
 	if err != nil {
 		return filepath.Base(path)
 	}
@@ -363,6 +1514,191 @@ func normalizeName(n string) string {
 	return n
 }
 
+// extractBlocks removes all @directive(...) ... endDirective blocks from input
+// and passes their arguments and trimmed content to fn.
+func extractBlocks(input string, directive string, endDirective string, fn func(args []string, content string) error) (string, error) {
+	return replaceBlocks(input, directive, endDirective, func(args []string, content string) (string, error) {
+		return "", fn(args, content)
+	})
+}
+
+// replaceBlocks replaces the blocks of directive up to endDirective with the text returned by fn.
+func replaceBlocks(input string, directive string, endDirective string, fn func(args []string, content string) (string, error)) (string, error) {
+	marker := "@" + directive + "("
+	cursor := 0
+	for {
+		rel := strings.Index(input[cursor:], marker)
+		if rel == -1 {
+			return input, nil
+		}
+		start := cursor + rel
+		callEnd, args, ok := parseDirectiveCall(input, start, directive)
+		if !ok {
+			cursor = start + 1
+			continue
+		}
+		endIdx := strings.Index(input[callEnd:], endDirective)
+		if endIdx == -1 {
+			return "", &missingEndError{directive: directive}
+		}
+		contentEnd := callEnd + endIdx
+		replacement, err := fn(args, strings.TrimSpace(input[callEnd:contentEnd]))
+		if err != nil {
+			return "", err
+		}
+		input = input[:start] + replacement + input[contentEnd+len(endDirective):]
+		cursor = start + len(replacement)
+	}
+}
+
+// parseIssetDirective converts the arguments of @isset to an if action, true when all of them are set.
+func parseIssetDirective(args []string) (string, bool) {
+	checks := make([]string, 0, len(args))
+	for _, arg := range args {
+		arg = strings.TrimSpace(arg)
+		if arg == "" {
+			return "", false
+		}
+		sm := reIssetPath.FindStringSubmatch(arg)
+		if sm == nil || (sm[1] == "" && sm[2] == "") {
+			checks = append(checks, fmt.Sprintf("(__isset (%s))", arg))
+			continue
+		}
+		base := sm[1]
+		if base == "" {
+			base = "."
+		}
+		check := "(__isset " + base
+		for _, name := range strings.Split(sm[2], ".")[1:] {
+			check += fmt.Sprintf(" %q", name)
+		}
+		checks = append(checks, check+")")
+	}
+	switch len(checks) {
+	case 0:
+		return "", false
+	case 1:
+		return "{{ if " + strings.TrimSuffix(strings.TrimPrefix(checks[0], "("), ")") + " }}", true
+	}
+	return "{{ if and " + strings.Join(checks, " ") + " }}", true
+}
+
+// forLoopRange converts the arguments of a C-like @for loop to a range action. The loop counts up with < and <=
+// and down with > and >=, by one unless it has a step: $i += 2 or $i -= 2.
+func forLoopRange(arg string) (string, bool) {
+	sm := reForLoop.FindStringSubmatch(arg)
+	if sm == nil || sm[3] != sm[1] || (sm[6] != "" && sm[6] != sm[1]) {
+		return "", false
+	}
+	up := strings.HasPrefix(sm[4], "<")
+	step := "1"
+	switch update := sm[7]; {
+	case update == "":
+		if !up {
+			step = "-1"
+		}
+	case update == "--":
+		step = "-1"
+	case strings.HasPrefix(update, "+="):
+		step = "(" + strings.TrimSpace(update[2:]) + ")"
+	case strings.HasPrefix(update, "-="):
+		step = "(sub 0 (" + strings.TrimSpace(update[2:]) + "))"
+	}
+	return fmt.Sprintf(`{{ range $%s, $__ := __for . (%s) (%s) %s %t }}`, sm[1], sm[2], sm[5], step, strings.HasSuffix(sm[4], "=")), true
+}
+
+// shortForLoopRange converts the arguments of a short @for loop, start, end and an optional step, to a range
+// action. The end is excluded like in @for($i = start; $i < end; $i++), and the last argument can bind a
+// variable: @for(0, 10 as $i). Without variable the counter is not bound.
+func shortForLoopRange(args []string) (string, bool) {
+	args = slices.Clone(args)
+	counter := "__for"
+	if sm := reForAs.FindStringSubmatch(args[len(args)-1]); sm != nil {
+		args[len(args)-1] = sm[1]
+		counter = sm[2]
+	}
+	step := "1"
+	if len(args) == 3 {
+		step = "(" + strings.TrimSpace(args[2]) + ")"
+	}
+	for _, arg := range args {
+		if strings.TrimSpace(arg) == "" {
+			return "", false
+		}
+	}
+	return fmt.Sprintf(`{{ range $%s, $__ := __for . (%s) (%s) %s false }}`, counter, strings.TrimSpace(args[0]), strings.TrimSpace(args[1]), step), true
+}
+
+// replaceDirectiveTokens replaces the matches of re, directives without arguments like @csrf, by the result of
+// replace. Matches following a word are text, like the domain of admin@once.dev.
+func replaceDirectiveTokens(input string, re *regexp.Regexp, replace func(token string) string) string {
+	var out strings.Builder
+	cursor := 0
+	for _, loc := range re.FindAllStringIndex(input, -1) {
+		if followsWord(input, loc[0]) {
+			continue
+		}
+		out.WriteString(input[cursor:loc[0]])
+		out.WriteString(replace(input[loc[0]:loc[1]]))
+		cursor = loc[1]
+	}
+	out.WriteString(input[cursor:])
+	return out.String()
+}
+
+// followsWord reports whether the @ at pos follows a word other than a directive, like the user of an email
+// address: @endrtl@ltr are two directives.
+func followsWord(input string, pos int) bool {
+	start := pos
+	for start > 0 && isWordByte(input[start-1]) {
+		start--
+	}
+	if start == pos {
+		return false
+	}
+	if start > 0 && input[start-1] == '@' {
+		_, known := knownDirectives[input[start:pos]]
+		return !known
+	}
+	return true
+}
+
+// elseBlocks are the block directives taking an @else branch.
+var elseBlocks = map[string]struct{}{"feature": {}, "experiment": {}, "unless": {}, "isset": {}, "empty": {}}
+
+// parseElseDirectives converts the @else branches of the blocks of elseBlocks located in raw to {{ else }}.
+// Other @else tokens and the ones preceded by a word are text, like in me@else.com.
+func parseElseDirectives(raw string, directives []DirectiveSpan) string {
+	var out strings.Builder
+	cursor := 0
+	for _, d := range directives {
+		if d.Name != "else" || d.Args != nil || followsWord(raw, d.Span.Start) {
+			continue
+		}
+		block, ok := enclosingBlock(directives, d.Span.Start)
+		if _, accepts := elseBlocks[block.Name]; !ok || !accepts {
+			continue
+		}
+		out.WriteString(raw[cursor:d.Span.Start])
+		out.WriteString("{{ else }}")
+		cursor = d.Span.End
+	}
+	out.WriteString(raw[cursor:])
+	return out.String()
+}
+
+// enclosingBlock returns the innermost block of directives whose body contains the offset pos.
+func enclosingBlock(directives []DirectiveSpan, pos int) (DirectiveSpan, bool) {
+	var block DirectiveSpan
+	found := false
+	for _, d := range directives {
+		if d.Body.End > d.Body.Start && d.Body.Start <= pos && pos < d.Body.End && (!found || d.Body.Start > block.Body.Start) {
+			block, found = d, true
+		}
+	}
+	return block, found
+}
+
 func replaceDirectiveCalls(input string, directive string, replacer func(args []string) (string, bool)) string {
 	marker := "@" + directive + "("
 	var out strings.Builder
@@ -490,9 +1826,9 @@ func splitTopLevelArgs(argsText string) []string {
 		}
 
 		switch ch {
-		case '(':
+		case '(', '[':
 			depth++
-		case ')':
+		case ')', ']':
 			if depth > 0 {
 				depth--
 			}
@@ -514,6 +1850,39 @@ func splitTopLevelArgs(argsText string) []string {
 	return args
 }
 
+// directiveValueToTemplate converts a directive value argument to template text.
+// A quoted string is used as literal text, anything else is treated as a pipeline.
+func directiveValueToTemplate(input string) string {
+	trimmed := strings.TrimSpace(input)
+	if text, ok := unquoteDirectiveString(trimmed); ok {
+		return text
+	}
+	return "{{ " + trimmed + " }}"
+}
+
+// unquoteDirectiveString returns the content of a single quoted string argument.
+func unquoteDirectiveString(input string) (string, bool) {
+	if len(input) < 2 || (input[0] != '\'' && input[0] != '"') || input[len(input)-1] != input[0] {
+		return "", false
+	}
+	quote := input[0]
+	var out strings.Builder
+	for i := 1; i < len(input)-1; i++ {
+		ch := input[i]
+		if ch == '\\' && i+1 < len(input)-1 {
+			i++
+			out.WriteByte(input[i])
+			continue
+		}
+		if ch == quote {
+			// the argument is an expression made of several strings
+			return "", false
+		}
+		out.WriteByte(ch)
+	}
+	return out.String(), true
+}
+
 func parseQuotedDirectiveName(input string) (string, bool) {
 	trimmed := strings.TrimSpace(input)
 	if len(trimmed) < 2 {
@@ -524,3 +1893,25 @@ func parseQuotedDirectiveName(input string) (string, bool) {
 	}
 	return normalizeName(trimmed[1 : len(trimmed)-1]), true
 }
+
+// parseNamedDirectiveArg splits a named directive argument like `key: 'value'`.
+func parseNamedDirectiveArg(input string) (string, string, bool) {
+	name, value, found := strings.Cut(strings.TrimSpace(input), ":")
+	if !found || strings.HasPrefix(value, "=") || !isIdentifier(name) {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(value), true
+}
+
+// isIdentifier reports whether input is a valid template variable or field name.
+func isIdentifier(input string) bool {
+	if input == "" {
+		return false
+	}
+	for i, ch := range input {
+		if ch != '_' && !unicode.IsLetter(ch) && (i == 0 || !unicode.IsDigit(ch)) {
+			return false
+		}
+	}
+	return true
+}
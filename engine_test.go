@@ -2,8 +2,17 @@
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"testing/fstest"
 	"time"
@@ -313,7 +322,895 @@ func TestParseFile_SectionShorthandExpression(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if got := parsed.Sections["title"]; got != `print .Name "!"` {
+	if got := parsed.Sections["title"]; got != `{{ print .Name "!" }}` {
 		t.Fatalf("section shorthand mismatch, got %q", got)
 	}
 }
+
+func TestKeyedPush(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `@push("scripts", key: "chartjs") <script src="chart.js"></script> @endpush <body>@stack("scripts")</body>`,
+		"page.blade": `@extends("layout")
+@push("scripts", key: "chartjs") <script src="chart.js"></script> @endpush
+@push("scripts") <script>draw()</script> @endpush
+@push("scripts", key: 'chartjs') <script src="chart.js"></script> @endpush`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	expected := `<body><script src="chart.js"></script> <script>draw()</script></body>`
+	if normalizeSpace(buf.String()) != expected {
+		t.Errorf("Keyed push mismatch.\nExp: %s\nGot: %s", expected, normalizeSpace(buf.String()))
+	}
+}
+
+func TestPrependOnce(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `@push("scripts") <script>app()</script> @endpush <body>@stack("scripts")</body>`,
+		"page.blade": `@extends("layout")
+@push("scripts") <script>draw()</script> @endpush
+@prependOnce("scripts") <script src="chart.js"></script> @endprependOnce
+@prependOnce("scripts", key: "jquery") <script src="jquery.js"></script> @endprependOnce
+@push("scripts", key: "jquery") <script src="jquery.js"></script> @endpush
+@prependOnce("scripts") <script src="chart.js"></script> @endprependOnce`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	expected := `<body><script src="chart.js"></script> <script src="jquery.js"></script> <script>app()</script> <script>draw()</script></body>`
+	if normalizeSpace(buf.String()) != expected {
+		t.Errorf("Prepend once mismatch.\nExp: %s\nGot: %s", expected, normalizeSpace(buf.String()))
+	}
+}
+
+func TestOnce(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"list.blade": `{{ range .Products }}@once(.ID)[{{ .ID }}]@endonce{{ end }}@once<once>@endonce`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	data := map[string]any{
+		"Products": []map[string]int{{"ID": 1}, {"ID": 2}, {"ID": 1}, {"ID": 3}},
+	}
+	for range 2 {
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, "list", data); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if buf.String() != "[1][2][3]<once>" {
+			t.Errorf("Expected each key to render once per render, got %q", buf.String())
+		}
+	}
+}
+
+func TestStatefulRendersConcurrently(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"page.blade": `{{ currentURL }}{{ range . }}@once<once>@endonce{{ end }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Go(func() {
+			for j := range 20 {
+				u := &url.URL{Path: fmt.Sprintf("/%d/%d", i, j)}
+				var buf bytes.Buffer
+				if err := engine.RenderContext(WithRequestURL(context.Background(), u), &buf, "page", []int{1, 2}); err != nil {
+					t.Errorf("Render failed: %v", err)
+					return
+				}
+				if expected := u.Path + "<once>"; buf.String() != expected {
+					t.Errorf("Expected %q, got %q", expected, buf.String())
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestDefineAndUse(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `<main>@yield("content")</main>`,
+		"page.blade": `@extends("layout")
+@define("badge")<span class="badge">{{ . }}</span>@enddefine
+@section("content")@use("badge", "new") @use("badge", .Status)@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", map[string]string{"Status": "sold"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	expected := `<main><span class="badge">new</span> <span class="badge">sold</span></main>`
+	if buf.String() != expected {
+		t.Errorf("Snippet mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
+
+func TestRecursiveInclude(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"page.blade":          `<nav>@include("partials/menu", .Menu)</nav>`,
+		"partials/menu.blade": `<ul>{{ range . }}<li>{{ .Name }}{{ if .Children }}@include("partials/menu", .Children){{ end }}</li>{{ end }}</ul>`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	type item struct {
+		Name     string
+		Children []item
+	}
+	data := map[string]any{
+		"Menu": []item{{Name: "A", Children: []item{{Name: "A1", Children: []item{{Name: "A1a"}}}}}, {Name: "B"}},
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<nav><ul><li>A<ul><li>A1<ul><li>A1a</li></ul></li></ul></li><li>B</li></ul></nav>`
+	if buf.String() != expected {
+		t.Errorf("Recursive include mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	engine.MaxIncludeDepth = 1
+	if err := engine.Render(&bytes.Buffer{}, "page", data); err == nil || !strings.Contains(err.Error(), "maximum include depth") {
+		t.Errorf("Expected maximum include depth error, got %v", err)
+	}
+}
+
+func TestMacros(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"_macros/forms.blade": `@macro("input", "name", "value")<input name="{{ $name }}" value="{{ $value }}">@endmacro
+@macro("label", "text")<label>{{ $text }}</label>@endmacro`,
+		"layout.blade": `@import("_macros/forms")<form>@yield("fields")</form>`,
+		"page.blade": `@extends("layout")
+@import("_macros.forms")
+@section("fields")@call("label", "Email")@call("input", "email", .Email)@call("input", "name")@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", map[string]string{"Email": "a@b.c"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<form><label>Email</label><input name="email" value="a@b.c"><input name="name" value=""></form>`
+	if buf.String() != expected {
+		t.Errorf("Macro mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
+
+func TestDirectivePipelines(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `<title>@yield("title", .Site | upper)</title><h1>@yield("heading", "Welcome, (guest)")</h1>@yield("body")`,
+		"page.blade": `@extends("layout")
+@section("body", printf "%s, %s" .Site "docs" | upper)
+@section("footer", 'Footer')`,
+		"alert.blade": `@include("badge", .Level | printf "level-%s")`,
+		"badge.blade": `<b>{{ . }}</b>`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.FuncMap["upper"] = strings.ToUpper
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		entry    string
+		expected string
+	}{
+		{"page", `<title>BLADE</title><h1>Welcome, (guest)</h1>BLADE, DOCS`},
+		{"alert", `<b>level-warn</b>`},
+	}
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, tc.entry, map[string]string{"Site": "blade", "Level": "warn"}); err != nil {
+			t.Fatalf("Render %s failed: %v", tc.entry, err)
+		}
+		if strings.TrimSpace(buf.String()) != tc.expected {
+			t.Errorf("Pipeline mismatch for %s.\nExp: %s\nGot: %s", tc.entry, tc.expected, buf.String())
+		}
+	}
+}
+
+func TestYieldIf(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `<main>@yield('content')</main>@yieldIf('sidebar', .ShowSidebar)@yieldIf('ads', and .ShowAds (not .Premium), 'No ads')`,
+		"page.blade": `@extends('layout')
+@section('content', 'Content')
+@section('sidebar')<aside>Sidebar</aside>@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if warnings := engine.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	tests := []struct {
+		data     map[string]bool
+		expected string
+	}{
+		{map[string]bool{}, `<main>Content</main>`},
+		{map[string]bool{"ShowSidebar": true}, `<main>Content</main><aside>Sidebar</aside>`},
+		{map[string]bool{"ShowAds": true}, `<main>Content</main>No ads`},
+		{map[string]bool{"ShowAds": true, "Premium": true}, `<main>Content</main>`},
+	}
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, "page", tc.data); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if strings.TrimSpace(buf.String()) != tc.expected {
+			t.Errorf("YieldIf mismatch for %v.\nExp: %s\nGot: %s", tc.data, tc.expected, buf.String())
+		}
+	}
+}
+
+func TestWith(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"order.blade": `@with(.Order.Customer as customer, .Order.Total as $total){{ $customer.Name }} {{ $total }} {{ .Shop }}@endwith` +
+			`|@with(.Order.Coupon){{ .Code }}@endwith`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	data := map[string]any{
+		"Shop":  "Acme",
+		"Order": map[string]any{"Customer": map[string]string{"Name": "Jane"}, "Total": 42, "Coupon": nil},
+	}
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "order", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `Jane 42 Acme|`; buf.String() != expected {
+		t.Errorf("With mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	data["Order"].(map[string]any)["Coupon"] = map[string]string{"Code": "SAVE10"}
+	buf.Reset()
+	if err := engine.Render(&buf, "order", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `Jane 42 Acme|SAVE10`; buf.String() != expected {
+		t.Errorf("With mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
+
+func TestDirectivesInText(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"page.blade": `@feature('beta')me@else.com @else off@endfeature|admin@once.dev info@csrf.io x@nonce.io|a @else b`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.Features = FeatureFlags{"beta": true}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `me@else.com |admin@once.dev info@csrf.io x@nonce.io|a @else b`
+	if buf.String() != expected {
+		t.Errorf("Directives in text mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
+
+func TestUnless(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"account.blade": `@unless(.Verified)Verify your email!@else Welcome@endunless|@unless(eq .Plan "pro")Upgrade@endunless`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		data     map[string]any
+		expected string
+	}{
+		{map[string]any{"Verified": false, "Plan": "free"}, `Verify your email!|Upgrade`},
+		{map[string]any{"Verified": true, "Plan": "pro"}, ` Welcome|`},
+	} {
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, "account", tc.data); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("Unless mismatch.\nExp: %s\nGot: %s", tc.expected, buf.String())
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"cart.blade": `@set('total', len .Items)@set('$label', printf "%d items" $total){{ $label }}|{{ $total }}` +
+			`{{ if gt $total 1 }}@set('total', 1){{ end }}|{{ $total }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "cart", map[string]any{"Items": []string{"a", "b", "c"}}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `3 items|3|1`; buf.String() != expected {
+		t.Errorf("Set mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
+
+func TestSetScopes(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `@yield('a')|@yield('b')|@yield('content')`,
+		"page.blade": `@extends('layout')@section('a')@set('x', 1){{ $x }}@endsection@section('b')@set('x', 2){{ $x }}@endsection` +
+			`@section('content'){{ if true }}@set('y', 1){{ $y }}{{ end }}@set('y', 2){{ $y }}` +
+			`@foreach(.Items as $item)@set('z', $item){{ $z }}@endforeach@set('z', 0){{ $z }}` +
+			`{{ if false }}@set('w', 1){{ else }}@set('w', 3)|{{ $w }}{{ end }}` +
+			`@set('v', 1)@feature('beta')@set('v', 2)@else@set('v', 3)@endfeature{{ $v }}@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.Features = FeatureFlags{"beta": true}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", map[string]any{"Items": []int{7, 8}}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `1|2|12780|32`; buf.String() != expected {
+		t.Errorf("Set mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
+
+func TestNullSafeEcho(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"profile.blade": `{{ .User?.Profile?.Name }}|{{ with $u := .User }}{{ $u?.Profile.Name }}{{ end }}|{{ "?.kept" }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.CompatibilityMode = true
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		data     map[string]any
+		expected string
+	}{
+		{map[string]any{"User": &optionalUser{Profile: &optionalProfile{Name: "John"}}}, "John|John|?.kept"},
+		{map[string]any{"User": &optionalUser{}}, "||?.kept"},
+		{map[string]any{}, "||?.kept"},
+	}
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, "profile", tc.data); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("Null-safe mismatch.\nExp: %s\nGot: %s", tc.expected, buf.String())
+		}
+	}
+}
+
+func TestDefaultValueOperator(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `<title>@yield("title", .Title ?? "Untitled")</title>{{ .Subtitle ?? .Title ?? "-" }}`,
+		"page.blade":   `@extends("layout")`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		data     map[string]string
+		expected string
+	}{
+		{map[string]string{"Title": "Home"}, "<title>Home</title>Home"},
+		{map[string]string{"Title": "", "Subtitle": "Sub"}, "<title>Untitled</title>Sub"},
+		{nil, "<title>Untitled</title>-"},
+	}
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, "page", tc.data); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if strings.TrimSpace(buf.String()) != tc.expected {
+			t.Errorf("Default value mismatch.\nExp: %s\nGot: %s", tc.expected, buf.String())
+		}
+	}
+}
+
+func TestForLoop(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"stars.blade": `@for($i = 0; $i < .Rating; $i++)★@endfor|@for($i = 1; $i <= 2)[{{ $i }}]@endfor|{{ range seq 3 }}{{ . }}{{ end }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "stars", map[string]int{"Rating": 3}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if buf.String() != "★★★|[1][2]|123" {
+		t.Errorf("For loop mismatch, got %q", buf.String())
+	}
+}
+
+func TestForLoopForms(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"down.blade":  `@for($i = 3; $i > 0; $i--){{ $i }}@endfor|@for($i = 4; $i >= 0; $i -= 2){{ $i }}@endfor|@for($i = 0; $i < 7; $i += .Step){{ $i }}@endfor`,
+		"dot.blade":   `@for($i = 0; $i < 2; $i++){{ .Name }}{{ $i }}@endfor|@for($i = 2; $i > 0; $i--){{ .Name }}{{ $i }}@endfor|{{ range seq 2 }}{{ . }}{{ end }}`,
+		"short.blade": `@for(0, 3)[{{ .Step }}]@endfor|@for(1, .Pages as $page){{ $page }}/{{ .Pages }} @endfor|@for(0, 10, .Step as $i){{ $i }}{{ .Step }}@endfor`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	data := map[string]any{"Step": 3, "Pages": 3, "Name": "a"}
+	for name, want := range map[string]string{
+		"down":  "321|420|036",
+		"dot":   "a0a1|a2a1|12",
+		"short": "[3][3][3]|1/3 2/3 |03336393",
+	} {
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, name, data); err != nil {
+			t.Fatalf("Render %s failed: %v", name, err)
+		}
+		if buf.String() != want {
+			t.Errorf("%s: got %q, want %q", name, buf.String(), want)
+		}
+	}
+}
+
+func TestIsset(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"profile.blade": `@isset(.Count)[{{ .Count }}]@endisset@isset(.User.Nickname)[{{ .User.Nickname }}]@else[no nickname]@endisset` +
+			`@isset(.Missing.Name)[missing]@endisset{{ $u := .User }}@isset($u.Age, .Admin)[{{ $u.Age }}]@endisset@isset(index .Tags 0)[tag]@endisset`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.StrictVariables = true
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{"Count": 0, "User": map[string]any{"Nickname": "", "Age": 0}, "Admin": false, "Tags": []any{nil}}
+	if err := engine.Render(&buf, "profile", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if buf.String() != "[0][][0]" {
+		t.Errorf("Isset mismatch, got %q", buf.String())
+	}
+
+	buf.Reset()
+	data = map[string]any{"Count": nil, "User": map[string]any{"Age": 30}, "Tags": []any{"go"}}
+	if err := engine.Render(&buf, "profile", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if buf.String() != "[no nickname][tag]" {
+		t.Errorf("Isset mismatch, got %q", buf.String())
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"cart.blade": `@empty(.Items)[no items]@else[{{ len .Items }} items]@endempty@empty(.Coupon)[no coupon]@endempty@empty(.Count)[no count]@endempty` +
+			`@forelse(.Items as $item){{ $item }}@empty[none]@endforelse|{{ empty .Note }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		data map[string]any
+		want string
+	}{
+		{map[string]any{"Items": []string{}, "Coupon": (*string)(nil), "Count": 0, "Note": ""}, "[no items][no coupon][none]|true"},
+		{map[string]any{"Items": []string{"a"}, "Coupon": "X", "Count": 0, "Note": "hi"}, "[1 items]a|false"},
+	} {
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, "cart", tc.data); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if buf.String() != tc.want {
+			t.Errorf("Empty mismatch, got %q, want %q", buf.String(), tc.want)
+		}
+	}
+
+	// the @empty branch of @forelse is not a block closed by @endempty
+	for _, d := range locateDirectives(`@forelse(.A as $a)@empty@endforelse@empty(.B)B@endempty`) {
+		if d.Name == "empty" && (d.Body == Span{}) == (d.Args != nil) {
+			t.Errorf("Unexpected @empty span: %+v", d)
+		}
+	}
+}
+
+// testCursor yields its rows one at a time, like a database cursor.
+type testCursor struct {
+	rows []string
+	Row  string
+}
+
+func (c *testCursor) Next() bool {
+	if len(c.rows) == 0 {
+		return false
+	}
+	c.Row, c.rows = c.rows[0], c.rows[1:]
+	return true
+}
+
+func TestWhile(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"rows.blade":    `@while(.Rows.Next)[{{ .Rows.Row }}]@endwhile|@while(.Rest.Next, 2)({{ .Rest.Row }})@endwhile`,
+		"endless.blade": `@while(true).@endwhile`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.MaxWhileIterations = 5
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{"Rows": &testCursor{rows: []string{"a", "b"}}, "Rest": &testCursor{rows: []string{"c", "d", "e"}}}
+	if err := engine.Render(&buf, "rows", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if buf.String() != "[a][b]|(c)(d)" {
+		t.Errorf("While mismatch, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := engine.Render(&buf, "endless", nil); err == nil || !strings.Contains(err.Error(), "exceeded maximum iterations 5") {
+		t.Errorf("Expected an iteration limit error, got %v", err)
+	}
+}
+
+func TestForeach(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"list.blade": `@foreach(.Groups as $group){{ $group.Name }}:@foreach($group.Items as $item)` +
+			`{{ if $loop.First }}[{{ end }}{{ $loop.Parent.Iteration }}.{{ $loop.Iteration }}/{{ $loop.Count }} {{ $item }}{{ if $loop.Last }}]{{ else }}, {{ end }}` +
+			`@endforeach{{ if not $loop.Last }}; {{ end }}@endforeach` +
+			`|@foreach(.Prices as $sku => $price){{ $sku }}={{ $price }}{{ if $loop.Odd }} {{ end }}@endforeach` +
+			`|@foreach(3 as $n){{ $n }}{{ $loop.Remaining }}@endforeach`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	data := map[string]any{
+		"Groups": []map[string]any{
+			{"Name": "fruits", "Items": []string{"apple", "pear"}},
+			{"Name": "nuts", "Items": []string{"almond"}},
+		},
+		"Prices": map[string]int{"b": 2, "a": 1},
+	}
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "list", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `fruits:[1.1/2 apple, 1.2/2 pear]; nuts:[2.1/1 almond]|a=1 b=2|021120`
+	if buf.String() != expected {
+		t.Errorf("Foreach mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
+
+func TestForeachKeepsDot(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"cart.blade": `@foreach(.Items as $item){{ $item }} {{ .Currency }} @endforeach` +
+			`|@foreach(.Stock as $sku => $qty){{ $sku }}{{ $qty }}{{ .Currency }} @endforeach|{{ .Currency }}`,
+		"broken.blade": `@foreach(.Currency as $c){{ $c }}@endforeach`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	stock := func(yield func(string, int) bool) {
+		_ = yield("b", 2) && yield("a", 1)
+	}
+	data := map[string]any{"Items": []int{10, 20}, "Stock": iter.Seq2[string, int](stock), "Currency": "EUR"}
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "cart", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `10 EUR 20 EUR |b2EUR a1EUR |EUR`
+	if buf.String() != expected {
+		t.Errorf("Foreach mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	if err := engine.Render(&bytes.Buffer{}, "broken", data); err == nil || !strings.Contains(err.Error(), "cannot range over string") {
+		t.Errorf("Expected a range error, got %v", err)
+	}
+}
+
+func TestForelse(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"orders.blade": `@forelse(.Orders as $order)#{{ $order.ID }}@forelse($order.Lines as $line){{ $loop.Parent.Iteration }}{{ $line }}@empty<i>none</i>@endforelse @empty<b>` +
+			`No orders</b>@foreach(.Suggestions as $s) {{ $s }}{{ $loop.Depth }}@endforeach@endforelse`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		data     map[string]any
+		expected string
+	}{
+		{map[string]any{"Orders": []map[string]any{{"ID": 1, "Lines": []string{"a", "b"}}, {"ID": 2, "Lines": nil}}}, `#11a1b #2<i>none</i> `},
+		{map[string]any{"Orders": nil, "Suggestions": []string{"x", "y"}}, `<b>No orders</b> x1 y1`},
+	} {
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, "orders", tc.data); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("Forelse mismatch.\nExp: %s\nGot: %s", tc.expected, buf.String())
+		}
+	}
+}
+
+func TestStateDirective(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"app.blade": `@state("appConfig", .Client)@state("boot", .Client, type: "json")`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{"Client": map[string]string{"user": "</script><script>alert(1)</script>"}}
+	if err := engine.Render(&buf, "app", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	escaped := `{"user":"\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e"}`
+	expected := `<script>window.appConfig = ` + escaped + `;</script>` +
+		`<script type="application/json" id="boot">` + escaped + `</script>`
+	if buf.String() != expected {
+		t.Errorf("State mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
+
+func TestXMLTemplate(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layouts/feed.xml": `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel>@yield('items')</channel></rss>`,
+		"feed.xml": `@extends('layouts/feed')
+@section('items'){{ range .Items }}<item><title>{{ .Title }}</title><link>{{ .URL }}</link>{{ $.Extra }}</item>{{ end }}@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{
+		"Items": []map[string]string{{"Title": "Tom & Jerry <3", "URL": "https://example.com/?a=1&b=2"}},
+		"Extra": XML("<guid>1</guid>"),
+	}
+	if err := engine.Render(&buf, "feed", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel>` +
+		`<item><title>Tom &amp; Jerry &lt;3</title><link>https://example.com/?a=1&amp;b=2</link><guid>1</guid></item>` +
+		`</channel></rss>`
+	if strings.TrimSpace(buf.String()) != expected {
+		t.Errorf("XML mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+	if ct := engine.ContentType("feed"); ct != "application/xml; charset=utf-8" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if _, ok := engine.GetTemplate("feed"); ok {
+		t.Errorf("GetTemplate should not return XML templates")
+	}
+}
+
+func TestDuplicateSection(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `@yield('content')`,
+		"page.blade":   "@extends('layout')\n@section('content')A@endsection\n\n@section('content', 'B')",
+	})
+	engine := NewEngineFS(mockFS)
+	err := engine.Load()
+	if err == nil {
+		t.Fatalf("expected an error for a duplicate section")
+	}
+	expected := `[page] duplicate section "content" at line 4, already defined at line 2`
+	if err.Error() != expected {
+		t.Errorf("Error mismatch.\nExp: %s\nGot: %s", expected, err.Error())
+	}
+}
+
+func TestDevModeBrokenViews(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade":  `<main>@yield('content')</main>`,
+		"home.blade":    `@extends('layout')@section('content')Home@endsection`,
+		"missing.blade": `@extends('layout')@section('content')@include('partials/nope')@endsection`,
+		"syntax.blade":  "@extends('layout')\n@section('content'){{ if .A }}@endsection",
+		"parse.blade":   `@section('content')`,
+		"feed.xml":      `<rss>{{ if }}</rss>`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err == nil {
+		t.Fatalf("expected Load to fail without DevMode")
+	}
+
+	engine = NewEngineFS(mockFS)
+	engine.DevMode = true
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed in DevMode: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "home", nil); err != nil || buf.String() != "<main>Home</main>" {
+		t.Errorf("expected home to render, got %q, %v", buf.String(), err)
+	}
+
+	for entry, expected := range map[string]string{
+		"missing": `template &#34;partials/nope&#34; not found to include`,
+		"syntax":  `unexpected EOF`,
+		"parse":   `missing @endsection`,
+	} {
+		buf.Reset()
+		var compileErr *CompileError
+		if err := engine.Render(&buf, entry, nil); !errors.As(err, &compileErr) || compileErr.Entry != entry {
+			t.Errorf("expected a compile error rendering %s, got %v", entry, err)
+		}
+		if !strings.Contains(buf.String(), "Failed to compile "+entry) || !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected the error overlay of %s with %q, got %s", entry, expected, buf.String())
+		}
+	}
+	buf.Reset()
+	if err := engine.Render(&buf, "feed", nil); !errors.As(err, new(*CompileError)) || buf.Len() != 0 {
+		t.Errorf("expected a compile error without overlay for XML views, got %q, %v", buf.String(), err)
+	}
+	if err := engine.Warmup(); err == nil {
+		t.Errorf("expected Warmup to report the broken views")
+	}
+	if err := engine.Ready(); err == nil || !strings.Contains(err.Error(), "failed to compile syntax") {
+		t.Errorf("expected Ready to report the broken views, got %v", err)
+	}
+	if _, err := engine.RenderBytes("syntax", nil); err == nil {
+		t.Errorf("expected RenderBytes to fail for a broken view")
+	}
+	w := httptest.NewRecorder()
+	if err := engine.Respond(w, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, "syntax", nil); err == nil ||
+		w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "Failed to compile syntax") {
+		t.Errorf("expected Respond to write the error overlay with the status 500, got %d, %v", w.Code, err)
+	}
+
+	mockFS["parse.blade"].Data = []byte(`Fixed`)
+	mockFS["parse.blade"].ModTime = time.Now().Add(time.Second)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed in DevMode: %v", err)
+	}
+	buf.Reset()
+	if err := engine.Render(&buf, "parse", nil); err != nil || buf.String() != "Fixed" {
+		t.Errorf("expected the fixed view, got %q, %v", buf.String(), err)
+	}
+}
+
+// flushRecorder records the output written before each flush.
+type flushRecorder struct {
+	bytes.Buffer
+	flushed []string
+}
+
+func (f *flushRecorder) Flush() error {
+	f.flushed = append(f.flushed, f.String())
+	return nil
+}
+
+func TestFlushStreaming(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"layout.blade": `<table>@yield('content')</table>`,
+		"export.blade": `@extends('layout')@section('content'){{ range .Rows }}<tr>{{ . }}</tr>@flush(2){{ end }}@flush@endsection`,
+	}))
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	rows := make(chan int)
+	go func() {
+		defer close(rows)
+		for i := 1; i <= 5; i++ {
+			rows <- i
+		}
+	}()
+	seq := func(yield func(int) bool) {
+		for i := 1; i <= 5; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	for _, data := range []any{map[string]any{"Rows": rows}, map[string]any{"Rows": iter.Seq[int](seq)}} {
+		var out flushRecorder
+		if err := engine.Render(&out, "export", data); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		expected := []string{
+			"<table><tr>1</tr><tr>2</tr>",
+			"<table><tr>1</tr><tr>2</tr><tr>3</tr><tr>4</tr>",
+			"<table><tr>1</tr><tr>2</tr><tr>3</tr><tr>4</tr><tr>5</tr>",
+		}
+		if !reflect.DeepEqual(out.flushed, expected) {
+			t.Errorf("unexpected flushes %q", out.flushed)
+		}
+	}
+}
+
+func TestLoadKeepsUnchangedTemplates(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"home.blade":         `@include('partials/nav') Home`,
+		"about.blade":        `About`,
+		"partials/nav.blade": `Nav`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	home, about := engine.set.Load().templates["home"], engine.set.Load().templates["about"]
+
+	mockFS["partials/nav.blade"].Data = []byte(`Menu`)
+	mockFS["partials/nav.blade"].ModTime = time.Now().Add(time.Second)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if engine.set.Load().templates["home"] == home {
+		t.Errorf("Expected the view including the changed partial to be compiled again")
+	}
+	if engine.set.Load().templates["about"] != about {
+		t.Errorf("Expected the unchanged view to keep its compiled template")
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "home", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if buf.String() != "Menu Home" {
+		t.Errorf("Unexpected output: %s", buf.String())
+	}
+}
@@ -0,0 +1,174 @@
+package blade
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"html/template"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template/parse"
+)
+
+// unescapedTypes are the types html/template trusts as safe and prints without escaping.
+var unescapedTypes = map[reflect.Type]string{
+	reflect.TypeFor[template.HTML]():     "template.HTML",
+	reflect.TypeFor[template.HTMLAttr](): "template.HTMLAttr",
+	reflect.TypeFor[template.JS]():       "template.JS",
+	reflect.TypeFor[template.JSStr]():    "template.JSStr",
+	reflect.TypeFor[template.CSS]():      "template.CSS",
+	reflect.TypeFor[template.URL]():      "template.URL",
+	reflect.TypeFor[template.Srcset]():   "template.Srcset",
+}
+
+// UnescapedOutput is an action printing a value html/template does not escape, like a template.HTML returned
+// by a helper, see Engine.EscapeReport.
+type UnescapedOutput struct {
+	// File and Line approximately locate the action in the Blade sources, like RenderError
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// Action is the printed pipeline, like ".Post.Body" or "nl2br .Bio"
+	Action string `json:"action"`
+	// Type is the trusted type printed, like "template.HTML"
+	Type string `json:"type"`
+	// Source is the origin of the value, like "func nl2br" or "field .Post.Body"
+	Source string `json:"source"`
+	// Entries are the views rendering the action
+	Entries []string `json:"entries"`
+}
+
+func (o UnescapedOutput) String() string {
+	return fmt.Sprintf("%s:%d: %s from %s: {{ %s }}", o.File, o.Line, o.Type, o.Source, o.Action)
+}
+
+// EscapeReport lists the actions of the loaded HTML views printing values html/template trusts as safe, like
+// template.HTML or template.JS, to audit the XSS exposure of the views. The values are found from the result
+// types of the funcs of FuncMap and helpers, and from the field types of the data of views declaring @param
+// types registered in ParamTypes. Funcs returning interfaces and undeclared data are not reported.
+func (e *Engine) EscapeReport() []UnescapedOutput {
+	set := e.set.Load()
+	funcs := []template.FuncMap{e.newRenderState(context.Background(), nil).funcs(), builtinFuncs(), e.FuncMap}
+
+	var report []UnescapedOutput
+	found := map[string]int{}
+	for _, entry := range sortedKeys(set.templates) {
+		tmpl := set.templates[entry]
+		f, ok := set.parsedFiles[entry]
+		if tmpl.err != nil || tmpl.xml || !ok {
+			continue
+		}
+		types := map[string]reflect.Type{}
+		for _, param := range entryParams(set.parsedFiles, f) {
+			if t, ok := e.resolveParamType(param.Type); ok {
+				types[param.Name] = t
+			}
+		}
+
+		for _, tree := range tmpl.proto.trees() {
+			// partials, snippets and macros are not executed with the data of the view
+			rootDot := !isSharedDefine(tree.Name)
+			walkActions(tree.Root, rootDot, func(action *parse.ActionNode, rootDot bool) {
+				typeName, source, snippet := unescapedAction(action, rootDot, funcs, types)
+				if typeName == "" {
+					return
+				}
+				file, line := locateRenderError(set, entry, tree.Name, snippet)
+				if file == "" {
+					file = f.Path
+				}
+				key := fmt.Sprintf("%s:%d:%s:%s", file, line, source, action.Pipe)
+				if i, ok := found[key]; ok {
+					if !slices.Contains(report[i].Entries, entry) {
+						report[i].Entries = append(report[i].Entries, entry)
+					}
+					return
+				}
+				found[key] = len(report)
+				report = append(report, UnescapedOutput{
+					File: file, Line: line, Action: action.Pipe.String(), Type: typeName, Source: source, Entries: []string{entry},
+				})
+			})
+		}
+	}
+	slices.SortFunc(report, func(a, b UnescapedOutput) int {
+		return cmp.Or(strings.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line), strings.Compare(a.Action, b.Action))
+	})
+	return report
+}
+
+// unescapedAction returns the trusted type printed by action, its source and the snippet locating it in the
+// Blade sources, or an empty type when the action prints no trusted value.
+func unescapedAction(action *parse.ActionNode, rootDot bool, funcs []template.FuncMap, types map[string]reflect.Type) (string, string, string) {
+	if len(action.Pipe.Decl) > 0 || len(action.Pipe.Cmds) == 0 {
+		return "", "", ""
+	}
+	cmd := action.Pipe.Cmds[len(action.Pipe.Cmds)-1]
+	switch arg := cmd.Args[0].(type) {
+	case *parse.IdentifierNode:
+		if strings.HasPrefix(arg.Ident, "__") {
+			return "", "", ""
+		}
+		// later func maps override the earlier ones when compiling
+		for _, m := range slices.Backward(funcs) {
+			fn, ok := m[arg.Ident]
+			if !ok {
+				continue
+			}
+			t := reflect.TypeOf(fn)
+			if t == nil || t.Kind() != reflect.Func || t.NumOut() == 0 {
+				return "", "", ""
+			}
+			return unescapedTypes[t.Out(0)], "func " + arg.Ident, arg.Ident
+		}
+	case *parse.FieldNode:
+		if rootDot && len(cmd.Args) == 1 {
+			return unescapedField(arg.Ident, types), "field " + arg.String(), arg.String()
+		}
+	case *parse.VariableNode:
+		if arg.Ident[0] == "$" && len(cmd.Args) == 1 {
+			return unescapedField(arg.Ident[1:], types), "field " + arg.String(), arg.String()
+		}
+	}
+	return "", "", ""
+}
+
+// unescapedField returns the trusted type of the data field idents of the declared params, empty when its type
+// is not trusted or not known.
+func unescapedField(idents []string, types map[string]reflect.Type) string {
+	if len(idents) == 0 {
+		return ""
+	}
+	t, ok := types[idents[0]]
+	if !ok {
+		return ""
+	}
+	if result, _, _, ok := followFields(t, idents[1:]); ok && result != nil {
+		return unescapedTypes[result]
+	}
+	return ""
+}
+
+// walkActions calls fn with the printing actions under node, and whether their dot is the top level data.
+func walkActions(node parse.Node, rootDot bool, fn func(action *parse.ActionNode, rootDot bool)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkActions(child, rootDot, fn)
+		}
+	case *parse.ActionNode:
+		fn(n, rootDot)
+	case *parse.IfNode:
+		walkActions(n.List, rootDot, fn)
+		walkActions(n.ElseList, rootDot, fn)
+	case *parse.RangeNode:
+		walkActions(n.List, false, fn)
+		walkActions(n.ElseList, rootDot, fn)
+	case *parse.WithNode:
+		walkActions(n.List, false, fn)
+		walkActions(n.ElseList, rootDot, fn)
+	}
+}
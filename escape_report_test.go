@@ -0,0 +1,48 @@
+package blade
+
+import (
+	"html/template"
+	"reflect"
+	"testing"
+)
+
+type escapeTestPost struct {
+	Title string
+	Body  template.HTML
+}
+
+func (p escapeTestPost) Script() template.JS {
+	return ""
+}
+
+func TestEscapeReport(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `<main>@yield('content')</main>`,
+		"bio.blade":    `<p>{{ nl2br .Bio }}</p>`,
+		"post.blade": `@extends('layout')
+@param('Post', 'models.Post')
+@section('content'){{ .Post.Title }}
+{{ .Post.Body }}
+<script>{{ .Post.Script }}</script>
+{{ markdown .Post.Title }} {{ .Post.Title | upper }}
+@include('bio', .Author)@endsection`,
+		"about.blade": `@include('bio', .)`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.ParamTypes["models.Post"] = reflect.TypeFor[escapeTestPost]()
+	engine.FuncMap["markdown"] = func(s string) template.HTML { return template.HTML(s) }
+	engine.FuncMap["upper"] = func(s string) string { return s }
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	expected := []UnescapedOutput{
+		{File: "bio.blade", Line: 1, Action: "nl2br .Bio", Type: "template.HTML", Source: "func nl2br", Entries: []string{"about", "bio", "post"}},
+		{File: "post.blade", Line: 4, Action: ".Post.Body", Type: "template.HTML", Source: "field .Post.Body", Entries: []string{"post"}},
+		{File: "post.blade", Line: 5, Action: ".Post.Script", Type: "template.JS", Source: "field .Post.Script", Entries: []string{"post"}},
+		{File: "post.blade", Line: 6, Action: "markdown .Post.Title", Type: "template.HTML", Source: "func markdown", Entries: []string{"post"}},
+	}
+	if report := engine.EscapeReport(); !reflect.DeepEqual(report, expected) {
+		t.Errorf("Report mismatch.\nExp: %+v\nGot: %+v", expected, report)
+	}
+}
@@ -0,0 +1,26 @@
+package blade
+
+import (
+	"fmt"
+	"html/template"
+)
+
+// esiInclude wraps the template call of an include with an esi argument, rendering an ESI tag fetching src in
+// ESI mode: {{ with __esi "/fragments/header" }}{{ . }}{{ else }}{{ template "__partial_header" . }}{{ end }}.
+// The src is a quoted URL or an expression.
+func esiInclude(src string, include string) string {
+	if url, ok := unquoteDirectiveString(src); ok {
+		src = fmt.Sprintf("%q", url)
+	} else {
+		src = "(" + src + ")"
+	}
+	return fmt.Sprintf(`{{ with __esi %s }}{{ . }}{{ else }}%s{{ end }}`, src, include)
+}
+
+// esi returns the ESI tag of src in the ESI mode of the render, and nothing otherwise.
+func (s *renderState) esi(src string) template.HTML {
+	if !ESIEnabled(s.ctx) {
+		return ""
+	}
+	return template.HTML(`<esi:include src="` + template.HTMLEscapeString(src) + `"/>`)
+}
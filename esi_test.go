@@ -0,0 +1,49 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestESI(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"header.blade": `<header>{{ .User }}</header>`,
+		"cart.blade":   `<aside>{{ .Count }}</aside>`,
+		"page.blade":   `@include('header', esi: '/fragments/header?a=1&b=2')@include('cart', .Cart, memo: true, esi: .CartURL)<main></main>`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	data := map[string]any{"User": "Ann", "Cart": map[string]int{"Count": 2}, "CartURL": "/fragments/cart"}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `<header>Ann</header><aside>2</aside><main></main>`; buf.String() != expected {
+		t.Errorf("Expected inline includes without ESI mode.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := engine.RenderContext(WithESI(context.Background()), &buf, "page", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<esi:include src="/fragments/header?a=1&amp;b=2"/><esi:include src="/fragments/cart"/><main></main>`
+	if buf.String() != expected {
+		t.Errorf("Expected ESI tags in ESI mode.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Surrogate-Capability", `varnish="ESI/1.0"`)
+	w := httptest.NewRecorder()
+	if err := engine.Respond(w, r, http.StatusOK, "page", data); err != nil {
+		t.Fatalf("Respond failed: %v", err)
+	}
+	if w.Body.String() != expected || w.Header().Get("Surrogate-Control") != `content="ESI/1.0"` {
+		t.Errorf("Expected an ESI response for a surrogate, got %s %v", w.Body.String(), w.Header())
+	}
+}
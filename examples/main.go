@@ -19,9 +19,10 @@ func main() {
 	ginEngine := gin.Default()
 	ginEngine.HTMLRender = blade.NewHTMLRender(bladeEngine)
 	ginEngine.Use(func(c *gin.Context) {
-		// For development, reload templates on each request.
-		err := bladeEngine.Load()
-		if err != nil {
+		// For development, reload templates on each request. The reload compiles in the background, this
+		// request renders with the current templates and reports the error of the last reload.
+		bladeEngine.LoadAsync()
+		if err := bladeEngine.Ready(); err != nil {
 			c.Status(500)
 			c.String(500, err.Error())
 			c.Abort()
@@ -0,0 +1,45 @@
+//go:build js && wasm
+
+// Command wasm renders the embedded views in the browser, for client-side previews of the same templates
+// rendered by the server. Build it with:
+//
+//	GOOS=js GOARCH=wasm go build -o blade.wasm ./examples/wasm
+//	tinygo build -o blade.wasm -target wasm ./examples/wasm
+//
+// and call bladeRender("card", JSON.stringify({Title: "Hello"})) from JavaScript.
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/dangdungcntt/go-blade"
+)
+
+//go:embed views
+var views embed.FS
+
+func main() {
+	eng := blade.NewEngineFS(views, "views")
+	if err := eng.Load(); err != nil {
+		panic(err)
+	}
+
+	js.Global().Set("bladeRender", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) != 2 {
+			return map[string]any{"error": "expected an entry and JSON data"}
+		}
+		var data any
+		if err := json.Unmarshal([]byte(args[1].String()), &data); err != nil {
+			return map[string]any{"error": err.Error()}
+		}
+		var buf bytes.Buffer
+		if err := eng.Render(&buf, args[0].String(), data); err != nil {
+			return map[string]any{"error": err.Error()}
+		}
+		return map[string]any{"html": buf.String()}
+	}))
+	select {}
+}
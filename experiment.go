@@ -0,0 +1,104 @@
+package blade
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+)
+
+// ExperimentAssigner assigns one of the variants of an experiment to a render, like the bucket of the user of
+// the context in an A/B testing service.
+type ExperimentAssigner interface {
+	AssignVariant(ctx context.Context, experiment string, variants []string) string
+}
+
+// ExperimentAssignerFunc adapts a function to an ExperimentAssigner.
+type ExperimentAssignerFunc func(ctx context.Context, experiment string, variants []string) string
+
+// AssignVariant calls f.
+func (f ExperimentAssignerFunc) AssignVariant(ctx context.Context, experiment string, variants []string) string {
+	return f(ctx, experiment, variants)
+}
+
+// Exposure is a variant of an experiment shown by a render, see Engine.OnExposure.
+type Exposure struct {
+	Experiment string
+	Variant    string
+}
+
+// StickyAssigner returns an ExperimentAssigner spreading the variants evenly by a hash of the key of the render,
+// like the ID of the user or of a session cookie, so the same key always sees the same variant. Renders without
+// a key get the first variant.
+func StickyAssigner(key func(ctx context.Context) string) ExperimentAssigner {
+	return ExperimentAssignerFunc(func(ctx context.Context, experiment string, variants []string) string {
+		k := key(ctx)
+		if k == "" {
+			return variants[0]
+		}
+		h := fnv.New32a()
+		h.Write([]byte(experiment + "\x00" + k))
+		return variants[h.Sum32()%uint32(len(variants))]
+	})
+}
+
+// experiment returns the variant of the experiment assigned to the render, assigning it and reporting the
+// exposure the first time the experiment renders. A variant the assigner returns that is not in variants
+// renders the @else block of the experiment.
+func (s *renderState) experiment(name string, variants ...string) string {
+	if variant, ok := s.variants[name]; ok {
+		return variant
+	}
+	variant := variants[0]
+	if s.e.Experiments != nil {
+		variant = s.e.Experiments.AssignVariant(s.ctx, name, variants)
+	}
+	s.variants[name] = variant
+	if s.e.OnExposure != nil && slices.Contains(variants, variant) {
+		s.e.OnExposure(s.ctx, Exposure{Experiment: name, Variant: variant})
+	}
+	return variant
+}
+
+// parseExperimentDirective converts @experiment('hero', ['a', 'b']) to
+// {{ if true }}{{ $__variant := __experiment "hero" "a" "b" }}{{ if false }}, each @variant continues the chain
+// with {{ else if eq $__variant "a" }} and @endexperiment closes both blocks. The variants can also be given
+// as arguments: @experiment('hero', 'a', 'b').
+func parseExperimentDirective(args []string) (string, bool) {
+	if len(args) < 2 {
+		return "", false
+	}
+	name, ok := unquoteDirectiveString(args[0])
+	if !ok || name == "" {
+		return "", false
+	}
+	variantArgs := args[1:]
+	if list, ok := strings.CutPrefix(args[1], "["); ok && len(args) == 2 && strings.HasSuffix(list, "]") {
+		variantArgs = splitTopLevelArgs(strings.TrimSuffix(list, "]"))
+	}
+	var variants strings.Builder
+	for _, arg := range variantArgs {
+		variant, ok := unquoteDirectiveString(arg)
+		if !ok || variant == "" {
+			return "", false
+		}
+		fmt.Fprintf(&variants, " %q", variant)
+	}
+	if variants.Len() == 0 {
+		return "", false
+	}
+	return fmt.Sprintf(`{{ if true }}{{ $__variant := __experiment %q%s }}{{ if false }}`, name, variants.String()), true
+}
+
+// parseVariantDirective converts @variant('a') to {{ else if eq $__variant "a" }}.
+func parseVariantDirective(args []string) (string, bool) {
+	if len(args) != 1 {
+		return "", false
+	}
+	variant, ok := unquoteDirectiveString(args[0])
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf(`{{ else if eq $__variant %q }}`, variant), true
+}
@@ -0,0 +1,68 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestExperiment(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"home.blade": `@experiment('hero', ['a', 'b'])
+	@variant('a')<h1>A</h1>
+	@variant('b')<h1>B</h1>
+	@else<h1>control</h1>
+@endexperiment|@experiment('hero', 'a', 'b')@variant('b')again@endexperiment`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	render := func(ctx context.Context) string {
+		var buf bytes.Buffer
+		if err := engine.RenderContext(ctx, &buf, "home", nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	if got := render(context.Background()); got != "<h1>A</h1>\n\t|" {
+		t.Errorf("Expected the first variant without an assigner, got %q", got)
+	}
+
+	type userKey struct{}
+	var exposures []Exposure
+	engine.Experiments = StickyAssigner(func(ctx context.Context) string {
+		user, _ := ctx.Value(userKey{}).(string)
+		return user
+	})
+	engine.OnExposure = func(ctx context.Context, exposure Exposure) {
+		exposures = append(exposures, exposure)
+	}
+	variants := map[string]int{}
+	for _, user := range []string{"u1", "u2", "u3", "u4", "u5", "u6", "u7", "u8"} {
+		ctx := context.WithValue(context.Background(), userKey{}, user)
+		got := render(ctx)
+		if got != render(ctx) {
+			t.Errorf("Expected the same variant for user %s", user)
+		}
+		variants[got]++
+	}
+	if len(variants) != 2 || variants["<h1>B</h1>\n\t|again"] == 0 {
+		t.Errorf("Expected both variants across users, got %v", variants)
+	}
+	if len(exposures) != 16 || exposures[0].Experiment != "hero" {
+		t.Errorf("Expected one exposure per render, got %+v", exposures)
+	}
+
+	exposures = nil
+	engine.Experiments = ExperimentAssignerFunc(func(context.Context, string, []string) string { return "holdout" })
+	if got := render(context.Background()); got != "<h1>control</h1>\n|" {
+		t.Errorf("Expected the @else block for an unknown variant, got %q", got)
+	}
+	if !reflect.DeepEqual(exposures, []Exposure(nil)) {
+		t.Errorf("Expected no exposure for an unknown variant, got %+v", exposures)
+	}
+}
@@ -0,0 +1,61 @@
+package blade
+
+import (
+	"context"
+	"fmt"
+)
+
+// FeatureChecker decides whether a feature flag is enabled for a render, like an adapter of LaunchDarkly or
+// OpenFeature reading the user to evaluate from the context.
+type FeatureChecker interface {
+	FeatureEnabled(ctx context.Context, flag string) bool
+}
+
+// FeatureCheckerFunc adapts a function to a FeatureChecker.
+type FeatureCheckerFunc func(ctx context.Context, flag string) bool
+
+// FeatureEnabled calls f.
+func (f FeatureCheckerFunc) FeatureEnabled(ctx context.Context, flag string) bool {
+	return f(ctx, flag)
+}
+
+// FeatureFlags is a FeatureChecker of fixed flags, missing flags are disabled.
+type FeatureFlags map[string]bool
+
+// FeatureEnabled returns the value of the flag.
+func (f FeatureFlags) FeatureEnabled(_ context.Context, flag string) bool {
+	return f[flag]
+}
+
+type featureCheckerKey struct{}
+
+// WithFeatures returns a context whose renders evaluate feature flags with checker instead of Engine.Features,
+// like the flags of the user of a request.
+func WithFeatures(ctx context.Context, checker FeatureChecker) context.Context {
+	return context.WithValue(ctx, featureCheckerKey{}, checker)
+}
+
+// feature reports whether the flag is enabled for the render, by the checker of WithFeatures or else
+// Engine.Features. Flags are disabled without a checker.
+func (s *renderState) feature(flag string) bool {
+	checker, _ := s.ctx.Value(featureCheckerKey{}).(FeatureChecker)
+	if checker == nil {
+		checker = s.e.Features
+	}
+	if checker == nil {
+		return false
+	}
+	return checker.FeatureEnabled(s.ctx, flag)
+}
+
+// parseFeatureDirective converts @feature('new-checkout') to {{ if feature "new-checkout" }}, the flag can be
+// an expression: @feature(.Experiment.Flag).
+func parseFeatureDirective(args []string) (string, bool) {
+	if len(args) != 1 || args[0] == "" {
+		return "", false
+	}
+	if flag, ok := unquoteDirectiveString(args[0]); ok {
+		return fmt.Sprintf(`{{ if feature %q }}`, flag), true
+	}
+	return fmt.Sprintf(`{{ if feature (%s) }}`, args[0]), true
+}
@@ -0,0 +1,44 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestFeature(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"checkout.blade": `@feature('new-checkout')<new-checkout>@else<legacy-checkout>@endfeature` +
+			`|@feature(.Flag)on@endfeature|{{ if and (feature "beta") .Beta }}beta{{ end }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	render := func(ctx context.Context) string {
+		var buf bytes.Buffer
+		if err := engine.RenderContext(ctx, &buf, "checkout", map[string]any{"Flag": "dark-mode", "Beta": true}); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	if got := render(context.Background()); got != "<legacy-checkout>||" {
+		t.Errorf("Expected disabled flags without a checker, got %s", got)
+	}
+
+	engine.Features = FeatureFlags{"new-checkout": true, "dark-mode": true}
+	if got := render(context.Background()); got != "<new-checkout>|on|" {
+		t.Errorf("Expected the flags of Engine.Features, got %s", got)
+	}
+
+	type userKey struct{}
+	perUser := FeatureCheckerFunc(func(ctx context.Context, flag string) bool {
+		return flag == "beta" && ctx.Value(userKey{}) == "tester"
+	})
+	ctx := WithFeatures(context.WithValue(context.Background(), userKey{}, "tester"), perUser)
+	if got := render(ctx); got != "<legacy-checkout>||beta" {
+		t.Errorf("Expected the flags of the context checker, got %s", got)
+	}
+}
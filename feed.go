@@ -0,0 +1,85 @@
+package blade
+
+import (
+	"io"
+	"iter"
+	"time"
+)
+
+// The built-in views of the sitemap and feeds, a view with the same name in the templates, like
+// views/blade/sitemap.xml, replaces the built-in one.
+const (
+	sitemapView = componentNamePrefix + "sitemap"
+	rssView     = componentNamePrefix + "rss"
+	atomView    = componentNamePrefix + "atom"
+)
+
+// Sitemap is the data of the blade/sitemap view.
+type Sitemap struct {
+	URLs iter.Seq[SitemapURL]
+}
+
+// SitemapURL is a page of a sitemap.
+type SitemapURL struct {
+	// Loc is the absolute URL of the page
+	Loc string
+	// LastMod is the time the page last changed, omitted when zero
+	LastMod time.Time
+	// ChangeFreq is how often the page changes, like "daily", omitted when empty
+	ChangeFreq string
+	// Priority is the priority of the page from 0.0 to 1.0, omitted when 0
+	Priority float64
+}
+
+// Feed is the data of the blade/rss and blade/atom views.
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the URL of the site of the feed
+	Link string
+	// FeedURL is the URL of the feed itself, the ID of Atom feeds when set
+	FeedURL string
+	Author  string
+	// Updated is the time the feed last changed, RenderAtom uses the current time when zero
+	Updated time.Time
+	Items   iter.Seq[FeedItem]
+}
+
+// FeedItem is an entry of a feed.
+type FeedItem struct {
+	Title string
+	// Link is the URL of the item, its ID when ID is empty
+	Link string
+	ID   string
+	// Description is the summary of the item
+	Description string
+	// Content is the HTML content of the item
+	Content   string
+	Author    string
+	Published time.Time
+	// Updated is the time the item last changed, Published when zero
+	Updated time.Time
+}
+
+// RenderSitemap renders a sitemap.xml of urls with the blade/sitemap view. The URLs are rendered as they are
+// iterated, so a sitemap of a large table streams from a database cursor:
+//
+//	eng.RenderSitemap(w, func(yield func(blade.SitemapURL) bool) {
+//		for rows.Next() { ... }
+//	})
+func (e *Engine) RenderSitemap(w io.Writer, urls iter.Seq[SitemapURL]) error {
+	return e.Render(w, sitemapView, Sitemap{URLs: urls})
+}
+
+// RenderRSS renders feed as RSS 2.0 with the blade/rss view.
+func (e *Engine) RenderRSS(w io.Writer, feed Feed) error {
+	return e.Render(w, rssView, feed)
+}
+
+// RenderAtom renders feed as Atom with the blade/atom view.
+func (e *Engine) RenderAtom(w io.Writer, feed Feed) error {
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Now()
+	}
+	return e.Render(w, atomView, feed)
+}
@@ -0,0 +1,138 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderSitemap(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{"home.blade": `home`}))
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err := engine.RenderSitemap(&buf, slices.Values([]SitemapURL{
+		{Loc: "https://example.com/"},
+		{Loc: "https://example.com/search?q=a&b", LastMod: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), ChangeFreq: "daily", Priority: 0.8},
+	}))
+	if err != nil {
+		t.Fatalf("RenderSitemap failed: %v", err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<url>
+<loc>https://example.com/</loc>
+</url>
+<url>
+<loc>https://example.com/search?q=a&amp;b</loc>
+<lastmod>2024-05-01T00:00:00Z</lastmod>
+<changefreq>daily</changefreq>
+<priority>0.8</priority>
+</url>
+</urlset>`
+	if buf.String() != expected {
+		t.Errorf("Sitemap mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+	if got := engine.ContentType(sitemapView); got != "application/xml; charset=utf-8" {
+		t.Errorf("Unexpected content type: %s", got)
+	}
+}
+
+func TestRenderFeeds(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{"home.blade": `home`}))
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	published := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	feed := Feed{
+		Title:   "Blog",
+		Link:    "https://example.com/blog",
+		FeedURL: "https://example.com/blog/atom.xml",
+		Updated: published,
+		Items: slices.Values([]FeedItem{
+			{Title: "Tips & tricks", Link: "https://example.com/blog/tips", Content: "<p>Hi</p>", Published: published},
+		}),
+	}
+
+	var buf bytes.Buffer
+	if err := engine.RenderRSS(&buf, feed); err != nil {
+		t.Fatalf("RenderRSS failed: %v", err)
+	}
+	for _, expected := range []string{
+		`<title>Tips &amp; tricks</title>`,
+		`<guid isPermaLink="true">https://example.com/blog/tips</guid>`,
+		`<content:encoded>&lt;p&gt;Hi&lt;/p&gt;</content:encoded>`,
+		`<pubDate>Wed, 01 May 2024 08:30:00 +0000</pubDate>`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("RSS is missing %s:\n%s", expected, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := engine.RenderAtom(&buf, feed); err != nil {
+		t.Fatalf("RenderAtom failed: %v", err)
+	}
+	for _, expected := range []string{
+		`<id>https://example.com/blog/atom.xml</id>`,
+		`<link href="https://example.com/blog/atom.xml" rel="self" type="application/atom+xml"/>`,
+		`<updated>2024-05-01T08:30:00Z</updated>`,
+		`<content type="html">&lt;p&gt;Hi&lt;/p&gt;</content>`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Atom is missing %s:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestRenderSitemapOverride(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"blade/sitemap.xml": `{{ range .URLs }}{{ .Loc }};{{ end }}`,
+	}))
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.RenderSitemap(&buf, slices.Values([]SitemapURL{{Loc: "/a"}, {Loc: "/b"}})); err != nil {
+		t.Fatalf("RenderSitemap failed: %v", err)
+	}
+	if buf.String() != "/a;/b;" {
+		t.Errorf("Unexpected sitemap: %s", buf.String())
+	}
+}
+
+func TestExportSiteSitemap(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{"page.blade": `page`}))
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	written, err := engine.ExportSite(context.Background(), SiteOptions{
+		Dir:     dir,
+		Pages:   []Page{{Path: "index.html", Entry: "page"}, {Path: "docs/index.html", Entry: "page"}},
+		BaseURL: "https://example.com/",
+	})
+	if err != nil {
+		t.Fatalf("ExportSite failed: %v", err)
+	}
+	if !slices.Contains(written, "sitemap.xml") {
+		t.Fatalf("Sitemap not written: %v", written)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "sitemap.xml"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if !strings.Contains(string(data), "<loc>https://example.com/</loc>") || !strings.Contains(string(data), "<loc>https://example.com/docs/</loc>") {
+		t.Errorf("Unexpected sitemap: %s", data)
+	}
+}
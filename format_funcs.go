@@ -0,0 +1,75 @@
+package blade
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// numberFormat formats v with the grouping and decimal separators of the render locale.
+// The optional decimals argument fixes the number of fraction digits.
+func (s *renderState) numberFormat(v any, decimals ...int) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", fmt.Errorf("numberFormat: %w", err)
+	}
+	opts := []number.Option{number.MaxFractionDigits(2)}
+	if len(decimals) > 0 {
+		opts = []number.Option{number.Scale(decimals[0])}
+	}
+	return s.printer().Sprint(number.Decimal(f, opts...)), nil
+}
+
+// money formats an amount in a currency: money 12.5 uses Engine.Currency, money "EUR" 12.5 uses the given code.
+func (s *renderState) money(args ...any) (string, error) {
+	code := s.e.Currency
+	var amount any
+	switch len(args) {
+	case 1:
+		amount = args[0]
+	case 2:
+		code, amount = fmt.Sprint(args[0]), args[1]
+	default:
+		return "", errors.New("money: expected an amount or a currency code and an amount")
+	}
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return "", fmt.Errorf("money: %w", err)
+	}
+	f, err := toFloat(amount)
+	if err != nil {
+		return "", fmt.Errorf("money: %w", err)
+	}
+	return s.printer().Sprint(currency.Symbol(unit.Amount(f))), nil
+}
+
+// humanBytes formats a size in bytes using binary IEC units, multiples of 1024, like 1.5 MiB.
+func (s *renderState) humanBytes(v any) (string, error) {
+	size, err := toFloat(v)
+	if err != nil {
+		return "", fmt.Errorf("humanBytes: %w", err)
+	}
+	unit := 0
+	for (size >= 1024 || size <= -1024) && unit < len(byteUnits)-1 {
+		size /= 1024
+		unit++
+	}
+	formatted := s.printer().Sprint(number.Decimal(size, number.MaxFractionDigits(1)))
+	return strings.Join([]string{formatted, byteUnits[unit]}, " "), nil
+}
+
+// printer returns a message printer for the render locale.
+func (s *renderState) printer() *message.Printer {
+	tag, err := language.Parse(s.locale())
+	if err != nil {
+		tag = language.English
+	}
+	return message.NewPrinter(tag)
+}
@@ -0,0 +1,40 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestFormatFuncs(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"price.blade": `{{ numberFormat .Total }}|{{ numberFormat .Total 1 }}|{{ money .Total }}|{{ money "EUR" .Total }}|{{ humanBytes .Size }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.Currency = "VND"
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	data := map[string]any{"Total": 1234567.891, "Size": 1536}
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{"", "1,234,567.89|1,234,567.9|₫ 1,234,568|€ 1,234,567.89|1.5 KiB"},
+		{"de", "1.234.567,89|1.234.567,9|₫ 1.234.568|€ 1.234.567,89|1,5 KiB"},
+	}
+	for _, tc := range tests {
+		ctx := context.Background()
+		if tc.locale != "" {
+			ctx = WithLocale(ctx, tc.locale)
+		}
+		var buf bytes.Buffer
+		if err := engine.RenderContext(ctx, &buf, "price", data); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("Format mismatch for locale %q.\nExp: %s\nGot: %s", tc.locale, tc.expected, buf.String())
+		}
+	}
+}
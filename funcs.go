@@ -0,0 +1,324 @@
+package blade
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"iter"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// builtinFuncs returns the funcs available to every template, they can be overridden by Engine.FuncMap.
+func builtinFuncs() template.FuncMap {
+	return template.FuncMap{
+		"__args":   macroArgs,
+		"__arg":    macroArg,
+		"optional": optional,
+		"__isset":  isset,
+		"empty":    empty,
+		"coalesce": coalesce,
+		"seq":      seq,
+		"times":    times,
+		"__for":    forRange,
+
+		"__foreach": foreachItems,
+
+		"__includeData": includeData,
+
+		"add":     add,
+		"sub":     sub,
+		"mul":     mul,
+		"div":     div,
+		"mod":     mod,
+		"min":     minOf,
+		"max":     maxOf,
+		"percent": percent,
+		"compare": compare,
+
+		"where":   where,
+		"sortBy":  sortBy,
+		"groupBy": groupBy,
+		"pluck":   pluck,
+		"chunk":   chunk,
+
+		"nl2br":     nl2br,
+		"excerpt":   excerpt,
+		"highlight": highlight,
+		"slugify":   slugify,
+		"initials":  initials,
+
+		"lighten":      lighten,
+		"darken":       darken,
+		"contrastText": contrastText,
+
+		"safeHTML": safeHTML,
+		"safeURL":  safeURL,
+		"safeJS":   safeJS,
+
+		"urlWithQuery": urlWithQuery,
+		"formatDate":   formatDate,
+
+		"__meta":          newMeta,
+		"metaTitle":       metaTitle,
+		"metaDescription": metaDescription,
+		"ogImage":         ogImage,
+		"breadcrumbList":  breadcrumbList,
+
+		"shared": sharedOutsideScope,
+	}
+}
+
+// sharedOutsideScope is the shared helper of renders outside of a Scope, without shared values.
+func sharedOutsideScope(string) any {
+	return nil
+}
+
+// macroArgs collects the arguments of a @call.
+func macroArgs(args ...any) []any {
+	return args
+}
+
+// macroArg returns the i-th argument of a @call, or nil when it was not passed.
+func macroArg(args []any, i int) any {
+	if i < 0 || i >= len(args) {
+		return nil
+	}
+	return args[i]
+}
+
+// optional walks fields, map keys or methods without arguments of v, returning nil as soon as a value is missing or nil.
+func optional(v any, path ...string) any {
+	current := reflect.ValueOf(v)
+	for _, name := range path {
+		current = indirectValue(current)
+		if !current.IsValid() {
+			return nil
+		}
+		next := reflect.Value{}
+		if method := current.MethodByName(name); method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() == 1 {
+			next = method.Call(nil)[0]
+		} else if current.CanAddr() {
+			if method := current.Addr().MethodByName(name); method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() == 1 {
+				next = method.Call(nil)[0]
+			}
+		}
+		if !next.IsValid() {
+			switch current.Kind() {
+			case reflect.Struct:
+				field, ok := current.Type().FieldByName(name)
+				if !ok || !field.IsExported() {
+					return nil
+				}
+				var err error
+				if next, err = current.FieldByIndexErr(field.Index); err != nil {
+					return nil
+				}
+			case reflect.Map:
+				if current.Type().Key().Kind() != reflect.String {
+					return nil
+				}
+				next = current.MapIndex(reflect.ValueOf(name).Convert(current.Type().Key()))
+			default:
+				return nil
+			}
+		}
+		current = next
+	}
+	if !indirectValue(current).IsValid() {
+		return nil
+	}
+	return current.Interface()
+}
+
+// isset reports whether the value at the path of fields, map keys or methods of v is defined and not nil, unlike
+// the if action it is true for zero values like 0, false or empty strings.
+func isset(v any, path ...string) bool {
+	return optional(v, path...) != nil
+}
+
+// empty reports whether v is nil, a nil pointer or interface, or an empty string, slice, array, map or channel,
+// for @empty. Unlike the if action, numbers and booleans are never empty.
+func empty(v any) bool {
+	rv := indirectValue(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return rv.Len() == 0
+	}
+	return false
+}
+
+// coalesce returns the first non-empty value, or the last value when all of them are empty.
+// Emptiness follows the rules of the if action: false, 0, nil, and empty strings, slices or maps.
+func coalesce(values ...any) any {
+	for _, v := range values {
+		if truth, _ := template.IsTrue(v); truth {
+			return v
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values[len(values)-1]
+}
+
+// indirectValue dereferences pointers and interfaces, returning the zero Value for nil.
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// seq returns a sequence of integers: seq 5 => 1..5, seq 2 5 => 2..5, seq 10 0 5 => 10, 5, 0. The integers are
+// yielded lazily, so large bounds do not allocate.
+func seq(args ...any) (iter.Seq[int], error) {
+	values := make([]int, len(args))
+	for i, arg := range args {
+		v, err := toInt(arg)
+		if err != nil {
+			return nil, fmt.Errorf("seq: %w", err)
+		}
+		values[i] = v
+	}
+
+	start, end, step := 1, 0, 1
+	switch len(values) {
+	case 1:
+		end = values[0]
+	case 2:
+		start, end = values[0], values[1]
+	case 3:
+		start, end, step = values[0], values[1], values[2]
+		if step < 0 {
+			step = -step
+		}
+	default:
+		return nil, errors.New("seq: expected 1 to 3 arguments")
+	}
+	if step == 0 {
+		return nil, errors.New("seq: step must not be zero")
+	}
+	if start > end {
+		step = -step
+	}
+	return intRange(start, end, step, true), nil
+}
+
+// times returns the integers from 0 to n-1, lazily like seq.
+func times(n any) (iter.Seq[int], error) {
+	count, err := toInt(n)
+	if err != nil {
+		return nil, fmt.Errorf("times: %w", err)
+	}
+	return intRange(0, count, 1, false), nil
+}
+
+// forRange returns the iterations of a @for loop from start to end by step, yielding the counter with dot as the
+// dot of the body, like whileRange.
+func forRange(dot any, start any, end any, step any, inclusive bool) (iter.Seq2[int, any], error) {
+	from, err := toInt(start)
+	if err != nil {
+		return nil, err
+	}
+	to, err := toInt(end)
+	if err != nil {
+		return nil, err
+	}
+	by, err := toInt(step)
+	if err != nil {
+		return nil, err
+	}
+	if by == 0 {
+		return nil, errors.New("loop step must not be zero")
+	}
+	return func(yield func(int, any) bool) {
+		for i := range intRange(from, to, by, inclusive) {
+			if !yield(i, dot) {
+				return
+			}
+		}
+	}, nil
+}
+
+// intRange yields the integers from start to end by a non-zero step, like a for loop, stopping before the
+// counter overflows.
+func intRange(start, end, step int, inclusive bool) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := start; (step > 0 && (i < end || inclusive && i == end)) || (step < 0 && (i > end || inclusive && i == end)); i += step {
+			if !yield(i) || i == end || (step > 0 && i+step < i) || (step < 0 && i+step > i) {
+				return
+			}
+		}
+	}
+}
+
+// toInt converts integers, floats and numeric strings to int.
+func toInt(v any) (int, error) {
+	rv := indirectValue(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return int(rv.Float()), nil
+	case reflect.String:
+		i, err := strconv.Atoi(rv.String())
+		if err != nil {
+			return 0, fmt.Errorf("cannot convert %q to int", rv.String())
+		}
+		return i, nil
+	default:
+		return 0, fmt.Errorf("cannot convert %v (%T) to int", v, v)
+	}
+}
+
+// urlWithQuery returns rawURL with query parameters set from key value pairs, a nil value removes the parameter.
+func urlWithQuery(rawURL string, pairs ...any) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", errors.New("urlWithQuery: expected key value pairs")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	for i := 0; i < len(pairs); i += 2 {
+		key := fmt.Sprint(pairs[i])
+		if pairs[i+1] == nil {
+			query.Del(key)
+			continue
+		}
+		query.Set(key, fmt.Sprint(pairs[i+1]))
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// toFloat converts integers, floats and numeric strings to float64.
+func toFloat(v any) (float64, error) {
+	rv := indirectValue(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		f, err := strconv.ParseFloat(rv.String(), 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot convert %q to number", rv.String())
+		}
+		return f, nil
+	default:
+		return 0, fmt.Errorf("cannot convert %v (%T) to number", v, v)
+	}
+}
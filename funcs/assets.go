@@ -0,0 +1,174 @@
+package funcs
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+// AssetOptions configures the asset helpers.
+type AssetOptions struct {
+	// FS holds the built assets, it is read for the manifest and hashed for integrity attributes
+	FS fs.FS
+	// Manifest is the path in FS of a manifest mapping source names to built files, either a Vite manifest.json
+	// or a JSON object of names to paths like the mix-manifest.json of Laravel Mix. Without a manifest, names
+	// are paths in FS.
+	Manifest string
+	// BaseURL prefixes the paths of the built files in URLs, like "/build/" or a CDN origin
+	BaseURL string
+	// CrossOrigin is the crossorigin attribute of tags with an integrity, "anonymous" when empty
+	CrossOrigin string
+}
+
+// manifestAsset is a built file of the manifest.
+type manifestAsset struct {
+	File      string `json:"file"`
+	Integrity string `json:"integrity"`
+}
+
+// assets resolves and hashes the built files of AssetOptions, the manifest is read once and hashes are cached.
+type assets struct {
+	opts     AssetOptions
+	manifest func() (map[string]manifestAsset, error)
+	hashes   sync.Map
+}
+
+// Assets returns the asset, integrity, scriptTag and styleTag helpers.
+func Assets(opts AssetOptions) template.FuncMap {
+	if opts.CrossOrigin == "" {
+		opts.CrossOrigin = "anonymous"
+	}
+	a := &assets{opts: opts}
+	a.manifest = sync.OnceValues(a.readManifest)
+	return template.FuncMap{
+		"asset":     a.asset,
+		"integrity": a.integrity,
+		"scriptTag": a.scriptTag,
+		"styleTag":  a.styleTag,
+	}
+}
+
+func (a *assets) readManifest() (map[string]manifestAsset, error) {
+	if a.opts.Manifest == "" {
+		return nil, nil
+	}
+	raw, err := fs.ReadFile(a.opts.FS, a.opts.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("assets: %w", err)
+	}
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("assets: invalid manifest %s: %w", a.opts.Manifest, err)
+	}
+	manifest := make(map[string]manifestAsset, len(entries))
+	for name, entry := range entries {
+		var file string
+		var asset manifestAsset
+		if err := json.Unmarshal(entry, &file); err == nil {
+			asset.File = file
+		} else if err := json.Unmarshal(entry, &asset); err != nil {
+			return nil, fmt.Errorf("assets: invalid manifest entry %s: %w", name, err)
+		}
+		manifest[strings.TrimPrefix(name, "/")] = asset
+	}
+	return manifest, nil
+}
+
+// resolve returns the built file of name, with the integrity given by the manifest.
+func (a *assets) resolve(name string) (manifestAsset, error) {
+	manifest, err := a.manifest()
+	if err != nil {
+		return manifestAsset{}, err
+	}
+	name = strings.TrimPrefix(name, "/")
+	if manifest == nil {
+		return manifestAsset{File: name}, nil
+	}
+	asset, ok := manifest[name]
+	if !ok {
+		return manifestAsset{}, fmt.Errorf("assets: %s not found in manifest %s", name, a.opts.Manifest)
+	}
+	asset.File = strings.TrimPrefix(asset.File, "/")
+	return asset, nil
+}
+
+// asset returns the URL of the built file of name.
+func (a *assets) asset(name string) (string, error) {
+	asset, err := a.resolve(name)
+	if err != nil {
+		return "", err
+	}
+	return a.url(asset.File), nil
+}
+
+func (a *assets) url(file string) string {
+	if a.opts.BaseURL == "" {
+		return "/" + file
+	}
+	return strings.TrimSuffix(a.opts.BaseURL, "/") + "/" + file
+}
+
+// integrity returns the Subresource Integrity of the built file of name, like "sha384-...", from the manifest
+// or from the SHA-384 digest of the file in FS.
+func (a *assets) integrity(name string) (string, error) {
+	asset, err := a.resolve(name)
+	if err != nil {
+		return "", err
+	}
+	if asset.Integrity != "" {
+		return asset.Integrity, nil
+	}
+	file, _, _ := strings.Cut(asset.File, "?")
+	if hash, ok := a.hashes.Load(file); ok {
+		return hash.(string), nil
+	}
+	if a.opts.FS == nil {
+		return "", fmt.Errorf("assets: no integrity for %s without AssetOptions.FS", name)
+	}
+	content, err := fs.ReadFile(a.opts.FS, path.Clean(file))
+	if err != nil {
+		return "", fmt.Errorf("assets: %w", err)
+	}
+	digest := sha512.Sum384(content)
+	hash := "sha384-" + base64.StdEncoding.EncodeToString(digest[:])
+	a.hashes.Store(file, hash)
+	return hash, nil
+}
+
+// scriptTag returns a module script element loading the built file of name with its integrity.
+func (a *assets) scriptTag(name string) (template.HTML, error) {
+	src, hash, err := a.tagAttributes(name)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(fmt.Sprintf(`<script type="module" src="%s" integrity="%s" crossorigin="%s"></script>`,
+		src, hash, template.HTMLEscapeString(a.opts.CrossOrigin))), nil
+}
+
+// styleTag returns a stylesheet link element loading the built file of name with its integrity.
+func (a *assets) styleTag(name string) (template.HTML, error) {
+	href, hash, err := a.tagAttributes(name)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(fmt.Sprintf(`<link rel="stylesheet" href="%s" integrity="%s" crossorigin="%s">`,
+		href, hash, template.HTMLEscapeString(a.opts.CrossOrigin))), nil
+}
+
+func (a *assets) tagAttributes(name string) (string, string, error) {
+	src, err := a.asset(name)
+	if err != nil {
+		return "", "", err
+	}
+	hash, err := a.integrity(name)
+	if err != nil {
+		return "", "", err
+	}
+	return template.HTMLEscapeString(src), template.HTMLEscapeString(hash), nil
+}
@@ -0,0 +1,56 @@
+package funcs
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAssets(t *testing.T) {
+	fsys := fstest.MapFS{
+		".vite/manifest.json": {Data: []byte(`{
+			"src/main.js": {"file": "assets/main-4889e940.js"},
+			"src/main.css": {"file": "assets/main-b2a6b9fe.css", "integrity": "sha384-fromManifest"}
+		}`)},
+		"assets/main-4889e940.js": {Data: []byte("console.log(1)")},
+		"mix-manifest.json":       {Data: []byte(`{"/js/app.js": "/js/app.js?id=abc"}`)},
+		"js/app.js":               {Data: []byte("console.log(2)")},
+	}
+
+	render := func(opts AssetOptions, text string) (string, error) {
+		tmpl := template.Must(template.New("assets").Funcs(Assets(opts)).Parse(text))
+		var buf bytes.Buffer
+		err := tmpl.Execute(&buf, nil)
+		return buf.String(), err
+	}
+
+	vite := AssetOptions{FS: fsys, Manifest: ".vite/manifest.json", BaseURL: "https://cdn.example.com/build/"}
+	got, err := render(vite, `{{ scriptTag "src/main.js" }}{{ styleTag "src/main.css" }}`)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	expected := `<script type="module" src="https://cdn.example.com/build/assets/main-4889e940.js" integrity="sha384-vuz+yO71bcb30P4dMUNzy6/D2y+6d/n0KcOnt5clJtTBxEDoKAqGay0stFlC8Dpr" crossorigin="anonymous"></script>` +
+		`<link rel="stylesheet" href="https://cdn.example.com/build/assets/main-b2a6b9fe.css" integrity="sha384-fromManifest" crossorigin="anonymous">`
+	if got != expected {
+		t.Errorf("Vite assets mismatch.\nExp: %s\nGot: %s", expected, got)
+	}
+
+	mix := AssetOptions{FS: fsys, Manifest: "mix-manifest.json", CrossOrigin: "use-credentials"}
+	got, err = render(mix, `<script src="{{ asset "/js/app.js" }}" integrity="{{ integrity "/js/app.js" }}"></script>`)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	expected = `<script src="/js/app.js?id=abc" integrity="sha384-WWRsrZfQIBmz/GoPBJ5tYhne9FtstFG0kAOfa5KAgscnlMSVCitXL3WlZ4ZXg8m9"></script>`
+	if got != expected {
+		t.Errorf("Mix assets mismatch.\nExp: %s\nGot: %s", expected, got)
+	}
+
+	if _, err := render(vite, `{{ asset "src/missing.js" }}`); err == nil || !strings.Contains(err.Error(), "not found in manifest") {
+		t.Errorf("Expected a missing manifest entry error, got %v", err)
+	}
+	if _, err := render(AssetOptions{FS: fsys}, `{{ integrity "missing.js" }}`); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
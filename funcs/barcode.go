@@ -0,0 +1,71 @@
+package funcs
+
+import (
+	"fmt"
+)
+
+// code128Patterns are the bar and space widths of the Code 128 symbols, the last one is the stop symbol.
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// encodeCode128 returns the bar and space widths of data in Code 128, using code set C for even strings of
+// digits and code set B for printable ASCII.
+func encodeCode128(data string) ([]int, error) {
+	if data == "" {
+		return nil, fmt.Errorf("empty barcode data")
+	}
+	var symbols []int
+	if len(data)%2 == 0 && isDigits(data) {
+		symbols = append(symbols, code128StartC)
+		for i := 0; i < len(data); i += 2 {
+			symbols = append(symbols, int(data[i]-'0')*10+int(data[i+1]-'0'))
+		}
+	} else {
+		symbols = append(symbols, code128StartB)
+		for _, r := range data {
+			if r < ' ' || r > '~' {
+				return nil, fmt.Errorf("cannot encode %q in a barcode", r)
+			}
+			symbols = append(symbols, int(r-' '))
+		}
+	}
+	checksum := symbols[0]
+	for i, symbol := range symbols[1:] {
+		checksum += (i + 1) * symbol
+	}
+	symbols = append(symbols, checksum%103, code128Stop)
+
+	var widths []int
+	for _, symbol := range symbols {
+		for _, w := range code128Patterns[symbol] {
+			widths = append(widths, int(w-'0'))
+		}
+	}
+	return widths, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package funcs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+// CodeOptions configures the QR code and barcode helpers.
+type CodeOptions struct {
+	// Level is the error correction level of QR codes, QRMedium by default
+	Level QRLevel
+	// Color is the color of the dark modules and bars, "#000" when empty
+	Color string
+	// Background is the color of the light modules and quiet zone, "#fff" when empty
+	Background string
+}
+
+// Codes returns the qrcode, qrcodeURL, barcode and barcodeURL helpers.
+func Codes(opts CodeOptions) template.FuncMap {
+	if opts.Color == "" {
+		opts.Color = "#000"
+	}
+	if opts.Background == "" {
+		opts.Background = "#fff"
+	}
+	return template.FuncMap{
+		"qrcode":     opts.qrcode,
+		"qrcodeURL":  opts.qrcodeURL,
+		"barcode":    opts.barcode,
+		"barcodeURL": opts.barcodeURL,
+	}
+}
+
+// qrcode returns an inline SVG of the QR code of data, size pixels wide: qrcode .TOTPURI 200.
+func (o CodeOptions) qrcode(data string, size int) (template.HTML, error) {
+	svg, err := o.qrcodeSVG(data, size)
+	return template.HTML(svg), err
+}
+
+// qrcodeURL returns a data URI of the SVG of the QR code of data, for an img src: qrcodeURL .TicketURL 200.
+func (o CodeOptions) qrcodeURL(data string, size int) (template.URL, error) {
+	svg, err := o.qrcodeSVG(data, size)
+	return svgDataURI(svg), err
+}
+
+// barcode returns an inline SVG of the Code 128 barcode of data, height pixels high: barcode .OrderID 60.
+func (o CodeOptions) barcode(data string, height int) (template.HTML, error) {
+	svg, err := o.barcodeSVG(data, height)
+	return template.HTML(svg), err
+}
+
+// barcodeURL returns a data URI of the SVG of the Code 128 barcode of data, for an img src.
+func (o CodeOptions) barcodeURL(data string, height int) (template.URL, error) {
+	svg, err := o.barcodeSVG(data, height)
+	return svgDataURI(svg), err
+}
+
+// qrQuietZone is the width in modules of the light border around QR codes.
+const qrQuietZone = 4
+
+func (o CodeOptions) qrcodeSVG(data string, size int) (string, error) {
+	q, err := encodeQR([]byte(data), o.Level)
+	if err != nil {
+		return "", fmt.Errorf("qrcode: %w", err)
+	}
+	var path strings.Builder
+	for y, row := range q.modules {
+		for x := 0; x < len(row); x++ {
+			// one rectangle per horizontal run of dark modules
+			run := 0
+			for x+run < len(row) && row[x+run] {
+				run++
+			}
+			if run > 0 {
+				fmt.Fprintf(&path, "M%d,%dh%dv1h-%dz", x+qrQuietZone, y+qrQuietZone, run, run)
+				x += run
+			}
+		}
+	}
+	return o.svg(size, size, q.size+qrQuietZone*2, q.size+qrQuietZone*2, path.String()), nil
+}
+
+// barcodeQuietZone is the width in modules of the light margins of barcodes.
+const barcodeQuietZone = 10
+
+func (o CodeOptions) barcodeSVG(data string, height int) (string, error) {
+	widths, err := encodeCode128(data)
+	if err != nil {
+		return "", fmt.Errorf("barcode: %w", err)
+	}
+	var path strings.Builder
+	x := barcodeQuietZone
+	for i, w := range widths {
+		if i%2 == 0 {
+			fmt.Fprintf(&path, "M%d,0h%dv1h-%dz", x, w, w)
+		}
+		x += w
+	}
+	modules := x + barcodeQuietZone
+	return o.svg(modules*2, height, modules, 1, path.String()), nil
+}
+
+// svg returns an SVG of the dark path over the background, scaled from the view box to width and height.
+func (o CodeOptions) svg(width int, height int, viewWidth int, viewHeight int, path string) string {
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" preserveAspectRatio="none" shape-rendering="crispEdges">`+
+		`<rect width="%d" height="%d" fill="%s"/><path d="%s" fill="%s"/></svg>`,
+		width, height, viewWidth, viewHeight, viewWidth, viewHeight, html.EscapeString(o.Background), path, html.EscapeString(o.Color))
+}
+
+func svgDataURI(svg string) template.URL {
+	if svg == "" {
+		return ""
+	}
+	return template.URL("data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg)))
+}
@@ -0,0 +1,133 @@
+package funcs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html"
+	"html/template"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	expected := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := reedSolomonRemainder(data, reedSolomonDivisor(10)); !bytes.Equal(got, expected) {
+		t.Errorf("Reed-Solomon mismatch.\nExp: %v\nGot: %v", expected, got)
+	}
+}
+
+func TestEncodeQR(t *testing.T) {
+	for _, test := range []struct {
+		length  int
+		level   QRLevel
+		version int
+	}{
+		{14, QRMedium, 1},
+		{15, QRMedium, 2},
+		{17, QRLow, 1},
+		{7, QRHigh, 1},
+		{2953, QRLow, 40},
+	} {
+		q, err := encodeQR(bytes.Repeat([]byte("a"), test.length), test.level)
+		if err != nil || q.size != test.version*4+17 {
+			t.Errorf("Expected %d bytes at level %d in version %d, got %v, %v", test.length, test.level, test.version, q, err)
+		}
+	}
+	if _, err := encodeQR(bytes.Repeat([]byte("a"), 2954), QRLow); err != errQRTooLong {
+		t.Errorf("Expected a too long error, got %v", err)
+	}
+	if positions := qrAlignmentPositions(32); !reflect.DeepEqual(positions, []int{6, 34, 60, 86, 112, 138}) {
+		t.Errorf("Alignment positions mismatch: %v", positions)
+	}
+
+	q, err := encodeQR([]byte("otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP"), QRMedium)
+	if err != nil {
+		t.Fatalf("encodeQR failed: %v", err)
+	}
+	// the format bits read from around the top left finder must be a valid code word of level M
+	bits := 0
+	for i := 14; i >= 9; i-- {
+		bits = bits<<1 | boolBit(q.modules[8][14-i])
+	}
+	bits = bits<<1 | boolBit(q.modules[8][7]) // i = 8
+	bits = bits<<1 | boolBit(q.modules[8][8]) // i = 7
+	bits = bits<<1 | boolBit(q.modules[7][8]) // i = 6
+	for i := 5; i >= 0; i-- {
+		bits = bits<<1 | boolBit(q.modules[i][8])
+	}
+	bits ^= 0x5412
+	if level := bits >> 13; level != qrFormatBits[QRMedium] {
+		t.Errorf("Expected the format bits of level M, got %015b", bits)
+	}
+	for y, row := range q.modules[:7] {
+		for x := range 7 {
+			dark := x == 0 || x == 6 || y == 0 || y == 6 || (x >= 2 && x <= 4 && y >= 2 && y <= 4)
+			if row[x] != dark || q.modules[y][q.size-7+x] != dark || q.modules[q.size-7+y][x] != dark {
+				t.Fatalf("Finder pattern mismatch at %d, %d", x, y)
+			}
+		}
+	}
+}
+
+func boolBit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestEncodeCode128(t *testing.T) {
+	for i, pattern := range code128Patterns {
+		sum := 0
+		for _, w := range pattern {
+			sum += int(w - '0')
+		}
+		if expected := 11 + 2*(i/code128Stop); sum != expected {
+			t.Errorf("Symbol %d is %d modules wide, expected %d", i, sum, expected)
+		}
+	}
+
+	widths, err := encodeCode128("1234")
+	if err != nil {
+		t.Fatalf("encodeCode128 failed: %v", err)
+	}
+	// start C, 12, 34, checksum (105 + 12 + 2*34) % 103 = 82, stop
+	expected := code128Patterns[code128StartC] + code128Patterns[12] + code128Patterns[34] + code128Patterns[82] + code128Patterns[code128Stop]
+	var got strings.Builder
+	for _, w := range widths {
+		got.WriteByte(byte('0' + w))
+	}
+	if got.String() != expected {
+		t.Errorf("Code 128 mismatch.\nExp: %s\nGot: %s", expected, got.String())
+	}
+	if _, err := encodeCode128("café"); err == nil {
+		t.Error("Expected an error for a character outside printable ASCII")
+	}
+}
+
+func TestCodes(t *testing.T) {
+	tmpl := template.Must(template.New("codes").Funcs(Codes(CodeOptions{Color: "#123"})).Parse(
+		`{{ qrcode .Secret 120 }}|<img src="{{ qrcodeURL .Secret 120 }}">|{{ barcode .Order 40 }}`))
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]string{"Secret": "otpauth://totp/a", "Order": "A-1"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	parts := strings.Split(buf.String(), "|")
+	if !strings.HasPrefix(parts[0], `<svg xmlns="http://www.w3.org/2000/svg" width="120" height="120" viewBox="0 0 33 33"`) ||
+		!strings.Contains(parts[0], `fill="#123"`) {
+		t.Errorf("Unexpected QR code SVG: %s", parts[0])
+	}
+	uri := strings.TrimSuffix(strings.TrimPrefix(html.UnescapeString(parts[1]), `<img src="data:image/svg+xml;base64,`), `">`)
+	if svg, err := base64.StdEncoding.DecodeString(uri); err != nil || string(svg) != parts[0] {
+		t.Errorf("Unexpected QR code data URI: %s", parts[1])
+	}
+	if !strings.Contains(parts[0], `<path d="M4,4h7v1h-7zM`) {
+		t.Errorf("Expected the top row of the finder as one rectangle: %s", parts[0])
+	}
+	// start, 3 symbols, checksum, stop and quiet zones: 11 * 5 + 13 + 20 modules
+	if !strings.HasPrefix(parts[2], `<svg xmlns="http://www.w3.org/2000/svg" width="176" height="40" viewBox="0 0 88 1"`) {
+		t.Errorf("Unexpected barcode SVG: %s", parts[2])
+	}
+}
@@ -0,0 +1,86 @@
+// Package funcs provides optional helpers that can be registered in blade.Engine.FuncMap.
+package funcs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ResizeFunc builds the URL of src resized to width and height, a zero dimension keeps the aspect ratio.
+type ResizeFunc func(src string, width int, height int) string
+
+// ImageOptions configures the image helpers.
+type ImageOptions struct {
+	// Resize builds resized image URLs, nil returns the source unchanged
+	Resize ResizeFunc
+	// GravatarSize is the default gravatar size in pixels
+	GravatarSize int
+	// GravatarDefault is the gravatar image used when no avatar exists, like identicon or mp
+	GravatarDefault string
+}
+
+// PatternResize returns a ResizeFunc replacing {src}, {width} and {height} in pattern,
+// e.g. "https://img.example.com/insecure/rs:fit:{width}:{height}/plain/{src}".
+func PatternResize(pattern string) ResizeFunc {
+	return func(src string, width int, height int) string {
+		return strings.NewReplacer(
+			"{src}", strings.ReplaceAll(url.QueryEscape(src), "+", "%20"),
+			"{width}", strconv.Itoa(width),
+			"{height}", strconv.Itoa(height),
+		).Replace(pattern)
+	}
+}
+
+// Image returns the gravatar, imageURL and srcset helpers.
+func Image(opts ImageOptions) template.FuncMap {
+	if opts.GravatarSize == 0 {
+		opts.GravatarSize = 80
+	}
+	if opts.GravatarDefault == "" {
+		opts.GravatarDefault = "identicon"
+	}
+	return template.FuncMap{
+		"gravatar": opts.gravatar,
+		"imageURL": opts.imageURL,
+		"srcset":   opts.srcset,
+	}
+}
+
+// gravatar returns the gravatar URL of an email: gravatar .Email or gravatar .Email 128.
+func (o ImageOptions) gravatar(email string, size ...int) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	s := o.GravatarSize
+	if len(size) > 0 {
+		s = size[0]
+	}
+	query := url.Values{}
+	query.Set("s", strconv.Itoa(s))
+	query.Set("d", o.GravatarDefault)
+	return "https://www.gravatar.com/avatar/" + hex.EncodeToString(hash[:]) + "?" + query.Encode()
+}
+
+// imageURL returns the URL of src resized to width and an optional height.
+func (o ImageOptions) imageURL(src string, width int, height ...int) string {
+	if o.Resize == nil {
+		return src
+	}
+	h := 0
+	if len(height) > 0 {
+		h = height[0]
+	}
+	return o.Resize(src, width, h)
+}
+
+// srcset returns a srcset attribute value for src resized to each width.
+func (o ImageOptions) srcset(src string, widths ...int) string {
+	candidates := make([]string, 0, len(widths))
+	for _, width := range widths {
+		candidates = append(candidates, fmt.Sprintf("%s %dw", o.imageURL(src, width), width))
+	}
+	return strings.Join(candidates, ", ")
+}
@@ -0,0 +1,24 @@
+package funcs
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+)
+
+func TestImage(t *testing.T) {
+	tmpl := template.Must(template.New("img").Funcs(Image(ImageOptions{
+		Resize: PatternResize("https://img.example.com/{width}x{height}/{src}"),
+	})).Parse(`<img src="{{ gravatar .Email }}"><img src="{{ imageURL .Src 100 50 }}" srcset="{{ srcset .Src 320 640 }}">`))
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]string{"Email": " John@Example.com ", "Src": "/a b.jpg"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	expected := `<img src="https://www.gravatar.com/avatar/855f96e983f1f8e8be944692b6f719fd54329826cb62e98015efee8e2e071dd4?d=identicon&amp;s=80">` +
+		`<img src="https://img.example.com/100x50/%2Fa%20b.jpg" srcset="https://img.example.com/320x0/%2Fa%20b.jpg 320w, https://img.example.com/640x0/%2Fa%20b.jpg 640w">`
+	if buf.String() != expected {
+		t.Errorf("Image helpers mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
@@ -0,0 +1,109 @@
+package blade
+
+import (
+	"iter"
+	"math"
+	"slices"
+	"testing"
+)
+
+type optionalProfile struct {
+	Name string
+}
+
+type optionalUser struct {
+	Profile *optionalProfile
+	Tags    map[string]string
+}
+
+func (u optionalUser) DisplayName() string {
+	if u.Profile == nil {
+		return "anonymous"
+	}
+	return u.Profile.Name
+}
+
+func TestOptional(t *testing.T) {
+	withProfile := &optionalUser{Profile: &optionalProfile{Name: "John"}, Tags: map[string]string{"role": "admin"}}
+	tests := []struct {
+		name     string
+		value    any
+		path     []string
+		expected any
+	}{
+		{"Field chain", withProfile, []string{"Profile", "Name"}, "John"},
+		{"Nil pointer in chain", &optionalUser{}, []string{"Profile", "Name"}, nil},
+		{"Nil root", nil, []string{"Profile"}, nil},
+		{"Map key", withProfile, []string{"Tags", "role"}, "admin"},
+		{"Missing map key", withProfile, []string{"Tags", "team"}, nil},
+		{"Method", optionalUser{}, []string{"DisplayName"}, "anonymous"},
+		{"Unknown field", withProfile, []string{"Email"}, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := optional(tc.value, tc.path...); got != tc.expected {
+				t.Errorf("optional(%v) = %v; want %v", tc.path, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSeqAndTimes(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      func() (iter.Seq[int], error)
+		expected []int
+	}{
+		{"seq end", func() (iter.Seq[int], error) { return seq(3) }, []int{1, 2, 3}},
+		{"seq start end", func() (iter.Seq[int], error) { return seq(2, 4) }, []int{2, 3, 4}},
+		{"seq descending", func() (iter.Seq[int], error) { return seq(10, 0, 5) }, []int{10, 5, 0}},
+		{"seq strings", func() (iter.Seq[int], error) { return seq("1", 2.0) }, []int{1, 2}},
+		{"times", func() (iter.Seq[int], error) { return times(3) }, []int{0, 1, 2}},
+		{"times zero", func() (iter.Seq[int], error) { return times(0) }, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.got()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := slices.Collect(got); !slices.Equal(got, tc.expected) {
+				t.Errorf("got %v; want %v", got, tc.expected)
+			}
+		})
+	}
+
+	if _, err := seq(1, 5, 0); err == nil {
+		t.Error("Expected error for zero step")
+	}
+
+	// the sequences are lazy, large bounds do not allocate
+	large, err := seq(1, math.MaxInt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range large {
+		if i == 3 {
+			break
+		}
+	}
+	if got := slices.Collect(intRange(math.MaxInt-1, math.MaxInt, 1, true)); len(got) != 2 {
+		t.Errorf("Expected the range to stop at the end without overflowing, got %v", got)
+	}
+}
+
+func TestURLWithQuery(t *testing.T) {
+	got, err := urlWithQuery("/products?page=2&sort=name", "sort", "price", "page", nil, "dir", "desc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "/products?dir=desc&sort=price" {
+		t.Errorf("urlWithQuery mismatch, got %q", got)
+	}
+
+	if _, err := urlWithQuery("/products", "sort"); err == nil {
+		t.Error("Expected error for odd number of arguments")
+	}
+}
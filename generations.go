@@ -0,0 +1,96 @@
+package blade
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// DefaultKeepGenerations is the default number of compiled generations kept for Rollback.
+const DefaultKeepGenerations = 3
+
+type generationKey struct{}
+
+// Generation describes a compiled set of templates kept by the engine.
+type Generation struct {
+	// Version is the hash of the template sources of the generation
+	Version  string
+	LoadedAt time.Time
+	// Current reports whether the generation is rendered by default
+	Current bool
+}
+
+// WithGeneration returns a context rendering views with the kept generation version instead of the current one,
+// like the previous generation while checking a deploy.
+func WithGeneration(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, generationKey{}, version)
+}
+
+// Generations returns the compiled generations kept by the engine, the newest first.
+func (e *Engine) Generations() []Generation {
+	current := e.set.Load()
+	kept := *e.generations.Load()
+	generations := make([]Generation, len(kept))
+	for i, set := range kept {
+		generations[len(kept)-1-i] = Generation{Version: set.version, LoadedAt: set.loadedAt, Current: set == current}
+	}
+	return generations
+}
+
+// Rollback makes the kept generation version current, without recompiling. The rolled back templates stay
+// current until a template source changes again, so a bad template pushed through a database loader can be
+// reverted while it is fixed.
+func (e *Engine) Rollback(version string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	set, err := e.generation(version)
+	if err != nil {
+		return err
+	}
+	e.set.Store(set)
+	return nil
+}
+
+// generation returns the kept generation version.
+func (e *Engine) generation(version string) (*compiledSet, error) {
+	for _, set := range *e.generations.Load() {
+		if set.version == version {
+			return set, nil
+		}
+	}
+	return nil, fmt.Errorf("generation %q not found", version)
+}
+
+// storeGeneration makes set current and keeps it for Rollback, dropping the oldest generations beyond KeepGenerations.
+func (e *Engine) storeGeneration(set *compiledSet) {
+	set.version = setVersion(set)
+	set.loadedAt = time.Now()
+	e.set.Store(set)
+
+	keep := max(e.KeepGenerations, 1)
+	kept := []*compiledSet{}
+	for _, old := range *e.generations.Load() {
+		if old.version != set.version {
+			kept = append(kept, old)
+		}
+	}
+	kept = append(kept, set)
+	if len(kept) > keep {
+		kept = kept[len(kept)-keep:]
+	}
+	e.generations.Store(&kept)
+}
+
+// setVersion hashes the sources of the files of set.
+func setVersion(set *compiledSet) string {
+	h := sha256.New()
+	for _, name := range sortedKeys(set.parsedFiles) {
+		fmt.Fprintf(h, "%s\x00%d\x00%s", name, len(set.parsedFiles[name].Raw), set.parsedFiles[name].Raw)
+	}
+	for _, name := range sortedKeys(set.brokenFiles) {
+		fmt.Fprintf(h, "%s\x00%d\x00%s", name, len(set.brokenFiles[name].file.Raw), set.brokenFiles[name].file.Raw)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
@@ -0,0 +1,72 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGenerations(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"home.blade": `v1`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.KeepGenerations = 2
+
+	render := func(ctx context.Context) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := engine.RenderContext(ctx, &buf, "home", nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		return buf.String()
+	}
+	deploy := func(content string, offset time.Duration) {
+		t.Helper()
+		mockFS["home.blade"].Data = []byte(content)
+		mockFS["home.blade"].ModTime = time.Now().Add(offset)
+		if err := engine.Load(); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+	}
+
+	deploy("v1", 0)
+	v1 := engine.Generations()[0].Version
+	deploy("v2", time.Second)
+	deploy("bad", 2*time.Second)
+
+	generations := engine.Generations()
+	if len(generations) != 2 || !generations[0].Current || generations[1].Current {
+		t.Fatalf("expected the 2 newest generations, the newest current, got %+v", generations)
+	}
+	if render(context.Background()) != "bad" {
+		t.Errorf("expected the newest generation to be rendered")
+	}
+	if got := render(WithGeneration(context.Background(), generations[1].Version)); got != "v2" {
+		t.Errorf("expected the previous generation, got %q", got)
+	}
+	if err := engine.Rollback(v1); err == nil {
+		t.Errorf("expected an error for a dropped generation")
+	}
+
+	if err := engine.Rollback(generations[1].Version); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if got := render(context.Background()); got != "v2" {
+		t.Errorf("expected the rolled back generation, got %q", got)
+	}
+	// the bad template is unchanged since it was loaded
+	mockFS["home.blade"].ModTime = time.Now().Add(-time.Minute)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := render(context.Background()); got != "v2" {
+		t.Errorf("expected the rollback to survive a Load without changes, got %q", got)
+	}
+
+	deploy("fixed", 3*time.Second)
+	if got := render(context.Background()); got != "fixed" {
+		t.Errorf("expected the fixed generation, got %q", got)
+	}
+}
@@ -0,0 +1,173 @@
+//go:build !tinygo
+
+package blade
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+var _ render.HTMLRender = (*HTMLRender)(nil)
+
+// HTMLRender gin HTMLRender compatible
+type HTMLRender struct {
+	e     *Engine
+	scope *Scope
+}
+
+// NewHTMLRender create a new HTMLRender
+func NewHTMLRender(e *Engine) *HTMLRender {
+	return &HTMLRender{e: e}
+}
+
+// NewScopedHTMLRender creates a HTMLRender rendering with the funcs and shared values of s
+func NewScopedHTMLRender(s *Scope) *HTMLRender {
+	return &HTMLRender{e: s.e, scope: s}
+}
+
+// Instance returns a new render.Render
+func (h *HTMLRender) Instance(name string, data any) render.Render {
+	if h.scope != nil {
+		data = h.scope.wrap(data)
+	}
+	return &Render{e: h.e, name: name, data: data}
+}
+
+// MultiHTMLRender routes templates to several gin HTMLRenders by name prefix, so large applications can split
+// their views across engines, or render some of them with other renderers.
+type MultiHTMLRender struct {
+	routes   []htmlRoute
+	fallback render.HTMLRender
+}
+
+// htmlRoute is a prefix of MultiHTMLRender.
+type htmlRoute struct {
+	prefix string
+	render render.HTMLRender
+}
+
+var _ render.HTMLRender = (*MultiHTMLRender)(nil)
+
+// NewMultiHTMLRender creates a MultiHTMLRender rendering the templates matching no prefix with fallback, which may be nil.
+func NewMultiHTMLRender(fallback render.HTMLRender) *MultiHTMLRender {
+	return &MultiHTMLRender{fallback: fallback}
+}
+
+// Handle renders the templates named with prefix, like "admin/", with r. The prefix is trimmed from the name
+// given to r, so "admin/dashboard" renders the view "dashboard" of an engine loaded from the admin directory.
+// The longest matching prefix wins.
+func (m *MultiHTMLRender) Handle(prefix string, r render.HTMLRender) *MultiHTMLRender {
+	m.routes = append(m.routes, htmlRoute{prefix: prefix, render: r})
+	sort.SliceStable(m.routes, func(i, j int) bool {
+		return len(m.routes[i].prefix) > len(m.routes[j].prefix)
+	})
+	return m
+}
+
+// Instance returns the render.Render of the HTMLRender handling name.
+func (m *MultiHTMLRender) Instance(name string, data any) render.Render {
+	for _, route := range m.routes {
+		if rest, ok := strings.CutPrefix(name, route.prefix); ok {
+			return route.render.Instance(rest, data)
+		}
+	}
+	if m.fallback == nil {
+		return unhandledRender{name: name}
+	}
+	return m.fallback.Instance(name, data)
+}
+
+// unhandledRender fails the render of a template handled by no HTMLRender of a MultiHTMLRender.
+type unhandledRender struct {
+	name string
+}
+
+func (r unhandledRender) Render(http.ResponseWriter) error {
+	return fmt.Errorf(`template "%s" is not handled by any HTMLRender`, r.name)
+}
+
+func (r unhandledRender) WriteContentType(http.ResponseWriter) {}
+
+// Render renders HTML template with data and write to w
+type Render struct {
+	e    *Engine
+	name string
+	data any
+}
+
+// ContextResponseWriter is a ResponseWriter carrying the render context of its request, like the writer set by
+// SetGinRenderContext, since gin renders are only given the writer.
+type ContextResponseWriter interface {
+	http.ResponseWriter
+	RenderContext() context.Context
+}
+
+// GinRenderContext returns the render context of the views rendered by c.HTML, set by SetGinRenderContext, or
+// the RequestContext of the request.
+func GinRenderContext(c *gin.Context) context.Context {
+	if cw, ok := c.Writer.(ContextResponseWriter); ok {
+		return cw.RenderContext()
+	}
+	return RequestContext(c.Request)
+}
+
+// SetGinRenderContext makes ctx the render context of the views rendered by c.HTML and the context of the
+// request, for middlewares adding request scoped values like the security and session packages. They extend
+// GinRenderContext, so they can be chained in any order.
+func SetGinRenderContext(c *gin.Context, ctx context.Context) {
+	c.Request = c.Request.WithContext(ctx)
+	if cw, ok := c.Writer.(*ginContextWriter); ok {
+		cw.ctx = ctx
+		return
+	}
+	c.Writer = &ginContextWriter{ResponseWriter: c.Writer, ctx: ctx}
+}
+
+// ginContextWriter carries the render context of SetGinRenderContext to Render.
+type ginContextWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+}
+
+// RenderContext implements ContextResponseWriter.
+func (w *ginContextWriter) RenderContext() context.Context {
+	return w.ctx
+}
+
+// Render renders HTML template with data and writes to w
+func (r *Render) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	ctx := context.Background()
+	if cw, ok := w.(ContextResponseWriter); ok {
+		ctx = cw.RenderContext()
+	}
+	if r.e.ServerTiming {
+		return r.e.renderTimed(ctx, w, 0, r.name, r.data)
+	}
+	return r.e.execute(ctx, w, r.name, r.data)
+}
+
+// WriteContentType write the content type of the template (HTML or XML) to the response header if not set
+func (r *Render) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = []string{r.e.ContentType(r.name)}
+	}
+}
+
+// Negotiate renders name with the gin HTMLRender for HTML requests, and encodes the same data as JSON for JSON requests.
+func Negotiate(c *gin.Context, code int, name string, data any) {
+	switch c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) {
+	case gin.MIMEJSON:
+		plain, _, _ := unwrapData(context.Background(), data)
+		c.JSON(code, plain)
+	default:
+		c.HTML(code, name, data)
+	}
+}
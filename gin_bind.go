@@ -0,0 +1,126 @@
+//go:build !tinygo
+
+package blade
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationMessages are the messages of the validation errors of BindForm by validate tag, {param} is replaced
+// by the parameter of the tag, like 8 for min=8. Other tags fail with the message of the empty tag.
+var ValidationMessages = map[string]string{
+	"":         "is invalid",
+	"required": "is required",
+	"email":    "must be a valid email address",
+	"url":      "must be a valid URL",
+	"min":      "must be at least {param}",
+	"max":      "must be at most {param}",
+	"len":      "must have a length of {param}",
+	"oneof":    "must be one of {param}",
+	"eqfield":  "must match {param}",
+	"numeric":  "must be a number",
+}
+
+// BindForm binds the query or form parameters of c into the view model obj with c.ShouldBind and validates it.
+// When it fails, it renders view again with data, or obj when data is nil, with the status 422 Unprocessable
+// Entity, and returns false: the view shows the errors by field with errors and refills its fields with old,
+// like after the redirect of a form with flashed errors.
+//
+//	var form SignupForm
+//	if !blade.BindForm(c, &form, "signup", nil) {
+//		return
+//	}
+//
+// The errors are keyed by the form tag of the fields, and errors not tied to a field, like a number that does
+// not parse, by the empty field: {{ (errors).First "" }}.
+func BindForm(c *gin.Context, obj any, view string, data any) bool {
+	err := c.ShouldBind(obj)
+	if err == nil {
+		return true
+	}
+	if data == nil {
+		data = obj
+	}
+	// the form is parsed by the binding, the query too when it only binds the query
+	_ = c.Request.ParseForm()
+	store := &formFlashes{
+		values: map[string][]any{
+			DefaultOldInputFlash: {url.Values(c.Request.Form)},
+			DefaultErrorsFlash:   {bindErrors(obj, err)},
+		},
+	}
+	ctx := GinRenderContext(c)
+	store.next = Flashes(ctx)
+	SetGinRenderContext(c, WithFlashStore(ctx, store))
+	c.HTML(http.StatusUnprocessableEntity, view, data)
+	return false
+}
+
+// formFlashes provides the old input and the errors of a form rendered again by BindForm, and the other flashes
+// from the flash store of the request.
+type formFlashes struct {
+	values map[string][]any
+	next   FlashStore
+}
+
+// Flashes implements FlashStore.
+func (f *formFlashes) Flashes(key string) []any {
+	if values, ok := f.values[key]; ok {
+		return values
+	}
+	if f.next != nil {
+		return f.next.Flashes(key)
+	}
+	return nil
+}
+
+// bindErrors converts the error of binding obj to the errors of the form by field.
+func bindErrors(obj any, err error) FormErrors {
+	errs := FormErrors{}
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		errs[""] = append(errs[""], err.Error())
+		return errs
+	}
+	for _, fe := range fieldErrs {
+		msg, ok := ValidationMessages[fe.Tag()]
+		if !ok {
+			msg = ValidationMessages[""]
+		}
+		field := formFieldName(reflect.TypeOf(obj), fe.StructNamespace())
+		errs[field] = append(errs[field], strings.ReplaceAll(msg, "{param}", fe.Param()))
+	}
+	return errs
+}
+
+// formFieldName returns the form name of the field at namespace in t, like "email" for "SignupForm.Email" with
+// a form:"email" tag, or the name of the field without tag.
+func formFieldName(t reflect.Type, namespace string) string {
+	path := strings.Split(namespace, ".")
+	name := path[len(path)-1]
+	for _, ident := range path[1:] {
+		for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return name
+		}
+		field, ok := t.FieldByName(strings.SplitN(ident, "[", 2)[0])
+		if !ok {
+			return name
+		}
+		name = ident
+		if tag, _, _ := strings.Cut(field.Tag.Get("form"), ","); tag != "" && tag != "-" {
+			name = tag
+		}
+		t = field.Type
+	}
+	return name
+}
@@ -0,0 +1,330 @@
+//go:build !tinygo
+
+package blade
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewHTMLRender(t *testing.T) {
+	engine := NewEngine("dir")
+	renderer := NewHTMLRender(engine)
+	if renderer == nil {
+		t.Fatal("NewHTMLRender returned nil")
+	}
+	if renderer.e != engine {
+		t.Error("Renderer engine mismatch")
+	}
+}
+
+func TestRender_Instance(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"hello.blade": "Hello {{ . }}",
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	renderer := NewHTMLRender(engine)
+	instance := renderer.Instance("hello", "World")
+
+	r, ok := instance.(*Render)
+	if !ok {
+		t.Fatal("Instance did not return *Render type")
+	}
+	if r.name != "hello" {
+		t.Errorf("Render name mismatch: %s", r.name)
+	}
+	if r.data != "World" {
+		t.Errorf("Render data mismatch: %v", r.data)
+	}
+
+	// Test Render Execution
+	w := httptest.NewRecorder()
+	err := instance.Render(w)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if w.Body.String() != "Hello World" {
+		t.Errorf("Render output mismatch. Got: %s", w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type mismatch. Got: %s", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestRender_ServerTiming(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"hello.blade": "Hello {{ . }}",
+	}))
+	engine.ServerTiming = true
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	if err := NewHTMLRender(engine).Instance("hello", "World").Render(w); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if w.Body.String() != "Hello World" {
+		t.Errorf("Render output mismatch. Got: %s", w.Body.String())
+	}
+	if timing := w.Header().Get("Server-Timing"); !strings.HasPrefix(timing, `blade-cache;desc="hit", blade-render;dur=`) {
+		t.Errorf("Server-Timing mismatch. Got: %s", timing)
+	}
+}
+
+func TestRender_WithFuncs(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"func.blade": "{{ upper . }}",
+	})
+	engine := NewEngineFS(mockFS)
+	engine.FuncMap["upper"] = strings.ToUpper
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	renderer := NewHTMLRender(engine)
+
+	funcs := template.FuncMap{
+		"upper": strings.ToUpper,
+	}
+	data := NewDataWithFuncs("test", funcs)
+
+	instance := renderer.Instance("func", data)
+	w := httptest.NewRecorder()
+
+	err := instance.Render(w)
+	if err != nil {
+		t.Fatalf("Refnder with funcs failed: %v", err)
+	}
+	if w.Body.String() != "TEST" {
+		t.Errorf("Expected TEST, got %s", w.Body.String())
+	}
+}
+
+func TestScopedHTMLRender(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"admin.blade": `{{ shared "panel" }} {{ adminURL . }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	scope := engine.Scope(template.FuncMap{
+		"adminURL": func(name string) string { return "/admin/" + name },
+	}).Share("panel", "Admin")
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	if err := NewScopedHTMLRender(scope).Instance("admin", "users").Render(w); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := "Admin /admin/users"; w.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, w.Body.String())
+	}
+}
+
+func TestMultiHTMLRender(t *testing.T) {
+	site := NewEngineFS(createMockFS(map[string]string{
+		"home.blade": "site home",
+	}))
+	admin := NewEngineFS(createMockFS(map[string]string{
+		"home.blade":       "admin home",
+		"users/list.blade": "admin users",
+	}))
+	users := NewEngineFS(createMockFS(map[string]string{
+		"list.blade": "users list",
+	}))
+	for _, engine := range []*Engine{site, admin, users} {
+		if err := engine.Load(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	renderer := NewMultiHTMLRender(NewHTMLRender(site)).
+		Handle("admin/", NewHTMLRender(admin)).
+		Handle("admin/users/", NewHTMLRender(users))
+
+	for name, expected := range map[string]string{
+		"home":             "site home",
+		"admin/home":       "admin home",
+		"admin/users/list": "users list",
+	} {
+		w := httptest.NewRecorder()
+		if err := renderer.Instance(name, nil).Render(w); err != nil {
+			t.Fatalf("Render %s failed: %v", name, err)
+		}
+		if w.Body.String() != expected {
+			t.Errorf("Render %s: expected %s, got %s", name, expected, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	err := NewMultiHTMLRender(nil).Handle("admin/", NewHTMLRender(admin)).Instance("home", nil).Render(w)
+	if err == nil || !strings.Contains(err.Error(), "not handled") {
+		t.Errorf("Expected an unhandled template error, got %v", err)
+	}
+}
+
+func TestRender_TemplateNotFound(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{}))
+	renderer := NewHTMLRender(engine)
+	instance := renderer.Instance("missing", nil)
+
+	if err := instance.Render(httptest.NewRecorder()); err == nil {
+		t.Error("Expected error for missing template, got nil")
+	}
+}
+
+func TestRender_WithContext(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"table.blade": `<a href="{{ queryReplace "sort" "price" "page" nil }}">Price</a> {{ currentURL }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/products?page=2&sort=name", nil)
+	expected := `<a href="/products?sort=price">Price</a> /products?page=2&amp;sort=name`
+
+	var buf strings.Builder
+	if err := engine.RenderContext(RequestContext(req), &buf, "table", nil); err != nil {
+		t.Fatalf("RenderContext failed: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, buf.String())
+	}
+
+	w := httptest.NewRecorder()
+	instance := NewHTMLRender(engine).Instance("table", NewDataWithContext(RequestContext(req), nil))
+	if err := instance.Render(w); err != nil {
+		t.Fatalf("Render with context failed: %v", err)
+	}
+	if w.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, w.Body.String())
+	}
+}
+
+func TestRender_Fragment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"post.blade": `<h1>{{ .Title }}</h1>@defer('comments', placeholder: 'Loading...'){{ range .Comments }}<p>{{ . }}</p>{{ end }}@enddefer`,
+	}))
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.HTMLRender = NewHTMLRender(engine)
+	router.GET("/posts/1", func(c *gin.Context) {
+		c.HTML(http.StatusOK, "post", NewDataWithContext(GinRenderContext(c), gin.H{"Title": "Hello", "Comments": []string{"First"}}))
+	})
+
+	for target, expected := range map[string]string{
+		"/posts/1":                    `<h1>Hello</h1><div hx-get="/posts/1?_fragment=comments" hx-trigger="load" hx-swap="outerHTML">Loading...</div>`,
+		"/posts/1?_fragment=comments": `<p>First</p>`,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Body.String() != expected {
+			t.Errorf("Render mismatch for %s.\nExp: %s\nGot: %s", target, expected, w.Body.String())
+		}
+	}
+}
+
+func TestRender_CompileError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"broken.blade": `{{ if }}`,
+	}))
+	engine.DevMode = true
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.HTMLRender = NewHTMLRender(engine)
+	router.GET("/", func(c *gin.Context) {
+		c.HTML(http.StatusOK, "broken", nil)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "Failed to compile broken") {
+		t.Errorf("Expected the error overlay with the status 500, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"user.blade": `<h1>{{ .Name }}</h1>`,
+	}))
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.HTMLRender = NewHTMLRender(engine)
+	router.GET("/user", func(c *gin.Context) {
+		Negotiate(c, http.StatusOK, "user", gin.H{"Name": "John"})
+	})
+
+	for accept, expected := range map[string]string{"text/html": `<h1>John</h1>`, "application/json": `{"Name":"John"}`} {
+		req := httptest.NewRequest(http.MethodGet, "/user", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Body.String() != expected {
+			t.Errorf("Negotiate mismatch for %s, got %s", accept, w.Body.String())
+		}
+	}
+}
+
+type testSignupForm struct {
+	Email    string `form:"email" binding:"required,email"`
+	Password string `form:"password" binding:"required,min=8"`
+	Age      int    `form:"age"`
+}
+
+func TestBindForm(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockFS := createMockFS(map[string]string{
+		"signup.blade": `<input name="email" value="{{ old "email" }}">{{ range $field, $msgs := errors }}[{{ $field }}: {{ index $msgs 0 }}]{{ end }}{{ .Email }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.HTMLRender = NewHTMLRender(engine)
+	router.POST("/signup", func(c *gin.Context) {
+		var form testSignupForm
+		if !BindForm(c, &form, "signup", nil) {
+			return
+		}
+		c.String(http.StatusOK, "welcome "+form.Email)
+	})
+
+	for _, tc := range []struct {
+		body   string
+		status int
+		want   string
+	}{
+		{"email=ann%40example.com&password=secret123", http.StatusOK, "welcome ann@example.com"},
+		{"email=ann&password=short", http.StatusUnprocessableEntity, `<input name="email" value="ann">[email: must be a valid email address][password: must be at least 8]ann`},
+		{"email=ann%40example.com&password=secret123&age=old", http.StatusUnprocessableEntity, `<input name="email" value="ann@example.com">[: strconv.ParseInt: parsing &#34;old&#34;: invalid syntax]ann@example.com`},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tc.status || w.Body.String() != tc.want {
+			t.Errorf("%s: got %d %q, want %d %q", tc.body, w.Code, w.Body, tc.status, tc.want)
+		}
+	}
+}
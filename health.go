@@ -0,0 +1,196 @@
+package blade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"time"
+)
+
+// errNotLoaded is reported by Ready before the first successful Load.
+var errNotLoaded = errors.New("templates not loaded")
+
+// engineHealth is the outcome of the last Load and the state of the watcher.
+type engineHealth struct {
+	loadErr  error
+	watchErr error
+}
+
+// setWatchError records why the watcher stopped, nil when it runs.
+func (e *Engine) setWatchError(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	h := *e.health.Load()
+	h.watchErr = err
+	e.health.Store(&h)
+}
+
+// setLoadError records the outcome of a Load.
+func (e *Engine) setLoadError(err error) {
+	h := *e.health.Load()
+	h.loadErr = err
+	e.health.Store(&h)
+}
+
+// Ready returns nil when the last Load succeeded without broken views and the watcher started by Watch, if
+// any, is running.
+// When a reload fails the previous templates are still served, Ready lets readiness probes stop routing
+// traffic to the instance until the templates are fixed.
+func (e *Engine) Ready() error {
+	h := e.health.Load()
+	if h.loadErr != nil {
+		return fmt.Errorf("blade: %w", h.loadErr)
+	}
+	if h.watchErr != nil {
+		return fmt.Errorf("blade: watcher stopped: %w", h.watchErr)
+	}
+	// in DevMode, broken views do not fail Load
+	set := e.set.Load()
+	var errs []error
+	for _, name := range sortedKeys(set.templates) {
+		if err := set.templates[name].err; err != nil {
+			errs = append(errs, &CompileError{Entry: name, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("blade: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Watch calls LoadAsync every interval until ctx is done, so modified templates are recompiled without a
+// restart. Load errors are reported by Ready and the previous templates keep being served.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) error {
+	e.setWatchError(nil)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			e.setWatchError(ctx.Err())
+			return ctx.Err()
+		case <-ticker.C:
+			e.LoadAsync()
+		}
+	}
+}
+
+// States of the background loads of LoadAsync.
+const (
+	asyncLoadIdle int32 = iota
+	asyncLoadRunning
+	// asyncLoadPending runs another Load after the running one, for the changes made while it compiles
+	asyncLoadPending
+)
+
+// LoadAsync runs Load in a background goroutine and returns at once, so a request triggering a reload during
+// development renders with the current templates instead of waiting for the compile, and the following
+// requests render with the new ones once they are swapped in. Calls while a Load runs are coalesced into a
+// single Load after it. Load errors are reported by Ready and the previous templates keep being served.
+func (e *Engine) LoadAsync() {
+	for {
+		switch e.asyncLoad.Load() {
+		case asyncLoadIdle:
+			if e.asyncLoad.CompareAndSwap(asyncLoadIdle, asyncLoadRunning) {
+				go e.runAsyncLoads()
+				return
+			}
+		case asyncLoadRunning:
+			if e.asyncLoad.CompareAndSwap(asyncLoadRunning, asyncLoadPending) {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Defaults of Engine.ReloadDebounce and Engine.ReloadMaxDelay.
+const (
+	DefaultReloadDebounce = 100 * time.Millisecond
+	DefaultReloadMaxDelay = 2 * time.Second
+)
+
+// runAsyncLoads runs Load until no LoadAsync call is pending.
+func (e *Engine) runAsyncLoads() {
+	for {
+		e.awaitQuietFiles()
+		// the calls made while waiting are loaded by this Load
+		e.asyncLoad.Store(asyncLoadRunning)
+		_ = e.Load()
+		if e.asyncLoad.CompareAndSwap(asyncLoadRunning, asyncLoadIdle) {
+			return
+		}
+		e.asyncLoad.Store(asyncLoadRunning)
+	}
+}
+
+// awaitQuietFiles waits until the template files have not changed for ReloadDebounce, at most ReloadMaxDelay,
+// so the files changed together are compiled by a single Load. It returns at once when no file changed since
+// the last Load, checked with its manifest without walking the fs when possible, or since the previous wait.
+// It is only called by runAsyncLoads, which never runs concurrently.
+func (e *Engine) awaitQuietFiles() {
+	debounce := e.ReloadDebounce
+	if debounce == 0 {
+		debounce = DefaultReloadDebounce
+	}
+	maxDelay := e.ReloadMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultReloadMaxDelay
+	}
+	if debounce < 0 || e.unchangedSinceLoad() {
+		return
+	}
+
+	snapshot := e.templateModTimes()
+	if snapshot == nil || maps.Equal(snapshot, e.reloadSnapshot) {
+		return
+	}
+	deadline := time.Now().Add(maxDelay)
+	for time.Now().Before(deadline) {
+		time.Sleep(min(debounce, time.Until(deadline)))
+		next := e.templateModTimes()
+		if maps.Equal(next, snapshot) {
+			break
+		}
+		snapshot = next
+	}
+	e.reloadSnapshot = snapshot
+}
+
+// unchangedSinceLoad reports whether the files of the manifest of the last Load are unchanged, false without
+// manifest or when the fs must be refreshed first.
+func (e *Engine) unchangedSinceLoad() bool {
+	if _, ok := e.fs.(RefreshableFS); ok {
+		return false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.manifest != nil && e.manifest.unchanged(e.fs)
+}
+
+// templateModTimes returns the modification times of the template files by path, nil when the fs cannot be
+// walked.
+func (e *Engine) templateModTimes() map[string]time.Time {
+	modTimes := map[string]time.Time{}
+	err := fs.WalkDir(e.fs, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if isTemplate, _ := e.templateFileKind(path); d.IsDir() || !isTemplate {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		modTimes[path] = info.ModTime()
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+	return modTimes
+}
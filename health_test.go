@@ -0,0 +1,166 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReady(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"home.blade": `Hello`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Ready(); err == nil {
+		t.Errorf("expected an error before Load")
+	}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := engine.Ready(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockFS["home.blade"].Data = []byte(`@section('a')`)
+	mockFS["home.blade"].ModTime = time.Now().Add(time.Second)
+	if err := engine.Load(); err == nil {
+		t.Fatalf("expected a Load error")
+	}
+	if err := engine.Ready(); err == nil {
+		t.Errorf("expected Ready to report the failed reload")
+	}
+
+	// the previous templates are still served
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "home", nil); err != nil || buf.String() != "Hello" {
+		t.Errorf("expected the previous template, got %q, %v", buf.String(), err)
+	}
+
+	mockFS["home.blade"].Data = []byte(`Fixed`)
+	mockFS["home.blade"].ModTime = time.Now().Add(2 * time.Second)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := engine.Ready(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"home.blade": `Hello`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- engine.Watch(ctx, time.Millisecond) }()
+
+	var buf bytes.Buffer
+	for i := 0; i < 100; i++ {
+		buf.Reset()
+		if err := engine.Render(&buf, "home", nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := engine.Ready(); err != nil {
+		t.Errorf("unexpected error while watching: %v", err)
+	}
+
+	cancel()
+	<-done
+	if err := engine.Ready(); err == nil {
+		t.Errorf("expected Ready to report the stopped watcher")
+	}
+}
+
+func TestLoadAsync(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"home.blade": `Hello`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	mockFS["home.blade"].Data = []byte(`Bye`)
+	mockFS["home.blade"].ModTime = time.Now().Add(time.Second)
+	var buf bytes.Buffer
+	for i := 0; i < 10; i++ {
+		engine.LoadAsync()
+		buf.Reset()
+		if err := engine.Render(&buf, "home", nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if got := buf.String(); got != "Hello" && got != "Bye" {
+			t.Fatalf("Unexpected output: %s", got)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for buf.String() != "Bye" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Changes not loaded, got %s", buf.String())
+		}
+		time.Sleep(time.Millisecond)
+		buf.Reset()
+		if err := engine.Render(&buf, "home", nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+	}
+	if err := engine.Ready(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadAsyncDebounce(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "home.blade")
+	if err := os.WriteFile(path, []byte(`v0`), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	engine := NewEngine(dir)
+	engine.KeepGenerations = 10
+	engine.ReloadDebounce = 200 * time.Millisecond
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// a burst of saves is compiled by a single Load once the files are quiet
+	modTime := time.Now()
+	for i := 1; i <= 5; i++ {
+		modTime = modTime.Add(time.Second)
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("v%d", i)), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Chtimes failed: %v", err)
+		}
+		engine.LoadAsync()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var buf bytes.Buffer
+	deadline := time.Now().Add(5 * time.Second)
+	for buf.String() != "v5" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Changes not loaded, got %s", buf.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+		buf.Reset()
+		if err := engine.Render(&buf, "home", nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+	}
+	if generations := engine.Generations(); len(generations) != 2 {
+		t.Errorf("Expected a single compile of the burst, got %d generations", len(generations))
+	}
+}
@@ -0,0 +1,103 @@
+package blade
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHoneypotField is the default name prefix of the fields rendered by @honeypot.
+const DefaultHoneypotField = "my_name"
+
+// DefaultHoneypotMinAge is the default time a form must be displayed before it is submitted.
+const DefaultHoneypotMinAge = 2 * time.Second
+
+// DefaultHoneypotMaxAge is the default time after rendering when a form is no longer accepted.
+const DefaultHoneypotMaxAge = 24 * time.Hour
+
+// ErrHoneypot is returned by ValidateHoneypot when a form submission looks automated.
+var ErrHoneypot = errors.New("honeypot: form submission looks like spam")
+
+// honeypot renders the fields of @honeypot: a hidden text field with a random name that people leave empty,
+// and a signed timestamp naming it.
+func (s *renderState) honeypot() (template.HTML, error) {
+	nonce := make([]byte, 6)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	field := template.HTMLEscapeString(s.e.honeypotField())
+	trap := hex.EncodeToString(nonce)
+	payload := strconv.FormatInt(s.e.now().UnixMilli(), 10) + "." + trap
+	token := payload + "." + s.e.honeypotSignature(payload)
+	return template.HTML(`<div style="display:none" aria-hidden="true">` +
+		`<input type="text" name="` + field + "_" + trap + `" value="" autocomplete="off" tabindex="-1">` +
+		`<input type="hidden" name="` + field + `_valid_from" value="` + token + `">` +
+		`</div>`), nil
+}
+
+// ValidateHoneypot returns ErrHoneypot when the submitted form of a view with @honeypot filled the hidden field,
+// lacks the fields, or was submitted sooner than Engine.HoneypotMinAge or later than Engine.HoneypotMaxAge after
+// rendering. Pass the parsed form, like r.PostForm.
+func (e *Engine) ValidateHoneypot(form url.Values) error {
+	field := e.honeypotField()
+	parts := strings.Split(form.Get(field+"_valid_from"), ".")
+	if len(parts) != 3 {
+		return ErrHoneypot
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(e.honeypotSignature(payload))) {
+		return ErrHoneypot
+	}
+	trap, ok := form[field+"_"+parts[1]]
+	if !ok || strings.Join(trap, "") != "" {
+		return ErrHoneypot
+	}
+	renderedAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ErrHoneypot
+	}
+	minAge := e.HoneypotMinAge
+	if minAge == 0 {
+		minAge = DefaultHoneypotMinAge
+	}
+	maxAge := e.HoneypotMaxAge
+	if maxAge == 0 {
+		maxAge = DefaultHoneypotMaxAge
+	}
+	if age := e.now().Sub(time.UnixMilli(renderedAt)); age < minAge || age > maxAge {
+		return ErrHoneypot
+	}
+	return nil
+}
+
+func (e *Engine) honeypotField() string {
+	if e.HoneypotField == "" {
+		return DefaultHoneypotField
+	}
+	return e.HoneypotField
+}
+
+func (e *Engine) honeypotSignature(payload string) string {
+	key := e.HoneypotKey
+	if len(key) == 0 {
+		key = e.honeypotKey
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// newHoneypotKey returns the random key signing the @honeypot timestamps when Engine.HoneypotKey is not set.
+func newHoneypotKey() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+}
@@ -0,0 +1,93 @@
+package blade
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestHoneypot(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"form.blade": `<form method="post">@honeypot<input name="email"></form>`,
+	})
+	engine := NewEngineFS(mockFS)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	render := func() url.Values {
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, "form", nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		form := url.Values{"email": {"jane@example.com"}}
+		for _, m := range regexp.MustCompile(`name="(my_name_\w+)" value="([^"]*)"`).FindAllStringSubmatch(buf.String(), -1) {
+			form.Set(m[1], m[2])
+		}
+		if len(form) != 3 {
+			t.Fatalf("Expected the honeypot fields, got %s", buf.String())
+		}
+		return form
+	}
+
+	form := render()
+	if err := engine.ValidateHoneypot(form); !errors.Is(err, ErrHoneypot) {
+		t.Errorf("Expected ErrHoneypot for a form submitted too fast, got %v", err)
+	}
+	now = now.Add(DefaultHoneypotMinAge)
+	if err := engine.ValidateHoneypot(form); err != nil {
+		t.Errorf("Expected a valid form, got %v", err)
+	}
+	now = now.Add(DefaultHoneypotMaxAge)
+	if err := engine.ValidateHoneypot(form); !errors.Is(err, ErrHoneypot) {
+		t.Errorf("Expected ErrHoneypot for an expired form, got %v", err)
+	}
+	engine.HoneypotMaxAge = 48 * time.Hour
+	if err := engine.ValidateHoneypot(form); err != nil {
+		t.Errorf("Expected a valid form with a longer HoneypotMaxAge, got %v", err)
+	}
+
+	if other := render(); other.Get("my_name_valid_from") == form.Get("my_name_valid_from") {
+		t.Error("Expected a random field for each render")
+	}
+
+	filled := url.Values{}
+	for name, values := range form {
+		filled[name] = values
+		if name != "email" && name != "my_name_valid_from" {
+			filled[name] = []string{"bot"}
+		}
+	}
+	if err := engine.ValidateHoneypot(filled); !errors.Is(err, ErrHoneypot) {
+		t.Errorf("Expected ErrHoneypot for a filled honeypot, got %v", err)
+	}
+
+	if err := engine.ValidateHoneypot(url.Values{"email": {"jane@example.com"}}); !errors.Is(err, ErrHoneypot) {
+		t.Errorf("Expected ErrHoneypot without honeypot fields, got %v", err)
+	}
+
+	forged := url.Values{}
+	for name, values := range form {
+		forged[name] = values
+	}
+	forged.Set("my_name_valid_from", forged.Get("my_name_valid_from")+"x")
+	if err := engine.ValidateHoneypot(forged); !errors.Is(err, ErrHoneypot) {
+		t.Errorf("Expected ErrHoneypot for a forged timestamp, got %v", err)
+	}
+
+	other := NewEngineFS(mockFS)
+	if err := other.ValidateHoneypot(form); !errors.Is(err, ErrHoneypot) {
+		t.Errorf("Expected ErrHoneypot for a form signed by another engine, got %v", err)
+	}
+	other.HoneypotKey, engine.HoneypotKey = []byte("shared"), []byte("shared")
+	other.now = func() time.Time { return now.Add(time.Minute) }
+	shared := render()
+	if err := other.ValidateHoneypot(shared); err != nil {
+		t.Errorf("Expected a form signed with a shared key to be valid, got %v", err)
+	}
+}
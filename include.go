@@ -0,0 +1,203 @@
+package blade
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template/parse"
+)
+
+// includeTemplate returns the template call rendering the partial with the data of pipeline, and registers
+// the include. Memoized includes render once per distinct data within a render.
+func (p *ParsedFile) includeTemplate(partialName string, pipeline string, memo bool) string {
+	p.Includes[partialName] = struct{}{}
+	if memo && partialName != p.Name {
+		return fmt.Sprintf(`{{ __memo "%s%s" (%s) }}`, partialNamePrefix, partialName, pipeline)
+	}
+	return fmt.Sprintf(`{{ template "%s%s" %s }}`, partialNamePrefix, partialName, pipeline)
+}
+
+// includeCycles finds the partials including themselves, directly or through other partials, with the
+// strongly connected components of the include graph. Their definitions are guarded with a depth limit.
+type includeCycles struct {
+	files   map[string]*ParsedFile
+	index   map[string]int
+	low     map[string]int
+	stack   []string
+	onStack map[string]bool
+	cyclic  map[string]struct{}
+}
+
+// has reports whether the partial name of files is on an include cycle.
+func (c *includeCycles) has(files map[string]*ParsedFile, name string) bool {
+	if c.index == nil {
+		c.files = files
+		c.index, c.low, c.onStack, c.cyclic = map[string]int{}, map[string]int{}, map[string]bool{}, map[string]struct{}{}
+	}
+	if _, ok := c.index[name]; !ok {
+		c.visit(name)
+	}
+	_, ok := c.cyclic[name]
+	return ok
+}
+
+// visit walks the partials included by name, marking the components with more than one partial, or with a
+// partial including itself, as cyclic.
+func (c *includeCycles) visit(name string) {
+	c.index[name] = len(c.index)
+	c.low[name] = c.index[name]
+	c.stack = append(c.stack, name)
+	c.onStack[name] = true
+	f, found := lookupFile(c.files, name)
+	if found {
+		for dep := range f.Includes {
+			if _, ok := c.index[dep]; !ok {
+				c.visit(dep)
+				c.low[name] = min(c.low[name], c.low[dep])
+			} else if c.onStack[dep] {
+				c.low[name] = min(c.low[name], c.index[dep])
+			}
+		}
+	}
+	if c.low[name] != c.index[name] {
+		return
+	}
+	i := len(c.stack) - 1
+	for c.stack[i] != name {
+		i--
+	}
+	component := c.stack[i:]
+	c.stack = c.stack[:i]
+	self := false
+	if found {
+		_, self = f.Includes[name]
+	}
+	for _, partialName := range component {
+		c.onStack[partialName] = false
+		if len(component) > 1 || self {
+			c.cyclic[partialName] = struct{}{}
+		}
+	}
+}
+
+// parseIncludeDataDirective converts @includeData to an include receiving only the data passed explicitly:
+// @includeData('card', title: .Title, user: .User) => {{ template "__partial_card" (__includeData "title" (.Title) "user" (.User)) }}
+// @includeData('card', .Card) passes .Card, and @includeData('card') an empty map instead of the data of the view.
+func (p *ParsedFile) parseIncludeDataDirective(args []string) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	partialName, ok := parseQuotedDirectiveName(args[0])
+	if !ok {
+		return "", false
+	}
+	if len(args) == 2 {
+		if _, _, named := parseNamedDirectiveArg(args[1]); !named && strings.TrimSpace(args[1]) != "" {
+			return p.includeTemplate(partialName, strings.TrimSpace(args[1]), false), true
+		}
+	}
+	var data strings.Builder
+	data.WriteString("(__includeData")
+	for _, arg := range args[1:] {
+		key, value, ok := parseNamedDirectiveArg(arg)
+		if !ok || !isIdentifier(key) {
+			return "", false
+		}
+		fmt.Fprintf(&data, " %q (%s)", key, value)
+	}
+	data.WriteString(")")
+	return p.includeTemplate(partialName, data.String(), false), true
+}
+
+// includeData returns the data of an @includeData partial from key and value pairs.
+func includeData(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("includeData: expected key value pairs")
+	}
+	data := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		data[fmt.Sprint(pairs[i])] = pairs[i+1]
+	}
+	return data, nil
+}
+
+// foldStaticIncludes replaces the calls of partials rendering only text, like a footer without actions, by
+// their text, so rendering them executes no template call. A partial including only static partials becomes
+// static once they are folded. Only the calls passing the dot, $ or no data are folded: evaluating other data
+// may call funcs or methods, and fail the render.
+func foldStaticIncludes(t templateSet) {
+	trees := map[string]*parse.Tree{}
+	for _, tree := range t.trees() {
+		trees[tree.Name] = tree
+	}
+	for folded := true; folded; {
+		folded = false
+		for _, tree := range trees {
+			walkTree(tree.Root, func(node parse.Node) bool {
+				list, ok := node.(*parse.ListNode)
+				if !ok || list == nil {
+					return true
+				}
+				nodes := list.Nodes[:0:0]
+				for _, child := range list.Nodes {
+					call, ok := child.(*parse.TemplateNode)
+					if !ok || !strings.HasPrefix(call.Name, partialNamePrefix) || !plainData(call.Pipe) {
+						nodes = append(nodes, child)
+						continue
+					}
+					text, static := staticText(trees[call.Name])
+					if !static {
+						nodes = append(nodes, child)
+						continue
+					}
+					if text != nil {
+						nodes = append(nodes, text)
+					}
+					folded = true
+				}
+				list.Nodes = nodes
+				return true
+			})
+		}
+	}
+}
+
+// staticText reports whether tree renders only text, and returns the text, nil when it renders nothing.
+func staticText(tree *parse.Tree) (*parse.TextNode, bool) {
+	if tree == nil {
+		return nil, false
+	}
+	if tree.Root == nil || len(tree.Root.Nodes) == 0 {
+		return nil, true
+	}
+	var text []byte
+	for _, node := range tree.Root.Nodes {
+		textNode, ok := node.(*parse.TextNode)
+		if !ok {
+			return nil, false
+		}
+		text = append(text, textNode.Text...)
+	}
+	// the copy keeps the position of the first node in the tree of the partial, for error contexts
+	textNode := tree.Root.Nodes[0].Copy().(*parse.TextNode)
+	textNode.Text = text
+	return textNode, true
+}
+
+// plainData reports whether the data of a template call is absent, the dot or $, which evaluate to nothing
+// else than the data already at hand.
+func plainData(pipe *parse.PipeNode) bool {
+	if pipe == nil {
+		return true
+	}
+	if len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.DotNode:
+		return true
+	case *parse.VariableNode:
+		return len(arg.Ident) == 1 && arg.Ident[0] == "$"
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package blade
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"text/template/parse"
+)
+
+func TestIncludeData(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"card.blade":  `[{{ .title }}|{{ .Name }}]`,
+		"badge.blade": `({{ . }})`,
+		"page.blade":  `@includeData('card', title: .Title | upper)@includeData('badge', .Status)@includeData('card')@include('card')`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.FuncMap["upper"] = strings.ToUpper
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	data := map[string]any{"Title": "hello", "Name": "page", "Status": "new", "title": "lower"}
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `[HELLO|](new)[|][lower|page]`; buf.String() != expected {
+		t.Errorf("Include data mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
+
+func TestIsolateIncludes(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"card.blade": `[{{ .Name }}]`,
+		"page.blade": `@include('card')@include('card', .)`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.IsolateIncludes = true
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", map[string]any{"Name": "page"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `[][page]`; buf.String() != expected {
+		t.Errorf("Isolated include mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
+
+func TestFoldStaticIncludes(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"footer.blade":  `<footer>&copy; Blade</footer>`,
+		"links.blade":   `<nav>@include('footer')</nav>`,
+		"user.blade":    `<b>{{ .Name }}</b>`,
+		"page.blade":    `@include('links')@include('user')@include('footer', .Name | upper)`,
+		"billing.blade": `@include('footer', .Account.Balance)`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.FuncMap["upper"] = strings.ToUpper
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var calls []string
+	for _, tree := range engine.set.Load().templates["page"].proto.trees() {
+		if tree.Name != "page" {
+			continue
+		}
+		walkTree(tree.Root, func(node parse.Node) bool {
+			if call, ok := node.(*parse.TemplateNode); ok {
+				calls = append(calls, call.Name)
+			}
+			return true
+		})
+	}
+	if expected := []string{"__partial_user", "__partial_footer"}; !slices.Equal(calls, expected) {
+		t.Errorf("Expected the static includes to be folded, got calls %v", calls)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", map[string]any{"Name": "<Ann>"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `<nav><footer>&copy; Blade</footer></nav><b>&lt;Ann&gt;</b><footer>&copy; Blade</footer>`; buf.String() != expected {
+		t.Errorf("Output mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	// the data of calls not folded is evaluated, failing the render
+	if err := engine.Render(&bytes.Buffer{}, "billing", map[string]any{"Account": failingAccount{}}); err == nil ||
+		!strings.Contains(err.Error(), "balance unavailable") {
+		t.Errorf("Expected the error of the data of the include, got %v", err)
+	}
+
+	// the profile reports the folded partials
+	profile, err := engine.Profile("page", map[string]any{"Name": "Ann"})
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	if !strings.Contains(profile.String(), "partial links") || !strings.Contains(profile.String(), "partial footer") {
+		t.Errorf("Expected the folded partials in the profile, got %s", profile)
+	}
+}
+
+type failingAccount struct{}
+
+func (failingAccount) Balance() (int, error) {
+	return 0, errors.New("balance unavailable")
+}
+
+func TestMutualRecursiveInclude(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"page.blade": `@include("a", .)`,
+		"a.blade":    `a{{ if .Next }}@include("b", .Next){{ end }}`,
+		"b.blade":    `b{{ if .Next }}@include("a", .Next){{ end }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	type node struct{ Next *node }
+	data := &node{Next: &node{Next: &node{}}}
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if buf.String() != "aba" {
+		t.Errorf("Expected aba, got %q", buf.String())
+	}
+
+	engine.MaxIncludeDepth = 1
+	if err := engine.Render(&bytes.Buffer{}, "page", data); err == nil || !strings.Contains(err.Error(), "maximum include depth") {
+		t.Errorf("Expected maximum include depth error, got %v", err)
+	}
+}
+
+func TestIncludeCyclesMissingPartial(t *testing.T) {
+	files := map[string]*ParsedFile{
+		"page": {Name: "page", Includes: map[string]struct{}{"missing": {}}},
+	}
+	var cycles includeCycles
+	if cycles.has(files, "page") || cycles.has(files, "missing") {
+		t.Error("Expected no include cycle through a missing partial")
+	}
+}
@@ -0,0 +1,54 @@
+package blade
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// islandLoads are the hydration strategies of @island.
+var islandLoads = map[string]struct{}{"load": {}, "idle": {}, "visible": {}}
+
+// parseIslandDirective converts @island to the server-side render of a partial wrapped in a marker element with
+// its props, so a frontend runtime hydrates only the islands of a page:
+// @island('cart-widget', .Cart) =>
+// <div data-island="cart-widget" style="display:contents"><script type="application/json">{{ .Cart }}</script>
+// <div data-island-root>{{ template "__partial_cart-widget" (.Cart) }}</div></div>
+// A trailing load: 'visible' or 'idle' argument is rendered as data-island-load for the runtime. The partial of
+// an island without props receives an empty map and its props are {}.
+func (p *ParsedFile) parseIslandDirective(args []string) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	islandName, ok := parseQuotedDirectiveName(args[0])
+	if !ok {
+		return "", false
+	}
+	args = args[1:]
+	load := ""
+	if len(args) > 0 {
+		if argName, value, named := parseNamedDirectiveArg(args[len(args)-1]); named {
+			load, ok = unquoteDirectiveString(value)
+			if _, known := islandLoads[load]; argName != "load" || !ok || !known {
+				return "", false
+			}
+			args = args[:len(args)-1]
+		}
+	}
+	if len(args) > 1 {
+		return "", false
+	}
+	props := "(__includeData)"
+	if len(args) == 1 {
+		props = "(" + args[0] + ")"
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, `<div data-island="%s"`, template.HTMLEscapeString(islandName))
+	if load != "" {
+		fmt.Fprintf(&out, ` data-island-load="%s"`, load)
+	}
+	fmt.Fprintf(&out, ` style="display:contents"><script type="application/json">{{ %s }}</script>`, props)
+	fmt.Fprintf(&out, `<div data-island-root>%s</div></div>`, p.includeTemplate(islandName, props, false))
+	return out.String(), true
+}
@@ -0,0 +1,39 @@
+package blade
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestIsland(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"cart-widget.blade": `<span>{{ .Count }} items</span>`,
+		"clock.blade":       `<time>{{ len . }}</time>`,
+		"page.blade":        `@island('cart-widget', .Cart, load: 'visible')|@island('clock')`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{"Cart": map[string]any{"Count": 2, "Note": "</script>"}}
+	if err := engine.Render(&buf, "page", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<div data-island="cart-widget" data-island-load="visible" style="display:contents">` +
+		`<script type="application/json">{"Count":2,"Note":"\u003c/script\u003e"}</script>` +
+		`<div data-island-root><span>2 items</span></div></div>|` +
+		`<div data-island="clock" style="display:contents"><script type="application/json">{}</script>` +
+		`<div data-island-root><time>0</time></div></div>`
+	if buf.String() != expected {
+		t.Errorf("Island mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	for _, content := range []string{`@island('clock', load: 'later')`, `@island('clock', .A, .B)`} {
+		file, err := engine.Parse("broken", content)
+		if err != nil || len(file.Includes) != 0 {
+			t.Errorf("Expected %s to be left as text, got %v", content, err)
+		}
+	}
+}
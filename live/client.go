@@ -0,0 +1,89 @@
+package live
+
+import (
+	"html/template"
+)
+
+// Script is the browser side of live components. It connects the elements with a data-live attribute holding
+// the URL of a Handler, morphs their content with the rendered HTML and sends the events of their
+// data-live-click, data-live-submit and data-live-change elements. The data-live-value-* attributes of a
+// clicked element are the payload of its event: data-live-value-id="3" sends {"id": "3"}.
+const Script = `(function () {
+  function morph(from, to) {
+    for (const a of Array.from(from.attributes)) if (!to.hasAttribute(a.name)) from.removeAttribute(a.name);
+    for (const a of Array.from(to.attributes)) if (from.getAttribute(a.name) !== a.value) from.setAttribute(a.name, a.value);
+    if ('value' in from && from !== document.activeElement) from.value = to.value;
+    morphChildren(from, to);
+  }
+  function morphChildren(from, to) {
+    const next = Array.from(to.childNodes);
+    next.forEach(function (node, i) {
+      const current = from.childNodes[i];
+      if (!current) from.appendChild(node);
+      else if (current.nodeType !== node.nodeType || current.nodeName !== node.nodeName) from.replaceChild(node, current);
+      else if (node.nodeType === 1) morph(current, node);
+      else if (current.nodeValue !== node.nodeValue) current.nodeValue = node.nodeValue;
+    });
+    while (from.childNodes.length > next.length) from.removeChild(from.lastChild);
+  }
+  function values(el) {
+    const payload = {};
+    for (const key in el.dataset) {
+      if (key.indexOf('liveValue') === 0 && key.length > 9) payload[key[9].toLowerCase() + key.slice(10)] = el.dataset[key];
+    }
+    return payload;
+  }
+  function mount(el) {
+    const url = new URL(el.dataset.live, location.href);
+    url.protocol = url.protocol === 'https:' ? 'wss:' : 'ws:';
+    const ws = new WebSocket(url);
+    let html = '';
+    ws.onmessage = function (e) {
+      const m = JSON.parse(e.data);
+      if (m.type === 'error') {
+        el.dispatchEvent(new CustomEvent('live:error', { detail: m.error }));
+        return;
+      }
+      html = m.type === 'patch' ? html.slice(0, m.start || 0) + (m.html || '') + html.slice(m.end || 0) : m.html || '';
+      const to = document.createElement(el.tagName);
+      to.innerHTML = html;
+      morphChildren(el, to);
+    };
+    function send(event, payload) {
+      if (ws.readyState === WebSocket.OPEN) ws.send(JSON.stringify({ event: event, payload: payload }));
+    }
+    el.addEventListener('click', function (e) {
+      const t = e.target.closest('[data-live-click]');
+      if (t && el.contains(t)) {
+        e.preventDefault();
+        send(t.dataset.liveClick, values(t));
+      }
+    });
+    el.addEventListener('submit', function (e) {
+      const t = e.target.closest('[data-live-submit]');
+      if (t && el.contains(t)) {
+        e.preventDefault();
+        send(t.dataset.liveSubmit, Object.fromEntries(new FormData(t)));
+      }
+    });
+    el.addEventListener('change', function (e) {
+      const t = e.target.closest('[data-live-change]');
+      if (t && el.contains(t)) send(t.dataset.liveChange, t.form ? Object.fromEntries(new FormData(t.form)) : { [t.name]: t.value });
+    });
+  }
+  document.querySelectorAll('[data-live]').forEach(mount);
+})();
+`
+
+// Funcs returns the liveScript helper, rendering Script in a script element, and the liveComponent helper,
+// rendering the element of a component served at a URL: {{ liveComponent "/live/counter?start=5" }}.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"liveScript": func() template.HTML {
+			return template.HTML("<script>" + Script + "</script>")
+		},
+		"liveComponent": func(url string) template.HTML {
+			return template.HTML(`<div data-live="` + template.HTMLEscapeString(url) + `"></div>`)
+		},
+	}
+}
@@ -0,0 +1,199 @@
+// Package live mounts blade components over a WebSocket: a view of the engine renders the state of a Go
+// component, events of the browser update the state and the re-rendered HTML is sent back as a patch.
+package live
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/dangdungcntt/go-blade"
+	"golang.org/x/net/websocket"
+)
+
+// Component is the state of a live component, a new one is created for every connection.
+type Component interface {
+	// Mount initializes the state from the query of the WebSocket URL. The socket updates the component from
+	// other goroutines, like a ticker or a subscription, until the context is done.
+	Mount(ctx context.Context, socket *Socket, params url.Values) error
+	// HandleEvent updates the state for an event of the browser, like "increment" for a click on an element
+	// with data-live-click="increment". The payload holds the form fields of submit and change events.
+	HandleEvent(ctx context.Context, event string, payload map[string]any) error
+}
+
+// Handler serves the WebSocket of a live component.
+type Handler struct {
+	// Engine renders the view of the component
+	Engine *blade.Engine
+	// View is rendered with the component as data
+	View string
+	// New creates the component of a connection
+	New func() Component
+	// Origins are the origins allowed to connect besides the origin of the request host, like
+	// "https://app.example.com". Other origins are refused to prevent cross-site WebSocket hijacking.
+	Origins []string
+	// OnError is called with the errors of connections, like an event failing or a view failing to render.
+	// The connection is closed after the error.
+	OnError func(r *http.Request, err error)
+}
+
+// Message is a message sent to the browser.
+type Message struct {
+	// Type is "render" for the whole HTML of the component, "patch" for a part of it or "error"
+	Type string `json:"type"`
+	// HTML replaces the HTML of the component for "render", or the patched part for "patch"
+	HTML string `json:"html,omitempty"`
+	// Start and End are the UTF-16 offsets of the part of the previous HTML replaced by a patch
+	Start int `json:"start,omitempty"`
+	End   int `json:"end,omitempty"`
+	// Error is the message of "error"
+	Error string `json:"error,omitempty"`
+}
+
+// event is a message of the browser.
+type event struct {
+	Event   string         `json:"event"`
+	Payload map[string]any `json:"payload"`
+}
+
+// ServeHTTP upgrades the request to a WebSocket and runs the component until the browser disconnects.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	websocket.Server{
+		Handshake: h.checkOrigin,
+		Handler: func(conn *websocket.Conn) {
+			if err := h.serve(r, conn); err != nil && h.OnError != nil {
+				h.OnError(r, err)
+			}
+		},
+	}.ServeHTTP(w, r)
+}
+
+// checkOrigin refuses the connections of browsers on other origins than the host of the request and Origins.
+func (h *Handler) checkOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(config, r)
+	if err != nil || origin == nil {
+		return errors.New("live: missing origin")
+	}
+	if origin.Host == r.Host || slices.Contains(h.Origins, origin.Scheme+"://"+origin.Host) {
+		return nil
+	}
+	return fmt.Errorf("live: origin %s is not allowed", origin)
+}
+
+func (h *Handler) serve(r *http.Request, conn *websocket.Conn) error {
+	ctx, cancel := context.WithCancel(blade.RequestContext(r))
+	defer cancel()
+	socket := &Socket{h: h, ctx: ctx, conn: conn, component: h.New()}
+
+	socket.mu.Lock()
+	err := socket.component.Mount(ctx, socket, r.URL.Query())
+	if err == nil {
+		err = socket.render()
+	}
+	socket.mu.Unlock()
+	if err != nil {
+		socket.fail(err)
+		return err
+	}
+
+	for {
+		var e event
+		if err := websocket.JSON.Receive(conn, &e); err != nil {
+			// the browser disconnected
+			return nil
+		}
+		if err := socket.Update(func() error { return socket.component.HandleEvent(ctx, e.Event, e.Payload) }); err != nil {
+			return err
+		}
+	}
+}
+
+// Socket is the connection of a mounted component.
+type Socket struct {
+	h         *Handler
+	ctx       context.Context
+	conn      *websocket.Conn
+	component Component
+	mu        sync.Mutex
+	html      []uint16
+	rendered  bool
+}
+
+// Update runs fn, which changes the state of the component, and sends the re-rendered HTML to the browser.
+// Updates are serialized with the events of the browser, so Mount and HandleEvent must not call it. A failing update sends the error to the browser and
+// closes the connection.
+func (s *Socket) Update(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil {
+		return s.ctx.Err()
+	}
+	err := fn()
+	if err == nil {
+		err = s.render()
+	}
+	if err != nil {
+		s.fail(err)
+	}
+	return err
+}
+
+// render renders the view and sends what changed since the previous render.
+func (s *Socket) render() error {
+	var buf bytes.Buffer
+	if err := s.h.Engine.RenderContext(s.ctx, &buf, s.h.View, s.component); err != nil {
+		return err
+	}
+	html := utf16.Encode([]rune(buf.String()))
+	message, changed := Message{Type: "render", HTML: buf.String()}, true
+	if s.rendered {
+		message, changed = diff(s.html, html)
+	}
+	s.html, s.rendered = html, true
+	if !changed {
+		return nil
+	}
+	return websocket.JSON.Send(s.conn, message)
+}
+
+// fail sends the error to the browser and closes the connection.
+func (s *Socket) fail(err error) {
+	_ = websocket.JSON.Send(s.conn, Message{Type: "error", Error: err.Error()})
+	_ = s.conn.Close()
+}
+
+// diff returns the message turning the previous HTML into next: a patch of the part between their common prefix
+// and suffix, or the whole HTML when the patch would not be smaller. HTML is compared in UTF-16 code units,
+// the unit of the offsets of JavaScript strings.
+func diff(previous []uint16, next []uint16) (Message, bool) {
+	prefix := 0
+	for prefix < len(previous) && prefix < len(next) && previous[prefix] == next[prefix] {
+		prefix++
+	}
+	if prefix == len(previous) && prefix == len(next) {
+		return Message{}, false
+	}
+	suffix := 0
+	for suffix < len(previous)-prefix && suffix < len(next)-prefix &&
+		previous[len(previous)-1-suffix] == next[len(next)-1-suffix] {
+		suffix++
+	}
+	// keep surrogate pairs whole
+	if prefix > 0 && utf16.IsSurrogate(rune(next[prefix-1])) && next[prefix-1] < 0xDC00 {
+		prefix--
+	}
+	if suffix > 0 && utf16.IsSurrogate(rune(next[len(next)-suffix])) && next[len(next)-suffix] >= 0xDC00 {
+		suffix--
+	}
+	part := next[prefix : len(next)-suffix]
+	if len(part)*2 > len(next) {
+		return Message{Type: "render", HTML: string(utf16.Decode(next))}, true
+	}
+	return Message{Type: "patch", HTML: string(utf16.Decode(part)), Start: prefix, End: len(previous) - suffix}, true
+}
@@ -0,0 +1,95 @@
+package live
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"unicode/utf16"
+
+	"github.com/dangdungcntt/go-blade"
+	"golang.org/x/net/websocket"
+)
+
+type counter struct {
+	Count int
+	Label string
+}
+
+func (c *counter) Mount(_ context.Context, _ *Socket, params url.Values) error {
+	c.Count, _ = strconv.Atoi(params.Get("start"))
+	c.Label = "clicks"
+	return nil
+}
+
+func (c *counter) HandleEvent(_ context.Context, event string, payload map[string]any) error {
+	switch event {
+	case "increment":
+		c.Count++
+	case "rename":
+		c.Label, _ = payload["label"].(string)
+	}
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	engine := blade.NewEngineFS(fstest.MapFS{
+		"counter.blade": {Data: []byte(`<p>{{ .Count }} {{ .Label }}</p><button data-live-click="increment">+</button>`)},
+	})
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	server := httptest.NewServer(&Handler{Engine: engine, View: "counter", New: func() Component { return &counter{} }})
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/?start=41"
+	conn, err := websocket.Dial(wsURL, "", server.URL)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	receive := func() Message {
+		var m Message
+		if err := websocket.JSON.Receive(conn, &m); err != nil {
+			t.Fatalf("Receive failed: %v", err)
+		}
+		return m
+	}
+	if m := receive(); m.Type != "render" || m.HTML != `<p>41 clicks</p><button data-live-click="increment">+</button>` {
+		t.Errorf("Unexpected mount message: %+v", m)
+	}
+
+	if err := websocket.JSON.Send(conn, map[string]any{"event": "increment"}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if m := receive(); m != (Message{Type: "patch", HTML: "2", Start: 4, End: 5}) {
+		t.Errorf("Unexpected patch: %+v", m)
+	}
+	if err := websocket.JSON.Send(conn, map[string]any{"event": "rename", "payload": map[string]any{"label": "taps"}}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if m := receive(); m != (Message{Type: "patch", HTML: "tap", Start: 6, End: 11}) {
+		t.Errorf("Unexpected patch: %+v", m)
+	}
+
+	if _, err := websocket.Dial(wsURL, "", "https://evil.example.com"); err == nil {
+		t.Error("Expected a connection from another origin to be refused")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	encode := func(s string) []uint16 { return utf16.Encode([]rune(s)) }
+	if _, changed := diff(encode("same"), encode("same")); changed {
+		t.Error("Expected no message for the same HTML")
+	}
+	if m, _ := diff(encode("<b>a😀</b>"), encode("<b>a😃</b>")); m != (Message{Type: "patch", HTML: "😃", Start: 4, End: 6}) {
+		t.Errorf("Expected the patch to keep surrogate pairs whole, got %+v", m)
+	}
+	if m, _ := diff(encode("<p>a</p>"), encode("<ul>b</ul>")); m.Type != "render" || m.HTML != "<ul>b</ul>" {
+		t.Errorf("Expected a render when the patch is not smaller, got %+v", m)
+	}
+}
@@ -0,0 +1,45 @@
+package blade
+
+import (
+	"io/fs"
+	"time"
+)
+
+// loadManifest records the modification times seen by the last successful Load, so a Load without changes
+// stats the known files and directories instead of walking the fs.
+type loadManifest struct {
+	files map[string]time.Time
+	// dirs change modification time when a file is added, removed or renamed
+	dirs map[string]time.Time
+}
+
+func newLoadManifest() *loadManifest {
+	return &loadManifest{files: map[string]time.Time{}, dirs: map[string]time.Time{}}
+}
+
+// add records the modification time of path, it returns false when the fs does not provide a usable
+// directory modification time, like embed.FS and in-memory sources, which cannot use the fast path.
+func (m *loadManifest) add(path string, info fs.FileInfo) bool {
+	if info.IsDir() {
+		if info.ModTime().IsZero() {
+			return false
+		}
+		m.dirs[path] = info.ModTime()
+		return true
+	}
+	m.files[path] = info.ModTime()
+	return true
+}
+
+// unchanged reports whether no file or directory of the manifest was modified, added or removed in fsys.
+func (m *loadManifest) unchanged(fsys fs.FS) bool {
+	for _, paths := range []map[string]time.Time{m.dirs, m.files} {
+		for path, modTime := range paths {
+			info, err := fs.Stat(fsys, path)
+			if err != nil || !info.ModTime().Equal(modTime) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package blade
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readDirCounter counts the directories read from an fs.
+type readDirCounter struct {
+	fs.FS
+	reads int
+}
+
+func (c *readDirCounter) ReadDir(name string) ([]fs.DirEntry, error) {
+	c.reads++
+	return fs.ReadDir(c.FS, name)
+}
+
+func TestLoadFastPath(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string, modTime time.Time) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-time.Hour)
+	write("pages/home.blade", `Home`, past)
+	counter := &readDirCounter{FS: os.DirFS(dir)}
+	engine := NewEngineFS(counter)
+
+	render := func(entry string) string {
+		t.Helper()
+		if err := engine.Load(); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, entry, nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	render("pages/home")
+	walked := counter.reads
+	if got := render("pages/home"); got != "Home" || counter.reads != walked {
+		t.Errorf("expected a Load without changes not to read directories, got %d reads", counter.reads-walked)
+	}
+
+	write("pages/home.blade", `Edited`, time.Now().Add(time.Second))
+	if got := render("pages/home"); got != "Edited" {
+		t.Errorf("expected the edited file, got %q", got)
+	}
+
+	write("pages/about.blade", `About`, past)
+	if got := render("pages/about"); got != "About" {
+		t.Errorf("expected the added file, got %q", got)
+	}
+}
+
+func TestAwaitQuietFilesFastPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "home.blade")
+	if err := os.WriteFile(path, []byte(`Home`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	counter := &readDirCounter{FS: os.DirFS(dir)}
+	engine := NewEngineFS(counter)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// a Watch tick without changes does not walk the fs
+	reads := counter.reads
+	engine.awaitQuietFiles()
+	if counter.reads != reads {
+		t.Errorf("expected no directory reads without changes, got %d", counter.reads-reads)
+	}
+
+	modTime := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	engine.awaitQuietFiles()
+	if counter.reads == reads {
+		t.Error("expected the changed files to be snapshot")
+	}
+}
@@ -0,0 +1,148 @@
+package loader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// DefaultMaxSize is the default limit of the total size of the templates fetched by the HTTP and S3 sources, and
+// of the uncompressed templates of their archives.
+const DefaultMaxSize = 64 << 20
+
+// ErrTooLarge is returned when the templates of a source exceed its maximum size.
+var ErrTooLarge = errors.New("templates exceed the maximum size")
+
+// archiveFormat detects the archive format from a file name or URL path: zip, tar or tar.gz.
+func archiveFormat(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(name, ".tar"):
+		return "tar"
+	}
+	return ""
+}
+
+// readArchive returns the regular files of a zip, tar or tar.gz archive by path, failing with ErrTooLarge when
+// their uncompressed size exceeds remaining, see readAtMost.
+func readArchive(data []byte, format string, remaining *int64) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	switch format {
+	case "zip":
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			content, err := readAtMost(rc, remaining)
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+			if err := addArchiveFile(files, f.Name, content); err != nil {
+				return nil, err
+			}
+		}
+	case "tar", "tar.gz":
+		var r io.Reader = bytes.NewReader(data)
+		if format == "tar.gz" {
+			gz, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			defer gz.Close()
+			r = gz
+		}
+		tr := tar.NewReader(r)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			content, err := readAtMost(tr, remaining)
+			if err != nil {
+				return nil, err
+			}
+			if err := addArchiveFile(files, hdr.Name, content); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q", format)
+	}
+	return files, nil
+}
+
+// addArchiveFile adds a file of an archive, rejecting paths escaping the archive root or not in their clean form,
+// except for a leading ./ like in the archives created with tar -C dir .
+func addArchiveFile(files map[string][]byte, name string, content []byte) error {
+	name = strings.TrimPrefix(strings.ReplaceAll(name, "\\", "/"), "./")
+	if !fs.ValidPath(name) || name == "." {
+		return fmt.Errorf("invalid archive path %q", name)
+	}
+	files[name] = content
+	return nil
+}
+
+// readAtMost reads r, failing with ErrTooLarge when it is larger than remaining, which is decreased by the size
+// read. A nil remaining reads without limit.
+func readAtMost(r io.Reader, remaining *int64) ([]byte, error) {
+	if remaining == nil {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, *remaining+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > *remaining {
+		return nil, ErrTooLarge
+	}
+	*remaining -= int64(len(data))
+	return data, nil
+}
+
+// maxSize returns the limit of the size of a source, DefaultMaxSize when zero.
+func maxSize(limit int64) int64 {
+	if limit == 0 {
+		return DefaultMaxSize
+	}
+	return limit
+}
+
+// newSnapshot builds a memFS from file contents, unchanged files keep their modification time in previous
+// and new or changed ones are dated now, so Load only sees the files that changed.
+func newSnapshot(previous memFS, contents map[string][]byte, now time.Time) memFS {
+	files := make(memFS, len(contents))
+	for name, content := range contents {
+		modTime := now
+		if old, ok := previous[name]; ok && bytes.Equal(old.data, content) {
+			modTime = old.modTime
+		}
+		files[name] = memFile{data: content, modTime: modTime}
+	}
+	return files
+}
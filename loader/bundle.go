@@ -0,0 +1,78 @@
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Bundle serves the templates of a .zip, .tar or .tar.gz archive, so plugins and themes can be
+// distributed as a single file and installed at runtime:
+//
+//	bundle, err := loader.OpenBundle("themes/dark-shop.zip")
+//	eng := blade.NewEngineFS(bundle)
+//
+// Replace installs a new version of the bundle, the next Engine.Load recompiles the changed templates.
+type Bundle struct {
+	files atomic.Pointer[memFS]
+}
+
+// OpenBundle reads the archive at path.
+func OpenBundle(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("loader: %w", err)
+	}
+	defer f.Close()
+	return ReadBundle(f)
+}
+
+// ReadBundle reads an archive from r, the format is detected from its content.
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	b := &Bundle{}
+	b.files.Store(&memFS{})
+	if err := b.Replace(r); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Replace replaces the templates of the bundle with the archive read from r.
+func (b *Bundle) Replace(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("loader: %w", err)
+	}
+	contents, err := readArchive(data, detectArchiveFormat(data), nil)
+	if err != nil {
+		return fmt.Errorf("loader: bundle: %w", err)
+	}
+	files := newSnapshot(*b.files.Load(), contents, time.Now())
+	b.files.Store(&files)
+	return nil
+}
+
+// Open implements fs.FS.
+func (b *Bundle) Open(name string) (fs.File, error) {
+	return b.files.Load().Open(name)
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (b *Bundle) ReadDir(name string) ([]fs.DirEntry, error) {
+	return b.files.Load().ReadDir(name)
+}
+
+// detectArchiveFormat detects a zip or gzip archive from its magic bytes, anything else is read as tar.
+func detectArchiveFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")), bytes.HasPrefix(data, []byte("PK\x05\x06")):
+		return "zip"
+	case bytes.HasPrefix(data, []byte("\x1f\x8b")):
+		return "tar.gz"
+	}
+	return "tar"
+}
@@ -0,0 +1,55 @@
+package loader
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dangdungcntt/go-blade"
+)
+
+func TestBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "theme.zip")
+	err := os.WriteFile(path, zipArchive(t, map[string]string{
+		"layouts/app.blade": `<main>@yield('content')</main>`,
+		"pages/home.blade":  `@extends('layouts/app')@section('content')Home@endsection`,
+	}), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := OpenBundle(path)
+	if err != nil {
+		t.Fatalf("OpenBundle failed: %v", err)
+	}
+	engine := blade.NewEngineFS(bundle)
+	render := func() string {
+		t.Helper()
+		if err := engine.Load(); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, "pages/home", nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		return buf.String()
+	}
+	if got := render(); got != "<main>Home</main>" {
+		t.Errorf("unexpected output %q", got)
+	}
+
+	err = bundle.Replace(bytes.NewReader(tarGzArchive(t, map[string]string{
+		"layouts/app.blade": `<main>@yield('content')</main>`,
+		"pages/home.blade":  `@extends('layouts/app')@section('content')Upgraded@endsection`,
+	})))
+	if err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+	if got := render(); got != "<main>Upgraded</main>" {
+		t.Errorf("unexpected output after Replace %q", got)
+	}
+
+	if _, err := ReadBundle(bytes.NewReader([]byte("not an archive"))); err == nil {
+		t.Errorf("expected an error for an invalid archive")
+	}
+}
@@ -0,0 +1,173 @@
+package loader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// HTTP serves templates fetched from an HTTP endpoint, either an archive of the template tree
+// (.zip, .tar, .tar.gz or .tgz) or a JSON manifest listing the files and their ETags:
+//
+//	{"files": {"layouts/app.blade": "\"3f2a\"", "pages/home.blade": "\"91bc\""}}
+//
+// Manifest files are fetched relative to the manifest URL. Refresh sends the last ETag in If-None-Match,
+// so an unchanged tree costs a single 304 response, and only the files whose ETag changed are downloaded.
+type HTTP struct {
+	// URL is the archive or manifest URL.
+	URL string
+	// Format is zip, tar, tar.gz or manifest, when empty it is detected from the URL path,
+	// a URL ending with .json is a manifest.
+	Format string
+	// Client sends the requests, http.DefaultClient when nil.
+	Client *http.Client
+	// Header is added to every request, like an Authorization header.
+	Header http.Header
+	// MaxSize limits the size of the archive or manifest and its templates, and the uncompressed size of the
+	// templates of an archive, DefaultMaxSize when zero.
+	MaxSize int64
+
+	snapshot atomic.Pointer[httpSnapshot]
+}
+
+type httpSnapshot struct {
+	fs   memFS
+	etag string
+	// etags are the ETags of the files of a manifest
+	etags map[string]string
+}
+
+// httpManifest is the JSON manifest of a template tree.
+type httpManifest struct {
+	Files map[string]string `json:"files"`
+}
+
+// NewHTTP returns a template source fetching the archive or manifest at rawURL.
+func NewHTTP(rawURL string) (*HTTP, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("loader: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("loader: unsupported URL scheme %q", u.Scheme)
+	}
+	return &HTTP{URL: rawURL}, nil
+}
+
+// Open implements fs.FS.
+func (h *HTTP) Open(name string) (fs.File, error) {
+	return h.current().fs.Open(name)
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (h *HTTP) ReadDir(name string) ([]fs.DirEntry, error) {
+	return h.current().fs.ReadDir(name)
+}
+
+func (h *HTTP) current() *httpSnapshot {
+	if s := h.snapshot.Load(); s != nil {
+		return s
+	}
+	return &httpSnapshot{fs: memFS{}}
+}
+
+// Refresh implements blade.RefreshableFS.
+func (h *HTTP) Refresh(ctx context.Context) error {
+	format := h.Format
+	if format == "" {
+		u, err := url.Parse(h.URL)
+		if err != nil {
+			return fmt.Errorf("loader: %w", err)
+		}
+		if format = archiveFormat(u.Path); format == "" && strings.HasSuffix(strings.ToLower(u.Path), ".json") {
+			format = "manifest"
+		}
+	}
+	if format == "" {
+		return fmt.Errorf("loader: cannot detect the format of %s", h.URL)
+	}
+
+	previous := h.current()
+	remaining := maxSize(h.MaxSize)
+	body, etag, err := h.get(ctx, h.URL, previous.etag, &remaining)
+	if err != nil || body == nil {
+		return err
+	}
+	now := time.Now()
+	if format != "manifest" {
+		uncompressed := maxSize(h.MaxSize)
+		contents, err := readArchive(body, format, &uncompressed)
+		if err != nil {
+			return fmt.Errorf("loader: %s: %w", h.URL, err)
+		}
+		h.snapshot.Store(&httpSnapshot{fs: newSnapshot(previous.fs, contents, now), etag: etag})
+		return nil
+	}
+
+	var manifest httpManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return fmt.Errorf("loader: %s: %w", h.URL, err)
+	}
+	base, err := url.Parse(h.URL)
+	if err != nil {
+		return fmt.Errorf("loader: %w", err)
+	}
+	files := memFS{}
+	for name, fileETag := range manifest.Files {
+		if !fs.ValidPath(name) {
+			return fmt.Errorf("loader: invalid template name %q", name)
+		}
+		if old, ok := previous.fs[name]; ok && fileETag != "" && previous.etags[name] == fileETag {
+			files[name] = old
+			continue
+		}
+		fileURL := base.ResolveReference(&url.URL{Path: name}).String()
+		data, _, err := h.get(ctx, fileURL, "", &remaining)
+		if err != nil {
+			return err
+		}
+		files[name] = memFile{data: data, modTime: now}
+	}
+	h.snapshot.Store(&httpSnapshot{fs: files, etag: etag, etags: manifest.Files})
+	return nil
+}
+
+// get fetches rawURL reading at most remaining bytes, it returns a nil body when the response is 304 Not Modified.
+func (h *HTTP) get(ctx context.Context, rawURL string, etag string, remaining *int64) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("loader: %w", err)
+	}
+	for key, values := range h.Header {
+		req.Header[key] = values
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("loader: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("loader: GET %s: %s", rawURL, resp.Status)
+	}
+	body, err := readAtMost(resp.Body, remaining)
+	if err != nil {
+		return nil, "", fmt.Errorf("loader: GET %s: %w", rawURL, err)
+	}
+	return body, resp.Header.Get("ETag"), nil
+}
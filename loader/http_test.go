@@ -0,0 +1,193 @@
+package loader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dangdungcntt/go-blade"
+)
+
+// remoteTree is a template tree served by a test server, with request counts by path.
+type remoteTree struct {
+	mu       sync.Mutex
+	files    map[string]string
+	version  int
+	requests map[string]int
+}
+
+func (r *remoteTree) set(name string, content string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files[name] = content
+	r.version++
+}
+
+func (r *remoteTree) count(path string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests[path]
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func (r *remoteTree) handler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests[req.URL.Path]++
+		var body []byte
+		switch {
+		case req.URL.Path == "/tree.zip":
+			body = zipArchive(t, r.files)
+		case req.URL.Path == "/tree.tar.gz":
+			body = tarGzArchive(t, r.files)
+		case req.URL.Path == "/tree/manifest.json":
+			manifest := httpManifest{Files: map[string]string{}}
+			for name, content := range r.files {
+				manifest.Files[name] = fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(content)))
+			}
+			body, _ = json.Marshal(manifest)
+		default:
+			content, ok := r.files[strings.TrimPrefix(req.URL.Path, "/tree/")]
+			if !ok {
+				http.NotFound(w, req)
+				return
+			}
+			body = []byte(content)
+		}
+		tag := `"` + req.URL.Path + "-" + strings.Repeat("x", r.version) + `"`
+		if req.Header.Get("If-None-Match") == tag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", tag)
+		w.Write(body)
+	})
+}
+
+func TestHTTP(t *testing.T) {
+	for _, path := range []string{"/tree.zip", "/tree.tar.gz", "/tree/manifest.json"} {
+		t.Run(path, func(t *testing.T) {
+			tree := &remoteTree{files: map[string]string{}, requests: map[string]int{}}
+			tree.set("layouts/app.blade", `<main>@yield('content')</main>`)
+			tree.set("pages/home.blade", `@extends('layouts/app')@section('content')Home@endsection`)
+			server := httptest.NewServer(tree.handler(t))
+			defer server.Close()
+
+			source, err := NewHTTP(server.URL + path)
+			if err != nil {
+				t.Fatalf("NewHTTP failed: %v", err)
+			}
+			engine := blade.NewEngineFS(source)
+			render := func() string {
+				t.Helper()
+				if err := engine.Load(); err != nil {
+					t.Fatalf("Load failed: %v", err)
+				}
+				var buf bytes.Buffer
+				if err := engine.Render(&buf, "pages/home", nil); err != nil {
+					t.Fatalf("Render failed: %v", err)
+				}
+				return buf.String()
+			}
+
+			if got := render(); got != "<main>Home</main>" {
+				t.Errorf("unexpected output %q", got)
+			}
+			render()
+			if n := tree.count(path); n != 2 {
+				t.Errorf("expected 2 requests, got %d", n)
+			}
+
+			tree.set("pages/home.blade", `@extends('layouts/app')@section('content')Edited@endsection`)
+			if got := render(); got != "<main>Edited</main>" {
+				t.Errorf("unexpected output after edit %q", got)
+			}
+			if path == "/tree/manifest.json" {
+				if n := tree.count("/tree/layouts/app.blade"); n != 1 {
+					t.Errorf("expected the unchanged layout to be fetched once, got %d", n)
+				}
+				if n := tree.count("/tree/pages/home.blade"); n != 2 {
+					t.Errorf("expected the edited page to be fetched twice, got %d", n)
+				}
+			}
+		})
+	}
+
+	if _, err := NewHTTP("file:///etc/templates.zip"); err == nil {
+		t.Errorf("expected an error for a file URL")
+	}
+	for _, name := range []string{"../secret.blade", "/etc/secret.blade", "pages/../../secret.blade"} {
+		if files, err := readArchive(zipArchive(t, map[string]string{name: "x"}), "zip", nil); err == nil {
+			t.Errorf("expected an error for the path %s escaping the archive, got %v", name, files)
+		}
+	}
+}
+
+func TestHTTPMaxSize(t *testing.T) {
+	bomb := strings.Repeat("x", 1<<20)
+	limit := int64(3 << 19)
+	if _, err := readArchive(zipArchive(t, map[string]string{"a.blade": bomb, "b.blade": bomb}), "zip", &limit); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge for an archive larger uncompressed than the limit, got %v", err)
+	}
+
+	for _, path := range []string{"/tree.tar.gz", "/tree/manifest.json"} {
+		tree := &remoteTree{files: map[string]string{}, requests: map[string]int{}}
+		tree.set("page.blade", bomb)
+		server := httptest.NewServer(tree.handler(t))
+		source, err := NewHTTP(server.URL + path)
+		if err != nil {
+			t.Fatalf("NewHTTP failed: %v", err)
+		}
+		source.MaxSize = 1 << 19
+		if err := source.Refresh(context.Background()); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s: expected ErrTooLarge, got %v", path, err)
+		}
+		source.MaxSize = 0
+		if err := source.Refresh(context.Background()); err != nil {
+			t.Errorf("%s: Refresh failed: %v", path, err)
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,5 @@
+// Package loader provides template sources for blade.NewEngineFS backed by a database, an HTTP endpoint, S3 storage
+// or an archive.
+// Each source holds an in-memory snapshot of the templates. The database and remote sources are blade.RefreshableFS,
+// Engine.Load refreshes them and recompiles the modified templates, so Engine.Watch picks up remote changes.
+package loader
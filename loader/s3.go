@@ -0,0 +1,262 @@
+package loader
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty request body.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3 serves templates stored under a prefix of an S3 compatible bucket, like AWS S3, MinIO or Cloudflare R2.
+// Objects are addressed path style, as {Endpoint}/{Bucket}/{key}, and the key without Prefix is the template path.
+// Refresh lists the prefix and only downloads the objects whose ETag changed.
+type S3 struct {
+	// Endpoint is the storage URL, like https://s3.eu-west-1.amazonaws.com.
+	Endpoint string
+	Bucket   string
+	// Prefix is the key prefix of the template tree, like "templates/".
+	Prefix string
+	// Region signs the requests, us-east-1 when empty.
+	Region string
+	// AccessKeyID and SecretAccessKey sign the requests with AWS Signature Version 4,
+	// requests are anonymous when AccessKeyID is empty.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Client sends the requests, http.DefaultClient when nil.
+	Client *http.Client
+	// MaxSize limits the total size of the templates, and of each listing response, DefaultMaxSize when zero.
+	MaxSize int64
+
+	snapshot atomic.Pointer[s3Snapshot]
+}
+
+type s3Snapshot struct {
+	fs    memFS
+	etags map[string]string
+}
+
+// s3ListResult is the response of ListObjectsV2.
+type s3ListResult struct {
+	IsTruncated           bool
+	NextContinuationToken string
+	Contents              []struct {
+		Key  string
+		ETag string
+	}
+}
+
+// Open implements fs.FS.
+func (s *S3) Open(name string) (fs.File, error) {
+	return s.current().fs.Open(name)
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (s *S3) ReadDir(name string) ([]fs.DirEntry, error) {
+	return s.current().fs.ReadDir(name)
+}
+
+func (s *S3) current() *s3Snapshot {
+	if snapshot := s.snapshot.Load(); snapshot != nil {
+		return snapshot
+	}
+	return &s3Snapshot{fs: memFS{}}
+}
+
+// Refresh implements blade.RefreshableFS.
+func (s *S3) Refresh(ctx context.Context) error {
+	etags, err := s.list(ctx)
+	if err != nil {
+		return err
+	}
+	previous := s.current()
+	now := time.Now()
+	files := memFS{}
+	remaining := maxSize(s.MaxSize)
+	for name, etag := range etags {
+		if old, ok := previous.fs[name]; ok && previous.etags[name] == etag {
+			files[name] = old
+			continue
+		}
+		body, err := s.do(ctx, "/"+uriEncode(s.Prefix+name, false), nil, &remaining)
+		if err != nil {
+			return err
+		}
+		files[name] = memFile{data: body, modTime: now}
+	}
+	s.snapshot.Store(&s3Snapshot{fs: files, etags: etags})
+	return nil
+}
+
+// list returns the ETags of the templates under the prefix.
+func (s *S3) list(ctx context.Context) (map[string]string, error) {
+	etags := map[string]string{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.Prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		remaining := maxSize(s.MaxSize)
+		body, err := s.do(ctx, "", query, &remaining)
+		if err != nil {
+			return nil, err
+		}
+		var result s3ListResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("loader: s3 list %s: %w", s.Bucket, err)
+		}
+		for _, object := range result.Contents {
+			name := strings.TrimPrefix(object.Key, s.Prefix)
+			if name == "" || strings.HasSuffix(name, "/") {
+				continue
+			}
+			if !fs.ValidPath(name) {
+				return nil, fmt.Errorf("loader: invalid template name %q", name)
+			}
+			etags[name] = object.ETag
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return etags, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// do sends a signed GET request for the escaped key path of the bucket, reading at most remaining bytes.
+func (s *S3) do(ctx context.Context, keyPath string, query url.Values, remaining *int64) ([]byte, error) {
+	rawURL := strings.TrimSuffix(s.Endpoint, "/") + "/" + uriEncode(s.Bucket, false) + keyPath
+	if len(query) > 0 {
+		rawURL += "?" + canonicalQuery(query)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("loader: %w", err)
+	}
+	if s.AccessKeyID != "" {
+		region := s.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		signV4(req, region, s.AccessKeyID, s.SecretAccessKey, s.SessionToken, time.Now())
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("loader: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := readAtMost(resp.Body, remaining)
+	if err != nil {
+		return nil, fmt.Errorf("loader: GET %s: %w", req.URL.Path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("loader: GET %s: %s", req.URL.Path, resp.Status)
+	}
+	return body, nil
+}
+
+// signV4 signs an S3 request without body with AWS Signature Version 4.
+func signV4(req *http.Request, region string, accessKeyID string, secretAccessKey string, sessionToken string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	day := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		if key = strings.ToLower(key); strings.HasPrefix(key, "x-amz-") {
+			headers[key] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+	scope := day + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + secretAccessKey)
+	for _, part := range []string{day, region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes a query string sorted by key, as Signature Version 4 requires.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes every byte but the unreserved characters, and slashes when encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
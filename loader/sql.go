@@ -0,0 +1,105 @@
+package loader
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+// reSQLIdentifier matches the table names accepted by NewSQL.
+var reSQLIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQL serves templates stored in a table with name, content and updated_at columns, like:
+//
+//	CREATE TABLE templates (name TEXT PRIMARY KEY, content TEXT NOT NULL, updated_at TIMESTAMP NOT NULL)
+//
+// Names are paths with extension, like pages/home.blade. Refresh only reads the table again when the number
+// of rows or the latest updated_at changed, so polling with Engine.Watch is cheap.
+type SQL struct {
+	db       *sql.DB
+	table    string
+	snapshot atomic.Pointer[sqlSnapshot]
+}
+
+type sqlSnapshot struct {
+	fs      memFS
+	version string
+}
+
+// NewSQL returns a template source reading the table of db.
+func NewSQL(db *sql.DB, table string) (*SQL, error) {
+	if !reSQLIdentifier.MatchString(table) {
+		return nil, fmt.Errorf("loader: invalid table name %q", table)
+	}
+	s := &SQL{db: db, table: table}
+	s.snapshot.Store(&sqlSnapshot{fs: memFS{}})
+	return s, nil
+}
+
+// Open implements fs.FS.
+func (s *SQL) Open(name string) (fs.File, error) {
+	return s.snapshot.Load().fs.Open(name)
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (s *SQL) ReadDir(name string) ([]fs.DirEntry, error) {
+	return s.snapshot.Load().fs.ReadDir(name)
+}
+
+// Invalidate forces the next Refresh to read the table, call it followed by Engine.Load
+// when notified of a change, like with a PostgreSQL LISTEN channel.
+func (s *SQL) Invalidate() {
+	current := s.snapshot.Load()
+	s.snapshot.Store(&sqlSnapshot{fs: current.fs})
+}
+
+// Refresh implements blade.RefreshableFS.
+func (s *SQL) Refresh(ctx context.Context) error {
+	var count int64
+	var latest any
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*), MAX(updated_at) FROM "+s.table).Scan(&count, &latest)
+	if err != nil {
+		return fmt.Errorf("loader: %w", err)
+	}
+	version := fmt.Sprintf("%d/%v", count, latest)
+	if version == s.snapshot.Load().version {
+		return nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT name, content, updated_at FROM "+s.table)
+	if err != nil {
+		return fmt.Errorf("loader: %w", err)
+	}
+	defer rows.Close()
+
+	previous := s.snapshot.Load().fs
+	now := time.Now()
+	files := memFS{}
+	for rows.Next() {
+		var name, content string
+		var updatedAt time.Time
+		if err := rows.Scan(&name, &content, &updatedAt); err != nil {
+			return fmt.Errorf("loader: %w", err)
+		}
+		if !fs.ValidPath(name) {
+			return fmt.Errorf("loader: invalid template name %q", name)
+		}
+		// changed templates are dated when they are read, so the clock of the database cannot hide them from Load
+		modTime := updatedAt
+		if old, ok := previous[name]; ok && string(old.data) == content {
+			modTime = old.modTime
+		} else if modTime.Before(now) {
+			modTime = now
+		}
+		files[name] = memFile{data: []byte(content), modTime: modTime}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("loader: %w", err)
+	}
+	s.snapshot.Store(&sqlSnapshot{fs: files, version: version})
+	return nil
+}
@@ -0,0 +1,150 @@
+package loader
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	blade "github.com/dangdungcntt/go-blade"
+)
+
+// fakeTable is a templates table served by the fakeDriver.
+type fakeTable struct {
+	mu      sync.Mutex
+	rows    map[string]fakeRow
+	queries int
+}
+
+type fakeRow struct {
+	content   string
+	updatedAt time.Time
+}
+
+func (t *fakeTable) set(name string, content string, updatedAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows[name] = fakeRow{content: content, updatedAt: updatedAt}
+}
+
+type fakeDriver struct{ table *fakeTable }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn(d), nil }
+
+type fakeConn struct{ table *fakeTable }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{table: c.table, query: query}, nil
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type fakeStmt struct {
+	table *fakeTable
+	query string
+}
+
+func (s fakeStmt) Close() error                               { return nil }
+func (s fakeStmt) NumInput() int                              { return 0 }
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+	if strings.HasPrefix(s.query, "SELECT COUNT(*), MAX(updated_at) FROM templates") {
+		var latest time.Time
+		for _, row := range s.table.rows {
+			if row.updatedAt.After(latest) {
+				latest = row.updatedAt
+			}
+		}
+		return &fakeRows{columns: []string{"count", "max"}, values: [][]driver.Value{{int64(len(s.table.rows)), latest}}}, nil
+	}
+	s.table.queries++
+	rows := &fakeRows{columns: []string{"name", "content", "updated_at"}}
+	for name, row := range s.table.rows {
+		rows.values = append(rows.values, []driver.Value{name, row.content, row.updatedAt})
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+var registerOnce sync.Once
+
+func TestSQL(t *testing.T) {
+	table := &fakeTable{rows: map[string]fakeRow{}}
+	registerOnce.Do(func() { sql.Register("blade-fake", fakeDriver{table: table}) })
+	db, err := sql.Open("blade-fake", "")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	// the database clock is behind the application clock
+	past := time.Now().Add(-time.Hour)
+	table.set("layouts/app.blade", `<main>@yield('content')</main>`, past)
+	table.set("pages/home.blade", `@extends('layouts/app')@section('content')Home@endsection`, past)
+
+	if _, err := NewSQL(db, "templates; DROP TABLE templates"); err == nil {
+		t.Errorf("expected an error for an invalid table name")
+	}
+	source, err := NewSQL(db, "templates")
+	if err != nil {
+		t.Fatalf("NewSQL failed: %v", err)
+	}
+	engine := blade.NewEngineFS(source)
+
+	render := func() string {
+		t.Helper()
+		if err := engine.Load(); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := engine.Render(&buf, "pages/home", nil); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	if got := render(); got != "<main>Home</main>" {
+		t.Errorf("unexpected output %q", got)
+	}
+	render()
+	if table.queries != 1 {
+		t.Errorf("expected the table to be read once while unchanged, got %d reads", table.queries)
+	}
+
+	table.set("pages/home.blade", `@extends('layouts/app')@section('content')Edited@endsection`, past.Add(time.Minute))
+	if got := render(); got != "<main>Edited</main>" {
+		t.Errorf("unexpected output after edit %q", got)
+	}
+
+	source.Invalidate()
+	if err := source.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if table.queries != 3 {
+		t.Errorf("expected Invalidate to force a read, got %d reads", table.queries)
+	}
+}
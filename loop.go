@@ -0,0 +1,267 @@
+package blade
+
+import (
+	"cmp"
+	"fmt"
+	"html/template"
+	"iter"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// reForeachToken matches the @foreach and @forelse blocks, their @empty branch and their end, to track
+	// their nesting
+	reForeachToken = regexp.MustCompile(`@(?:foreach|forelse)\(|@(?:endforeach|endforelse|empty)\b`)
+	// reForeachAs matches the arguments of @foreach and @forelse: .Items as $item, or .Prices as $sku => $price
+	reForeachAs = regexp.MustCompile(`^\s*(.+?)\s+as\s+(?:\$(\w+)\s*=>\s*)?\$(\w+)\s*$`)
+)
+
+// Loop is the $loop variable of the iterations of @foreach.
+type Loop struct {
+	// Index is the zero based index of the iteration, Iteration the one based one
+	Index     int
+	Iteration int
+	// Count is the number of items, and Remaining the number of iterations after the current one. Both are
+	// -1 when the number of items is not known before ranging over them, like for iterators and channels.
+	Count     int
+	Remaining int
+	First     bool
+	// Last is always false when Count is not known
+	Last bool
+	// Even and Odd report whether Iteration is even or odd
+	Even bool
+	Odd  bool
+	// Depth is the nesting level of the loop, 1 for the outermost @foreach
+	Depth int
+	// Parent is the $loop of the enclosing @foreach, nil for the outermost one
+	Parent *Loop
+}
+
+// loopItems holds the items of a @foreach and counts its iterations.
+type loopItems struct {
+	Items  any
+	count  int
+	index  int
+	parent *Loop
+	// key is the key of the current item, bound by @foreach(.Prices as $sku => $price)
+	key any
+}
+
+// foreachItems returns the items of a @foreach, parent is the $loop of the enclosing @foreach.
+func foreachItems(items any, parent ...*Loop) *loopItems {
+	l := &loopItems{Items: items, count: -1}
+	if len(parent) > 0 {
+		l.parent = parent[0]
+	}
+	switch v := indirectValue(reflect.ValueOf(items)); v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		l.count = v.Len()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		l.count = max(int(v.Int()), 0)
+	case reflect.Invalid:
+		l.count = 0
+	}
+	return l
+}
+
+// Next returns the $loop of the next iteration.
+func (l *loopItems) Next() *Loop {
+	loop := &Loop{
+		Index:     l.index,
+		Iteration: l.index + 1,
+		Count:     l.count,
+		Remaining: -1,
+		First:     l.index == 0,
+		Even:      (l.index+1)%2 == 0,
+		Odd:       (l.index+1)%2 == 1,
+		Depth:     1,
+		Parent:    l.parent,
+	}
+	if l.count >= 0 {
+		loop.Remaining = l.count - l.index - 1
+		loop.Last = loop.Remaining == 0
+	}
+	if l.parent != nil {
+		loop.Depth = l.parent.Depth + 1
+	}
+	l.index++
+	return loop
+}
+
+// Key returns the key of the current item: its index, or its key in a map.
+func (l *loopItems) Key() any {
+	return l.key
+}
+
+// Each returns the items with dot as the dot of each iteration, so the body of a @foreach keeps the dot of
+// the block, like @while. The items range like with {{ range }}: slices, arrays, maps sorted by key, integers,
+// channels and iterators.
+func (l *loopItems) Each(dot any) (iter.Seq2[any, any], error) {
+	v := indirectValue(reflect.ValueOf(l.Items))
+	var items iter.Seq2[reflect.Value, reflect.Value]
+	switch v.Kind() {
+	case reflect.Invalid:
+		items = func(func(reflect.Value, reflect.Value) bool) {}
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, compareKeys)
+		items = func(yield func(reflect.Value, reflect.Value) bool) {
+			for _, key := range keys {
+				if !yield(key, v.MapIndex(key)) {
+					return
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items = v.Seq2()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Chan:
+		items = indexed(v.Seq())
+	case reflect.Func:
+		if v.Type().CanSeq2() {
+			items = v.Seq2()
+		} else if v.Type().CanSeq() {
+			items = indexed(v.Seq())
+		}
+	}
+	if items == nil {
+		return nil, fmt.Errorf("foreach: cannot range over %s", v.Type())
+	}
+	return func(yield func(any, any) bool) {
+		for key, value := range items {
+			l.key = key.Interface()
+			var item any
+			if value.IsValid() && value.CanInterface() {
+				item = value.Interface()
+			}
+			if !yield(item, dot) {
+				return
+			}
+		}
+	}, nil
+}
+
+// indexed returns the values of seq with their index as key.
+func indexed(seq iter.Seq[reflect.Value]) iter.Seq2[reflect.Value, reflect.Value] {
+	return func(yield func(reflect.Value, reflect.Value) bool) {
+		i := 0
+		for value := range seq {
+			if !yield(reflect.ValueOf(i), value) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// compareKeys orders the keys of a map like {{ range }}: numbers and strings by value, others by their text.
+func compareKeys(a, b reflect.Value) int {
+	switch {
+	case a.CanInt() && b.CanInt():
+		return cmp.Compare(a.Int(), b.Int())
+	case a.CanUint() && b.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint())
+	case a.CanFloat() && b.CanFloat():
+		return cmp.Compare(a.Float(), b.Float())
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	}
+	return cmp.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+}
+
+// loopBlock is an open @foreach or @forelse block of parseForeachDirectives.
+type loopBlock struct {
+	forelse bool
+	// inEmpty reports whether the block is in its @empty branch, where its $loop is not defined
+	inEmpty bool
+}
+
+// parseForeachDirectives converts the @foreach and @forelse blocks of rest to range actions binding $loop and
+// keeping the dot:
+// @foreach(.Items as $item) ... @endforeach =>
+// {{ $__foreach1 := __foreach (.Items) }}{{ range $item, $__dot := $__foreach1.Each . }}{{ $loop := $__foreach1.Next }} ... {{ end }}
+// @forelse(.Items as $item) ... @empty ... @endforelse renders the @empty branch when there are no items, as the
+// else branch of the range. Nested blocks pass the $loop of the enclosing block as the parent of theirs.
+func parseForeachDirectives(rest string) string {
+	var out strings.Builder
+	var open []loopBlock
+	count, cursor := 0, 0
+	for _, loc := range reForeachToken.FindAllStringIndex(rest, -1) {
+		if loc[0] < cursor {
+			continue
+		}
+		out.WriteString(rest[cursor:loc[0]])
+		cursor = loc[1]
+		token := rest[loc[0]:loc[1]]
+		switch {
+		case token == "@endforeach" || token == "@endforelse":
+			out.WriteString("{{ end }}")
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+			continue
+		case token == "@empty":
+			// only @empty without arguments in a @forelse is its branch
+			if len(open) == 0 || !open[len(open)-1].forelse || strings.HasPrefix(rest[cursor:], "(") {
+				out.WriteString(token)
+				continue
+			}
+			open[len(open)-1].inEmpty = true
+			out.WriteString("{{ else }}")
+			continue
+		}
+
+		directive := strings.TrimSuffix(token[1:], "(")
+		end, args, ok := parseDirectiveCall(rest, loc[0], directive)
+		var sm []string
+		if ok && len(args) == 1 {
+			sm = reForeachAs.FindStringSubmatch(args[0])
+		}
+		if sm == nil {
+			out.WriteString(token)
+			continue
+		}
+		count++
+		items := fmt.Sprintf("$__foreach%d", count)
+		parent := ""
+		if slices.ContainsFunc(open, func(b loopBlock) bool { return !b.inEmpty }) {
+			parent = " $loop"
+		}
+		key := ""
+		if sm[2] != "" {
+			key = fmt.Sprintf("{{ $%s := %s.Key }}", sm[2], items)
+		}
+		fmt.Fprintf(&out, `{{ %s := __foreach (%s)%s }}{{ range $%s, $__dot := %s.Each . }}%s{{ $loop := %s.Next }}`, items, sm[1], parent, sm[3], items, key, items)
+		open = append(open, loopBlock{forelse: directive == "forelse"})
+		cursor = end
+	}
+	out.WriteString(rest[cursor:])
+	return out.String()
+}
+
+// whileRange returns the iterations of a @while loop, keeping dot as the dot of its body: its bound, or one more
+// than Engine.MaxWhileIterations so whileCheck can fail the loops iterating more.
+func (s *renderState) whileRange(dot any, bound ...any) (iter.Seq2[int, any], error) {
+	n := s.e.MaxWhileIterations + 1
+	if len(bound) > 0 {
+		var err error
+		if n, err = toInt(bound[0]); err != nil {
+			return nil, fmt.Errorf("@while: %w", err)
+		}
+	}
+	return func(yield func(int, any) bool) {
+		for i := 0; i < n && yield(i, dot); i++ {
+		}
+	}, nil
+}
+
+// whileCheck reports whether the iteration i of a @while loop without bound runs, when its condition is true.
+func (s *renderState) whileCheck(i int, cond any) (bool, error) {
+	truth, _ := template.IsTrue(cond)
+	if truth && i >= s.e.MaxWhileIterations {
+		return false, fmt.Errorf("@while exceeded maximum iterations %d", s.e.MaxWhileIterations)
+	}
+	return truth, nil
+}
@@ -0,0 +1,216 @@
+package blade
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// operand is an integer or float operand of the arithmetic helpers.
+type operand struct {
+	i       int64
+	f       float64
+	isFloat bool
+}
+
+// toOperand converts integers, floats and numeric strings to an operand, integers stay integers.
+func toOperand(v any) (operand, error) {
+	rv := indirectValue(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return operand{i: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return operand{i: int64(rv.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return operand{f: rv.Float(), isFloat: true}, nil
+	case reflect.String:
+		if i, err := strconv.ParseInt(rv.String(), 10, 64); err == nil {
+			return operand{i: i}, nil
+		}
+		f, err := strconv.ParseFloat(rv.String(), 64)
+		if err != nil {
+			return operand{}, fmt.Errorf("cannot convert %q to number", rv.String())
+		}
+		return operand{f: f, isFloat: true}, nil
+	default:
+		return operand{}, fmt.Errorf("cannot convert %v (%T) to number", v, v)
+	}
+}
+
+// float returns the number as a float64.
+func (n operand) float() float64 {
+	if n.isFloat {
+		return n.f
+	}
+	return float64(n.i)
+}
+
+// value returns the number as an int, or a float64 for floats.
+func (n operand) value() any {
+	if n.isFloat {
+		return n.f
+	}
+	return int(n.i)
+}
+
+// arithmetic folds the operands left to right with intOp, or floatOp as soon as an operand is a float.
+func arithmetic(name string, args []any, intOp func(a, b int64) (int64, error), floatOp func(a, b float64) (float64, error)) (any, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("%s: expected at least 2 operands", name)
+	}
+	acc, err := toOperand(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	for _, arg := range args[1:] {
+		n, err := toOperand(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if acc.isFloat || n.isFloat {
+			f, err := floatOp(acc.float(), n.float())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			acc = operand{f: f, isFloat: true}
+			continue
+		}
+		i, err := intOp(acc.i, n.i)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		acc = operand{i: i}
+	}
+	return acc.value(), nil
+}
+
+var errDivisionByZero = errors.New("division by zero")
+
+// add returns the sum of the operands, an int when all of them are integers and a float64 otherwise.
+func add(args ...any) (any, error) {
+	return arithmetic("add", args,
+		func(a, b int64) (int64, error) { return a + b, nil },
+		func(a, b float64) (float64, error) { return a + b, nil })
+}
+
+// sub subtracts the following operands from the first one.
+func sub(args ...any) (any, error) {
+	return arithmetic("sub", args,
+		func(a, b int64) (int64, error) { return a - b, nil },
+		func(a, b float64) (float64, error) { return a - b, nil })
+}
+
+// mul returns the product of the operands.
+func mul(args ...any) (any, error) {
+	return arithmetic("mul", args,
+		func(a, b int64) (int64, error) { return a * b, nil },
+		func(a, b float64) (float64, error) { return a * b, nil })
+}
+
+// div divides the first operand by the following ones, integers use integer division.
+func div(args ...any) (any, error) {
+	return arithmetic("div", args,
+		func(a, b int64) (int64, error) {
+			if b == 0 {
+				return 0, errDivisionByZero
+			}
+			return a / b, nil
+		},
+		func(a, b float64) (float64, error) {
+			if b == 0 {
+				return 0, errDivisionByZero
+			}
+			return a / b, nil
+		})
+}
+
+// mod returns the remainder of the division of a by b.
+func mod(a any, b any) (any, error) {
+	return arithmetic("mod", []any{a, b},
+		func(a, b int64) (int64, error) {
+			if b == 0 {
+				return 0, errDivisionByZero
+			}
+			return a % b, nil
+		},
+		func(a, b float64) (float64, error) {
+			if b == 0 {
+				return 0, errDivisionByZero
+			}
+			return math.Mod(a, b), nil
+		})
+}
+
+// minOf returns the smallest operand.
+func minOf(args ...any) (any, error) {
+	return pick("min", args, func(a, b float64) bool { return b < a })
+}
+
+// maxOf returns the largest operand.
+func maxOf(args ...any) (any, error) {
+	return pick("max", args, func(a, b float64) bool { return b > a })
+}
+
+// pick returns the operand kept by better, converted like the operands of arithmetic.
+func pick(name string, args []any, better func(a, b float64) bool) (any, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%s: expected at least 1 operand", name)
+	}
+	var picked operand
+	anyFloat := false
+	for i, arg := range args {
+		n, err := toOperand(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		anyFloat = anyFloat || n.isFloat
+		if i == 0 || better(picked.float(), n.float()) {
+			picked = n
+		}
+	}
+	if anyFloat {
+		return picked.float(), nil
+	}
+	return picked.value(), nil
+}
+
+// percent returns part as a percentage of total, rounded to decimals (0 by default), and 0 when total is 0,
+// like an empty progress bar.
+func percent(part any, total any, decimals ...int) (float64, error) {
+	p, err := toOperand(part)
+	if err != nil {
+		return 0, fmt.Errorf("percent: %w", err)
+	}
+	t, err := toOperand(total)
+	if err != nil {
+		return 0, fmt.Errorf("percent: %w", err)
+	}
+	if t.float() == 0 {
+		return 0, nil
+	}
+	scale := 1.0
+	if len(decimals) > 0 {
+		scale = math.Pow(10, float64(decimals[0]))
+	}
+	return math.Round(p.float()/t.float()*100*scale) / scale, nil
+}
+
+// compare returns -1, 0 or 1 when a is lower than, equal to or greater than b, comparing integers and floats
+// by value where the eq and lt builtins fail on mixed types.
+func compare(a any, b any) (int, error) {
+	x, err := toOperand(a)
+	if err != nil {
+		return 0, fmt.Errorf("compare: %w", err)
+	}
+	y, err := toOperand(b)
+	if err != nil {
+		return 0, fmt.Errorf("compare: %w", err)
+	}
+	if !x.isFloat && !y.isFloat {
+		return cmp.Compare(x.i, y.i), nil
+	}
+	return cmp.Compare(x.float(), y.float()), nil
+}
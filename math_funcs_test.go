@@ -0,0 +1,78 @@
+package blade
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestArithmeticFuncs(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func() (any, error)
+		expected any
+	}{
+		{"Add ints", func() (any, error) { return add(1, int64(2), uint8(3)) }, 6},
+		{"Add mixed", func() (any, error) { return add(1, 2.5) }, 3.5},
+		{"Add numeric strings", func() (any, error) { return add("2", 3) }, 5},
+		{"Sub", func() (any, error) { return sub(10, 3, 2) }, 5},
+		{"Mul mixed", func() (any, error) { return mul(3, 1.5) }, 4.5},
+		{"Div ints", func() (any, error) { return div(7, 2) }, 3},
+		{"Div floats", func() (any, error) { return div(7, 2.0) }, 3.5},
+		{"Mod", func() (any, error) { return mod(7, 3) }, 1},
+		{"Mod floats", func() (any, error) { return mod(7.5, 2) }, 1.5},
+		{"Min ints", func() (any, error) { return minOf(3, 1, 2) }, 1},
+		{"Max mixed", func() (any, error) { return maxOf(3, 1.5) }, 3.0},
+		{"Percent", func() (any, error) { return percent(1, 3, 1) }, 33.3},
+		{"Percent of zero", func() (any, error) { return percent(1, 0) }, 0.0},
+		{"Compare mixed", func() (any, error) { return compare(2, 2.5) }, -1},
+		{"Compare equal", func() (any, error) { return compare(uint(2), 2.0) }, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %v (%T), got %v (%T)", tc.expected, tc.expected, got, got)
+			}
+		})
+	}
+
+	for name, fn := range map[string]func() (any, error){
+		"division by zero": func() (any, error) { return div(1, 0) },
+		"modulo by zero":   func() (any, error) { return mod(1, 0) },
+		"not a number":     func() (any, error) { return add(1, "one") },
+		"single operand":   func() (any, error) { return add(1) },
+	} {
+		if _, err := fn(); err == nil {
+			t.Errorf("expected an error for %s", name)
+		}
+	}
+}
+
+func TestArithmeticInTemplates(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"cart.blade": `{{ mul .Price .Qty }}|{{ percent .Done .Total }}|{{ if lt (compare .Price 10) 0 }}cheap{{ end }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{"Price": 2.5, "Qty": 4, "Done": 1, "Total": 4}
+	if err := engine.Render(&buf, "cart", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := "10|25|cheap"; buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+
+	buf.Reset()
+	err := engine.Render(&buf, "cart", map[string]any{"Price": "free", "Qty": 1, "Done": 1, "Total": 4})
+	if err == nil || !strings.Contains(err.Error(), `mul: cannot convert "free" to number`) {
+		t.Errorf("expected a conversion error, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package blade
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+)
+
+// memoInclude renders the partial define name with data once per render, later includes with equal data
+// reuse its output. Data is compared by its Go syntax representation, so it must not change during the render.
+func (s *renderState) memoInclude(name string, data any) (any, error) {
+	if s.tmpl == nil {
+		return nil, errors.New("memo: include rendered outside of a render")
+	}
+	digest := sha256.Sum256(fmt.Appendf(nil, "%#v", data))
+	key := name + "\x00" + hex.EncodeToString(digest[:])
+	if out, ok := s.memo[key]; ok {
+		return out, nil
+	}
+
+	out, err := s.executeDefine(name, data)
+	if err != nil {
+		return nil, err
+	}
+	s.memo[key] = out
+	return out, nil
+}
+
+// executeDefine renders the define name of the render template set with data, the output is marked safe
+// for the template kind of the render since it is already escaped.
+func (s *renderState) executeDefine(name string, data any) (any, error) {
+	var buf bytes.Buffer
+	if err := s.tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+	if _, ok := s.tmpl.(textTemplateSet); ok {
+		return XML(buf.String()), nil
+	}
+	return template.HTML(buf.String()), nil
+}
@@ -0,0 +1,61 @@
+package blade
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+)
+
+func TestIncludeMemo(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"badge.blade": `<span class="{{ .Color }}">{{ count .Label }}</span>`,
+		"table.blade": `{{ range .Rows }}@include('badge', .Status, memo: true){{ end }}|@include('badge', newStatus)`,
+		"feed.xml":    `{{ range .Rows }}@include('item', ., memo: true){{ end }}`,
+		"item.xml":    `<item>{{ count .Label }}</item>`,
+	})
+	type status struct{ Label, Color string }
+	calls := 0
+	engine := NewEngineFS(mockFS)
+	engine.FuncMap["count"] = func(s string) string { calls++; return s }
+	engine.FuncMap["newStatus"] = func() status { return status{"<new>", "blue"} }
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	open, closed := status{"Open & new", "green"}, status{"Closed", "grey"}
+	data := map[string]any{"Rows": []map[string]any{{"Status": open}, {"Status": closed}, {"Status": open}, {"Status": open}}}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "table", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	badge := func(s status) string {
+		return `<span class="` + s.Color + `">` + template.HTMLEscapeString(s.Label) + `</span>`
+	}
+	expected := badge(open) + badge(closed) + badge(open) + badge(open) + "|" + badge(status{"<new>", "blue"})
+	if buf.String() != expected {
+		t.Errorf("Memo output mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+	if calls != 3 {
+		t.Errorf("Expected the partial to render once per distinct data and once without memo, got %d renders", calls)
+	}
+
+	calls = 0
+	buf.Reset()
+	if err := engine.Render(&buf, "table", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected the memo to be scoped to a render, got %d renders", calls)
+	}
+
+	calls = 0
+	buf.Reset()
+	xmlData := map[string]any{"Rows": []status{open, open}}
+	if err := engine.Render(&buf, "feed", xmlData); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `<item>Open &amp; new</item><item>Open &amp; new</item>`; buf.String() != expected || calls != 1 {
+		t.Errorf("XML memo mismatch after %d renders.\nExp: %s\nGot: %s", calls, expected, buf.String())
+	}
+}
@@ -0,0 +1,84 @@
+package blade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Compile cache statuses of RenderMetrics.
+const (
+	// CacheHit is a render executing the shared compiled template
+	CacheHit = "hit"
+	// CacheClone is a render executing a clone of the compiled template binding render scoped funcs, pooled
+	// across the renders of the template, or cloned for the funcs supplied with the data
+	CacheClone = "clone"
+	// CacheError is a render of a view that failed to compile, rendering the error overlay
+	CacheError = "error"
+)
+
+// RenderMetrics describes a single render, see Engine.OnRender.
+type RenderMetrics struct {
+	Entry    string
+	Duration time.Duration
+	// Cache is the compile cache status of the render: CacheHit, CacheClone, CacheError,
+	// or empty when the view was not found
+	Cache string
+	// Err is the error of the render
+	Err error
+}
+
+type renderMetricsKey struct{}
+
+// executeIn renders entry from the compiled set, see executeSet, measuring the render for Engine.OnRender
+// and the metrics requested in the context by the HTTP adapters.
+func (e *Engine) executeIn(ctx context.Context, set *compiledSet, w io.Writer, entry string, data any) error {
+	sink, _ := ctx.Value(renderMetricsKey{}).(*RenderMetrics)
+	if e.OnRender == nil && sink == nil {
+		return e.executeSet(ctx, set, w, entry, data, nil)
+	}
+
+	m := RenderMetrics{Entry: normalizeName(entry)}
+	start := time.Now()
+	m.Err = e.executeSet(ctx, set, w, entry, data, &m)
+	m.Duration = time.Since(start)
+	if sink != nil {
+		*sink = m
+	}
+	if e.OnRender != nil {
+		e.OnRender(m)
+	}
+	return m.Err
+}
+
+// renderTimed renders entry into a buffer, setting the Server-Timing header of w with the metrics of the render
+// before writing status and the output. A zero status leaves the status to the first write.
+func (e *Engine) renderTimed(ctx context.Context, w http.ResponseWriter, status int, entry string, data any) error {
+	var m RenderMetrics
+	buf := getBuffer()
+	defer putBuffer(buf)
+	err := e.executeIn(context.WithValue(ctx, renderMetricsKey{}, &m), nil, buf, entry, data)
+	var compileErr *CompileError
+	if errors.As(err, &compileErr) {
+		// write the error overlay of the broken view
+		status = http.StatusInternalServerError
+	} else if err != nil {
+		return err
+	}
+	w.Header().Add("Server-Timing", serverTiming(m))
+	if status != 0 {
+		w.WriteHeader(status)
+	}
+	if _, writeErr := buf.WriteTo(w); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+// serverTiming formats the Server-Timing header value of m, durations are in milliseconds.
+func serverTiming(m RenderMetrics) string {
+	return fmt.Sprintf(`blade-cache;desc="%s", blade-render;dur=%.3f`, m.Cache, float64(m.Duration.Microseconds())/1000)
+}
@@ -0,0 +1,63 @@
+package blade
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestOnRender(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"static.blade": `<p>{{ .Name }}</p>`,
+		"once.blade":   `@once<p>once</p>@endonce`,
+	}))
+	var metrics []RenderMetrics
+	engine.OnRender = func(m RenderMetrics) { metrics = append(metrics, m) }
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	_ = engine.Render(&buf, "static", map[string]string{"Name": "John"})
+	_ = engine.Render(&buf, "once", nil)
+	if err := engine.Render(&buf, "missing", nil); err == nil {
+		t.Fatal("Expected an error for a missing view")
+	}
+
+	expected := []struct{ entry, cache string }{{"static", CacheHit}, {"once", CacheClone}, {"missing", ""}}
+	if len(metrics) != len(expected) {
+		t.Fatalf("Expected %d metrics, got %d", len(expected), len(metrics))
+	}
+	for i, tc := range expected {
+		if metrics[i].Entry != tc.entry || metrics[i].Cache != tc.cache {
+			t.Errorf("Metrics %d mismatch, got %+v", i, metrics[i])
+		}
+	}
+	if metrics[2].Err == nil {
+		t.Error("Expected the error of the missing view in its metrics")
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"user.blade": `<h1>{{ .Name }}</h1>`,
+	}))
+	engine.ServerTiming = true
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/user", nil)
+	if err := engine.Respond(w, req, http.StatusCreated, "user", map[string]string{"Name": "John"}); err != nil {
+		t.Fatalf("Respond failed: %v", err)
+	}
+	if w.Code != http.StatusCreated || w.Body.String() != "<h1>John</h1>" {
+		t.Errorf("Response mismatch, got %d %s", w.Code, w.Body.String())
+	}
+	if timing := w.Header().Get("Server-Timing"); !regexp.MustCompile(`^blade-cache;desc="hit", blade-render;dur=\d+\.\d{3}$`).MatchString(timing) {
+		t.Errorf("Server-Timing mismatch, got %q", timing)
+	}
+}
@@ -0,0 +1,85 @@
+package blade
+
+import (
+	"strings"
+	"unicode"
+)
+
+// rawTextElements keep their whitespace when minifying.
+var rawTextElements = []string{"pre", "textarea", "script", "style"}
+
+// minifyHTML collapses the runs of whitespace of the HTML text of a template text into a single space,
+// leaving actions and the content of pre, textarea, script and style elements untouched.
+func minifyHTML(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	space := false
+	for i := 0; i < len(text); {
+		if strings.HasPrefix(text[i:], "{{") {
+			end := actionEnd(text, i)
+			b.WriteString(text[i:end])
+			i, space = end, false
+			continue
+		}
+		if text[i] == '<' {
+			if end := rawTextEnd(text, i); end > i {
+				b.WriteString(text[i:end])
+				i, space = end, false
+				continue
+			}
+		}
+		if c := rune(text[i]); c < unicode.MaxASCII && unicode.IsSpace(c) {
+			if !space {
+				b.WriteByte(' ')
+				space = true
+			}
+			i++
+			continue
+		}
+		b.WriteByte(text[i])
+		i, space = i+1, false
+	}
+	return b.String()
+}
+
+// actionEnd returns the index after the action starting at start, skipping the quoted strings of the action.
+func actionEnd(text string, start int) int {
+	for i := start + 2; i < len(text); i++ {
+		switch text[i] {
+		case '"', '`', '\'':
+			quote := text[i]
+			for i++; i < len(text) && text[i] != quote; i++ {
+				if text[i] == '\\' && quote != '`' {
+					i++
+				}
+			}
+		case '}':
+			if strings.HasPrefix(text[i:], "}}") {
+				return i + 2
+			}
+		}
+	}
+	return len(text)
+}
+
+// rawTextEnd returns the index after the closing tag of the raw text element opened at start,
+// or start when the tag at start is not a raw text element.
+func rawTextEnd(text string, start int) int {
+	for _, tag := range rawTextElements {
+		open := text[start+1:]
+		if len(open) <= len(tag) || !strings.EqualFold(open[:len(tag)], tag) {
+			continue
+		}
+		if c := open[len(tag)]; c != '>' && c != '/' && !unicode.IsSpace(rune(c)) {
+			continue
+		}
+		closing := "</" + tag
+		for i := start + 1; i+len(closing) <= len(text); i++ {
+			if text[i] == '<' && strings.EqualFold(text[i:i+len(closing)], closing) {
+				return i
+			}
+		}
+		return len(text)
+	}
+	return start
+}
@@ -0,0 +1,99 @@
+package blade
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Respond renders entry for HTML requests, or its @defer block for the requests of its placeholders, and encodes the same data as JSON when the request prefers application/json.
+func (e *Engine) Respond(w http.ResponseWriter, r *http.Request, status int, entry string, data any) error {
+	if prefersJSON(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		plain, _, _ := unwrapData(context.Background(), data)
+		return json.NewEncoder(w).Encode(plain)
+	}
+
+	ctx := RequestContext(r)
+	w.Header().Set("Content-Type", e.ContentType(entry))
+	if ESIEnabled(ctx) {
+		// ask the surrogate to process the ESI tags of the response
+		w.Header().Set("Surrogate-Control", `content="ESI/1.0"`)
+	}
+	if e.ServerTiming {
+		return e.renderTimed(ctx, w, status, entry, data)
+	}
+	sw := &statusWriter{ResponseWriter: w, status: status}
+	if err := e.RenderContext(ctx, sw, entry, data); err != nil {
+		sw.writeHeader()
+		return err
+	}
+	sw.writeHeader()
+	return nil
+}
+
+// statusWriter writes the status of a response with its first bytes, so a render replaces it until then, like
+// the render of a broken view writing its error overlay with the status 500.
+type statusWriter struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+// WriteHeader replaces the status while the response is not written.
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.written {
+		w.status = status
+	}
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.writeHeader()
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher for @flush.
+func (w *statusWriter) Flush() {
+	w.writeHeader()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the response writer, for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusWriter) writeHeader() {
+	if !w.written {
+		w.written = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+// prefersJSON reports whether an Accept header ranks application/json above text/html.
+func prefersJSON(accept string) bool {
+	jsonQuality, htmlQuality := -1.0, -1.0
+	for part := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			quality = q
+		}
+		switch mediaType {
+		case "application/json":
+			jsonQuality = max(jsonQuality, quality)
+		case "text/html":
+			htmlQuality = max(htmlQuality, quality)
+		}
+	}
+	return jsonQuality > 0 && jsonQuality > htmlQuality
+}
@@ -0,0 +1,45 @@
+package blade
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRespond(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"user.blade": `<h1>{{ .Name }}</h1>`,
+	}))
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"text/html,application/xhtml+xml", "text/html; charset=utf-8", `<h1>John</h1>`},
+		{"application/json", "application/json; charset=utf-8", `{"Name":"John"}`},
+		{"application/json;q=0.5, text/html", "text/html; charset=utf-8", `<h1>John</h1>`},
+		{"", "text/html; charset=utf-8", `<h1>John</h1>`},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/user", nil)
+		req.Header.Set("Accept", tc.accept)
+		w := httptest.NewRecorder()
+		if err := engine.Respond(w, req, http.StatusCreated, "user", NewDataWithFuncs(map[string]string{"Name": "John"}, nil)); err != nil {
+			t.Fatalf("Respond failed: %v", err)
+		}
+		if w.Code != http.StatusCreated {
+			t.Errorf("Status mismatch for %q, got %d", tc.accept, w.Code)
+		}
+		if w.Header().Get("Content-Type") != tc.contentType {
+			t.Errorf("Content-Type mismatch for %q, got %s", tc.accept, w.Header().Get("Content-Type"))
+		}
+		if strings.TrimSpace(w.Body.String()) != tc.body {
+			t.Errorf("Body mismatch for %q, got %s", tc.accept, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package blade
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Outline is the structure of a template file with the location of its directives in the source, for linters,
+// documentation generators and editor integrations. Unlike ParsedFile, which holds compiled template text,
+// it holds the source of the directive arguments and blocks. It encodes to JSON with LSP ranges.
+type Outline struct {
+	Name     string           `json:"name"`
+	Path     string           `json:"path,omitempty"`
+	Extends  string           `json:"extends,omitempty"`
+	Sections []OutlineSection `json:"sections"`
+	Yields   []OutlineYield   `json:"yields"`
+	Stacks   []OutlineStack   `json:"stacks"`
+	Pushes   []OutlinePush    `json:"pushes"`
+	Includes []OutlineInclude `json:"includes"`
+}
+
+// OutlineSection is a @section directive.
+type OutlineSection struct {
+	Name string `json:"name"`
+	// Content is the source of the block, or the value of an inline section as written
+	Content string `json:"content"`
+	// Inline reports whether the section is written @section('name', value)
+	Inline bool `json:"inline"`
+	// Policy is the policy argument of the section, empty when omitted
+	Policy SectionPolicy `json:"policy,omitempty"`
+	Range  Range         `json:"range"`
+}
+
+// OutlineYield is a @yield or @yieldIf directive.
+type OutlineYield struct {
+	Name string `json:"name"`
+	// Default is the default content as written, empty without default
+	Default string `json:"default,omitempty"`
+	// Condition is the condition of @yieldIf
+	Condition string `json:"condition,omitempty"`
+	Range     Range  `json:"range"`
+}
+
+// OutlineStack is a @stack directive.
+type OutlineStack struct {
+	Name  string `json:"name"`
+	Range Range  `json:"range"`
+}
+
+// OutlinePush is a @push block.
+type OutlinePush struct {
+	Stack   string `json:"stack"`
+	Key     string `json:"key,omitempty"`
+	Content string `json:"content"`
+	// Prepend reports a @prependOnce
+	Prepend bool  `json:"prepend,omitempty"`
+	Range   Range `json:"range"`
+}
+
+// OutlineInclude is an @include directive.
+type OutlineInclude struct {
+	Name string `json:"name"`
+	// Pipeline is the data passed to the partial as written, "." when omitted by @include
+	Pipeline string `json:"pipeline"`
+	Memo     bool   `json:"memo,omitempty"`
+	// Isolated reports whether the partial only receives the data passed by @includeData
+	Isolated bool  `json:"isolated,omitempty"`
+	Range    Range `json:"range"`
+}
+
+// Parse parses the Blade source of the template name without loading it, for tools checking unsaved files.
+func (e *Engine) Parse(name string, source string) (*ParsedFile, error) {
+	return e.parseFile(normalizeName(name), source)
+}
+
+// Outline returns the outline of the loaded template name.
+func (e *Engine) Outline(name string) (*Outline, error) {
+	f, ok := lookupFile(e.set.Load().parsedFiles, normalizeName(name))
+	if !ok {
+		return nil, fmt.Errorf("template %s not loaded", name)
+	}
+	return f.Outline(), nil
+}
+
+// Outline returns the sections, yields, stacks, pushes and includes of the file, in source order.
+func (p *ParsedFile) Outline() *Outline {
+	o := &Outline{
+		Name:     p.Name,
+		Path:     p.Path,
+		Extends:  p.Extends,
+		Sections: []OutlineSection{},
+		Yields:   []OutlineYield{},
+		Stacks:   []OutlineStack{},
+		Pushes:   []OutlinePush{},
+		Includes: []OutlineInclude{},
+	}
+	raw := p.Raw
+	for _, d := range p.Directives {
+		if len(d.Args) == 0 {
+			continue
+		}
+		name, ok := parseQuotedDirectiveName(d.Args[0])
+		if !ok {
+			continue
+		}
+		body := strings.TrimSpace(raw[d.Body.Start:d.Body.End])
+		switch d.Name {
+		case "section":
+			section := OutlineSection{Name: name, Content: body, Range: d.Span.Range(raw)}
+			if _, policy, err := parseSectionPolicy(d.Args); err == nil {
+				section.Policy = policy
+			}
+			if args := sectionArgs(d.Args); len(args) > 1 {
+				section.Content = strings.TrimSpace(args[1])
+				section.Inline = true
+			} else if d.Body == (Span{}) {
+				continue
+			}
+			o.Sections = append(o.Sections, section)
+		case "yield", "yieldIf":
+			y := OutlineYield{Name: name, Range: d.Span.Range(raw)}
+			defaultArg := 1
+			if d.Name == "yieldIf" {
+				if len(d.Args) < 2 {
+					continue
+				}
+				y.Condition = strings.TrimSpace(d.Args[1])
+				defaultArg = 2
+			}
+			if len(d.Args) > defaultArg {
+				y.Default = strings.TrimSpace(d.Args[defaultArg])
+			}
+			o.Yields = append(o.Yields, y)
+		case "stack":
+			o.Stacks = append(o.Stacks, OutlineStack{Name: name, Range: d.Span.Range(raw)})
+		case "push", "prependOnce":
+			if d.Body == (Span{}) {
+				continue
+			}
+			push := OutlinePush{Stack: name, Content: body, Prepend: d.Name == "prependOnce", Range: d.Span.Range(raw)}
+			for _, arg := range d.Args[1:] {
+				if argName, value, ok := parseNamedDirectiveArg(arg); ok && argName == "key" {
+					push.Key, _ = parseQuotedDirectiveName(value)
+				}
+			}
+			o.Pushes = append(o.Pushes, push)
+		case "include":
+			include := OutlineInclude{Name: name, Pipeline: ".", Range: d.Span.Range(raw)}
+			args := d.Args
+			if argName, value, ok := parseNamedDirectiveArg(args[len(args)-1]); ok && len(args) > 1 && argName == "memo" {
+				include.Memo = value == "true"
+				args = args[:len(args)-1]
+			}
+			if len(args) > 1 && strings.TrimSpace(args[1]) != "" {
+				include.Pipeline = strings.TrimSpace(args[1])
+			}
+			o.Includes = append(o.Includes, include)
+		case "includeData":
+			include := OutlineInclude{Name: name, Isolated: true, Range: d.Span.Range(raw)}
+			data := make([]string, 0, len(d.Args)-1)
+			for _, arg := range d.Args[1:] {
+				data = append(data, strings.TrimSpace(arg))
+			}
+			include.Pipeline = strings.Join(data, ", ")
+			o.Includes = append(o.Includes, include)
+		}
+	}
+	return o
+}
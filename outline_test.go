@@ -0,0 +1,77 @@
+package blade
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOutline(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": "<title>@yield('title', 'Home')</title>\n@yieldIf('aside', .ShowAside)\n@stack('scripts')",
+		"page.blade": "@extends('layout')\n@section('title', 'Page')\n@section('aside')\n  @include('partials/nav', .Links, memo: true)\n@endsection\n" +
+			"@push('scripts', key: 'app')<script></script>@endpush",
+		"partials/nav.blade": "<nav></nav>",
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	outline, err := engine.Outline("page")
+	if err != nil {
+		t.Fatalf("Outline failed: %v", err)
+	}
+	expected := &Outline{
+		Name:    "page",
+		Path:    "page.blade",
+		Extends: "layout",
+		Sections: []OutlineSection{
+			{Name: "title", Content: "'Page'", Inline: true, Range: Range{Start: Position{1, 0}, End: Position{1, 25}}},
+			{Name: "aside", Content: "@include('partials/nav', .Links, memo: true)", Range: Range{Start: Position{2, 0}, End: Position{4, 11}}},
+		},
+		Yields: []OutlineYield{},
+		Stacks: []OutlineStack{},
+		Pushes: []OutlinePush{
+			{Stack: "scripts", Key: "app", Content: "<script></script>", Range: Range{Start: Position{5, 0}, End: Position{5, 53}}},
+		},
+		Includes: []OutlineInclude{
+			{Name: "partials/nav", Pipeline: ".Links", Memo: true, Range: Range{Start: Position{3, 2}, End: Position{3, 46}}},
+		},
+	}
+	if !reflect.DeepEqual(outline, expected) {
+		t.Errorf("Outline mismatch.\nExp: %+v\nGot: %+v", expected, outline)
+	}
+
+	outline, err = engine.Outline("layout")
+	if err != nil {
+		t.Fatalf("Outline failed: %v", err)
+	}
+	expectedYields := []OutlineYield{
+		{Name: "title", Default: "'Home'", Range: Range{Start: Position{0, 7}, End: Position{0, 30}}},
+		{Name: "aside", Condition: ".ShowAside", Range: Range{Start: Position{1, 0}, End: Position{1, 29}}},
+	}
+	if !reflect.DeepEqual(outline.Yields, expectedYields) {
+		t.Errorf("Yields mismatch.\nExp: %+v\nGot: %+v", expectedYields, outline.Yields)
+	}
+	if expectedStacks := []OutlineStack{{Name: "scripts", Range: Range{Start: Position{2, 0}, End: Position{2, 17}}}}; !reflect.DeepEqual(outline.Stacks, expectedStacks) {
+		t.Errorf("Stacks mismatch.\nExp: %+v\nGot: %+v", expectedStacks, outline.Stacks)
+	}
+
+	if _, err := engine.Outline("missing"); err == nil {
+		t.Error("Expected an error for a template not loaded")
+	}
+}
+
+func TestParse(t *testing.T) {
+	engine := NewEngine("dir")
+	f, err := engine.Parse("draft", "@section('content')Draft@endsection")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if f.Sections["content"] != "Draft" || len(f.Outline().Sections) != 1 {
+		t.Errorf("Unexpected parse result: %+v", f)
+	}
+	if _, err := engine.Parse("broken", "@section('content')Draft"); err == nil {
+		t.Error("Expected a parse error")
+	}
+}
@@ -0,0 +1,79 @@
+package blade
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// reTemplateErrorLine matches the line of the compiled template text in html/template errors: template: pages/home:16: ...
+var reTemplateErrorLine = regexp.MustCompile(`template: [^:]+:(\d+):`)
+
+var errorOverlayTemplate = template.Must(template.New("overlay").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Template error: {{ .Entry }}</title>
+<style>
+body { margin: 0; padding: 2rem; background: #1e1e1e; color: #eee; font: 14px/1.5 ui-monospace, monospace; }
+h1 { color: #ff6b6b; font-size: 1.25rem; }
+pre { background: #111; padding: 1rem; overflow: auto; }
+.error { white-space: pre-wrap; }
+.highlight { background: #5c1f1f; display: block; }
+.line { color: #777; user-select: none; }
+</style>
+</head>
+<body>
+<h1>Failed to compile {{ .Entry }}</h1>
+<pre class="error">{{ .Error }}</pre>
+{{ if .Lines }}<p>Compiled template:</p>
+<pre>{{ range .Lines }}<span{{ if .Highlight }} class="highlight"{{ end }}><span class="line">{{ printf "%4d" .Number }} </span>{{ .Text }}
+</span>{{ end }}</pre>{{ end }}
+</body>
+</html>
+`))
+
+// CompileError is the error of a render of a view that failed to parse or compile in DevMode, returned once
+// the error overlay of HTML views is written, so handlers respond with an error status.
+type CompileError struct {
+	// Entry is the view rendered
+	Entry string
+	Err   error
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("failed to compile %s: %v", e.Entry, e.Err)
+}
+
+func (e *CompileError) Unwrap() error {
+	return e.Err
+}
+
+type overlayLine struct {
+	Number    int
+	Text      string
+	Highlight bool
+}
+
+// renderErrorOverlay writes an HTML page describing the compile error of a broken view in DevMode,
+// with the lines of the compiled template text around the error when it is known.
+func renderErrorOverlay(w io.Writer, entry string, err error, text string) error {
+	var lines []overlayLine
+	if m := reTemplateErrorLine.FindStringSubmatch(err.Error()); m != nil && text != "" {
+		errLine, _ := strconv.Atoi(m[1])
+		for i, line := range strings.Split(text, "\n") {
+			number := i + 1
+			if number >= errLine-5 && number <= errLine+5 {
+				lines = append(lines, overlayLine{Number: number, Text: line, Highlight: number == errLine})
+			}
+		}
+	}
+	return errorOverlayTemplate.Execute(w, map[string]any{
+		"Entry": entry,
+		"Error": err.Error(),
+		"Lines": lines,
+	})
+}
@@ -0,0 +1,175 @@
+package blade
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// overrideSet renders views with override templates, like the templates of a tenant or a theme, over the
+// engine templates. Only the views depending on an override are compiled, the others are rendered from the
+// engine set, so many override sets do not multiply the memory of the engine.
+type overrideSet struct {
+	e *Engine
+	// label prefixes errors, like "tenant acme"
+	label string
+	// overrides parses the override templates
+	overrides func() (map[string]*ParsedFile, error)
+	// version identifies the override templates, changing versions are loaded again, nil when they
+	// only change with Load
+	version func() (string, error)
+	mu      sync.Mutex
+	state   atomic.Pointer[overrideState]
+}
+
+// overrideState is the compiled set of the overrides and the engine set and version it was compiled against.
+type overrideState struct {
+	base    *compiledSet
+	set     *compiledSet
+	version string
+}
+
+// Load reads the override templates and compiles the views depending on them.
+func (o *overrideSet) Load() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	version, err := o.currentVersion()
+	if err != nil {
+		return err
+	}
+	return o.load(o.e.set.Load(), version)
+}
+
+func (o *overrideSet) currentVersion() (string, error) {
+	if o.version == nil {
+		return "", nil
+	}
+	version, err := o.version()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", o.label, err)
+	}
+	return version, nil
+}
+
+func (o *overrideSet) load(base *compiledSet, version string) error {
+	overrides, err := o.overrides()
+	if err != nil {
+		return fmt.Errorf("%s: %w", o.label, err)
+	}
+
+	set := newCompiledSet()
+	set.parsedFiles = maps.Clone(base.parsedFiles)
+	maps.Copy(set.parsedFiles, overrides)
+	for name, f := range set.parsedFiles {
+		if !o.e.EntryFilter(f) || !dependsOnAny(set.parsedFiles, name, overrides) {
+			continue
+		}
+		tmplText, err := o.e.buildTemplateText(set.parsedFiles, f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", o.label, err)
+		}
+		if !o.e.DisableDebugTemplates {
+			set.debugTemplates[name] = tmplText
+		}
+		tmpl, err := o.e.compileTemplate(name, tmplText, f.XML)
+		if err != nil {
+			return fmt.Errorf("%s: %w", o.label, err)
+		}
+		tmpl.textSize = len(tmplText)
+		set.templates[name] = tmpl
+	}
+
+	o.state.Store(&overrideState{base: base, set: set, version: version})
+	return nil
+}
+
+// resolve returns the compiled set rendering entry, loading the overrides when the engine reloaded
+// or their version changed.
+func (o *overrideSet) resolve(entry string) (*compiledSet, error) {
+	base := o.e.set.Load()
+	version, err := o.currentVersion()
+	if err != nil {
+		return nil, err
+	}
+	state := o.state.Load()
+	if state == nil || state.base != base || state.version != version {
+		o.mu.Lock()
+		state = o.state.Load()
+		if state == nil || state.base != base || state.version != version {
+			if err := o.load(base, version); err != nil {
+				o.mu.Unlock()
+				return nil, err
+			}
+			state = o.state.Load()
+		}
+		o.mu.Unlock()
+	}
+	if _, ok := state.set.templates[normalizeName(entry)]; ok {
+		return state.set, nil
+	}
+	return state.base, nil
+}
+
+// parseOverrides parses the templates of fsys, failing on the first parse error.
+func (e *Engine) parseOverrides(fsys fs.FS) (map[string]*ParsedFile, error) {
+	var parseErr error
+	files, err := e.parseFS(fsys, ".", func(path string, raw string, err error) {
+		if parseErr == nil {
+			parseErr = err
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	return files, nil
+}
+
+// dependsOnAny reports whether the view name, its layouts, includes or imports, recursively, is one of files.
+func dependsOnAny(all map[string]*ParsedFile, name string, files map[string]*ParsedFile) bool {
+	found := false
+	visitDependencies(all, name, func(dep string) bool {
+		_, found = files[dep]
+		return !found
+	})
+	return found
+}
+
+// visitDependencies calls fn for name and every file it depends on through @extends, @include and @import,
+// until fn returns false.
+func visitDependencies(files map[string]*ParsedFile, name string, fn func(name string) bool) {
+	visited := map[string]struct{}{}
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		if _, ok := visited[name]; ok {
+			return true
+		}
+		visited[name] = struct{}{}
+		if !fn(name) {
+			return false
+		}
+		f, ok := files[name]
+		if !ok {
+			return true
+		}
+		if f.Extends != "" && !visit(f.Extends) {
+			return false
+		}
+		for _, dep := range sortedKeys(f.Includes) {
+			if !visit(dep) {
+				return false
+			}
+		}
+		for _, dep := range sortedKeys(f.Imports) {
+			if !visit(dep) {
+				return false
+			}
+		}
+		return true
+	}
+	visit(name)
+}
@@ -0,0 +1,213 @@
+package blade
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template/parse"
+)
+
+// entryParams returns the @param declarations of f and then its layouts, the child declaration wins.
+func entryParams(files map[string]*ParsedFile, f *ParsedFile) []Param {
+	var params []Param
+	visited := map[string]struct{}{}
+	for ; f != nil; f = files[f.Extends] {
+		if _, ok := visited[f.Name]; ok {
+			break
+		}
+		visited[f.Name] = struct{}{}
+		for _, param := range f.Params {
+			if !slices.ContainsFunc(params, func(p Param) bool { return p.Name == param.Name }) {
+				params = append(params, param)
+			}
+		}
+	}
+	return params
+}
+
+// resolveParamType returns the Go type of a @param type name registered in ParamTypes,
+// pointer and slice types of registered types are resolved too.
+func (e *Engine) resolveParamType(name string) (reflect.Type, bool) {
+	name = strings.TrimSpace(name)
+	if t, ok := e.ParamTypes[name]; ok {
+		return t, true
+	}
+	if elem, ok := strings.CutPrefix(name, "*"); ok {
+		if t, ok := e.resolveParamType(elem); ok {
+			return reflect.PointerTo(t), true
+		}
+	}
+	if elem, ok := strings.CutPrefix(name, "[]"); ok {
+		if t, ok := e.resolveParamType(elem); ok {
+			return reflect.SliceOf(t), true
+		}
+	}
+	return nil, false
+}
+
+// paramWarnings checks the field references of the declared params of the entry f against their Go types.
+// Only references to the top level data are checked: the entry body, sections and stacks outside
+// range and with blocks, and $ variables.
+func (e *Engine) paramWarnings(files map[string]*ParsedFile, f *ParsedFile, tmpl templateSet) []Diagnostic {
+	types := map[string]reflect.Type{}
+	typeNames := map[string]string{}
+	for _, param := range entryParams(files, f) {
+		if t, ok := e.resolveParamType(param.Type); ok {
+			types[param.Name] = t
+			typeNames[param.Name] = param.Type
+		}
+	}
+	if len(types) == 0 {
+		return nil
+	}
+
+	var warnings []Diagnostic
+	reported := map[string]struct{}{}
+	check := func(idents []string) {
+		if len(idents) < 2 {
+			return
+		}
+		t, ok := types[idents[0]]
+		if !ok {
+			return
+		}
+		field, owner, ok := missingField(t, idents[1:])
+		if ok {
+			return
+		}
+		ref := "." + strings.Join(idents, ".")
+		if _, ok := reported[ref]; ok {
+			return
+		}
+		reported[ref] = struct{}{}
+		ownerName := owner.String()
+		if owner == t {
+			ownerName = typeNames[idents[0]]
+		}
+		warnings = append(warnings, referenceDiagnostic(files, f, ref, fmt.Sprintf(`field "%s" not found in %s (%s)`, field, ownerName, ref)))
+	}
+
+	for _, tree := range tmpl.trees() {
+		if tree.Name != f.Name && !strings.HasPrefix(tree.Name, sectionNamePrefix) && !strings.HasPrefix(tree.Name, stackNamePrefix) {
+			continue
+		}
+		walkDataReferences(tree.Root, true, check)
+	}
+	return sortDiagnostics(warnings)
+}
+
+// referenceDiagnostic returns a warning located at the first occurrence of ref in f or its layouts.
+func referenceDiagnostic(files map[string]*ParsedFile, f *ParsedFile, ref string, message string) Diagnostic {
+	visited := map[string]struct{}{}
+	for file := f; file != nil; file = files[file.Extends] {
+		if _, ok := visited[file.Name]; ok {
+			break
+		}
+		visited[file.Name] = struct{}{}
+		if idx := strings.Index(file.Raw, ref); idx >= 0 {
+			return newDiagnostic(file, idx, idx+len(ref), SeverityWarning, DiagnosticUnknownField, message)
+		}
+	}
+	return newDiagnostic(f, 0, 0, SeverityWarning, DiagnosticUnknownField, message)
+}
+
+// missingField follows path from t through fields, methods and map values,
+// returning the first name not found and the type it was looked up in.
+func missingField(t reflect.Type, path []string) (string, reflect.Type, bool) {
+	_, name, owner, ok := followFields(t, path)
+	return name, owner, ok
+}
+
+// followFields follows path from t through fields, methods and map values, returning the type at the end of
+// the path, nil when it is only known at render, or the first name not found and the type it was looked up in.
+func followFields(t reflect.Type, path []string) (reflect.Type, string, reflect.Type, bool) {
+	for _, name := range path {
+		if method, ok := t.MethodByName(name); ok {
+			if method.Type.NumOut() == 0 {
+				return nil, name, t, false
+			}
+			t = method.Type.Out(0)
+			continue
+		}
+		if t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface {
+			if method, ok := reflect.PointerTo(t).MethodByName(name); ok {
+				if method.Type.NumOut() == 0 {
+					return nil, name, t, false
+				}
+				t = method.Type.Out(0)
+				continue
+			}
+		}
+
+		owner := t
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := t.FieldByName(name)
+			if !ok || !field.IsExported() {
+				return nil, name, owner, false
+			}
+			t = field.Type
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Interface:
+			// the dynamic type is only known at render
+			return nil, "", nil, true
+		default:
+			return nil, name, owner, false
+		}
+	}
+	return t, "", nil, true
+}
+
+// walkDataReferences calls fn with the identifiers of field chains evaluated against the top level data,
+// like [User Name] for .User.Name while the dot is the data, or $.User.Name anywhere.
+func walkDataReferences(node parse.Node, rootDot bool, fn func(idents []string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkDataReferences(child, rootDot, fn)
+		}
+	case *parse.ActionNode:
+		walkDataReferences(n.Pipe, rootDot, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkDataReferences(cmd, rootDot, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkDataReferences(arg, rootDot, fn)
+		}
+	case *parse.FieldNode:
+		if rootDot {
+			fn(n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 0 && n.Ident[0] == "$" {
+			fn(n.Ident[1:])
+		}
+	case *parse.IfNode:
+		walkDataReferences(n.Pipe, rootDot, fn)
+		walkDataReferences(n.List, rootDot, fn)
+		walkDataReferences(n.ElseList, rootDot, fn)
+	case *parse.RangeNode:
+		walkDataReferences(n.Pipe, rootDot, fn)
+		walkDataReferences(n.List, false, fn)
+		walkDataReferences(n.ElseList, rootDot, fn)
+	case *parse.WithNode:
+		walkDataReferences(n.Pipe, rootDot, fn)
+		walkDataReferences(n.List, false, fn)
+		walkDataReferences(n.ElseList, rootDot, fn)
+	case *parse.TemplateNode:
+		walkDataReferences(n.Pipe, rootDot, fn)
+	}
+}
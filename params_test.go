@@ -0,0 +1,58 @@
+package blade
+
+import (
+	"reflect"
+	"testing"
+)
+
+type paramTestProfile struct {
+	Bio string
+}
+
+type paramTestUser struct {
+	Name    string
+	Profile *paramTestProfile
+	Meta    map[string]string
+}
+
+func (u paramTestUser) DisplayName() string {
+	return u.Name
+}
+
+func TestParamWarnings(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `@yield('content')`,
+		"page.blade": `@extends('layout')
+@param('User', 'models.User')
+@param('Items', '[]models.User')
+@section('content'){{ .User.Name }} {{ .User.DisplayName }} {{ .User.Profile.Bio }} {{ .User.Meta.key }}
+{{ .User.Email }} {{ .User.Profile.Avatar }}
+{{ range .Items }}{{ .Name }} {{ .Anything }} {{ $.User.Age }}{{ end }}
+{{ .Unknown.Field }}@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.ParamTypes["models.User"] = reflect.TypeFor[paramTestUser]()
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var messages []string
+	for _, w := range engine.Warnings() {
+		if w.Code == DiagnosticUnknownField {
+			messages = append(messages, w.Message)
+		}
+	}
+	expected := []string{
+		`field "Email" not found in models.User (.User.Email)`,
+		`field "Avatar" not found in *blade.paramTestProfile (.User.Profile.Avatar)`,
+		`field "Age" not found in models.User (.User.Age)`,
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Warnings mismatch.\nExp: %q\nGot: %q", expected, messages)
+	}
+
+	warning := engine.Warnings()[0]
+	if warning.File != "page.blade" || warning.Range.Start != (Position{Line: 4, Character: 3}) {
+		t.Errorf("unexpected warning location %v", warning)
+	}
+}
@@ -7,6 +7,8 @@
 
 type ParsedFile struct {
 	Name string
+	// Path is the path of the file in the engine fs
+	Path string
 	// Raw is the raw file content
 	Raw string
 	// Extends is the file to extend
@@ -17,16 +19,48 @@ type ParsedFile struct {
 	Yields map[string]string
 	// Sections is a map of section names to content
 	Sections map[string]string
+	// SectionPolicies is a map of section names to the policy given to @section
+	SectionPolicies map[string]SectionPolicy
 	// Stacks is a map of stack names
 	Stacks map[string]struct{}
 	// PushStacks is a map of stack names to values to push
-	PushStacks map[string][]string
+	PushStacks map[string][]StackPush
+	// Snippets is a map of @define snippet names to content
+	Snippets map[string]string
+	// Macros is a map of @macro names to content, including argument bindings
+	Macros map[string]string
+	// Imports is a list of files whose macros are callable from this file
+	Imports map[string]struct{}
+	// Deferred is a map of @defer block names to content, rendered by fragment requests
+	Deferred map[string]string
+	// Params are the @param data contract declarations, in order
+	Params []Param
+	// Directives locates the directives of Raw, in source order
+	Directives []DirectiveSpan
 	// StandaloneBody is the body of the file without sections and includes
 	StandaloneBody string
+	// XML reports whether the file is compiled with XML escaping instead of html/template
+	XML bool
 	// ParsedAt is the time when the file was parsed in unix milliseconds
 	ParsedAt int64
 }
 
+// Param is a @param declaration of a top level data field and its Go type name
+type Param struct {
+	Name string
+	Type string
+}
+
+// StackPush is a single @push block
+type StackPush struct {
+	// Key deduplicates pushes to the same stack, empty means always pushed
+	Key string
+	// Content is the pushed template content
+	Content string
+	// Prepend renders the content before the pushes of the stack, see @prependOnce
+	Prepend bool
+}
+
 // ToTemplateString converts the parsed file to a template string.
 func (p *ParsedFile) ToTemplateString(ctx *CompileContext) (body string, def string, err error) {
 	var bodyBuilder strings.Builder
@@ -49,29 +83,70 @@ func (p *ParsedFile) ToTemplateString(ctx *CompileContext) (body string, def str
 		defBuilder.WriteString(stackNamePrefix)
 		defBuilder.WriteString(name)
 		defBuilder.WriteString("\" }}")
-		// Pop from stack
+		// Pop from stack, the prepended contents first, keyed pushes are rendered only the first time their key
+		// appears
 		size := len(ctx.PushStacks[name])
-		for i := range ctx.PushStacks[name] {
-			if i > 0 {
-				defBuilder.WriteString("\n")
+		pushedKeys := map[string]struct{}{}
+		written := 0
+		for _, prepend := range []bool{true, false} {
+			for i := range ctx.PushStacks[name] {
+				push := ctx.PushStacks[name][size-1-i]
+				if push.Prepend != prepend {
+					continue
+				}
+				if push.Key != "" {
+					if _, ok := pushedKeys[push.Key]; ok {
+						continue
+					}
+					pushedKeys[push.Key] = struct{}{}
+				}
+				if written > 0 {
+					defBuilder.WriteString("\n")
+				}
+				defBuilder.WriteString(push.Content)
+				written++
 			}
-			defBuilder.WriteString(ctx.PushStacks[name][size-1-i])
 		}
 		defBuilder.WriteString("{{ end }}")
 	}
 
 	for name, s := range p.Sections {
+		// sections already filled are combined with the sections of the view extending the file, following their
+		// policies, and ignored in partials
 		if _, ok := ctx.FilledSections[name]; ok {
-			continue
+			contents := ctx.SectionContents[name]
+			child, found := lookupFile(ctx.Files, contents[len(contents)-1].FileName)
+			if !found || child.Extends != p.Name {
+				continue
+			}
 		}
+		ctx.SectionContents[name] = append(ctx.SectionContents[name], SectionContent{
+			FileName: p.Name,
+			Content:  s,
+			Policy:   ctx.sectionPolicy(p, name),
+		})
+		ctx.FilledSections[name] = struct{}{}
+	}
+
+	for name, s := range p.Snippets {
 		defBuilder.WriteString("{{ define \"")
-		defBuilder.WriteString(sectionNamePrefix)
+		defBuilder.WriteString(snippetTemplateName(p.Name, name))
+		defBuilder.WriteString("\" }}")
+		defBuilder.WriteString(s)
+		defBuilder.WriteString("{{ end }}")
+	}
+
+	for name, s := range p.Deferred {
+		defBuilder.WriteString("{{ define \"")
+		defBuilder.WriteString(deferNamePrefix)
 		defBuilder.WriteString(name)
 		defBuilder.WriteString("\" }}")
 		defBuilder.WriteString(s)
 		defBuilder.WriteString("{{ end }}")
+	}
 
-		ctx.FilledSections[name] = struct{}{}
+	if err := p.writeMacros(ctx, &defBuilder); err != nil {
+		return "", "", err
 	}
 
 	for name, defaultValue := range p.Yields {
@@ -88,7 +163,7 @@ func (p *ParsedFile) ToTemplateString(ctx *CompileContext) (body string, def str
 	if p.Extends == "" {
 		bodyBuilder.WriteString(p.StandaloneBody)
 	} else {
-		parent, found := ctx.Files[p.Extends]
+		parent, found := lookupFile(ctx.Files, p.Extends)
 		if !found {
 			return "", "", fmt.Errorf(`[%s] template "%s" not found to extends`, p.Name, p.Extends)
 		}
@@ -100,14 +175,33 @@ func (p *ParsedFile) ToTemplateString(ctx *CompileContext) (body string, def str
 		defBuilder.WriteString(defText)
 	}
 
+	// the file filling a section first writes it, once its layouts filled their content
+	for name := range p.Sections {
+		if contents := ctx.SectionContents[name]; len(contents) == 0 || contents[0].FileName != p.Name {
+			continue
+		}
+		content, err := ctx.combineSections(name)
+		if err != nil {
+			return "", "", err
+		}
+		defBuilder.WriteString("{{ define \"")
+		defBuilder.WriteString(sectionNamePrefix)
+		defBuilder.WriteString(name)
+		defBuilder.WriteString("\" }}")
+		defBuilder.WriteString(content)
+		defBuilder.WriteString("{{ end }}")
+	}
+
 	for partialName := range p.Includes {
 		if _, ok := ctx.FilledIncludes[partialName]; ok {
 			continue
 		}
-		partial, found := ctx.Files[partialName]
+		partial, found := lookupFile(ctx.Files, partialName)
 		if !found {
 			return "", "", fmt.Errorf(`[%s] template "%s" not found to include`, p.Name, partialName)
 		}
+		// mark as filled before compiling, so partials including each other don't expand forever
+		ctx.FilledIncludes[partialName] = struct{}{}
 		templateText, defText, err := partial.ToTemplateString(ctx)
 		if err != nil {
 			return "", "", err
@@ -117,11 +211,47 @@ func (p *ParsedFile) ToTemplateString(ctx *CompileContext) (body string, def str
 		defBuilder.WriteString(partialNamePrefix)
 		defBuilder.WriteString(partialName)
 		defBuilder.WriteString("\" }}")
+		if ctx.cycles.has(ctx.Files, partialName) {
+			// partials including each other, like a menu rendering its children, render to a depth limit
+			templateText = fmt.Sprintf(`{{ __enterInclude %q }}%s{{ __leaveInclude %q }}`, partialName, templateText, partialName)
+		}
 		defBuilder.WriteString(templateText)
 		defBuilder.WriteString("{{ end }}")
-
-		ctx.FilledIncludes[partialName] = struct{}{}
 	}
 
 	return bodyBuilder.String(), defBuilder.String(), nil
 }
+
+// writeMacros writes the macros of the file and its imports, each macro is defined only once.
+func (p *ParsedFile) writeMacros(ctx *CompileContext, defBuilder *strings.Builder) error {
+	for name, s := range p.Macros {
+		if fileName, ok := ctx.Macros[name]; ok {
+			if fileName == p.Name {
+				continue
+			}
+			return fmt.Errorf(`[%s] duplicate macro name "%s", already defined in file "%s"`, p.Name, name, fileName)
+		}
+		ctx.Macros[name] = p.Name
+		defBuilder.WriteString("{{ define \"")
+		defBuilder.WriteString(macroNamePrefix)
+		defBuilder.WriteString(name)
+		defBuilder.WriteString("\" }}")
+		defBuilder.WriteString(s)
+		defBuilder.WriteString("{{ end }}")
+	}
+
+	for importName := range p.Imports {
+		if _, ok := ctx.Imports[importName]; ok {
+			continue
+		}
+		ctx.Imports[importName] = struct{}{}
+		imported, found := lookupFile(ctx.Files, importName)
+		if !found {
+			return fmt.Errorf(`[%s] template "%s" not found to import`, p.Name, importName)
+		}
+		if err := imported.writeMacros(ctx, defBuilder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// PDFConverter converts a rendered HTML document to PDF, see the pdf package for adapters.
+type PDFConverter interface {
+	ConvertHTML(ctx context.Context, html io.Reader, w io.Writer) error
+}
+
+// RenderPDF renders entry to a buffer and writes it converted to PDF by converter into w.
+func (e *Engine) RenderPDF(ctx context.Context, w io.Writer, converter PDFConverter, entry string, data any) error {
+	var buf bytes.Buffer
+	if err := e.RenderContext(ctx, &buf, entry, data); err != nil {
+		return err
+	}
+	return converter.ConvertHTML(ctx, &buf, w)
+}
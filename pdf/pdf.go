@@ -0,0 +1,86 @@
+// Package pdf provides HTML to PDF backends for blade.Engine.RenderPDF.
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// Gotenberg converts HTML with the Chromium route of a Gotenberg server.
+type Gotenberg struct {
+	// URL is the base URL of the server, like http://localhost:3000
+	URL string
+	// Client sends the requests, nil uses http.DefaultClient
+	Client *http.Client
+	// Fields are extra form fields like paperWidth, marginTop or printBackground
+	Fields map[string]string
+}
+
+// ConvertHTML implements blade.PDFConverter.
+func (g Gotenberg) ConvertHTML(ctx context.Context, html io.Reader, w io.Writer) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for key, value := range g.Fields {
+		if err := form.WriteField(key, value); err != nil {
+			return err
+		}
+	}
+	file, err := form.CreateFormFile("files", "index.html")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, html); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(g.URL, "/")+"/forms/chromium/convert/html", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("gotenberg: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// Command converts HTML with an external program reading HTML from stdin and writing PDF to stdout,
+// like Command{Name: "wkhtmltopdf", Args: []string{"--quiet", "-", "-"}}.
+type Command struct {
+	Name string
+	Args []string
+}
+
+// ConvertHTML implements blade.PDFConverter.
+func (c Command) ConvertHTML(ctx context.Context, html io.Reader, w io.Writer) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Stdin = html
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", c.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
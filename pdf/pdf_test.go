@@ -0,0 +1,57 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGotenberg(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/forms/chromium/convert/html" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.FormValue("paperWidth") != "8.27" {
+			http.Error(w, "missing paperWidth", http.StatusBadRequest)
+			return
+		}
+		file, header, err := r.FormFile("files")
+		if err != nil || header.Filename != "index.html" {
+			http.Error(w, "missing index.html", http.StatusBadRequest)
+			return
+		}
+		html, _ := io.ReadAll(file)
+		w.Write(append([]byte("%PDF "), html...))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	g := Gotenberg{URL: server.URL + "/", Fields: map[string]string{"paperWidth": "8.27"}}
+	if err := g.ConvertHTML(context.Background(), strings.NewReader("<h1>Invoice</h1>"), &buf); err != nil {
+		t.Fatalf("ConvertHTML failed: %v", err)
+	}
+	if buf.String() != "%PDF <h1>Invoice</h1>" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	g.Fields = nil
+	if err := g.ConvertHTML(context.Background(), strings.NewReader("<h1>Invoice</h1>"), &buf); err == nil {
+		t.Errorf("expected an error for a failed conversion")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	var buf bytes.Buffer
+	c := Command{Name: "cat"}
+	if err := c.ConvertHTML(context.Background(), strings.NewReader("<h1>Invoice</h1>"), &buf); err != nil {
+		t.Skipf("cat is not available: %v", err)
+	}
+	if buf.String() != "<h1>Invoice</h1>" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
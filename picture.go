@@ -0,0 +1,126 @@
+package blade
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// DefaultPictureWidths are the default breakpoint widths of the srcset candidates of @picture.
+var DefaultPictureWidths = []int{320, 640, 960, 1280, 1920}
+
+// PictureOptions configures the images rendered by @picture.
+type PictureOptions struct {
+	// Widths are the breakpoint widths of the srcset candidates, DefaultPictureWidths when empty
+	Widths []int
+	// Formats are the formats of the sources listed before the fallback image, in order of preference,
+	// like "avif" and "webp". The fallback image keeps the format of the source.
+	Formats []string
+	// Resize builds the URL of src resized to width and converted to format, an empty format keeps the format
+	// of the source. Without Resize, pictures have a single image without srcset.
+	Resize func(src string, width int, format string) string
+}
+
+// Picture is the data of the built-in blade/picture component.
+type Picture struct {
+	// Src is the fallback image URL, resized to the largest width
+	Src string
+	// Srcset are the candidates of the fallback image
+	Srcset  string
+	Sources []PictureSource
+	Alt     string
+	Sizes   string
+	Class   string
+	Width   string
+	Height  string
+	// Loading is "lazy" unless set to "eager", for images visible without scrolling
+	Loading string
+}
+
+// PictureSource is a source element of a picture, in a converted format.
+type PictureSource struct {
+	Type   string
+	Srcset string
+}
+
+// pictureFields maps the named arguments of @picture to the fields of Picture.
+var pictureFields = map[string]func(p *Picture, value string){
+	"alt":     func(p *Picture, value string) { p.Alt = value },
+	"sizes":   func(p *Picture, value string) { p.Sizes = value },
+	"class":   func(p *Picture, value string) { p.Class = value },
+	"width":   func(p *Picture, value string) { p.Width = value },
+	"height":  func(p *Picture, value string) { p.Height = value },
+	"loading": func(p *Picture, value string) { p.Loading = value },
+}
+
+// parsePictureDirective converts @picture to an include of the built-in blade/picture component:
+// @picture(.Cover, alt: .Title) => @include('blade/picture', (__picture (.Cover) "alt" (.Title)))
+func parsePictureDirective(args []string) (string, bool) {
+	if len(args) == 0 || strings.TrimSpace(args[0]) == "" {
+		return "", false
+	}
+	src := strings.TrimSpace(args[0])
+	if text, ok := unquoteDirectiveString(src); ok {
+		src = fmt.Sprintf("%q", text)
+	}
+	var call strings.Builder
+	fmt.Fprintf(&call, "__picture (%s)", src)
+	for _, arg := range args[1:] {
+		name, value, ok := parseNamedDirectiveArg(arg)
+		if _, known := pictureFields[name]; !ok || !known {
+			return "", false
+		}
+		if text, ok := unquoteDirectiveString(value); ok {
+			value = fmt.Sprintf("%q", text)
+		}
+		fmt.Fprintf(&call, " %q (%s)", name, value)
+	}
+	return fmt.Sprintf(`@include('%spicture', (%s))`, componentNamePrefix, call.String()), true
+}
+
+// picture builds the Picture of src with Engine.Picture, from the name and value pairs of a @picture directive.
+func (s *renderState) picture(src string, pairs ...any) (Picture, error) {
+	p := Picture{Src: src}
+	if len(pairs)%2 != 0 {
+		return p, errors.New("picture: expected name value pairs")
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		set, ok := pictureFields[fmt.Sprint(pairs[i])]
+		if !ok {
+			return p, fmt.Errorf("picture: unknown field %v", pairs[i])
+		}
+		if pairs[i+1] != nil {
+			set(&p, fmt.Sprint(pairs[i+1]))
+		}
+	}
+	if p.Loading != "eager" {
+		p.Loading = "lazy"
+	}
+
+	opts := s.e.Picture
+	if opts.Resize == nil {
+		return p, nil
+	}
+	widths := opts.Widths
+	if len(widths) == 0 {
+		widths = DefaultPictureWidths
+	}
+	srcset := func(format string) string {
+		candidates := make([]string, len(widths))
+		for i, width := range widths {
+			candidates[i] = fmt.Sprintf("%s %dw", opts.Resize(src, width, format), width)
+		}
+		return strings.Join(candidates, ", ")
+	}
+	for _, format := range opts.Formats {
+		mimeType := mime.TypeByExtension("." + format)
+		if mimeType == "" {
+			mimeType = "image/" + format
+		}
+		p.Sources = append(p.Sources, PictureSource{Type: mimeType, Srcset: srcset(format)})
+	}
+	p.Srcset = srcset("")
+	p.Src = opts.Resize(src, widths[len(widths)-1], "")
+	return p, nil
+}
@@ -0,0 +1,52 @@
+package blade
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestPictureDirective(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"page.blade": `@picture(.Cover, alt: .Title, sizes: '(min-width: 768px) 50vw, 100vw', width: 1200, height: 800)`,
+		"hero.blade": `@picture('/img/hero.jpg', loading: 'eager')`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "hero", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<picture>
+<img src="/img/hero.jpg" alt="" loading="eager" decoding="async">
+</picture>`
+	if buf.String() != expected {
+		t.Errorf("Picture without resize mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	engine.Picture = PictureOptions{
+		Widths:  []int{320, 640},
+		Formats: []string{"avif", "webp"},
+		Resize: func(src string, width int, format string) string {
+			if format == "" {
+				return fmt.Sprintf("https://img.example.com/%d%s", width, src)
+			}
+			return fmt.Sprintf("https://img.example.com/%d%s.%s", width, src, format)
+		},
+	}
+	buf.Reset()
+	if err := engine.Render(&buf, "page", map[string]any{"Cover": "/img/cover.jpg", "Title": `"Cover"`}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected = `<picture>
+<source type="image/avif" srcset="https://img.example.com/320/img/cover.jpg.avif 320w, https://img.example.com/640/img/cover.jpg.avif 640w" sizes="(min-width: 768px) 50vw, 100vw">
+<source type="image/webp" srcset="https://img.example.com/320/img/cover.jpg.webp 320w, https://img.example.com/640/img/cover.jpg.webp 640w" sizes="(min-width: 768px) 50vw, 100vw">
+<img src="https://img.example.com/640/img/cover.jpg" srcset="https://img.example.com/320/img/cover.jpg 320w, https://img.example.com/640/img/cover.jpg 640w" sizes="(min-width: 768px) 50vw, 100vw" alt="&#34;Cover&#34;" width="1200" height="800" loading="lazy" decoding="async">
+</picture>`
+	if buf.String() != expected {
+		t.Errorf("Picture mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
@@ -0,0 +1,113 @@
+package blade
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// pluralForms are the CLDR plural categories accepted by @plural.
+var pluralForms = map[string]plural.Form{
+	"zero":  plural.Zero,
+	"one":   plural.One,
+	"two":   plural.Two,
+	"few":   plural.Few,
+	"many":  plural.Many,
+	"other": plural.Other,
+}
+
+// parsePluralDirective converts the arguments of @plural to a __plural call:
+// @plural(.Count, 'one item', '# items') => {{ __plural (.Count) "one" "one item" "other" "# items" }}
+// @plural(.Count, one: '# plik', few: '# pliki', other: '# plików') names the CLDR categories.
+func parsePluralDirective(args []string) (string, bool) {
+	if len(args) < 2 {
+		return "", false
+	}
+	var call strings.Builder
+	fmt.Fprintf(&call, "{{ __plural (%s)", args[0])
+	if len(args) == 3 {
+		one, okOne := unquoteDirectiveString(strings.TrimSpace(args[1]))
+		other, okOther := unquoteDirectiveString(strings.TrimSpace(args[2]))
+		if okOne && okOther {
+			fmt.Fprintf(&call, " %q %q %q %q }}", "one", one, "other", other)
+			return call.String(), true
+		}
+	}
+	hasOther := false
+	for _, arg := range args[1:] {
+		form, value, ok := parseNamedDirectiveArg(arg)
+		if !ok {
+			return "", false
+		}
+		text, ok := unquoteDirectiveString(value)
+		if _, known := pluralForms[form]; !known || !ok {
+			return "", false
+		}
+		hasOther = hasOther || form == "other"
+		fmt.Fprintf(&call, " %q %q", form, text)
+	}
+	if !hasOther {
+		return "", false
+	}
+	call.WriteString(" }}")
+	return call.String(), true
+}
+
+// plural returns the form of the CLDR plural category of count in the render locale, with # replaced by the
+// formatted count. Forms are category and text pairs, categories missing a form use the "other" form.
+func (s *renderState) plural(count any, forms ...string) (string, error) {
+	if len(forms)%2 != 0 {
+		return "", errors.New("plural: expected category and text pairs")
+	}
+	n, err := toFloat(count)
+	if err != nil {
+		return "", fmt.Errorf("plural: %w", err)
+	}
+	tag, err := language.Parse(s.locale())
+	if err != nil {
+		tag = language.English
+	}
+	category := pluralCategory(tag, n)
+
+	text, other := "", ""
+	for i := 0; i < len(forms); i += 2 {
+		if pluralForms[forms[i]] == category {
+			text = forms[i+1]
+		}
+		if forms[i] == "other" {
+			other = forms[i+1]
+		}
+	}
+	if text == "" {
+		text = other
+	}
+	if !strings.Contains(text, "#") {
+		return text, nil
+	}
+	formatted, err := s.numberFormat(n)
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(text, "#", formatted), nil
+}
+
+// pluralCategory returns the CLDR cardinal plural category of n in the language tag.
+func pluralCategory(tag language.Tag, n float64) plural.Form {
+	digits := strconv.FormatFloat(math.Abs(n), 'f', -1, 64)
+	integer, fraction, _ := strings.Cut(digits, ".")
+	i, err := strconv.Atoi(integer)
+	if err != nil {
+		// too large to match the rules of small numbers
+		return plural.Other
+	}
+	v := len(fraction)
+	f, _ := strconv.Atoi("0" + fraction)
+	trimmed := strings.TrimRight(fraction, "0")
+	t, _ := strconv.Atoi("0" + trimmed)
+	return plural.Cardinal.MatchPlural(tag, i, v, len(trimmed), f, t)
+}
@@ -0,0 +1,67 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestPluralDirective(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"cart.blade":  `@plural(.Count, 'one item', '# items')`,
+		"files.blade": `@plural(.Count, one: '# plik', few: '# pliki', many: '# plików', other: '# pliku')`,
+		"days.blade":  `@plural(.Count, zero: 'لا أيام', one: 'يوم', two: 'يومان', few: '# أيام', many: '# يومًا', other: '# يوم')`,
+		"ru.blade":    `@plural(.Count, one: '# файл', few: '# файла', many: '# файлов', other: '# файла')`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		entry    string
+		locale   string
+		count    any
+		expected string
+	}{
+		{"cart", "", 1, "one item"},
+		{"cart", "", 0, "0 items"},
+		{"cart", "", 1500, "1,500 items"},
+		{"cart", "", 1.5, "1.5 items"},
+		{"cart", "fr", 0, "one item"},
+		{"cart", "ja", 1, "1 items"},
+		{"files", "pl", 1, "1 plik"},
+		{"files", "pl", 3, "3 pliki"},
+		{"files", "pl", 5, "5 plików"},
+		{"files", "pl", 22, "22 pliki"},
+		{"files", "pl", 1.5, "1,5 pliku"},
+		{"ru", "ru", 21, "21 файл"},
+		{"ru", "ru", 11, "11 файлов"},
+		{"days", "ar", 0, "لا أيام"},
+		{"days", "ar", 2, "يومان"},
+		{"days", "ar", int64(11), "١١ يومًا"},
+	}
+	for _, tc := range tests {
+		ctx := context.Background()
+		if tc.locale != "" {
+			ctx = WithLocale(ctx, tc.locale)
+		}
+		var buf bytes.Buffer
+		if err := engine.RenderContext(ctx, &buf, tc.entry, map[string]any{"Count": tc.count}); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("@plural mismatch for %v in %q.\nExp: %s\nGot: %s", tc.count, tc.locale, tc.expected, buf.String())
+		}
+	}
+
+	for _, invalid := range [][]string{
+		{".Count", "'items'"},
+		{".Count", "one: 'item'"},
+		{".Count", "some: 'item'", "other: 'items'"},
+	} {
+		if out, ok := parsePluralDirective(invalid); ok {
+			t.Errorf("expected %v to be invalid, got %s", invalid, out)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package blade
+
+import (
+	"context"
+	"time"
+)
+
+// DevelopmentWatchInterval is the interval at which WithDevelopment reloads the templates.
+const DevelopmentWatchInterval = time.Second
+
+// WithProduction configures the engine for production: broken views fail Load, the compiled template texts of
+// GetDebugTemplates are not kept, renders are buffered so a failing render writes nothing, and templates are
+// minified. Call it before Load, and pair it with LoadCache to skip parsing the templates at startup.
+func (e *Engine) WithProduction() *Engine {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.DevMode = false
+	e.DisableDebugTemplates = true
+	e.BufferOutput = true
+	e.Minify = true
+	return e
+}
+
+// WithDevelopment configures the engine for development: broken views render an error overlay instead of
+// failing Load, renders reading missing map keys fail, and templates are reloaded every
+// DevelopmentWatchInterval until ctx is done. Call it before Load.
+func (e *Engine) WithDevelopment(ctx context.Context) *Engine {
+	e.mu.Lock()
+	e.DevMode = true
+	e.DisableDebugTemplates = false
+	e.BufferOutput = false
+	e.Minify = false
+	e.StrictVariables = true
+	e.mu.Unlock()
+
+	go func() { _ = e.Watch(ctx, DevelopmentWatchInterval) }()
+	return e
+}
@@ -0,0 +1,65 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithProduction(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"page.blade": "<ul>\n    <li>{{ .Name }}</li>\n    <li>{{ \"a   b\" }}</li>\n</ul>\n<pre>\n  keep\n</pre><script>\n  let a = 1\n</script>\n{{ if .Fail }}{{ fail }}{{ end }}",
+	})
+	engine := NewEngineFS(mockFS).WithProduction()
+	engine.FuncMap["fail"] = func() (string, error) { return "", errors.New("boom") }
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(engine.GetDebugTemplates()) != 0 {
+		t.Error("Expected no debug templates in production")
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", map[string]any{"Name": "John"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := "<ul> <li>John</li> <li>a   b</li> </ul> <pre>\n  keep\n</pre><script>\n  let a = 1\n</script> "
+	if buf.String() != expected {
+		t.Errorf("Minified output mismatch.\nExp: %q\nGot: %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := engine.Render(&buf, "page", map[string]any{"Name": "John", "Fail": true}); err == nil {
+		t.Fatal("Expected the render to fail")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected a failing buffered render to write nothing, got %q", buf.String())
+	}
+}
+
+func TestWithDevelopment(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"page.blade":   `<p>{{ .Name }}</p>`,
+		"broken.blade": `{{ if }}`,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine := NewEngineFS(mockFS).WithDevelopment(ctx)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Expected broken views not to fail Load in development, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", map[string]any{}); err == nil || !strings.Contains(err.Error(), "Name") {
+		t.Errorf("Expected a missing key error, got %v", err)
+	}
+	buf.Reset()
+	if err := engine.Render(&buf, "broken", nil); err == nil {
+		t.Fatalf("Expected a compile error")
+	}
+	if !strings.Contains(buf.String(), "broken") {
+		t.Errorf("Expected the error overlay, got %s", buf.String())
+	}
+}
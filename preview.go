@@ -0,0 +1,104 @@
+package blade
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPreview is returned when rendering with a preview token that is malformed, forged or expired.
+var ErrInvalidPreview = errors.New("invalid or expired preview token")
+
+type previewKey struct{}
+
+// WithPreview returns a context rendering views with the draft templates of Engine.DraftFS,
+// when token is a valid token of Engine.SignPreview. An invalid token fails the render with ErrInvalidPreview.
+func WithPreview(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, previewKey{}, token)
+}
+
+// SignPreview returns a token enabling the draft templates with WithPreview for ttl, signed with
+// Engine.PreviewKey. Share it in the preview links of a CMS, like ?preview=<token>.
+func (e *Engine) SignPreview(ttl time.Duration) (string, error) {
+	if len(e.PreviewKey) == 0 {
+		return "", errors.New("preview: Engine.PreviewKey is not set")
+	}
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	return expires + "." + e.previewSignature(expires), nil
+}
+
+// VerifyPreview returns ErrInvalidPreview when token is not a valid, unexpired token of SignPreview.
+func (e *Engine) VerifyPreview(token string) error {
+	expires, signature, ok := strings.Cut(token, ".")
+	if !ok || len(e.PreviewKey) == 0 || !hmac.Equal([]byte(signature), []byte(e.previewSignature(expires))) {
+		return ErrInvalidPreview
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return ErrInvalidPreview
+	}
+	return nil
+}
+
+func (e *Engine) previewSignature(payload string) string {
+	h := hmac.New(sha256.New, e.PreviewKey)
+	h.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// newDraftSet returns the override set of the draft templates, reloaded when the drafts change.
+func (e *Engine) newDraftSet() *overrideSet {
+	return &overrideSet{
+		e:     e,
+		label: "preview",
+		overrides: func() (map[string]*ParsedFile, error) {
+			if e.DraftFS == nil {
+				return nil, errors.New("Engine.DraftFS is not set")
+			}
+			return e.parseOverrides(e.DraftFS)
+		},
+		version: func() (string, error) {
+			if e.DraftFS == nil {
+				return "", nil
+			}
+			return draftsVersion(e.DraftFS)
+		},
+	}
+}
+
+// draftsVersion identifies the state of the draft templates by their count, size and latest modification.
+func draftsVersion(fsys fs.FS) (string, error) {
+	if r, ok := fsys.(RefreshableFS); ok {
+		if err := r.Refresh(context.Background()); err != nil {
+			return "", err
+		}
+	}
+	var count, size int64
+	var latest time.Time
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		count++
+		size += info.Size()
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d/%d/%d", count, size, latest.UnixNano()), nil
+}
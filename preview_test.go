@@ -0,0 +1,57 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPreview(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"layout.blade": `<main>@yield('content')</main>`,
+		"home.blade":   `@extends('layout')@section('content')Published@endsection`,
+	}))
+	drafts := createMockFS(map[string]string{
+		"home.blade": `@extends('layout')@section('content')Draft@endsection`,
+	})
+	engine.DraftFS = drafts
+	if _, err := engine.SignPreview(time.Hour); err == nil {
+		t.Errorf("expected an error without PreviewKey")
+	}
+	engine.PreviewKey = []byte("secret")
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	token, err := engine.SignPreview(time.Hour)
+	if err != nil {
+		t.Fatalf("SignPreview failed: %v", err)
+	}
+
+	render := func(ctx context.Context) (string, error) {
+		var buf bytes.Buffer
+		err := engine.RenderContext(ctx, &buf, "home", nil)
+		return buf.String(), err
+	}
+
+	if got, err := render(context.Background()); err != nil || got != "<main>Published</main>" {
+		t.Errorf("expected the published view, got %q, %v", got, err)
+	}
+	if got, err := render(WithPreview(context.Background(), token)); err != nil || got != "<main>Draft</main>" {
+		t.Errorf("expected the draft view, got %q, %v", got, err)
+	}
+
+	drafts["home.blade"].Data = []byte(`@extends('layout')@section('content')Edited draft@endsection`)
+	drafts["home.blade"].ModTime = time.Now().Add(time.Second)
+	if got, err := render(WithPreview(context.Background(), token)); err != nil || got != "<main>Edited draft</main>" {
+		t.Errorf("expected the edited draft, got %q, %v", got, err)
+	}
+
+	expired, _ := engine.SignPreview(-time.Minute)
+	for _, invalid := range []string{"", "123", token + "x", expired} {
+		if _, err := render(WithPreview(context.Background(), invalid)); !errors.Is(err, ErrInvalidPreview) {
+			t.Errorf("expected ErrInvalidPreview for %q, got %v", invalid, err)
+		}
+	}
+}
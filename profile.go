@@ -0,0 +1,235 @@
+package blade
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"text/template/parse"
+	"time"
+)
+
+// Profile is the timing of a single render, see Engine.Profile.
+type Profile struct {
+	Entry string
+	// Duration is the total render time
+	Duration time.Duration
+	// Bytes is the total size of the output
+	Bytes int64
+	// Nodes are the sections, stacks and partials rendered by the entry, with the ones they render as children
+	Nodes []*ProfileNode
+}
+
+// ProfileNode is the aggregated timing of a section, stack, partial, snippet or macro within its parent.
+type ProfileNode struct {
+	// Name describes the node, like "section content" or "partial nav"
+	Name string
+	// Calls is the number of times it was rendered
+	Calls int
+	// Duration is the total time spent rendering it, including its children
+	Duration time.Duration
+	// Bytes is the total size of its output, including its children
+	Bytes    int64
+	Children []*ProfileNode
+}
+
+// String formats the profile as an indented tree.
+func (p *Profile) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %v %dB\n", p.Entry, p.Duration, p.Bytes)
+	var writeNodes func(nodes []*ProfileNode, depth int)
+	writeNodes = func(nodes []*ProfileNode, depth int) {
+		for _, n := range nodes {
+			fmt.Fprintf(&b, "%s%s %v %dB x%d\n", strings.Repeat("  ", depth), n.Name, n.Duration, n.Bytes, n.Calls)
+			writeNodes(n.Children, depth+1)
+		}
+	}
+	writeNodes(p.Nodes, 1)
+	return b.String()
+}
+
+// Profile renders entry with data into io.Discard and reports how long each section, stack and partial took
+// and how many bytes it wrote. The entry is recompiled with timers, so it is meant for diagnosing slow views.
+func (e *Engine) Profile(entry string, data any) (*Profile, error) {
+	return e.ProfileContext(context.Background(), entry, data)
+}
+
+// ProfileContext is like Profile with a render context.
+func (e *Engine) ProfileContext(ctx context.Context, entry string, data any) (*Profile, error) {
+	name := normalizeName(entry)
+	set := e.set.Load()
+	tmpl, ok := set.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("template %s not loaded", entry)
+	}
+
+	if tmpl.err != nil {
+		return nil, tmpl.err
+	}
+	text, ok := set.debugTemplates[name]
+	if !ok {
+		return nil, fmt.Errorf("template %s cannot be profiled without debug templates", entry)
+	}
+	// parse again, the trees of the loaded template are shared by its clones, and keep the calls of the static
+	// partials folded by compileTemplate, so they are profiled
+	profiled, err := e.parseTemplateSet(name, text, tmpl.xml, e.newRenderState(ctx, nil).funcs())
+	if err != nil {
+		return nil, err
+	}
+	for _, tree := range profiled.trees() {
+		instrumentTemplateCalls(tree, tree.Root)
+	}
+
+	var funcs template.FuncMap
+	data, funcs, ctx = unwrapData(ctx, data)
+	w := &countingWriter{w: io.Discard}
+	p := &profiler{w: w, root: &ProfileNode{}}
+	state := e.newRenderState(ctx, w)
+	bindFuncs := []template.FuncMap{state.funcs(), p.funcs()}
+	if funcs != nil {
+		bindFuncs = append(bindFuncs, funcs)
+	}
+	profiledSet, err := profiled.clone(bindFuncs...)
+	if err != nil {
+		return nil, err
+	}
+
+	state.tmpl = profiledSet
+
+	start := time.Now()
+	if err := profiledSet.Execute(w, data); err != nil {
+		return nil, err
+	}
+	return &Profile{Entry: name, Duration: time.Since(start), Bytes: w.n, Nodes: p.root.Children}, nil
+}
+
+// instrumentTemplateCalls surrounds every {{ template }} call of list with __profileEnter and __profileLeave actions.
+func instrumentTemplateCalls(tree *parse.Tree, list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	nodes := make([]parse.Node, 0, len(list.Nodes))
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.TemplateNode:
+			nodes = append(nodes, profileAction(tree, n, "__profileEnter"), n, profileAction(tree, n, "__profileLeave"))
+			continue
+		case *parse.IfNode:
+			instrumentTemplateCalls(tree, n.List)
+			instrumentTemplateCalls(tree, n.ElseList)
+		case *parse.RangeNode:
+			instrumentTemplateCalls(tree, n.List)
+			instrumentTemplateCalls(tree, n.ElseList)
+		case *parse.WithNode:
+			instrumentTemplateCalls(tree, n.List)
+			instrumentTemplateCalls(tree, n.ElseList)
+		}
+		nodes = append(nodes, node)
+	}
+	list.Nodes = nodes
+}
+
+// profileAction returns the action {{ fn "name" }} for the template call n.
+func profileAction(tree *parse.Tree, n *parse.TemplateNode, fn string) *parse.ActionNode {
+	return &parse.ActionNode{
+		NodeType: parse.NodeAction,
+		Pos:      n.Pos,
+		Line:     n.Line,
+		Pipe: &parse.PipeNode{
+			NodeType: parse.NodePipe,
+			Pos:      n.Pos,
+			Line:     n.Line,
+			Cmds: []*parse.CommandNode{{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args: []parse.Node{
+					parse.NewIdentifier(fn).SetTree(tree).SetPos(n.Pos),
+					&parse.StringNode{NodeType: parse.NodeString, Pos: n.Pos, Quoted: strconv.Quote(n.Name), Text: n.Name},
+				},
+			}},
+		},
+	}
+}
+
+// profileNodeName describes a define name, like "section content" for __section_content.
+func profileNodeName(name string) string {
+	for _, kind := range []struct{ prefix, label string }{
+		{sectionNamePrefix, "section "},
+		{stackNamePrefix, "stack "},
+		{partialNamePrefix, "partial "},
+		{snippetNamePrefix, "snippet "},
+		{macroNamePrefix, "macro "},
+	} {
+		if rest, ok := strings.CutPrefix(name, kind.prefix); ok {
+			return kind.label + rest
+		}
+	}
+	return name
+}
+
+type profileFrame struct {
+	node  *ProfileNode
+	start time.Time
+	bytes int64
+}
+
+// profiler aggregates the timers of the instrumented template calls of a render.
+type profiler struct {
+	w     *countingWriter
+	root  *ProfileNode
+	stack []profileFrame
+}
+
+func (p *profiler) funcs() template.FuncMap {
+	return template.FuncMap{
+		"__profileEnter": p.enter,
+		"__profileLeave": p.leave,
+	}
+}
+
+func (p *profiler) enter(name string) string {
+	parent := p.root
+	if len(p.stack) > 0 {
+		parent = p.stack[len(p.stack)-1].node
+	}
+	label := profileNodeName(name)
+	var node *ProfileNode
+	for _, child := range parent.Children {
+		if child.Name == label {
+			node = child
+			break
+		}
+	}
+	if node == nil {
+		node = &ProfileNode{Name: label}
+		parent.Children = append(parent.Children, node)
+	}
+	p.stack = append(p.stack, profileFrame{node: node, start: time.Now(), bytes: p.w.n})
+	return ""
+}
+
+func (p *profiler) leave(string) string {
+	if len(p.stack) == 0 {
+		return ""
+	}
+	frame := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	frame.node.Calls++
+	frame.node.Duration += time.Since(frame.start)
+	frame.node.Bytes += p.w.n - frame.bytes
+	return ""
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,54 @@
+package blade
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade":       `<main>@yield('content')</main>@stack('scripts')`,
+		"partials/row.blade": `<li>{{ . }}</li>`,
+		"page.blade":         `@extends('layout')@section('content'){{ range .Items }}@include('partials/row', .){{ end }}@endsection@push('scripts')<script></script>@endpush`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	profile, err := engine.Profile("page", map[string]any{"Items": []string{"a", "b", "c"}})
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+
+	content := findProfileNode(profile.Nodes, "section content")
+	if content == nil {
+		t.Fatalf("missing section content in profile:\n%s", profile)
+	}
+	if content.Calls != 1 || content.Bytes != int64(len("<li>a</li><li>b</li><li>c</li>")) {
+		t.Errorf("unexpected section node %+v", content)
+	}
+	row := findProfileNode(content.Children, "partial partials/row")
+	if row == nil || row.Calls != 3 || row.Bytes != 30 {
+		t.Errorf("unexpected partial node %+v", row)
+	}
+	if stack := findProfileNode(profile.Nodes, "stack scripts"); stack == nil || stack.Bytes != int64(len("<script></script>")) {
+		t.Errorf("unexpected stack node %+v", stack)
+	}
+	// the loaded template is not instrumented
+	if !strings.Contains(engine.GetDebugTemplates()["page"], "partials/row") || strings.Contains(engine.GetDebugTemplates()["page"], "__profile") {
+		t.Errorf("debug template should not change")
+	}
+	if profile.Bytes < 30 || !strings.Contains(profile.String(), "  partial partials/row") {
+		t.Errorf("unexpected profile:\n%s", profile)
+	}
+}
+
+func findProfileNode(nodes []*ProfileNode, name string) *ProfileNode {
+	for _, n := range nodes {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
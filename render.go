@@ -1,11 +1,9 @@
 package blade
 
 import (
-	"fmt"
+	"context"
 	"html/template"
 	"net/http"
-
-	"github.com/gin-gonic/gin/render"
 )
 
 type View[T any] interface {
@@ -44,23 +42,6 @@ func (v view[T]) Status() int {
 	return v.status
 }
 
-var _ render.HTMLRender = (*HTMLRender)(nil)
-
-// HTMLRender gin HTMLRender compatible
-type HTMLRender struct {
-	e *Engine
-}
-
-// NewHTMLRender create a new HTMLRender
-func NewHTMLRender(e *Engine) *HTMLRender {
-	return &HTMLRender{e: e}
-}
-
-// Instance returns a new render.Render
-func (h *HTMLRender) Instance(name string, data any) render.Render {
-	return &Render{e: h.e, name: name, data: data}
-}
-
 type DataWithFuncs interface {
 	Data() any
 	Funcs() template.FuncMap
@@ -86,35 +67,52 @@ func (d *dataWithFuncs) Funcs() template.FuncMap {
 	return d.funcs
 }
 
-// Render renders HTML template with data and write to w
-type Render struct {
-	e    *Engine
-	name string
+type DataWithContext interface {
+	Data() any
+	Context() context.Context
+}
+
+type dataWithContext struct {
 	data any
+	ctx  context.Context
 }
 
-// Render renders HTML template with data and writes to w
-func (r *Render) Render(w http.ResponseWriter) error {
-	r.WriteContentType(w)
-	tmpl, ok := r.e.GetTemplate(r.name)
-	if !ok {
-		return fmt.Errorf("template %s not found", r.name)
-	}
-	if d, ok := r.data.(DataWithFuncs); ok {
-		cloneTmpl, err := tmpl.Clone()
-		if err != nil {
-			return err
-		}
-		return cloneTmpl.Funcs(d.Funcs()).Execute(w, d.Data())
+// NewDataWithContext attaches a context to data, helpers like currentURL read request scoped values from it.
+func NewDataWithContext(ctx context.Context, data any) DataWithContext {
+	return &dataWithContext{
+		data: data,
+		ctx:  ctx,
 	}
+}
 
-	return tmpl.Execute(w, r.data)
+func (d *dataWithContext) Data() any {
+	return d.data
 }
 
-// WriteContentType write an HTML content type to the response header if not set
-func (r *Render) WriteContentType(w http.ResponseWriter) {
-	header := w.Header()
-	if val := header["Content-Type"]; len(val) == 0 {
-		header["Content-Type"] = []string{"text/html; charset=utf-8"}
+func (d *dataWithContext) Context() context.Context {
+	return d.ctx
+}
+
+// unwrapData returns the data to execute with, the funcs and the context attached to it.
+func unwrapData(ctx context.Context, data any) (any, template.FuncMap, context.Context) {
+	var funcs template.FuncMap
+	for {
+		switch d := data.(type) {
+		case DataWithFuncs:
+			if funcs == nil {
+				funcs = template.FuncMap{}
+			}
+			for name, fn := range d.Funcs() {
+				if _, ok := funcs[name]; !ok {
+					funcs[name] = fn
+				}
+			}
+			data = d.Data()
+		case DataWithContext:
+			ctx = d.Context()
+			data = d.Data()
+		default:
+			return data, funcs, ctx
+		}
 	}
 }
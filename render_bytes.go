@@ -0,0 +1,58 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which render buffers are not returned to the pool,
+// so one huge render does not pin its memory.
+const maxPooledBuffer = 1 << 20
+
+// bufferPool holds the render buffers reused across renders.
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBuffer {
+		bufferPool.Put(buf)
+	}
+}
+
+// RenderBytes renders entry into a pooled buffer and returns its content. Pass it to ReleaseBytes
+// once written, like after a response write on a hot endpoint, so the next render reuses its memory.
+// The slice must not be used after its release.
+func (e *Engine) RenderBytes(entry string, data any) ([]byte, error) {
+	buf := getBuffer()
+	if err := e.execute(context.Background(), buf, entry, data); err != nil {
+		putBuffer(buf)
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ReleaseBytes returns the output of RenderBytes to the buffer pool.
+func ReleaseBytes(b []byte) {
+	putBuffer(bytes.NewBuffer(b[:0]))
+}
+
+// RenderInto appends the output of entry to dst and returns the extended slice, like the append
+// functions of strconv, so a caller can reuse its own buffer across renders. On error, dst is returned unchanged.
+func (e *Engine) RenderInto(dst []byte, entry string, data any) ([]byte, error) {
+	return e.RenderIntoContext(context.Background(), dst, entry, data)
+}
+
+// RenderIntoContext is like RenderInto with a render context.
+func (e *Engine) RenderIntoContext(ctx context.Context, dst []byte, entry string, data any) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	if err := e.execute(ctx, buf, entry, data); err != nil {
+		return dst, err
+	}
+	return buf.Bytes(), nil
+}
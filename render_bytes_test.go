@@ -0,0 +1,39 @@
+package blade
+
+import "testing"
+
+func TestRenderBytes(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"greet.blade": `Hello {{ . }}`,
+	}))
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	out, err := engine.RenderBytes("greet", "Ada")
+	if err != nil || string(out) != "Hello Ada" {
+		t.Fatalf("unexpected output %q, %v", out, err)
+	}
+	ReleaseBytes(out)
+	if _, err := engine.RenderBytes("missing", nil); err == nil {
+		t.Errorf("expected an error for a missing view")
+	}
+
+	dst := make([]byte, 0, 64)
+	dst = append(dst, "> "...)
+	dst, err = engine.RenderInto(dst, "greet", "Bob")
+	if err != nil || string(dst) != "> Hello Bob" {
+		t.Fatalf("unexpected output %q, %v", dst, err)
+	}
+	dst, err = engine.RenderInto(dst, "missing", nil)
+	if err == nil || string(dst) != "> Hello Bob" {
+		t.Errorf("expected dst to be unchanged on error, got %q, %v", dst, err)
+	}
+
+	// the render reuses the memory of dst
+	reused := make([]byte, 0, 64)
+	got, _ := engine.RenderInto(reused, "greet", "Cy")
+	if &got[0] != &reused[:1][0] {
+		t.Errorf("expected the output to use the memory of dst")
+	}
+}
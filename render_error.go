@@ -0,0 +1,121 @@
+package blade
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	texttemplate "text/template"
+)
+
+var (
+	// reExecErrorFunc matches the func of an execution error: ... error calling money: ...
+	reExecErrorFunc = regexp.MustCompile(`error calling (\w+):`)
+	// reExecErrorContext matches the action of an execution error: ... at <.User.Email>: ...
+	reExecErrorContext = regexp.MustCompile(` at <([^>]+)>: `)
+)
+
+// RenderError is the error of a render failing while executing a template, like a func returning an error or
+// panicking, which text/template recovers, or a panic escaping the execution, like one of the writer.
+type RenderError struct {
+	// Entry is the view rendered
+	Entry string
+	// Template is the compiled template executing when the render failed, like the entry, or
+	// "__partial_cards/product" for the partial cards/product
+	Template string
+	// Func is the func that failed, empty when the error is not a func call
+	Func string
+	// File and Line approximately locate the failing action in the Blade sources: the first line of the
+	// partial, the view or its layouts calling the func. File is empty when it is not found.
+	File string
+	Line int
+	// Panic is the value of a panic escaping the execution, nil for errors
+	Panic any
+	// Stack is the stack trace of the panic
+	Stack []byte
+	// Err is the execution error, or an error describing the panic
+	Err error
+}
+
+func (e *RenderError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// renderError converts the execution errors of entry to a RenderError, other errors are returned as they are.
+func renderError(set *compiledSet, entry string, err error) error {
+	var renderErr *RenderError
+	var execErr texttemplate.ExecError
+	if errors.As(err, &renderErr) || !errors.As(err, &execErr) {
+		return err
+	}
+	renderErr = &RenderError{Entry: entry, Template: execErr.Name, Err: err}
+	snippet := ""
+	if m := reExecErrorContext.FindStringSubmatch(err.Error()); m != nil {
+		snippet = m[1]
+	}
+	if m := reExecErrorFunc.FindStringSubmatch(err.Error()); m != nil {
+		renderErr.Func = m[1]
+		snippet = m[1]
+	}
+	renderErr.File, renderErr.Line = locateRenderError(set, entry, execErr.Name, snippet)
+	return renderErr
+}
+
+// panicError converts a panic escaping the execution of entry to a RenderError. The panics aborting an HTTP
+// handler are not recovered.
+func panicError(entry string, r any) error {
+	if r == http.ErrAbortHandler {
+		panic(r)
+	}
+	return &RenderError{Entry: entry, Template: entry, Panic: r, Stack: debug.Stack(), Err: fmt.Errorf("panic rendering %s: %v", entry, r)}
+}
+
+// locateRenderError returns the file and line of the first occurrence of snippet in the Blade sources
+// compiled into tmplName: the partial it defines, or the entry, its layouts and partials.
+func locateRenderError(set *compiledSet, entry string, tmplName string, snippet string) (string, int) {
+	var candidates []string
+	if partial, ok := strings.CutPrefix(tmplName, partialNamePrefix); ok {
+		candidates = append(candidates, partial)
+	}
+	f, span, ok := locateSnippet(set.parsedFiles, append(candidates, entry), snippet)
+	if !ok {
+		return "", 0
+	}
+	file := f.Path
+	if file == "" {
+		file = f.Name
+	}
+	return file, span.Line(f.Raw)
+}
+
+// locateSnippet returns the first occurrence of snippet, an identifier or a pipeline, in the sources of the files
+// named candidates, followed by the layouts and partials of the last one.
+func locateSnippet(files map[string]*ParsedFile, candidates []string, snippet string) (*ParsedFile, Span, bool) {
+	if snippet == "" || len(candidates) == 0 {
+		return nil, Span{}, false
+	}
+	if info, err := describe(files, candidates[len(candidates)-1]); err == nil {
+		candidates = append(candidates, info.Layouts...)
+		candidates = append(candidates, info.Includes...)
+	}
+	reSnippet := regexp.MustCompile(`(^|[^\w.$])` + regexp.QuoteMeta(snippet) + `($|\W)`)
+	for _, name := range candidates {
+		f, ok := lookupFile(files, name)
+		if !ok {
+			continue
+		}
+		if loc := reSnippet.FindStringSubmatchIndex(f.Raw); loc != nil {
+			return f, Span{Start: loc[3], End: loc[3] + len(snippet)}, true
+		}
+	}
+	return nil, Span{}, false
+}
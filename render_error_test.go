@@ -0,0 +1,78 @@
+package blade
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRenderErrorFuncPanic(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade":        "<main>\n@yield('main')\n</main>",
+		"partials/card.blade": "<div>\n{{ .Title }}\n{{ explode .Title }}\n</div>",
+		"page.blade":          "@extends('layout')\n@section('main')\n@include('partials/card', .)\n@endsection",
+	})
+	engine := NewEngineFS(mockFS)
+	engine.FuncMap["explode"] = func(s string) string { panic("cannot explode " + s) }
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	err := engine.Render(io.Discard, "page", map[string]string{"Title": "card"})
+	var renderErr *RenderError
+	if !errors.As(err, &renderErr) {
+		t.Fatalf("Expected a RenderError, got %v", err)
+	}
+	if renderErr.Entry != "page" || renderErr.Template != "__partial_partials/card" || renderErr.Func != "explode" {
+		t.Errorf("Unexpected render error: %+v", renderErr)
+	}
+	if renderErr.File != "partials/card.blade" || renderErr.Line != 3 {
+		t.Errorf("Unexpected location %s:%d", renderErr.File, renderErr.Line)
+	}
+	if !strings.HasPrefix(err.Error(), "partials/card.blade:3: ") || !strings.Contains(err.Error(), "cannot explode card") {
+		t.Errorf("Unexpected message: %v", err)
+	}
+}
+
+func TestRenderErrorMissingField(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{"page.blade": "<p>\n{{ .User.Email }}</p>"}))
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	err := engine.Render(io.Discard, "page", map[string]any{"User": struct{ Name string }{"Ada"}})
+	var renderErr *RenderError
+	if !errors.As(err, &renderErr) {
+		t.Fatalf("Expected a RenderError, got %v", err)
+	}
+	if renderErr.Func != "" || renderErr.Line != 2 {
+		t.Errorf("Unexpected render error: %+v", renderErr)
+	}
+}
+
+type panickingWriter struct{ value any }
+
+func (w panickingWriter) Write([]byte) (int, error) { panic(w.value) }
+
+func TestRenderErrorWriterPanic(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{"page.blade": "<p>{{ .Title }}</p>"}))
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	err := engine.Render(panickingWriter{"broken"}, "page", map[string]string{"Title": "Hi"})
+	var renderErr *RenderError
+	if !errors.As(err, &renderErr) || renderErr.Panic != "broken" || !bytes.Contains(renderErr.Stack, []byte("panic")) {
+		t.Fatalf("Expected a RenderError of the panic, got %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("Expected http.ErrAbortHandler to be panicked again, got %v", r)
+		}
+	}()
+	_ = engine.Render(panickingWriter{http.ErrAbortHandler}, "page", map[string]string{"Title": "Hi"})
+}
@@ -0,0 +1,181 @@
+package blade
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+)
+
+// renderState holds values scoped to a single render.
+type renderState struct {
+	// e is the engine rendering
+	e *Engine
+	// ctx is the context of the render
+	ctx context.Context
+	// w is the writer of the render, flushed by @flush
+	w io.Writer
+	// flushes counts the times each @flush directive was reached
+	flushes map[string]int
+	// once is a set of @once blocks already rendered
+	once map[string]struct{}
+	// includeDepth is the current depth of each recursive partial
+	includeDepth map[string]int
+	// tmpl is the template set executed by the render, it renders the partials of @include with memo
+	tmpl templateSet
+	// proto is the prototype of tmpl and dataFuncs the funcs supplied with the data, sections with a timeout
+	// render from their own clone
+	proto     templateSet
+	dataFuncs []template.FuncMap
+	// memo holds the outputs of the partials of @include with memo by partial and data
+	memo map[string]any
+	// captures holds the outputs of the @capture blocks rendered so far by name
+	captures map[string]any
+	// variants holds the variants assigned to the experiments rendered so far by name
+	variants map[string]string
+	// flashed holds the flash messages read so far by key, the store removes them once read
+	flashed map[string][]any
+}
+
+func (e *Engine) newRenderState(ctx context.Context, w io.Writer) *renderState {
+	return &renderState{
+		e:            e,
+		ctx:          ctx,
+		w:            w,
+		flushes:      map[string]int{},
+		once:         map[string]struct{}{},
+		includeDepth: map[string]int{},
+		memo:         map[string]any{},
+		captures:     map[string]any{},
+		variants:     map[string]string{},
+		flashed:      map[string][]any{},
+	}
+}
+
+// reset clears the state of a previous render of a pooled clone for a render of ctx into w.
+func (s *renderState) reset(ctx context.Context, w io.Writer) {
+	s.ctx, s.w = ctx, w
+	clear(s.flushes)
+	clear(s.once)
+	clear(s.includeDepth)
+	clear(s.memo)
+	clear(s.captures)
+	clear(s.variants)
+	clear(s.flashed)
+}
+
+// funcs returns the render scoped funcs bound to the state.
+func (s *renderState) funcs() template.FuncMap {
+	return template.FuncMap{
+		"__once":           s.onceFunc,
+		"__enterInclude":   s.enterInclude,
+		"__leaveInclude":   s.leaveInclude,
+		"__flush":          s.flush,
+		"__while":          s.whileRange,
+		"__whileCheck":     s.whileCheck,
+		"currentURL":       s.currentURL,
+		"queryReplace":     s.queryReplace,
+		"sortURL":          s.sortURL,
+		"pageURL":          s.pageURL,
+		"numberFormat":     s.numberFormat,
+		"money":            s.money,
+		"humanBytes":       s.humanBytes,
+		"timeAgo":          s.timeAgo,
+		"dateDiff":         s.dateDiff,
+		"__plural":         s.plural,
+		"dir":              s.dir,
+		"isRTL":            s.isRTL,
+		"__honeypot":       s.honeypot,
+		"__picture":        s.picture,
+		"__memo":           s.memoInclude,
+		"__deferOpen":      s.deferOpen,
+		"__deferClose":     s.deferClose,
+		"__capture":        s.capture,
+		"captured":         s.captured,
+		"feature":          s.feature,
+		"__experiment":     s.experiment,
+		"track":            s.track,
+		"trackEvent":       s.trackEvent,
+		"__esi":            s.esi,
+		"__timedSection":   s.timedSection,
+		"__guardedInclude": s.guardedInclude,
+		"__csrf":           s.csrf,
+		"csrfToken":        s.csrfToken,
+		"__nonce":          s.nonceAttr,
+		"cspNonce":         s.cspNonce,
+		"session":          s.session,
+		"old":              s.old,
+		"errors":           s.errors,
+	}
+}
+
+// onceFunc reports whether the @once block identified by id and key is rendered for the first time.
+func (s *renderState) onceFunc(id string, key ...any) bool {
+	if len(key) > 0 {
+		id += "\x00" + fmt.Sprint(key...)
+	}
+	if _, ok := s.once[id]; ok {
+		return false
+	}
+	s.once[id] = struct{}{}
+	return true
+}
+
+// enterInclude increases the depth of a recursive partial, failing the render when it exceeds the limit.
+func (s *renderState) enterInclude(name string) (string, error) {
+	s.includeDepth[name]++
+	if s.includeDepth[name] > s.e.MaxIncludeDepth {
+		return "", fmt.Errorf(`template "%s" exceeded maximum include depth %d`, name, s.e.MaxIncludeDepth)
+	}
+	return "", nil
+}
+
+// leaveInclude decreases the depth of a recursive partial.
+func (s *renderState) leaveInclude(name string) string {
+	s.includeDepth[name]--
+	return ""
+}
+
+// flush flushes the writer of the render every n-th time the @flush directive id is reached, so the
+// rows of a large loop reach the client while the following ones render.
+// Writers implementing http.Flusher or Flush() error, like bufio.Writer, are flushed.
+func (s *renderState) flush(id string, n ...int) (string, error) {
+	s.flushes[id]++
+	if len(n) > 0 && n[0] > 1 && s.flushes[id]%n[0] != 0 {
+		return "", nil
+	}
+	switch w := s.w.(type) {
+	case http.Flusher:
+		w.Flush()
+	case interface{ Flush() error }:
+		return "", w.Flush()
+	}
+	return "", nil
+}
+
+// currentURL returns the URL of the request being rendered, see WithRequestURL.
+func (s *renderState) currentURL() string {
+	u := RequestURL(s.ctx)
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+// queryReplace returns the URL of the request being rendered with query parameters replaced, a nil value removes the parameter.
+func (s *renderState) queryReplace(pairs ...any) (string, error) {
+	u := RequestURL(s.ctx)
+	if u == nil {
+		return urlWithQuery("", pairs...)
+	}
+	return urlWithQuery(u.String(), pairs...)
+}
+
+// locale returns the locale of the render, see WithLocale and Engine.Locale.
+func (s *renderState) locale() string {
+	if locale := Locale(s.ctx); locale != "" {
+		return locale
+	}
+	return s.e.Locale
+}
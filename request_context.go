@@ -0,0 +1,87 @@
+package blade
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type (
+	requestURLKey struct{}
+	localeKey     struct{}
+	tenantKey     struct{}
+	themeKey      struct{}
+	esiKey        struct{}
+)
+
+// WithRequestURL returns a context carrying the URL of the request being rendered.
+func WithRequestURL(ctx context.Context, u *url.URL) context.Context {
+	return context.WithValue(ctx, requestURLKey{}, u)
+}
+
+// RequestURL returns the URL stored by WithRequestURL, or nil.
+func RequestURL(ctx context.Context) *url.URL {
+	u, _ := ctx.Value(requestURLKey{}).(*url.URL)
+	return u
+}
+
+// RequestContext returns the context of r carrying its request scoped values for rendering.
+// Requests of a surrogate announcing ESI support with a Surrogate-Capability header render in ESI mode, and
+// requests with a fragment parameter render the @defer block of their placeholder, see Fragment.
+func RequestContext(r *http.Request) context.Context {
+	ctx := WithRequestURL(r.Context(), r.URL)
+	if fragment := Fragment(r); fragment != "" {
+		ctx = context.WithValue(ctx, fragmentKey{}, fragment)
+	}
+	if strings.Contains(r.Header.Get("Surrogate-Capability"), "ESI/1.0") {
+		ctx = WithESI(ctx)
+	}
+	return ctx
+}
+
+// WithLocale returns a context carrying the locale used by helpers, overriding Engine.Locale.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// Locale returns the locale stored by WithLocale, or an empty string.
+func Locale(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// WithTenant returns a context rendering views with the templates of tenant, see Engine.Tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantName returns the tenant stored by WithTenant, or an empty string.
+func TenantName(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// WithTheme returns a context rendering views with the templates of theme, see Engine.Theme.
+// A tenant of the context takes precedence over its theme.
+func WithTheme(ctx context.Context, theme string) context.Context {
+	return context.WithValue(ctx, themeKey{}, theme)
+}
+
+// ThemeName returns the theme stored by WithTheme, or an empty string.
+func ThemeName(ctx context.Context) string {
+	theme, _ := ctx.Value(themeKey{}).(string)
+	return theme
+}
+
+// WithESI returns a context rendering the includes with an esi argument as Edge Side Includes tags, for an
+// edge cache like Varnish or Fastly assembling the page.
+func WithESI(ctx context.Context) context.Context {
+	return context.WithValue(ctx, esiKey{}, true)
+}
+
+// ESIEnabled reports whether the context renders in the ESI mode of WithESI.
+func ESIEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(esiKey{}).(bool)
+	return enabled
+}
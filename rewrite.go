@@ -0,0 +1,250 @@
+package blade
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// reNullSafeChain matches a field chain using the null-safe operator, like .User?.Profile.Name or $user?.Name
+	reNullSafeChain = regexp.MustCompile(`(\$\w*(?:\.\w+)*|(?:\.\w+)+)(\?\.\w+(?:\??\.\w+)*)`)
+	// reActionPrefix matches the part of an action before its pipeline: keywords, template names and variable declarations
+	reActionPrefix = regexp.MustCompile(`^\s*(?:(?:else\s+)?(?:if|with|range)\s+|(?:template|block)\s+"[^"]*"\s*)?(?:\$\w*(?:\s*,\s*\$\w*)?\s*:?=\s*)?`)
+)
+
+// rewriteExpressions rewrites the expression shorthands supported inside template actions.
+func (e *Engine) rewriteExpressions(text string) string {
+	return rewriteActions(text, func(action string) string {
+		if e.CompatibilityMode {
+			action = mapActionCode(action, rewriteNullSafe)
+		}
+		return rewriteCoalesce(action)
+	})
+}
+
+// rewriteNullSafe compiles .User?.Name into (optional .User "Name").
+func rewriteNullSafe(code string) string {
+	return reNullSafeChain.ReplaceAllStringFunc(code, func(m string) string {
+		sm := reNullSafeChain.FindStringSubmatch(m)
+		var out strings.Builder
+		out.WriteString("(optional ")
+		out.WriteString(sm[1])
+		fields := strings.FieldsFunc(sm[2], func(r rune) bool {
+			return r == '?' || r == '.'
+		})
+		for _, field := range fields {
+			out.WriteString(` "`)
+			out.WriteString(field)
+			out.WriteString(`"`)
+		}
+		out.WriteString(")")
+		return out.String()
+	})
+}
+
+// rewriteCoalesce compiles the default-value operator {{ .Title ?? "Untitled" }} into {{ coalesce (.Title) ("Untitled") }}.
+func rewriteCoalesce(action string) string {
+	if !codeContains(action, "??") {
+		return action
+	}
+	prefix := reActionPrefix.FindString(action)
+	return prefix + rewriteCoalescePipeline(action[len(prefix):])
+}
+
+// rewriteCoalescePipeline rewrites the ?? operator in each command of a pipeline, including parenthesized groups.
+func rewriteCoalescePipeline(pipeline string) string {
+	commands := splitTopLevel(pipeline, "|")
+	for i, command := range commands {
+		operands := splitTopLevel(command, "??")
+		for j, operand := range operands {
+			operands[j] = rewriteCoalesceGroups(operand)
+		}
+		if len(operands) == 1 {
+			commands[i] = operands[0]
+			continue
+		}
+		// keep the whitespace around the command
+		var out strings.Builder
+		out.WriteString(command[:len(command)-len(strings.TrimLeft(command, " \t\r\n"))])
+		out.WriteString("coalesce")
+		for _, operand := range operands {
+			out.WriteString(" (")
+			out.WriteString(strings.TrimSpace(operand))
+			out.WriteString(")")
+		}
+		out.WriteString(command[len(strings.TrimRight(command, " \t\r\n")):])
+		commands[i] = out.String()
+	}
+	return strings.Join(commands, "|")
+}
+
+// rewriteCoalesceGroups rewrites the ?? operator inside the top-level parenthesized groups of code.
+func rewriteCoalesceGroups(code string) string {
+	var out strings.Builder
+	depth := 0
+	groupStart := 0
+	for i := 0; i < len(code); i++ {
+		switch code[i] {
+		case '"', '\'', '`':
+			end := findLiteralEnd(code, i)
+			if end == -1 {
+				end = len(code) - 1
+			}
+			if depth == 0 {
+				out.WriteString(code[i : end+1])
+			}
+			i = end
+		case '(':
+			if depth == 0 {
+				groupStart = i + 1
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				out.WriteString("(")
+				out.WriteString(rewriteCoalescePipeline(code[groupStart:i]))
+				out.WriteString(")")
+			}
+		default:
+			if depth == 0 {
+				out.WriteByte(code[i])
+			}
+		}
+	}
+	if depth > 0 {
+		// unbalanced parentheses are left for the template parser to report
+		return code
+	}
+	return out.String()
+}
+
+// splitTopLevel splits code around sep, ignoring separators inside string literals and parentheses.
+func splitTopLevel(code string, sep string) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(code); i++ {
+		switch code[i] {
+		case '"', '\'', '`':
+			if end := findLiteralEnd(code, i); end != -1 {
+				i = end
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+		default:
+			if depth == 0 && strings.HasPrefix(code[i:], sep) {
+				parts = append(parts, code[start:i])
+				i += len(sep) - 1
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, code[start:])
+}
+
+// codeContains reports whether substr appears in action outside string literals.
+func codeContains(action string, substr string) bool {
+	found := false
+	mapActionCode(action, func(code string) string {
+		found = found || strings.Contains(code, substr)
+		return code
+	})
+	return found
+}
+
+// rewriteActions calls fn with the inner text of every {{ ... }} action in text and replaces it with the result.
+// Trim markers and comments are preserved.
+func rewriteActions(text string, fn func(action string) string) string {
+	var out strings.Builder
+	cursor := 0
+	for {
+		rel := strings.Index(text[cursor:], "{{")
+		if rel == -1 {
+			out.WriteString(text[cursor:])
+			return out.String()
+		}
+		start := cursor + rel
+		end := findActionEnd(text, start+2)
+		if end == -1 {
+			out.WriteString(text[cursor:])
+			return out.String()
+		}
+		out.WriteString(text[cursor:start])
+
+		inner := text[start+2 : end]
+		open, closing := "{{", "}}"
+		if strings.HasPrefix(inner, "- ") {
+			open, inner = "{{- ", inner[2:]
+		}
+		if strings.HasSuffix(inner, " -") {
+			closing, inner = " -}}", inner[:len(inner)-2]
+		}
+		out.WriteString(open)
+		if strings.HasPrefix(strings.TrimSpace(inner), "/*") {
+			out.WriteString(inner)
+		} else {
+			out.WriteString(fn(inner))
+		}
+		out.WriteString(closing)
+		cursor = end + 2
+	}
+}
+
+// findActionEnd returns the index of the "}}" closing the action starting at from, skipping string literals.
+func findActionEnd(text string, from int) int {
+	for i := from; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'', '`':
+			end := findLiteralEnd(text, i)
+			if end == -1 {
+				return -1
+			}
+			i = end
+		case '}':
+			if i+1 < len(text) && text[i+1] == '}' {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// findLiteralEnd returns the index of the quote closing the literal starting at start.
+func findLiteralEnd(text string, start int) int {
+	quote := text[start]
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// mapActionCode calls fn for the parts of an action outside string literals.
+func mapActionCode(action string, fn func(code string) string) string {
+	var out strings.Builder
+	cursor := 0
+	for i := 0; i < len(action); i++ {
+		switch action[i] {
+		case '"', '\'', '`':
+			end := findLiteralEnd(action, i)
+			if end == -1 {
+				end = len(action) - 1
+			}
+			out.WriteString(fn(action[cursor:i]))
+			out.WriteString(action[i : end+1])
+			cursor = end + 1
+			i = end
+		}
+	}
+	out.WriteString(fn(action[cursor:]))
+	return out.String()
+}
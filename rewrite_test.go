@@ -0,0 +1,24 @@
+package blade
+
+import "testing"
+
+func TestRewriteCoalesce(t *testing.T) {
+	tests := []struct {
+		action   string
+		expected string
+	}{
+		{` .Title ?? "Untitled" `, ` coalesce (.Title) ("Untitled") `},
+		{` .A ?? .B ?? "x" | upper `, ` coalesce (.A) (.B) ("x") | upper `},
+		{` if .A ?? .B `, ` if coalesce (.A) (.B) `},
+		{` $title := .Title ?? "??" `, ` $title := coalesce (.Title) ("??") `},
+		{` upper (.Title ?? "x") `, ` upper (coalesce (.Title) ("x")) `},
+		{` template "x" .A ?? . `, ` template "x" coalesce (.A) (.) `},
+		{` "??" `, ` "??" `},
+	}
+
+	for _, tc := range tests {
+		if got := rewriteCoalesce(tc.action); got != tc.expected {
+			t.Errorf("rewriteCoalesce(%q) = %q; want %q", tc.action, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package blade
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template/parse"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// unsafeURL replaces the URLs rejected by safeURL, like html/template does for unsafe URLs.
+const unsafeURL = "#ZgotmplZ"
+
+// safeElements are the elements kept by safeHTML, with their allowed attributes.
+var safeElements = map[string][]string{
+	"a": {"href", "title"}, "abbr": {"title"}, "b": nil, "blockquote": nil, "br": nil, "code": nil, "del": nil,
+	"em": nil, "h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil, "hr": nil, "i": nil,
+	"img": {"src", "alt", "title", "width", "height"}, "li": nil, "ol": nil, "p": nil, "pre": nil, "s": nil,
+	"small": nil, "span": nil, "strong": nil, "sub": nil, "sup": nil, "table": nil, "tbody": nil, "td": nil,
+	"th": nil, "thead": nil, "tr": nil, "u": nil, "ul": nil,
+}
+
+// droppedElements are removed by safeHTML with their content, other elements are replaced by their content.
+var droppedElements = map[string]struct{}{
+	"script": {}, "style": {}, "iframe": {}, "object": {}, "embed": {}, "template": {}, "noscript": {},
+	"textarea": {}, "select": {}, "title": {}, "svg": {}, "math": {},
+}
+
+// safeHTML sanitizes v to basic formatting markup, like the output of a Markdown renderer or a rich text
+// editor, before trusting it as HTML: scripts, styles and embeds are removed, other unknown elements are
+// replaced by their content, and only a few attributes are kept, with links restricted to safe URLs.
+func safeHTML(v any) template.HTML {
+	if h, ok := v.(template.HTML); ok {
+		v = string(h)
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(fmt.Sprint(v)), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return template.HTML(template.HTMLEscapeString(fmt.Sprint(v)))
+	}
+	var b strings.Builder
+	for _, node := range nodes {
+		writeSafeNode(&b, node)
+	}
+	return template.HTML(b.String())
+}
+
+// writeSafeNode writes the sanitized markup of node.
+func writeSafeNode(b *strings.Builder, node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		b.WriteString(html.EscapeString(node.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if _, ok := droppedElements[node.Data]; ok {
+		return
+	}
+	attrs, safe := safeElements[node.Data]
+	if safe {
+		b.WriteString("<" + node.Data)
+		for _, attr := range node.Attr {
+			if attr.Namespace != "" || !slices.Contains(attrs, attr.Key) {
+				continue
+			}
+			if (attr.Key == "href" || attr.Key == "src") && !isSafeURL(attr.Val) {
+				continue
+			}
+			fmt.Fprintf(b, ` %s="%s"`, attr.Key, html.EscapeString(attr.Val))
+		}
+		b.WriteString(">")
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeSafeNode(b, child)
+	}
+	if safe && node.Data != "br" && node.Data != "hr" && node.Data != "img" {
+		b.WriteString("</" + node.Data + ">")
+	}
+}
+
+// safeURL trusts v as a URL when it is relative or uses the http, https, mailto or tel scheme, other URLs,
+// like javascript: ones, are replaced by "#ZgotmplZ".
+func safeURL(v any) template.URL {
+	u := strings.TrimSpace(fmt.Sprint(v))
+	if !isSafeURL(u) {
+		return unsafeURL
+	}
+	return template.URL(u)
+}
+
+// isSafeURL reports whether u is relative or uses the http, https, mailto or tel scheme.
+func isSafeURL(u string) bool {
+	u = strings.TrimSpace(u)
+	scheme, _, ok := strings.Cut(u, ":")
+	if !ok || strings.ContainsAny(scheme, "/?#") {
+		return true
+	}
+	switch strings.ToLower(scheme) {
+	case "http", "https", "mailto", "tel":
+		return true
+	}
+	return false
+}
+
+// safeJS encodes v as a JSON literal trusted as JavaScript, like a config object read by a script:
+// <script>const config = {{ safeJS .Config }};</script>. The encoding escapes <, > and &, so the value cannot
+// close the script element.
+func safeJS(v any) (template.JS, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(data), nil
+}
+
+// checkSandbox returns an error when a template of t calls a func of FuncMap returning a type html/template
+// does not escape, unless the func is listed in SandboxTrustedFuncs.
+func (e *Engine) checkSandbox(t templateSet) error {
+	for _, tree := range t.trees() {
+		var err error
+		walkTree(tree.Root, func(node parse.Node) bool {
+			ident, ok := node.(*parse.IdentifierNode)
+			if !ok || slices.Contains(e.SandboxTrustedFuncs, ident.Ident) {
+				return true
+			}
+			fn := reflect.TypeOf(e.FuncMap[ident.Ident])
+			if fn == nil || fn.Kind() != reflect.Func || fn.NumOut() == 0 {
+				return true
+			}
+			if typeName, ok := unescapedTypes[fn.Out(0)]; ok {
+				err = fmt.Errorf("sandbox: %s calls %s returning %s, use safeHTML, safeURL or safeJS", tree.Name, ident.Ident, typeName)
+			}
+			return err == nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package blade
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestSafeFuncs(t *testing.T) {
+	for input, expected := range map[string]template.HTML{
+		`<p onclick="x()">Hi <b>there</b></p>`:                        `<p>Hi <b>there</b></p>`,
+		`<script>alert(1)</script><em>ok</em>`:                        `<em>ok</em>`,
+		`<a href="javascript:alert(1)" target="_blank">link</a>`:      `<a>link</a>`,
+		`<a href="https://example.com/?a=1&amp;b=2">link</a>`:         `<a href="https://example.com/?a=1&amp;b=2">link</a>`,
+		`<div><img src="/a.png" alt="A" onerror="x()"><br></div>`:     `<img src="/a.png" alt="A"><br>`,
+		`1 < 2 & <unknown>text</unknown><style>p{}</style>`:           `1 &lt; 2 &amp; text`,
+		`<a href=" JavaScript:alert(1)">x</a><a href="/docs">y</a>`:   `<a>x</a><a href="/docs">y</a>`,
+		`<iframe src="https://evil.example"></iframe>plain`:           `plain`,
+		`<ul><li>one<li>two</ul><a href="mailto:a@example.com">m</a>`: `<ul><li>one</li><li>two</li></ul><a href="mailto:a@example.com">m</a>`,
+	} {
+		if got := safeHTML(input); got != expected {
+			t.Errorf("safeHTML(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+
+	for input, expected := range map[string]template.URL{
+		"https://example.com": "https://example.com",
+		"/path?q=1#top":       "/path?q=1#top",
+		"tel:+123":            "tel:+123",
+		"javascript:alert(1)": unsafeURL,
+		"data:text/html,<b>":  unsafeURL,
+		"page.html?next=a:b":  "page.html?next=a:b",
+	} {
+		if got := safeURL(input); got != expected {
+			t.Errorf("safeURL(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+
+	if got, err := safeJS(map[string]string{"name": "</script><b>"}); err != nil || got != `{"name":"\u003c/script\u003e\u003cb\u003e"}` {
+		t.Errorf("safeJS mismatch: %q (%v)", got, err)
+	}
+}
+
+func TestSafeFuncsRender(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"post.blade": `<a href="{{ safeURL .Link }}">{{ safeHTML .Body }}</a><script>const post = {{ safeJS .Meta }};</script>`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{"Link": "javascript:alert(1)", "Body": `<b onmouseover="x()">Hi</b>`, "Meta": map[string]int{"id": 1}}
+	if err := engine.Render(&buf, "post", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `<a href="#ZgotmplZ"><b>Hi</b></a><script>const post = {"id":1};</script>`; buf.String() != expected {
+		t.Errorf("Output mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"post.blade": `{{ raw .Body }}{{ markdown .Text }}{{ safeHTML .Body }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.FuncMap["raw"] = func(s string) template.HTML { return template.HTML(s) }
+	engine.FuncMap["markdown"] = func(s string) template.HTML { return safeHTML(s) }
+	engine.Sandbox = true
+	err := engine.Load()
+	if err == nil || !strings.Contains(err.Error(), "sandbox: post calls raw returning template.HTML") {
+		t.Fatalf("Expected the sandbox to reject raw, got %v", err)
+	}
+
+	delete(engine.FuncMap, "raw")
+	mockFS["post.blade"].Data = []byte(`{{ markdown .Text }}{{ safeHTML .Body }}`)
+	engine.SandboxTrustedFuncs = []string{"markdown"}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package blade
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"net/http"
+)
+
+// Scope renders the templates of its engine with its own funcs and shared values, like the admin panel and the
+// public site of an application needing different helpers. It shares the compiled templates of the engine,
+// nothing is compiled twice.
+type Scope struct {
+	e      *Engine
+	funcs  template.FuncMap
+	shared map[string]any
+}
+
+// Scope returns a child of the engine rendering with funcs added to the engine funcs. Funcs missing from
+// Engine.FuncMap are declared in it so templates using them compile, rendering them outside of the scope fails.
+// Call it before Load.
+func (e *Engine) Scope(funcs template.FuncMap) *Scope {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for name := range funcs {
+		if _, ok := e.FuncMap[name]; !ok {
+			e.FuncMap[name] = scopedFuncPlaceholder(name)
+		}
+	}
+	s := &Scope{e: e, funcs: maps.Clone(funcs), shared: map[string]any{}}
+	if s.funcs == nil {
+		s.funcs = template.FuncMap{}
+	}
+	s.funcs["shared"] = s.sharedValue
+	return s
+}
+
+// scopedFuncPlaceholder declares a func of a scope in the engine funcs.
+func scopedFuncPlaceholder(name string) func(...any) (any, error) {
+	return func(...any) (any, error) {
+		return nil, fmt.Errorf("%s is only available in the scope declaring it", name)
+	}
+}
+
+// Share makes value available to the templates rendered by the scope as {{ shared "key" }}.
+// Share values while configuring the scope, before it renders.
+func (s *Scope) Share(key string, value any) *Scope {
+	s.shared[key] = value
+	return s
+}
+
+func (s *Scope) sharedValue(key string) any {
+	return s.shared[key]
+}
+
+// Engine returns the engine of the scope.
+func (s *Scope) Engine() *Engine {
+	return s.e
+}
+
+// Render executes the template identified by entry into w with data, with the funcs and shared values of the scope.
+func (s *Scope) Render(w io.Writer, entry string, data any) error {
+	return s.RenderContext(context.Background(), w, entry, data)
+}
+
+// RenderContext is like Render with a render context.
+func (s *Scope) RenderContext(ctx context.Context, w io.Writer, entry string, data any) error {
+	return s.e.execute(ctx, w, entry, s.wrap(data))
+}
+
+// Respond is like Engine.Respond with the funcs and shared values of the scope.
+func (s *Scope) Respond(w http.ResponseWriter, r *http.Request, status int, entry string, data any) error {
+	return s.e.Respond(w, r, status, entry, s.wrap(data))
+}
+
+// wrap attaches the funcs of the scope to data, the funcs supplied with data take precedence.
+func (s *Scope) wrap(data any) any {
+	background := context.Background()
+	plain, funcs, ctx := unwrapData(background, data)
+	merged := maps.Clone(s.funcs)
+	maps.Copy(merged, funcs)
+	wrapped := NewDataWithFuncs(plain, merged)
+	if ctx != background {
+		return NewDataWithContext(ctx, wrapped)
+	}
+	return wrapped
+}
@@ -0,0 +1,55 @@
+package blade
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestScope(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"admin/dashboard.blade": `{{ shared "panel" }}: {{ adminLink "users" }} {{ greet }}`,
+		"home.blade":            `{{ greet }}{{ shared "panel" }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.FuncMap["greet"] = func() string { return "hello" }
+	admin := engine.Scope(template.FuncMap{
+		"adminLink": func(name string) string { return "/admin/" + name },
+		"greet":     func() string { return "hello admin" },
+	}).Share("panel", "Admin")
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := admin.Render(&buf, "admin/dashboard", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `Admin: /admin/users hello admin`; buf.String() != expected {
+		t.Errorf("Scope mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := engine.Render(&buf, "home", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `hello`; buf.String() != expected {
+		t.Errorf("Engine render mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	buf.Reset()
+	err := engine.Render(&buf, "admin/dashboard", nil)
+	if err == nil || !strings.Contains(err.Error(), "adminLink is only available in the scope") {
+		t.Errorf("Expected a scope error outside of the scope, got %v", err)
+	}
+
+	buf.Reset()
+	data := NewDataWithFuncs(nil, template.FuncMap{"greet": func() string { return "hello data" }})
+	if err := admin.Render(&buf, "home", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `hello dataAdmin`; buf.String() != expected {
+		t.Errorf("Data funcs precedence mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
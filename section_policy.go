@@ -0,0 +1,134 @@
+package blade
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SectionPolicy decides how the section of a child view combines with the same section of its layouts.
+type SectionPolicy string
+
+const (
+	// SectionOverride replaces the content of the layout, the default
+	SectionOverride SectionPolicy = "override"
+	// SectionAppend renders the content after the content of the layout
+	SectionAppend SectionPolicy = "append"
+	// SectionPrepend renders the content before the content of the layout
+	SectionPrepend SectionPolicy = "prepend"
+	// SectionError fails the compile when a layout defines the section too
+	SectionError SectionPolicy = "error"
+)
+
+// sectionPolicies are the policies accepted by @section.
+var sectionPolicies = map[SectionPolicy]struct{}{
+	SectionOverride: {}, SectionAppend: {}, SectionPrepend: {}, SectionError: {},
+}
+
+// SectionContent is the content of a section filled by a file of the layout chain.
+type SectionContent struct {
+	FileName string
+	Content  string
+	Policy   SectionPolicy
+}
+
+// parseSectionPolicy removes a trailing policy argument from the arguments of @section:
+// @section('scripts', policy: 'append').
+func parseSectionPolicy(args []string) ([]string, SectionPolicy, error) {
+	argName, value, ok := parseNamedDirectiveArg(args[len(args)-1])
+	if !ok || len(args) < 2 || argName != "policy" {
+		return args, "", nil
+	}
+	policy, ok := unquoteDirectiveString(value)
+	if _, known := sectionPolicies[SectionPolicy(policy)]; !ok || !known {
+		return nil, "", fmt.Errorf("invalid @section policy %s", value)
+	}
+	return args[:len(args)-1], SectionPolicy(policy), nil
+}
+
+// sectionArgs returns the arguments of @section without its policy and timeout, ignoring invalid policies.
+func sectionArgs(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	if withoutTimeout, _, _, err := parseSectionTimeout(args); err == nil {
+		args = withoutTimeout
+	}
+	if withoutPolicy, _, err := parseSectionPolicy(args); err == nil {
+		return withoutPolicy
+	}
+	return args[:len(args)-1]
+}
+
+// sectionPolicy returns the policy of the section name filled by f: the policy of its @section,
+// then the policy of Engine.SectionPolicies, then Engine.SectionPolicy.
+func (ctx *CompileContext) sectionPolicy(f *ParsedFile, name string) SectionPolicy {
+	if policy := f.SectionPolicies[name]; policy != "" {
+		return policy
+	}
+	if policy := ctx.SectionPolicies[name]; policy != "" {
+		return policy
+	}
+	if ctx.DefaultSectionPolicy != "" {
+		return ctx.DefaultSectionPolicy
+	}
+	return SectionOverride
+}
+
+// combineSections returns the content of the section name from the contents filled by the layout chain, from
+// the child to the root layout. The content of the root layout combines with the default of the yield.
+func (ctx *CompileContext) combineSections(name string) (string, error) {
+	contents := ctx.SectionContents[name]
+	root := contents[len(contents)-1]
+	content := root.Content
+	if info, ok := ctx.Yields[name]; ok && info.Default != "" {
+		content = combineSection(root, info.Default)
+	}
+	for i := len(contents) - 2; i >= 0; i-- {
+		child, parent := contents[i], contents[i+1]
+		if child.Policy == SectionError {
+			return "", fmt.Errorf(`[%s] section "%s" conflicts with the section of "%s"`, child.FileName, name, parent.FileName)
+		}
+		content = combineSection(child, content)
+	}
+	return content, nil
+}
+
+// combineSection combines the content of a section with the content of its layout.
+func combineSection(child SectionContent, parentContent string) string {
+	switch child.Policy {
+	case SectionAppend:
+		return strings.Join([]string{parentContent, child.Content}, "\n")
+	case SectionPrepend:
+		return strings.Join([]string{child.Content, parentContent}, "\n")
+	default:
+		return child.Content
+	}
+}
+
+// orphanSections fails for the first section of the entry f and its layouts that is not yielded by the compiled
+// view, see Engine.StrictSections.
+func orphanSections(ctx *CompileContext, f *ParsedFile) error {
+	chain := []*ParsedFile{f}
+	var layouts []string
+	for file := f; file.Extends != ""; {
+		parent, ok := lookupFile(ctx.Files, file.Extends)
+		if !ok || slices.Contains(layouts, parent.Name) {
+			break
+		}
+		chain = append(chain, parent)
+		layouts = append(layouts, parent.Name)
+		file = parent
+	}
+	for _, file := range chain {
+		if file.Extends == "" {
+			continue
+		}
+		for _, name := range sortedKeys(file.Sections) {
+			if _, ok := ctx.Yields[name]; !ok {
+				return fmt.Errorf("[%s] %s", file.Name, orphanSectionMessage(name, layouts))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package blade
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSectionPolicies(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layouts/base.blade": `@yield('scripts', 'base.js')|@yield('title')|@yield('meta')`,
+		"layouts/app.blade":  `@extends('layouts/base')@section('scripts', policy: 'append')app.js@endsection@section('title', 'App')@section('meta')app-meta@endsection`,
+		"page.blade":         `@extends('layouts/app')@section('scripts', policy: 'append')page.js@endsection@section('title', 'Page', policy: 'prepend')@section('meta')page-meta@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := "base.js\napp.js\npage.js|Page\nApp|page-meta"; buf.String() != expected {
+		t.Errorf("Section policies mismatch.\nExp: %q\nGot: %q", expected, buf.String())
+	}
+
+	engine = NewEngineFS(mockFS)
+	engine.SectionPolicies = map[string]SectionPolicy{"meta": SectionAppend}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	buf.Reset()
+	if err := engine.Render(&buf, "page", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "|app-meta\npage-meta") {
+		t.Errorf("Expected the engine policy to append meta, got %q", buf.String())
+	}
+
+	engine = NewEngineFS(mockFS)
+	engine.SectionPolicy = SectionError
+	err := engine.Load()
+	if err == nil || !strings.Contains(err.Error(), `[page] section "meta" conflicts with the section of "layouts/app"`) {
+		t.Errorf("Expected a section conflict, got %v", err)
+	}
+
+	if _, err := engine.Parse("page", `@section('title', policy: 'merge')Title@endsection`); err == nil {
+		t.Error("Expected an invalid policy error")
+	}
+}
+
+func TestStrictSections(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layouts/base.blade": `@yield('content')`,
+		"layouts/app.blade":  `@extends('layouts/base')@section('content')@include('nav')@endsection`,
+		"nav.blade":          `<nav>@yield('nav')</nav>`,
+		"page.blade":         `@extends('layouts/app')@section('nav')Nav@endsection@section('sidebar')Dropped@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	warnings := engine.Warnings()
+	if len(warnings) != 1 || warnings[0].Message != `section "sidebar" is not yielded by any layout, searched layouts/app, layouts/base` {
+		t.Errorf("Expected an unused section warning, got %v", warnings)
+	}
+
+	engine = NewEngineFS(mockFS)
+	engine.StrictSections = true
+	err := engine.Load()
+	if err == nil || err.Error() != `[page] section "sidebar" is not yielded by any layout, searched layouts/app, layouts/base` {
+		t.Errorf("Expected an orphan section error, got %v", err)
+	}
+
+	mockFS["page.blade"].Data = []byte(`@extends('layouts/app')@section('nav')Nav@endsection`)
+	if err := engine.Load(); err != nil {
+		t.Errorf("Load failed: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package blade
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"time"
+)
+
+// timedSectionSnippet is the name prefix of the snippets holding the content of sections with a timeout.
+const timedSectionSnippet = "__timeout_"
+
+// blockOutput is the output of a section with a timeout or of an include with a fallback, OK is false when
+// the fallback renders instead.
+type blockOutput struct {
+	OK     bool
+	Output any
+}
+
+// parseSectionTimeout removes the timeout and fallback arguments from the arguments of @section:
+// @section('report', timeout: '2s', fallback: 'Report unavailable'). It returns a zero timeout when the
+// section has none.
+func parseSectionTimeout(args []string) ([]string, time.Duration, string, error) {
+	if len(args) < 2 {
+		return args, 0, "", nil
+	}
+	kept := args[:1:1]
+	var timeout time.Duration
+	var fallback string
+	hasFallback := false
+	for _, arg := range args[1:] {
+		argName, value, ok := parseNamedDirectiveArg(arg)
+		switch {
+		case ok && argName == "timeout":
+			text, _ := unquoteDirectiveString(value)
+			d, err := time.ParseDuration(text)
+			if err != nil || d <= 0 {
+				return nil, 0, "", fmt.Errorf("invalid @section timeout %s", value)
+			}
+			timeout = d
+		case ok && argName == "fallback":
+			fallback, hasFallback = directiveValueToTemplate(value), true
+		default:
+			kept = append(kept, arg)
+		}
+	}
+	if hasFallback && timeout == 0 {
+		return nil, 0, "", fmt.Errorf("@section fallback without timeout")
+	}
+	return kept, timeout, fallback, nil
+}
+
+// timedSection moves the content of the section name to a snippet rendered with a timeout, the fallback
+// renders in place of the content when it times out:
+// {{ $__timed := __timedSection "report" "__snippet_page:__timeout_report" 2000000000 . }}
+// {{ if $__timed.OK }}{{ $__timed.Output }}{{ else }}fallback{{ end }}
+func (p *ParsedFile) timedSection(name string, content string, timeout time.Duration, fallback string) string {
+	snippet := timedSectionSnippet + name
+	p.Snippets[snippet] = content
+	return fmt.Sprintf(`{{ $__timed := __timedSection %q %q %d . }}{{ if $__timed.OK }}{{ $__timed.Output }}{{ else }}%s{{ end }}`,
+		name, snippetTemplateName(p.Name, snippet), int64(timeout), fallback)
+}
+
+// timedSection renders the snippet of a section in its own goroutine, with its own render state and a
+// context canceled after timeout. When the section does not finish in time, the render continues with the
+// fallback and the section is reported to Engine.OnSectionTimeout, while its goroutine runs to completion
+// and its output is dropped. Renders without a compiled template, like profiles, render it in place.
+func (s *renderState) timedSection(section string, snippet string, timeout int64, data any) (blockOutput, error) {
+	if s.proto == nil {
+		out, err := s.executeDefine(snippet, data)
+		return blockOutput{OK: err == nil, Output: out}, err
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, time.Duration(timeout))
+	defer cancel()
+	state := s.e.newRenderState(ctx, nil)
+	tmpl, err := s.proto.clone(append([]template.FuncMap{state.funcs()}, s.dataFuncs...)...)
+	if err != nil {
+		return blockOutput{}, err
+	}
+	state.tmpl, state.proto, state.dataFuncs = tmpl, s.proto, s.dataFuncs
+
+	type result struct {
+		out any
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("section %s panicked: %v", section, r)}
+			}
+		}()
+		out, err := state.executeDefine(snippet, data)
+		done <- result{out: out, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return blockOutput{OK: r.err == nil, Output: r.out}, r.err
+	case <-ctx.Done():
+		if err := s.ctx.Err(); err != nil {
+			return blockOutput{}, err
+		}
+		if s.e.OnSectionTimeout != nil {
+			s.e.OnSectionTimeout(s.ctx, section, time.Duration(timeout))
+		} else {
+			slog.WarnContext(s.ctx, "blade: section timed out, rendering its fallback", "section", section, "timeout", time.Duration(timeout))
+		}
+		return blockOutput{}, nil
+	}
+}
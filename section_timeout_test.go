@@ -0,0 +1,64 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSectionTimeout(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `<main>@yield('report')</main><aside>@yield('side')</aside>`,
+		"page.blade": `@extends('layout')` +
+			`@section('report', timeout: '20ms', fallback: 'Report unavailable'){{ wait .Delay }}Report {{ .Name }}@endsection` +
+			`@section('side', timeout: '1s', 'Side')`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.FuncMap["wait"] = func(d time.Duration) string {
+		time.Sleep(d)
+		return ""
+	}
+	var timedOut []string
+	engine.OnSectionTimeout = func(ctx context.Context, section string, timeout time.Duration) {
+		timedOut = append(timedOut, section+" "+timeout.String())
+	}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", map[string]any{"Delay": time.Duration(0), "Name": "<Q1>"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `<main>Report &lt;Q1&gt;</main><aside>Side</aside>`; buf.String() != expected {
+		t.Errorf("Render mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	buf.Reset()
+	start := time.Now()
+	if err := engine.Render(&buf, "page", map[string]any{"Delay": 500 * time.Millisecond, "Name": "Q1"}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("Render waited for the slow section: %s", elapsed)
+	}
+	if expected := `<main>Report unavailable</main><aside>Side</aside>`; buf.String() != expected {
+		t.Errorf("Render mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+	if len(timedOut) != 1 || timedOut[0] != "report 20ms" {
+		t.Errorf("Unexpected timeouts: %v", timedOut)
+	}
+}
+
+func TestSectionTimeoutInvalid(t *testing.T) {
+	for _, raw := range []string{
+		`@section('report', timeout: 'soon')x@endsection`,
+		`@section('report', fallback: 'none')x@endsection`,
+	} {
+		engine := NewEngineFS(createMockFS(map[string]string{"page.blade": raw}))
+		if err := engine.Load(); err == nil {
+			t.Errorf("Expected an error for %s", raw)
+		}
+	}
+}
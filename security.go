@@ -0,0 +1,141 @@
+package blade
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"maps"
+	"strings"
+)
+
+// Defaults of SecurityOptions.
+const (
+	DefaultCSRFField  = "_csrf"
+	DefaultCSRFHeader = "X-CSRF-Token"
+	DefaultCSRFCookie = "_csrf"
+	// DefaultContentSecurityPolicy allows the scripts and styles of the site and the inline ones carrying the
+	// nonce of the request, rendered by @nonce
+	DefaultContentSecurityPolicy = "default-src 'self'; script-src 'self' 'nonce-{nonce}'; style-src 'self' 'nonce-{nonce}'; " +
+		"object-src 'none'; base-uri 'self'; frame-ancestors 'self'"
+)
+
+// DefaultSecurityHeaders are the headers sent by the security middleware besides the Content-Security-Policy.
+var DefaultSecurityHeaders = map[string]string{
+	"X-Content-Type-Options": "nosniff",
+	"X-Frame-Options":        "SAMEORIGIN",
+	"Referrer-Policy":        "strict-origin-when-cross-origin",
+}
+
+var (
+	errNoCSRFToken = errors.New("@csrf: no CSRF token in the render context, use the security middleware or WithCSRFToken")
+	errNoNonce     = errors.New("@nonce: no CSP nonce in the render context, use the security middleware or WithNonce")
+)
+
+type (
+	csrfTokenKey struct{}
+	nonceKey     struct{}
+)
+
+// SecurityOptions configures @csrf and @nonce and the middleware of the security package providing their
+// values, so the views and the middleware always agree on the field names and the policy.
+type SecurityOptions struct {
+	// CSRFField is the form field rendered by @csrf, DefaultCSRFField when empty
+	CSRFField string
+	// CSRFHeader is the request header holding the token of requests without form, like fetch ones,
+	// DefaultCSRFHeader when empty
+	CSRFHeader string
+	// CSRFCookie is the cookie holding the token of the browser, DefaultCSRFCookie when empty
+	CSRFCookie string
+	// ContentSecurityPolicy is the Content-Security-Policy header, {nonce} is replaced by the nonce of the
+	// request rendered by @nonce. DefaultContentSecurityPolicy when empty, "-" sends none.
+	ContentSecurityPolicy string
+	// Headers are the other headers of the responses, DefaultSecurityHeaders when nil
+	Headers map[string]string
+}
+
+// WithDefaults returns the options with the defaults of the empty fields.
+func (o SecurityOptions) WithDefaults() SecurityOptions {
+	if o.CSRFField == "" {
+		o.CSRFField = DefaultCSRFField
+	}
+	if o.CSRFHeader == "" {
+		o.CSRFHeader = DefaultCSRFHeader
+	}
+	if o.CSRFCookie == "" {
+		o.CSRFCookie = DefaultCSRFCookie
+	}
+	if o.ContentSecurityPolicy == "" {
+		o.ContentSecurityPolicy = DefaultContentSecurityPolicy
+	}
+	if o.Headers == nil {
+		o.Headers = maps.Clone(DefaultSecurityHeaders)
+	}
+	return o
+}
+
+// ContentSecurityPolicyFor returns the Content-Security-Policy header of a response with nonce, empty when the
+// options send none.
+func (o SecurityOptions) ContentSecurityPolicyFor(nonce string) string {
+	policy := o.WithDefaults().ContentSecurityPolicy
+	if policy == "-" {
+		return ""
+	}
+	return strings.ReplaceAll(policy, "{nonce}", nonce)
+}
+
+// WithCSRFToken returns a context carrying the CSRF token rendered by @csrf.
+func WithCSRFToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, csrfTokenKey{}, token)
+}
+
+// CSRFToken returns the token stored by WithCSRFToken, or an empty string.
+func CSRFToken(ctx context.Context) string {
+	token, _ := ctx.Value(csrfTokenKey{}).(string)
+	return token
+}
+
+// WithNonce returns a context carrying the Content-Security-Policy nonce rendered by @nonce.
+func WithNonce(ctx context.Context, nonce string) context.Context {
+	return context.WithValue(ctx, nonceKey{}, nonce)
+}
+
+// Nonce returns the nonce stored by WithNonce, or an empty string.
+func Nonce(ctx context.Context) string {
+	nonce, _ := ctx.Value(nonceKey{}).(string)
+	return nonce
+}
+
+// csrf renders the hidden field of @csrf.
+func (s *renderState) csrf() (template.HTML, error) {
+	token := CSRFToken(s.ctx)
+	if token == "" {
+		return "", errNoCSRFToken
+	}
+	field := s.e.Security.WithDefaults().CSRFField
+	return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(field) + `" value="` + template.HTMLEscapeString(token) + `">`), nil
+}
+
+// csrfToken returns the CSRF token of the render, for a meta tag read by scripts.
+func (s *renderState) csrfToken() (string, error) {
+	if token := CSRFToken(s.ctx); token != "" {
+		return token, nil
+	}
+	return "", errNoCSRFToken
+}
+
+// nonceAttr renders the nonce attribute of @nonce: <script @nonce> => <script nonce="...">
+func (s *renderState) nonceAttr() (template.HTMLAttr, error) {
+	nonce, err := s.cspNonce()
+	if err != nil {
+		return "", err
+	}
+	return template.HTMLAttr(`nonce="` + template.HTMLEscapeString(nonce) + `"`), nil
+}
+
+// cspNonce returns the Content-Security-Policy nonce of the render.
+func (s *renderState) cspNonce() (string, error) {
+	if nonce := Nonce(s.ctx); nonce != "" {
+		return nonce, nil
+	}
+	return "", errNoNonce
+}
@@ -0,0 +1,105 @@
+// Package security provides a gin middleware generating the CSRF tokens rendered by @csrf and the
+// Content-Security-Policy nonces rendered by @nonce, and sending the security headers, all configured by the
+// SecurityOptions of the blade.Engine rendering the views.
+package security
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/dangdungcntt/go-blade"
+	"github.com/gin-gonic/gin"
+)
+
+// tokenSize is the size in bytes of the CSRF tokens and nonces.
+const tokenSize = 32
+
+// Middleware returns a gin middleware protecting the routes with the options of eng.Security:
+//
+//   - the CSRF token of the browser is kept in a cookie, requests with other methods than GET, HEAD, OPTIONS and
+//     TRACE are refused with 403 Forbidden unless they send it in the @csrf form field or the CSRF header
+//   - every request gets a new nonce, sent in the Content-Security-Policy header and rendered by @nonce
+//   - the other security headers are sent with every response
+//
+// The token and the nonce are added to the request context for the views rendered with c.HTML and the gin
+// HTMLRender of the engine, or with Engine.RenderContext and blade.RequestContext.
+func Middleware(eng *blade.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts := eng.Security.WithDefaults()
+
+		token, known := cookieToken(c.Request, opts.CSRFCookie)
+		if !known {
+			var err error
+			if token, err = randomToken(); err != nil {
+				_ = c.AbortWithError(http.StatusInternalServerError, err)
+				return
+			}
+			http.SetCookie(c.Writer, &http.Cookie{
+				Name:     opts.CSRFCookie,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   c.Request.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		if !safeMethod(c.Request.Method) {
+			submitted := c.GetHeader(opts.CSRFHeader)
+			if submitted == "" {
+				submitted = c.PostForm(opts.CSRFField)
+			}
+			if !known || subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+		}
+
+		nonce, err := randomToken()
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		for name, value := range opts.Headers {
+			c.Header(name, value)
+		}
+		if policy := opts.ContentSecurityPolicyFor(nonce); policy != "" {
+			c.Header("Content-Security-Policy", policy)
+		}
+
+		blade.SetGinRenderContext(c, blade.WithNonce(blade.WithCSRFToken(blade.GinRenderContext(c), token), nonce))
+		c.Next()
+	}
+}
+
+// cookieToken returns the CSRF token of the cookie name, false when it is missing or malformed.
+func cookieToken(r *http.Request, name string) (string, bool) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return "", false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil || len(raw) != tokenSize {
+		return "", false
+	}
+	return cookie.Value, true
+}
+
+// randomToken returns a random URL safe token.
+func randomToken() (string, error) {
+	raw := make([]byte, tokenSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// safeMethod reports whether requests with method do not change state and are not checked for a CSRF token.
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dangdungcntt/go-blade"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := blade.NewEngineFS(fstest.MapFS{
+		"form.blade": {Data: []byte(`<form method="post">@csrf</form><script @nonce>start()</script>`)},
+	})
+	eng.Security.CSRFField = "token"
+	if err := eng.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	router := gin.New()
+	router.HTMLRender = blade.NewHTMLRender(eng)
+	router.Use(Middleware(eng))
+	router.GET("/form", func(c *gin.Context) { c.HTML(http.StatusOK, "form", nil) })
+	router.POST("/form", func(c *gin.Context) { c.String(http.StatusOK, "saved") })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/form", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status %d: %s", w.Code, w.Body)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != blade.DefaultCSRFCookie || !cookies[0].HttpOnly {
+		t.Fatalf("Unexpected cookies: %v", cookies)
+	}
+	token := cookies[0].Value
+	nonce := regexp.MustCompile(`'nonce-([^']+)'`).FindStringSubmatch(w.Header().Get("Content-Security-Policy"))
+	if nonce == nil {
+		t.Fatalf("Missing nonce in the policy: %s", w.Header().Get("Content-Security-Policy"))
+	}
+	expected := `<form method="post"><input type="hidden" name="token" value="` + token + `"></form><script nonce="` + nonce[1] + `">start()</script>`
+	if w.Body.String() != expected {
+		t.Errorf("Body mismatch.\nExp: %s\nGot: %s", expected, w.Body)
+	}
+	if w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("Missing security headers: %v", w.Header())
+	}
+
+	for _, tc := range []struct {
+		name   string
+		cookie bool
+		form   url.Values
+		header string
+		status int
+	}{
+		{"form token", true, url.Values{"token": {token}}, "", http.StatusOK},
+		{"header token", true, nil, token, http.StatusOK},
+		{"missing token", true, nil, "", http.StatusForbidden},
+		{"wrong token", true, url.Values{"token": {"forged"}}, "", http.StatusForbidden},
+		{"missing cookie", false, url.Values{"token": {token}}, "", http.StatusForbidden},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/form", strings.NewReader(tc.form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if tc.cookie {
+			r.AddCookie(cookies[0])
+		}
+		if tc.header != "" {
+			r.Header.Set(blade.DefaultCSRFHeader, tc.header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.name, tc.status, w.Code)
+		}
+	}
+}
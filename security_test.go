@@ -0,0 +1,37 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCSRFAndNonce(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"form.blade": `<meta name="csrf-token" content="{{ csrfToken }}"><form>@csrf</form><style @nonce>p{}</style>`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.RenderContext(context.Background(), &buf, "form", nil); !errors.Is(err, errNoCSRFToken) {
+		t.Errorf("Expected a missing token error, got %v", err)
+	}
+
+	buf.Reset()
+	ctx := WithNonce(WithCSRFToken(context.Background(), `a"b`), "n1")
+	if err := engine.RenderContext(ctx, &buf, "form", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<meta name="csrf-token" content="a&#34;b"><form><input type="hidden" name="_csrf" value="a&#34;b"></form><style nonce="n1">p{}</style>`
+	if buf.String() != expected {
+		t.Errorf("Output mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	if policy := (SecurityOptions{}).ContentSecurityPolicyFor("n1"); policy != "default-src 'self'; script-src 'self' 'nonce-n1'; style-src 'self' 'nonce-n1'; object-src 'none'; base-uri 'self'; frame-ancestors 'self'" {
+		t.Errorf("Unexpected policy: %s", policy)
+	}
+}
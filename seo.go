@@ -0,0 +1,102 @@
+package blade
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// metaStack is the stack @meta pushes to, layouts render it in their head with @stack('meta').
+const metaStack = "meta"
+
+// Meta describes a page for search engines and link previews, it is the data of the built-in blade/meta component.
+type Meta struct {
+	Title       string
+	Description string
+	// Image is the URL of the Open Graph image
+	Image string
+	// URL is the canonical URL of the page
+	URL string
+	// Type is the Open Graph type, "website" when empty
+	Type     string
+	SiteName string
+}
+
+// metaFields maps the named arguments of @meta to the fields of Meta.
+var metaFields = map[string]func(m *Meta, value string){
+	"title":       func(m *Meta, value string) { m.Title = value },
+	"description": func(m *Meta, value string) { m.Description = value },
+	"image":       func(m *Meta, value string) { m.Image = value },
+	"url":         func(m *Meta, value string) { m.URL = value },
+	"type":        func(m *Meta, value string) { m.Type = value },
+	"siteName":    func(m *Meta, value string) { m.SiteName = value },
+}
+
+// parseMetaDirective converts @meta to a push of the built-in blade/meta component to the meta stack:
+// @meta(title: .Title, image: .Cover) => @push('meta')@include('blade/meta', (__meta "title" (.Title) "image" (.Cover)))@endpush
+func parseMetaDirective(args []string) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	var call strings.Builder
+	call.WriteString("__meta")
+	for _, arg := range args {
+		name, value, ok := parseNamedDirectiveArg(arg)
+		if _, known := metaFields[name]; !ok || !known {
+			return "", false
+		}
+		if text, ok := unquoteDirectiveString(value); ok {
+			value = fmt.Sprintf("%q", text)
+		}
+		fmt.Fprintf(&call, " %q (%s)", name, value)
+	}
+	return fmt.Sprintf(`@push('%s')@include('%smeta', (%s))@endpush`, metaStack, componentNamePrefix, call.String()), true
+}
+
+// newMeta builds the Meta of a @meta directive from name and value pairs.
+func newMeta(pairs ...any) (Meta, error) {
+	var m Meta
+	if len(pairs)%2 != 0 {
+		return m, errors.New("meta: expected name value pairs")
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		set, ok := metaFields[fmt.Sprint(pairs[i])]
+		if !ok {
+			return m, fmt.Errorf("meta: unknown field %v", pairs[i])
+		}
+		if pairs[i+1] != nil {
+			set(&m, fmt.Sprint(pairs[i+1]))
+		}
+	}
+	return m, nil
+}
+
+// metaTitle returns the title element of a page and its Open Graph title.
+func metaTitle(title string) template.HTML {
+	return template.HTML("<title>" + template.HTMLEscapeString(title) + "</title>\n" + metaProperty("og:title", title))
+}
+
+// metaDescription returns the description meta tag of a page and its Open Graph description.
+func metaDescription(description string) template.HTML {
+	return template.HTML(`<meta name="description" content="` + template.HTMLEscapeString(description) + "\">\n" + metaProperty("og:description", description))
+}
+
+// ogImage returns the Open Graph image of a page, previewed as a large card. Only http, https and relative
+// URLs are accepted.
+func ogImage(imageURL string) (template.HTML, error) {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return "", fmt.Errorf("ogImage: %w", err)
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("ogImage: unsupported URL scheme %q", u.Scheme)
+	}
+	return template.HTML(metaProperty("og:image", imageURL) + "\n" + `<meta name="twitter:card" content="summary_large_image">`), nil
+}
+
+// metaProperty returns an Open Graph meta tag.
+func metaProperty(property string, content string) string {
+	return `<meta property="` + property + `" content="` + template.HTMLEscapeString(content) + `">`
+}
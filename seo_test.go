@@ -0,0 +1,70 @@
+package blade
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMetaDirective(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `<head>@stack('meta')</head>@yield('content')`,
+		"page.blade": `@extends('layout')
+@meta(title: .Title, description: 'A "quoted" <summary>', url: 'https://example.com/posts/1', image: .Cover, type: 'article')
+@section('content')<p>{{ .Title }}</p>@endsection`,
+		"custom.blade": `@push('meta'){{ metaTitle "Custom" }}@endpush<head>@stack('meta')</head>`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{"Title": "Tom & Jerry", "Cover": "https://example.com/cover.png"}
+	if err := engine.Render(&buf, "page", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<head><title>Tom &amp; Jerry</title>
+<meta property="og:title" content="Tom &amp; Jerry">
+<meta name="description" content="A &#34;quoted&#34; &lt;summary&gt;">
+<meta property="og:description" content="A &#34;quoted&#34; &lt;summary&gt;">
+<link rel="canonical" href="https://example.com/posts/1">
+<meta property="og:url" content="https://example.com/posts/1">
+<meta property="og:type" content="article">
+<meta property="og:image" content="https://example.com/cover.png">
+<meta name="twitter:card" content="summary_large_image"></head><p>Tom &amp; Jerry</p>`
+	if buf.String() != expected {
+		t.Errorf("Meta mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := engine.Render(&buf, "custom", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `<head><title>Custom</title>
+<meta property="og:title" content="Custom"></head>`; buf.String() != expected {
+		t.Errorf("Meta helpers mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := engine.Render(&buf, "page", map[string]any{"Title": "x", "Cover": "javascript:alert(1)"}); err == nil {
+		t.Error("Expected an error for a javascript: og:image")
+	}
+}
+
+func TestMetaComponentOverride(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"blade/meta.blade": `<title>{{ .Title }} | Site</title>`,
+		"page.blade":       `@meta(title: 'Home')<head>@stack('meta')</head>`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `<head><title>Home | Site</title></head>`; buf.String() != expected {
+		t.Errorf("Override mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+}
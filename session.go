@@ -0,0 +1,195 @@
+package blade
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// Default flash keys of the old input and the validation errors of a form, read by old and errors.
+const (
+	DefaultOldInputFlash = "_old_input"
+	DefaultErrorsFlash   = "_errors"
+)
+
+// SessionReader reads the session of the request rendered, for @session and the session func.
+type SessionReader interface {
+	Get(key string) any
+}
+
+// FlashStore reads the flash messages of the request rendered, which the store removes once read. A render
+// reads each key at most once, so old and errors can be called for every field of a form.
+type FlashStore interface {
+	Flashes(key string) []any
+}
+
+type (
+	sessionKey    struct{}
+	flashStoreKey struct{}
+)
+
+// WithSession returns a context carrying the session read by @session, session, old and errors. The flashes
+// are read from s when it implements FlashStore and the context carries no other one, like the adapters of the
+// session package.
+func WithSession(ctx context.Context, s SessionReader) context.Context {
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+// Session returns the session stored by WithSession, or nil.
+func Session(ctx context.Context) SessionReader {
+	s, _ := ctx.Value(sessionKey{}).(SessionReader)
+	return s
+}
+
+// WithFlashStore returns a context carrying the flash messages read by @session, old and errors.
+func WithFlashStore(ctx context.Context, s FlashStore) context.Context {
+	return context.WithValue(ctx, flashStoreKey{}, s)
+}
+
+// Flashes returns the flash store stored by WithFlashStore, or the session when it implements FlashStore, or nil.
+func Flashes(ctx context.Context) FlashStore {
+	if s, ok := ctx.Value(flashStoreKey{}).(FlashStore); ok {
+		return s
+	}
+	s, _ := Session(ctx).(FlashStore)
+	return s
+}
+
+// FormErrors are the validation errors of a form by field, returned by errors.
+type FormErrors map[string][]string
+
+// Has reports whether field has errors.
+func (e FormErrors) Has(field string) bool {
+	return len(e[field]) > 0
+}
+
+// First returns the first error of field, or an empty string.
+func (e FormErrors) First(field string) string {
+	if msgs := e[field]; len(msgs) > 0 {
+		return msgs[0]
+	}
+	return ""
+}
+
+// Get returns the errors of field.
+func (e FormErrors) Get(field string) []string {
+	return e[field]
+}
+
+// Any reports whether the form has errors.
+func (e FormErrors) Any() bool {
+	return len(e) > 0
+}
+
+// All returns the errors of all the fields, sorted by field.
+func (e FormErrors) All() []string {
+	var all []string
+	for _, field := range sortedKeys(e) {
+		all = append(all, e[field]...)
+	}
+	return all
+}
+
+// flashes returns the flash messages of key, read once per render.
+func (s *renderState) flashes(key string) []any {
+	if values, ok := s.flashed[key]; ok {
+		return values
+	}
+	var values []any
+	if store := Flashes(s.ctx); store != nil {
+		values = store.Flashes(key)
+	}
+	s.flashed[key] = values
+	return values
+}
+
+// session returns the value of key in the session, or its first flash message: @session('status') shows the
+// status flashed by the previous request. It returns nil without session.
+func (s *renderState) session(key string) any {
+	if sess := Session(s.ctx); sess != nil {
+		if v := sess.Get(key); v != nil {
+			return v
+		}
+	}
+	if values := s.flashes(key); len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+// old returns the input of field flashed by the request redirecting back to the form, or def, to refill the
+// form after a validation error: <input name="email" value="{{ old "email" }}">.
+func (s *renderState) old(field string, def ...any) any {
+	for _, input := range slices.Backward(s.flashes(DefaultOldInputFlash)) {
+		if v, ok := formValue(input, field); ok {
+			return v
+		}
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return ""
+}
+
+// errors returns the validation errors flashed by the request redirecting back to the form:
+// {{ if (errors).Has "email" }}{{ (errors).First "email" }}{{ end }}.
+func (s *renderState) errors() FormErrors {
+	errs := FormErrors{}
+	for _, flash := range s.flashes(DefaultErrorsFlash) {
+		switch v := flash.(type) {
+		case FormErrors:
+			mergeFormErrors(errs, v)
+		case map[string][]string:
+			mergeFormErrors(errs, v)
+		case url.Values:
+			mergeFormErrors(errs, v)
+		case map[string]string:
+			for field, msg := range v {
+				errs[field] = append(errs[field], msg)
+			}
+		case map[string]any:
+			for field, msg := range v {
+				switch msg := msg.(type) {
+				case []string:
+					errs[field] = append(errs[field], msg...)
+				case []any:
+					for _, m := range msg {
+						errs[field] = append(errs[field], fmt.Sprint(m))
+					}
+				default:
+					errs[field] = append(errs[field], fmt.Sprint(msg))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// mergeFormErrors appends the errors of src to dst.
+func mergeFormErrors(dst FormErrors, src map[string][]string) {
+	for field, msgs := range src {
+		dst[field] = append(dst[field], msgs...)
+	}
+}
+
+// formValue returns the value of field in the flashed input of a form.
+func formValue(input any, field string) (any, bool) {
+	switch v := input.(type) {
+	case url.Values:
+		if values, ok := v[field]; ok && len(values) > 0 {
+			return values[0], true
+		}
+	case map[string][]string:
+		if values, ok := v[field]; ok && len(values) > 0 {
+			return values[0], true
+		}
+	case map[string]string:
+		value, ok := v[field]
+		return value, ok
+	case map[string]any:
+		value, ok := v[field]
+		return value, ok
+	}
+	return nil, false
+}
@@ -0,0 +1,99 @@
+// Package session adapts the sessions of gorilla/sessions and gin-contrib/sessions to the blade.SessionReader
+// and blade.FlashStore read by @session, old and errors, and provides a gin middleware adding them to the render
+// context. The adapters depend on the methods of the sessions only, so the package imports neither library.
+package session
+
+import (
+	"github.com/dangdungcntt/go-blade"
+	"github.com/gin-gonic/gin"
+)
+
+// GinContribSession is the part of the sessions.Session of gin-contrib/sessions used by the views and wizards.
+type GinContribSession interface {
+	Get(key any) any
+	Set(key any, val any)
+	Flashes(vars ...string) []any
+}
+
+// Adapter is a blade.SessionWriter and blade.FlashStore using a session of another library. It keeps the
+// flashes it read, so they can be preloaded before the session is saved.
+type Adapter struct {
+	get     func(key string) any
+	set     func(key string, value any)
+	flashes func(key string) []any
+	flashed map[string][]any
+}
+
+var (
+	_ blade.SessionWriter = (*Adapter)(nil)
+	_ blade.FlashStore    = (*Adapter)(nil)
+)
+
+// Gorilla adapts a session of gorilla/sessions from its values and its Flashes method:
+// session.Gorilla(sess.Values, sess.Flashes).
+func Gorilla(values map[any]any, flashes func(vars ...string) []any) *Adapter {
+	return &Adapter{
+		get:     func(key string) any { return values[key] },
+		set:     func(key string, value any) { values[key] = value },
+		flashes: func(key string) []any { return flashes(key) },
+		flashed: map[string][]any{},
+	}
+}
+
+// GinContrib adapts a session of gin-contrib/sessions: session.GinContrib(sessions.Default(c)).
+func GinContrib(s GinContribSession) *Adapter {
+	return &Adapter{
+		get:     func(key string) any { return s.Get(key) },
+		set:     func(key string, value any) { s.Set(key, value) },
+		flashes: func(key string) []any { return s.Flashes(key) },
+		flashed: map[string][]any{},
+	}
+}
+
+// Get implements blade.SessionReader.
+func (a *Adapter) Get(key string) any {
+	return a.get(key)
+}
+
+// Set implements blade.SessionWriter, the session must be saved afterwards like with the library.
+func (a *Adapter) Set(key string, value any) {
+	a.set(key, value)
+}
+
+// Flashes implements blade.FlashStore.
+func (a *Adapter) Flashes(key string) []any {
+	if values, ok := a.flashed[key]; ok {
+		return values
+	}
+	values := a.flashes(key)
+	a.flashed[key] = values
+	return values
+}
+
+// Preload reads the flashes of keys now, the old input and the errors of forms when keys is empty. Both
+// libraries only remove read flashes when the session is saved, which must happen before the response is
+// written for cookie stores: preload the flashes and save the session before rendering.
+func (a *Adapter) Preload(keys ...string) *Adapter {
+	if len(keys) == 0 {
+		keys = []string{blade.DefaultOldInputFlash, blade.DefaultErrorsFlash}
+	}
+	for _, key := range keys {
+		a.Flashes(key)
+	}
+	return a
+}
+
+// Middleware returns a gin middleware adding the session returned by adapt to the render context of the
+// request, for the views rendered with c.HTML and the gin HTMLRender of blade:
+//
+//	r.Use(sessions.Sessions("app", store), session.Middleware(func(c *gin.Context) blade.SessionReader {
+//		return session.GinContrib(sessions.Default(c))
+//	}))
+func Middleware(adapt func(c *gin.Context) blade.SessionReader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s := adapt(c); s != nil {
+			blade.SetGinRenderContext(c, blade.WithSession(blade.GinRenderContext(c), s))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,90 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dangdungcntt/go-blade"
+	"github.com/gin-gonic/gin"
+)
+
+// ginSession mimics the sessions.Session of gin-contrib/sessions.
+type ginSession struct {
+	values  map[any]any
+	flashes map[string][]any
+}
+
+func (s *ginSession) Get(key any) any {
+	return s.values[key]
+}
+
+func (s *ginSession) Set(key any, val any) {
+	s.values[key] = val
+}
+
+func (s *ginSession) Flashes(vars ...string) []any {
+	key := "_flash"
+	if len(vars) > 0 {
+		key = vars[0]
+	}
+	values := s.flashes[key]
+	delete(s.flashes, key)
+	return values
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := blade.NewEngineFS(fstest.MapFS{
+		"form.blade": {Data: []byte(`@session('status'){{ $value }} @endsession{{ session "user" }} {{ old "email" }} {{ (errors).First "email" }}`)},
+	})
+	if err := eng.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	sess := &ginSession{
+		values: map[any]any{"user": "ann"},
+		flashes: map[string][]any{
+			"status":                   {"Saved"},
+			blade.DefaultOldInputFlash: {map[string]string{"email": "ann@"}},
+			blade.DefaultErrorsFlash:   {map[string]string{"email": "is invalid"}},
+		},
+	}
+	router := gin.New()
+	router.HTMLRender = blade.NewHTMLRender(eng)
+	router.Use(Middleware(func(c *gin.Context) blade.SessionReader {
+		return GinContrib(sess).Preload()
+	}))
+	router.GET("/form", func(c *gin.Context) {
+		// the old input and the errors were removed from the session before rendering
+		if len(sess.flashes) != 1 {
+			t.Errorf("Flashes not preloaded: %v", sess.flashes)
+		}
+		c.HTML(http.StatusOK, "form", nil)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/form", nil))
+	if expected := "Saved ann ann@ is invalid"; w.Body.String() != expected {
+		t.Errorf("Body mismatch.\nExp: %s\nGot: %s", expected, w.Body)
+	}
+}
+
+func TestGorilla(t *testing.T) {
+	values := map[any]any{"user": "ann"}
+	flashes := map[string][]any{"status": {"Saved"}}
+	a := Gorilla(values, func(vars ...string) []any {
+		values := flashes[vars[0]]
+		delete(flashes, vars[0])
+		return values
+	})
+	if a.Get("user") != "ann" || a.Get("missing") != nil {
+		t.Errorf("Unexpected values: %v %v", a.Get("user"), a.Get("missing"))
+	}
+	for range 2 {
+		if got := a.Flashes("status"); len(got) != 1 || got[0] != "Saved" {
+			t.Errorf("Unexpected flashes: %v", got)
+		}
+	}
+}
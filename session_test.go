@@ -0,0 +1,67 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"testing"
+)
+
+// testFlashStore removes the flashes once read, like session libraries.
+type testFlashStore struct {
+	values  map[string]any
+	flashes map[string][]any
+	reads   int
+}
+
+func (s *testFlashStore) Get(key string) any {
+	return s.values[key]
+}
+
+func (s *testFlashStore) Flashes(key string) []any {
+	s.reads++
+	values := s.flashes[key]
+	delete(s.flashes, key)
+	return values
+}
+
+func TestSessionHelpers(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"form.blade": `@session('status')<p>{{ $value }}</p>@endsession@session('user')[{{ $value }}]@endsession` +
+			`<input name="email" value="{{ old "email" }}"><input name="name" value="{{ old "name" "Guest" }}">` +
+			`{{ if (errors).Has "email" }}<em>{{ (errors).First "email" }}</em>{{ end }}{{ len (errors).All }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.RenderContext(context.Background(), &buf, "form", nil); err != nil {
+		t.Fatalf("Render without session failed: %v", err)
+	}
+	if expected := `<input name="email" value=""><input name="name" value="Guest">0`; buf.String() != expected {
+		t.Errorf("Output mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+
+	store := &testFlashStore{
+		values: map[string]any{"user": "ann"},
+		flashes: map[string][]any{
+			"status":             {"Saved <b>"},
+			DefaultOldInputFlash: {url.Values{"email": {"ann@"}}},
+			DefaultErrorsFlash:   {map[string][]string{"email": {"is invalid", "is taken"}}, map[string]string{"name": "is short"}},
+		},
+	}
+	buf.Reset()
+	if err := engine.RenderContext(WithSession(context.Background(), store), &buf, "form", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<p>Saved &lt;b&gt;</p>[ann]<input name="email" value="ann@"><input name="name" value="Guest"><em>is invalid</em>3`
+	if buf.String() != expected {
+		t.Errorf("Output mismatch.\nExp: %s\nGot: %s", expected, buf.String())
+	}
+	// user is read from the values, status, the old input and the errors from the flashes once each
+	if store.reads != 3 {
+		t.Errorf("Expected 3 flash reads, got %d", store.reads)
+	}
+}
@@ -0,0 +1,145 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// sitemapFile is the sitemap written by ExportSite.
+const sitemapFile = "sitemap.xml"
+
+// Page is a page written by ExportSite.
+type Page struct {
+	// Path is the file written, relative to SiteOptions.Dir, like "blog/hello/index.html"
+	Path string
+	// Entry is the view rendered
+	Entry string
+	// Data is the data of the view
+	Data any
+}
+
+// PageProvider returns pages to export, like a page of a view per post of a blog.
+type PageProvider func(ctx context.Context) ([]Page, error)
+
+// SiteOptions configures ExportSite.
+type SiteOptions struct {
+	// Dir is the directory the pages and assets are written to, it is created when missing
+	Dir string
+	// Pages are exported as they are
+	Pages []Page
+	// Providers return more pages to export
+	Providers []PageProvider
+	// Assets are copied to AssetsDir, like the public files of the application
+	Assets fs.FS
+	// AssetsDir is the directory of the assets, relative to Dir
+	AssetsDir string
+	// BaseURL is the URL the site is served at, like "https://example.com". When set, a sitemap.xml of the
+	// pages is written with the blade/sitemap view.
+	BaseURL string
+}
+
+// ExportSite renders pages of the loaded views to static HTML files, so sections like marketing pages or docs
+// are pre-rendered from the views of the dynamic application, and copies the assets next to them. The views
+// render with the URL of their page, the directory of index.html files, for helpers like currentURL. It returns
+// the paths of the written files, relative to Dir.
+func (e *Engine) ExportSite(ctx context.Context, opts SiteOptions) ([]string, error) {
+	pages := append([]Page{}, opts.Pages...)
+	for _, provider := range opts.Providers {
+		provided, err := provider(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("export site: %w", err)
+		}
+		pages = append(pages, provided...)
+	}
+
+	var written []string
+	seen := map[string]string{}
+	var buf bytes.Buffer
+	for _, page := range pages {
+		if !filepath.IsLocal(page.Path) {
+			return written, fmt.Errorf(`export site: invalid page path "%s"`, page.Path)
+		}
+		name := path.Clean(filepath.ToSlash(page.Path))
+		if entry, ok := seen[name]; ok {
+			return written, fmt.Errorf(`export site: "%s" is written by "%s" and "%s"`, name, entry, page.Entry)
+		}
+		seen[name] = page.Entry
+
+		buf.Reset()
+		pageURL := &url.URL{Path: "/" + strings.TrimSuffix(name, "index.html")}
+		if err := e.RenderContext(WithRequestURL(ctx, pageURL), &buf, page.Entry, page.Data); err != nil {
+			return written, fmt.Errorf("export site: %s: %w", name, err)
+		}
+		if err := writeSiteFile(filepath.Join(opts.Dir, name), buf.Bytes()); err != nil {
+			return written, fmt.Errorf("export site: %w", err)
+		}
+		written = append(written, name)
+	}
+
+	if opts.BaseURL != "" {
+		if entry, ok := seen[sitemapFile]; ok {
+			return written, fmt.Errorf(`export site: the sitemap conflicts with the page of "%s"`, entry)
+		}
+		buf.Reset()
+		baseURL := strings.TrimSuffix(opts.BaseURL, "/")
+		urls := func(yield func(SitemapURL) bool) {
+			for _, name := range written {
+				if !yield(SitemapURL{Loc: baseURL + "/" + strings.TrimSuffix(name, "index.html")}) {
+					return
+				}
+			}
+		}
+		if err := e.RenderSitemap(&buf, urls); err != nil {
+			return written, fmt.Errorf("export site: %s: %w", sitemapFile, err)
+		}
+		if err := writeSiteFile(filepath.Join(opts.Dir, sitemapFile), buf.Bytes()); err != nil {
+			return written, fmt.Errorf("export site: %w", err)
+		}
+		seen[sitemapFile] = sitemapView
+		written = append(written, sitemapFile)
+	}
+
+	if opts.Assets == nil {
+		return written, nil
+	}
+	if opts.AssetsDir != "" && !filepath.IsLocal(opts.AssetsDir) {
+		return written, fmt.Errorf(`export site: invalid assets directory "%s"`, opts.AssetsDir)
+	}
+	err := fs.WalkDir(opts.Assets, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		target := path.Join(filepath.ToSlash(opts.AssetsDir), name)
+		if entry, ok := seen[target]; ok {
+			return fmt.Errorf(`asset "%s" conflicts with the page of "%s"`, target, entry)
+		}
+		data, err := fs.ReadFile(opts.Assets, name)
+		if err != nil {
+			return err
+		}
+		if err := writeSiteFile(filepath.Join(opts.Dir, filepath.FromSlash(target)), data); err != nil {
+			return err
+		}
+		written = append(written, target)
+		return nil
+	})
+	if err != nil {
+		return written, fmt.Errorf("export site: %w", err)
+	}
+	return written, nil
+}
+
+// writeSiteFile writes a file of an exported site, creating its directory.
+func writeSiteFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
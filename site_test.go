@@ -0,0 +1,70 @@
+package blade
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExportSite(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"layout.blade": `<title>@yield('title')</title><link rel="canonical" href="{{ currentURL }}">@yield('main')`,
+		"home.blade":   `@extends('layout')@section('title', 'Home')@section('main'){{ .Tagline }}@endsection`,
+		"post.blade":   `@extends('layout')@section('title'){{ .Title }}@endsection@section('main'){{ .Body }}@endsection`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	posts := PageProvider(func(ctx context.Context) ([]Page, error) {
+		var pages []Page
+		for _, slug := range []string{"hello", "world"} {
+			pages = append(pages, Page{Path: "blog/" + slug + "/index.html", Entry: "post", Data: map[string]string{"Title": slug, "Body": "<p>"}})
+		}
+		return pages, nil
+	})
+	dir := t.TempDir()
+	written, err := engine.ExportSite(context.Background(), SiteOptions{
+		Dir:       dir,
+		Pages:     []Page{{Path: "index.html", Entry: "home", Data: map[string]string{"Tagline": "Fast"}}},
+		Providers: []PageProvider{posts},
+		Assets:    fstest.MapFS{"css/site.css": {Data: []byte("body{}")}},
+		AssetsDir: "static",
+	})
+	if err != nil {
+		t.Fatalf("ExportSite failed: %v", err)
+	}
+	expected := []string{"index.html", "blog/hello/index.html", "blog/world/index.html", "static/css/site.css"}
+	if !reflect.DeepEqual(written, expected) {
+		t.Errorf("Written files mismatch.\nExp: %v\nGot: %v", expected, written)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("ReadFile failed: %v", err)
+		}
+		return string(data)
+	}
+	if got := read("blog/hello/index.html"); got != `<title>hello</title><link rel="canonical" href="/blog/hello/">&lt;p&gt;` {
+		t.Errorf("Unexpected page: %s", got)
+	}
+	if got := read("static/css/site.css"); got != "body{}" {
+		t.Errorf("Unexpected asset: %s", got)
+	}
+
+	for _, pages := range [][]Page{
+		{{Path: "../outside.html", Entry: "home"}},
+		{{Path: "a.html", Entry: "home"}, {Path: "./a.html", Entry: "post"}},
+		{{Path: "missing.html", Entry: "missing"}},
+	} {
+		if _, err := engine.ExportSite(context.Background(), SiteOptions{Dir: dir, Pages: pages}); err == nil || !strings.HasPrefix(err.Error(), "export site: ") {
+			t.Errorf("Expected an export error for %v, got %v", pages, err)
+		}
+	}
+}
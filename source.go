@@ -0,0 +1,154 @@
+package blade
+
+import (
+	"fmt"
+	"strings"
+)
+
+// blockEndDirectives maps the block directives to their end directive.
+var blockEndDirectives = map[string]string{
+	"section": "endsection", "push": "endpush", "prependOnce": "endprependOnce", "define": "enddefine", "macro": "endmacro", "capture": "endcapture",
+	"defer": "enddefer", "once": "endonce", "rtl": "endrtl", "ltr": "endltr", "for": "endfor", "while": "endwhile", "with": "endwith",
+	"feature": "endfeature", "experiment": "endexperiment", "unless": "endunless",
+	"foreach": "endforeach", "forelse": "endforelse", "session": "endsession", "step": "endstep", "isset": "endisset",
+	"empty": "endempty",
+}
+
+// Span is a range of bytes of the raw content of a file, the end is exclusive.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Range returns the lines and characters of the span in raw.
+func (s Span) Range(raw string) Range {
+	return Range{Start: offsetPosition(raw, s.Start), End: offsetPosition(raw, s.End)}
+}
+
+// Line returns the one based line of the start of the span in raw.
+func (s Span) Line(raw string) int {
+	return strings.Count(raw[:min(s.Start, len(raw))], "\n") + 1
+}
+
+// DirectiveSpan locates a directive in the raw content of a file.
+type DirectiveSpan struct {
+	// Name is the directive name without @
+	Name string
+	// Args are the arguments of the directive as written
+	Args []string
+	// Span covers the directive and its arguments, up to the end directive for blocks
+	Span Span
+	// Body covers the content of a block, it is empty for other directives and blocks missing their end directive
+	Body Span
+}
+
+// isBlock reports whether the directive opens a block closed by an end directive.
+func (d DirectiveSpan) isBlock() bool {
+	if _, ok := blockEndDirectives[d.Name]; !ok {
+		return false
+	}
+	// @section('title', 'Home') is inline, @once(.Key) and @once are both blocks, @empty without arguments is the
+	// branch of a @forelse
+	switch d.Name {
+	case "section":
+		return len(sectionArgs(d.Args)) == 1
+	case "empty":
+		return d.Args != nil
+	}
+	return true
+}
+
+// locateDirectives returns the known directives of raw in source order, pairing blocks with their end directive.
+func locateDirectives(raw string) []DirectiveSpan {
+	var directives []DirectiveSpan
+	var open []int
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '@' {
+			continue
+		}
+		nameEnd := i + 1
+		for nameEnd < len(raw) && isWordByte(raw[nameEnd]) {
+			nameEnd++
+		}
+		name := raw[i+1 : nameEnd]
+		if _, ok := knownDirectives[name]; !ok {
+			continue
+		}
+		d := DirectiveSpan{Name: name, Span: Span{Start: i, End: nameEnd}}
+		if nameEnd < len(raw) && raw[nameEnd] == '(' {
+			end, args, ok := parseDirectiveCall(raw, i, name)
+			if !ok {
+				continue
+			}
+			d.Span.End, d.Args = end, args
+		}
+		i = d.Span.End - 1
+
+		if opener, ok := strings.CutPrefix(name, "end"); ok && blockEndDirectives[opener] == name {
+			// close the innermost open block of the directive
+			for j := len(open) - 1; j >= 0; j-- {
+				block := &directives[open[j]]
+				if block.Name != opener {
+					continue
+				}
+				block.Body = Span{Start: block.Span.End, End: d.Span.Start}
+				block.Span.End = d.Span.End
+				open = append(open[:j], open[j+1:]...)
+				break
+			}
+		}
+		if d.isBlock() {
+			open = append(open, len(directives))
+		}
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+// isWordByte reports whether b is an ASCII letter, digit or underscore.
+func isWordByte(b byte) bool {
+	return b == '_' || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
+
+// missingEndError is returned for a block missing its end directive.
+type missingEndError struct {
+	directive string
+}
+
+func (e *missingEndError) Error() string {
+	return "missing @" + blockEndDirectives[e.directive]
+}
+
+// locateError adds the line of the first unclosed block to a missingEndError, and the file name to err.
+func (p *ParsedFile) locateError(err error) error {
+	if missing, ok := err.(*missingEndError); ok {
+		if d, ok := unclosedBlock(p.Directives, missing.directive); ok {
+			return fmt.Errorf("[%s] %w for @%s at line %d", p.Name, err, missing.directive, d.Span.Line(p.Raw))
+		}
+	}
+	return fmt.Errorf("[%s] %w", p.Name, err)
+}
+
+// unclosedBlock returns the first block of directive missing its end directive.
+func unclosedBlock(directives []DirectiveSpan, directive string) (DirectiveSpan, bool) {
+	for _, d := range directives {
+		if d.Name == directive && d.isBlock() && d.Body == (Span{}) {
+			return d, true
+		}
+	}
+	return DirectiveSpan{}, false
+}
+
+// directiveLines returns the one based lines of the directives named directive whose first argument is name.
+func (p *ParsedFile) directiveLines(directive string, name string) []int {
+	var lines []int
+	for _, d := range p.Directives {
+		if d.Name != directive || len(d.Args) == 0 {
+			continue
+		}
+		if argName, ok := parseQuotedDirectiveName(d.Args[0]); ok && argName == name {
+			lines = append(lines, d.Span.Line(p.Raw))
+		}
+	}
+	return lines
+}
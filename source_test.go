@@ -0,0 +1,53 @@
+package blade
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLocateDirectives(t *testing.T) {
+	raw := "@section('title', 'Home')\n@section('main')\n@once <b>@include('nav')</b>@endonce\n@endsection"
+	directives := locateDirectives(raw)
+
+	expected := []DirectiveSpan{
+		{Name: "section", Args: []string{"'title'", "'Home'"}, Span: Span{0, 25}},
+		{Name: "section", Args: []string{"'main'"}, Span: Span{26, 91}, Body: Span{42, 80}},
+		{Name: "once", Span: Span{43, 79}, Body: Span{48, 71}},
+		{Name: "include", Args: []string{"'nav'"}, Span: Span{52, 67}},
+		{Name: "endonce", Span: Span{71, 79}},
+		{Name: "endsection", Span: Span{80, 91}},
+	}
+	if !reflect.DeepEqual(directives, expected) {
+		t.Errorf("Directives mismatch.\nExp: %+v\nGot: %+v", expected, directives)
+	}
+	if line := directives[3].Span.Line(raw); line != 3 {
+		t.Errorf("Expected the include at line 3, got %d", line)
+	}
+}
+
+func TestMissingEndLocation(t *testing.T) {
+	engine := NewEngine("dir")
+	for content, expected := range map[string]string{
+		"@section('a')A@endsection\n\n@section('b')B":          `[page] missing @endsection for @section at line 3`,
+		"Text\n@push('scripts')<script></script>":              `[page] missing @endpush for @push at line 2`,
+		"@define('row')\n<tr></tr>@enddefine\n@define('cell')": `[page] missing @enddefine for @define at line 3`,
+	} {
+		_, err := engine.Parse("page", content)
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %q, got %v", expected, err)
+		}
+	}
+
+	mockFS := createMockFS(map[string]string{
+		"page.blade": "Text\n  @push('scripts')<script></script>",
+	})
+	diagnostics, err := NewEngineFS(mockFS).Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	if len(diagnostics) != 1 || !strings.Contains(diagnostics[0].Message, "missing @endpush") ||
+		diagnostics[0].Range != (Range{Start: Position{1, 2}, End: Position{1, 18}}) {
+		t.Errorf("Expected the parse error at the unclosed push, got %+v", diagnostics)
+	}
+}
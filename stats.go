@@ -0,0 +1,96 @@
+package blade
+
+import (
+	"strings"
+	"text/template/parse"
+)
+
+// Stats reports the memory footprint of the loaded templates, see Engine.Stats.
+type Stats struct {
+	// Templates are the stats of each entry, sorted by name
+	Templates []TemplateStats
+	// TextBytes is the total size of the compiled template texts
+	TextBytes int
+	// DebugTextBytes is the size of the template texts kept for GetDebugTemplates, see Engine.DisableDebugTemplates
+	DebugTextBytes int
+	// Nodes is the total number of parse tree nodes
+	Nodes int
+	// PartialNodes is the number of parse tree nodes of partials, snippets and macros embedded in entries
+	PartialNodes int
+	// UniquePartialNodes is the number of parse tree nodes of distinct partials, snippets and macros
+	UniquePartialNodes int
+	// DuplicationFactor is PartialNodes / UniquePartialNodes, how many times partials are compiled on average
+	DuplicationFactor float64
+}
+
+// TemplateStats reports the footprint of a single entry.
+type TemplateStats struct {
+	Name string
+	// TextBytes is the size of the compiled template text
+	TextBytes int
+	// Nodes is the number of parse tree nodes of the entry and its defines
+	Nodes int
+	// Defines is the number of sections, stacks, partials, snippets and macros compiled into the entry
+	Defines int
+	// PartialNodes is the number of parse tree nodes of the embedded partials, snippets and macros
+	PartialNodes int
+}
+
+// Stats reports the compiled size, parse tree node count and partial duplication of the loaded templates.
+// Every entry embeds its own copy of the partials it includes, a high DuplicationFactor means partials
+// shared by many views dominate memory.
+func (e *Engine) Stats() Stats {
+	var stats Stats
+	uniquePartials := map[string]int{}
+
+	set := e.set.Load()
+	for _, name := range sortedKeys(set.templates) {
+		tmpl := set.templates[name]
+		if tmpl.err != nil {
+			continue
+		}
+		ts := TemplateStats{Name: name, TextBytes: tmpl.textSize}
+		for _, tree := range tmpl.proto.trees() {
+			nodes := countNodes(tree.Root)
+			ts.Nodes += nodes
+			if tree.Name == name {
+				continue
+			}
+			ts.Defines++
+			if isSharedDefine(tree.Name) {
+				ts.PartialNodes += nodes
+				uniquePartials[tree.Name] = nodes
+			}
+		}
+		stats.Templates = append(stats.Templates, ts)
+		stats.TextBytes += ts.TextBytes
+		stats.Nodes += ts.Nodes
+		stats.PartialNodes += ts.PartialNodes
+	}
+	for _, text := range set.debugTemplates {
+		stats.DebugTextBytes += len(text)
+	}
+	for _, nodes := range uniquePartials {
+		stats.UniquePartialNodes += nodes
+	}
+	stats.DuplicationFactor = 1
+	if stats.UniquePartialNodes > 0 {
+		stats.DuplicationFactor = float64(stats.PartialNodes) / float64(stats.UniquePartialNodes)
+	}
+	return stats
+}
+
+// isSharedDefine reports whether a define comes from a file that can be embedded in several entries.
+func isSharedDefine(name string) bool {
+	return strings.HasPrefix(name, partialNamePrefix) || strings.HasPrefix(name, snippetNamePrefix) || strings.HasPrefix(name, macroNamePrefix)
+}
+
+// countNodes returns the number of nodes of the tree rooted at node.
+func countNodes(node parse.Node) int {
+	count := 0
+	walkTree(node, func(parse.Node) bool {
+		count++
+		return true
+	})
+	return count
+}
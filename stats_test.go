@@ -0,0 +1,41 @@
+package blade
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"partials/nav.blade": `<nav>{{ range .Links }}<a>{{ . }}</a>{{ end }}</nav>`,
+		"home.blade":         `@include('partials/nav')Home`,
+		"about.blade":        `@include('partials/nav')About`,
+		"contact.blade":      `Contact`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.EntryFilter = func(f *ParsedFile) bool { return f.Name != "partials/nav" }
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	stats := engine.Stats()
+	if len(stats.Templates) != 3 || stats.Templates[0].Name != "about" {
+		t.Fatalf("unexpected templates %+v", stats.Templates)
+	}
+	about := stats.Templates[0]
+	if about.Defines != 1 || about.PartialNodes == 0 || about.Nodes <= about.PartialNodes || about.TextBytes == 0 {
+		t.Errorf("unexpected about stats %+v", about)
+	}
+	if stats.PartialNodes != 2*stats.UniquePartialNodes || stats.DuplicationFactor != 2 {
+		t.Errorf("unexpected duplication %+v", stats)
+	}
+	if stats.DebugTextBytes != stats.TextBytes {
+		t.Errorf("expected debug texts to be kept, got %d of %d bytes", stats.DebugTextBytes, stats.TextBytes)
+	}
+
+	engine = NewEngineFS(mockFS)
+	engine.DisableDebugTemplates = true
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if stats := engine.Stats(); stats.DebugTextBytes != 0 || stats.TextBytes == 0 {
+		t.Errorf("unexpected stats without debug templates %+v", stats)
+	}
+}
@@ -0,0 +1,169 @@
+package blade
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ellipsis marks the text cut by excerpt.
+const ellipsis = "…"
+
+var reNewline = regexp.MustCompile(`\r\n|\r|\n`)
+
+// slugLetters are the letters without a decomposition to a base letter and marks.
+var slugLetters = strings.NewReplacer("đ", "d", "Đ", "d", "ß", "ss", "æ", "ae", "Æ", "ae", "ø", "o", "Ø", "o", "ł", "l", "Ł", "l", "œ", "oe", "Œ", "oe")
+
+// nl2br escapes the text of v and converts its line breaks to <br> elements.
+// Values of type template.HTML are trusted and not escaped.
+func nl2br(v any) template.HTML {
+	text, ok := v.(template.HTML)
+	if !ok {
+		text = template.HTML(template.HTMLEscapeString(fmt.Sprint(v)))
+	}
+	return template.HTML(reNewline.ReplaceAllString(string(text), "<br>\n"))
+}
+
+// excerpt returns the first length characters of the text of v cut at a word boundary, with an ellipsis when
+// it is cut. With a search term, the excerpt is taken around the first match of the term instead:
+// excerpt .Body 160, excerpt .Body 160 .Query. The result is plain text, escaped by the template.
+func excerpt(v any, length any, term ...string) (string, error) {
+	n, err := toInt(length)
+	if err != nil {
+		return "", fmt.Errorf("excerpt: %w", err)
+	}
+	text := []rune(strings.Join(strings.Fields(fmt.Sprint(v)), " "))
+	if len(text) <= n {
+		return string(text), nil
+	}
+
+	start := 0
+	if len(term) > 0 && term[0] != "" {
+		if at := indexFold(string(text), term[0]); at != -1 {
+			// center the window on the match
+			match := utf8.RuneCountInString(string(text)[:at])
+			start = max(0, min(match-(n-utf8.RuneCountInString(term[0]))/2, len(text)-n))
+		}
+	}
+	end := start + n
+	if start > 0 {
+		// move to the start of the next word
+		for start < end && text[start-1] != ' ' {
+			start++
+		}
+	}
+	if end < len(text) {
+		// move to the end of the previous word
+		for end > start && text[end] != ' ' {
+			end--
+		}
+		if end == start {
+			end = start + n
+		}
+	}
+
+	result := strings.TrimSpace(string(text[start:end]))
+	if start > 0 {
+		result = ellipsis + result
+	}
+	if end < len(text) {
+		result += ellipsis
+	}
+	return result, nil
+}
+
+// highlight escapes the text of v and wraps the case-insensitive matches of the terms in <mark> elements:
+// {{ highlight (excerpt .Body 160 .Query) .Query }}.
+func highlight(v any, terms ...string) template.HTML {
+	text := fmt.Sprint(v)
+	var b strings.Builder
+	for text != "" {
+		at, length := -1, 0
+		for _, term := range terms {
+			if term == "" {
+				continue
+			}
+			if i := indexFold(text, term); i != -1 && (at == -1 || i < at) {
+				at, length = i, matchLength(text[i:], term)
+			}
+		}
+		if at == -1 {
+			break
+		}
+		b.WriteString(template.HTMLEscapeString(text[:at]))
+		b.WriteString("<mark>")
+		b.WriteString(template.HTMLEscapeString(text[at : at+length]))
+		b.WriteString("</mark>")
+		text = text[at+length:]
+	}
+	b.WriteString(template.HTMLEscapeString(text))
+	return template.HTML(b.String())
+}
+
+// indexFold returns the byte index of the first case-insensitive match of term in text, or -1.
+func indexFold(text string, term string) int {
+	for i := range text {
+		if matchLength(text[i:], term) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchLength returns the byte length of the case-insensitive match of term at the start of text, or 0.
+func matchLength(text string, term string) int {
+	length := 0
+	for _, want := range term {
+		got, size := utf8.DecodeRuneInString(text[length:])
+		if size == 0 || (got != want && !strings.EqualFold(string(got), string(want))) {
+			return 0
+		}
+		length += size
+	}
+	return length
+}
+
+// slugify returns a lowercase URL slug of the text of v, with accents removed and words joined by dashes:
+// "Crème Brûlée à Đà Nẵng" => "creme-brulee-a-da-nang".
+func slugify(v any) string {
+	text := slugLetters.Replace(fmt.Sprint(v))
+	text, _, _ = transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), text)
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// initials returns the uppercase initials of the first and last words of a name, or of its first n words:
+// initials "Ada King Lovelace" => "AL", initials "Ada King Lovelace" 3 => "AKL".
+func initials(v any, n ...int) string {
+	words := strings.Fields(fmt.Sprint(v))
+	if len(n) == 0 && len(words) > 2 {
+		words = []string{words[0], words[len(words)-1]}
+	} else if len(n) > 0 && len(words) > n[0] {
+		words = words[:max(n[0], 0)]
+	}
+	var b strings.Builder
+	for _, word := range words {
+		r, _ := utf8.DecodeRuneInString(word)
+		b.WriteString(strings.ToUpper(string(r)))
+	}
+	return b.String()
+}
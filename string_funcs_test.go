@@ -0,0 +1,87 @@
+package blade
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+)
+
+func TestStringFuncs(t *testing.T) {
+	if got := nl2br("a < b\r\nc\nd"); got != "a &lt; b<br>\nc<br>\nd" {
+		t.Errorf("nl2br mismatch: %q", got)
+	}
+	if got := nl2br(template.HTML("<b>a</b>\nb")); got != "<b>a</b><br>\nb" {
+		t.Errorf("nl2br safe HTML mismatch: %q", got)
+	}
+
+	text := "The quick brown fox jumps over the lazy dog"
+	for _, test := range []struct {
+		length   int
+		term     []string
+		expected string
+	}{
+		{100, nil, text},
+		{18, nil, "The quick brown…"},
+		{16, []string{"FOX"}, "…brown fox jumps…"},
+		{16, []string{"cat"}, "The quick brown…"},
+		{12, []string{"dog"}, "…the lazy dog"},
+	} {
+		got, err := excerpt(text, test.length, test.term...)
+		if err != nil || got != test.expected {
+			t.Errorf("excerpt(%d, %v): expected %q, got %q (%v)", test.length, test.term, test.expected, got, err)
+		}
+	}
+	if _, err := excerpt(text, "many"); err == nil {
+		t.Error("expected an error for a length that is not a number")
+	}
+
+	if got := highlight("<Go> and go", "go"); got != "&lt;<mark>Go</mark>&gt; and <mark>go</mark>" {
+		t.Errorf("highlight mismatch: %q", got)
+	}
+	if got := highlight("a&b", "&", ""); got != "a<mark>&amp;</mark>b" {
+		t.Errorf("highlight escaping mismatch: %q", got)
+	}
+
+	for input, expected := range map[string]string{
+		"Crème Brûlée à Đà Nẵng": "creme-brulee-a-da-nang",
+		"  Hello, World!! 2026 ": "hello-world-2026",
+		"Straße & Smørrebrød":    "strasse-smorrebrod",
+	} {
+		if got := slugify(input); got != expected {
+			t.Errorf("slugify(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+
+	if got := initials("ada king lovelace"); got != "AL" {
+		t.Errorf("initials mismatch: %q", got)
+	}
+	if got := initials("Ada King Lovelace", 3); got != "AKL" {
+		t.Errorf("initials with count mismatch: %q", got)
+	}
+	if got := initials("Émile"); got != "É" {
+		t.Errorf("initials single name mismatch: %q", got)
+	}
+}
+
+func TestStringFuncsInTemplates(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"post.blade": `<p>{{ nl2br .Body }}</p><h1>{{ highlight .Title .Query }}</h1>` +
+			`<a href="/posts/{{ slugify .Title }}" title="{{ highlight .Title .Query }}">{{ excerpt .Body 12 }}</a>`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{"Title": "Tips & Tricks", "Body": "<i>one</i>\ntwo three", "Query": "tricks"}
+	if err := engine.Render(&buf, "post", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<p>&lt;i&gt;one&lt;/i&gt;<br>
+two three</p><h1>Tips &amp; <mark>Tricks</mark></h1>` +
+		`<a href="/posts/tips-tricks" title="Tips &amp; Tricks">&lt;i&gt;one&lt;/i&gt;…</a>`
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
@@ -0,0 +1,171 @@
+package blade
+
+// Paginator is a page of a paginated list, the data of the built-in blade/pagination component.
+type Paginator struct {
+	// Page is the one based current page
+	Page    int
+	PerPage int
+	// Total is the number of items of all the pages
+	Total int
+	// Param is the query parameter of the page in the links, "page" when empty
+	Param string
+}
+
+// NewPaginator returns the paginator of page, clamped to the pages of total items.
+func NewPaginator(page int, perPage int, total int) *Paginator {
+	p := &Paginator{Page: page, PerPage: max(perPage, 1), Total: max(total, 0)}
+	p.Page = min(max(p.Page, 1), p.Pages())
+	return p
+}
+
+// Pages returns the number of pages, at least one.
+func (p *Paginator) Pages() int {
+	if p.PerPage <= 0 || p.Total <= 0 {
+		return 1
+	}
+	return (p.Total + p.PerPage - 1) / p.PerPage
+}
+
+// Offset returns the number of items before the page, for the query of its items.
+func (p *Paginator) Offset() int {
+	return max(p.Page-1, 0) * p.PerPage
+}
+
+// From returns the one based number of the first item of the page, zero without items.
+func (p *Paginator) From() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Offset() + 1
+}
+
+// To returns the one based number of the last item of the page, zero without items.
+func (p *Paginator) To() int {
+	return min(p.Offset()+p.PerPage, p.Total)
+}
+
+// HasPrev reports whether the page has a previous page.
+func (p *Paginator) HasPrev() bool {
+	return p.Page > 1
+}
+
+// HasNext reports whether the page has a next page.
+func (p *Paginator) HasNext() bool {
+	return p.Page < p.Pages()
+}
+
+// PrevPage returns the number of the previous page.
+func (p *Paginator) PrevPage() int {
+	return max(p.Page-1, 1)
+}
+
+// NextPage returns the number of the next page.
+func (p *Paginator) NextPage() int {
+	return min(p.Page+1, p.Pages())
+}
+
+// Window returns the numbers of the pages linked around the current one: the first and the last pages, and the
+// pages at most size pages away from the current one. Zero stands for the gaps between them.
+func (p *Paginator) Window(size int) []int {
+	pages := p.Pages()
+	var window []int
+	for page := 1; page <= pages; page++ {
+		if page == 1 || page == pages || (page >= p.Page-size && page <= p.Page+size) {
+			window = append(window, page)
+		} else if len(window) > 0 && window[len(window)-1] != 0 {
+			window = append(window, 0)
+		}
+	}
+	return window
+}
+
+// param returns the query parameter of the page.
+func (p *Paginator) param() string {
+	if p.Param == "" {
+		return "page"
+	}
+	return p.Param
+}
+
+// TableColumn is a column of a Table.
+type TableColumn struct {
+	// Key is the dotted path of fields, map keys or methods of the rows shown in the column, like "Customer.Name",
+	// and the value of the sort parameter of the column
+	Key string
+	// Label is the header of the column, the key when empty
+	Label string
+	// Sortable links the header to the page sorted by the column
+	Sortable bool
+	// Class is the class of the cells of the column, like "numeric"
+	Class string
+}
+
+// Header returns the header of the column, its label or its key.
+func (c TableColumn) Header() string {
+	if c.Label == "" {
+		return c.Key
+	}
+	return c.Label
+}
+
+// Table describes the columns and rows of the built-in blade/table component: sortable headers link to the
+// current URL with the sort and order query parameters, and the page of the Paginator is rendered below it.
+type Table struct {
+	Columns []TableColumn
+	// Rows is a slice of the rows, structs or maps
+	Rows any
+	// Sort is the key of the column sorting the rows, Desc reports whether the order is descending
+	Sort string
+	Desc bool
+	// SortParam and OrderParam are the query parameters of the sort links, "sort" and "order" when empty
+	SortParam  string
+	OrderParam string
+	// Paginator is the page of the rows, nil when they are not paginated
+	Paginator *Paginator
+	// Empty is shown when there are no rows
+	Empty string
+	Class string
+}
+
+// Cell returns the value of column in row.
+func (t *Table) Cell(row any, column TableColumn) any {
+	return itemValue(row, column.Key)
+}
+
+// Order returns the order of the rows by key, "asc" or "desc", or an empty string when they are sorted by
+// another column.
+func (t *Table) Order(key string) string {
+	switch {
+	case key != t.Sort:
+		return ""
+	case t.Desc:
+		return "desc"
+	}
+	return "asc"
+}
+
+// sortURL returns the URL of the request rendered sorted by key: ascending, or descending when it is already
+// sorted ascending by key. The page is reset.
+func (s *renderState) sortURL(t *Table, key string) (string, error) {
+	sortParam, orderParam := t.SortParam, t.OrderParam
+	if sortParam == "" {
+		sortParam = "sort"
+	}
+	if orderParam == "" {
+		orderParam = "order"
+	}
+	order := "asc"
+	if t.Order(key) == "asc" {
+		order = "desc"
+	}
+	pageParam := "page"
+	if t.Paginator != nil {
+		pageParam = t.Paginator.param()
+	}
+	return s.queryReplace(sortParam, key, orderParam, order, pageParam, nil)
+}
+
+// pageURL returns the URL of the request rendered at page of p.
+func (s *renderState) pageURL(p *Paginator, page int) (string, error) {
+	return s.queryReplace(p.param(), page)
+}
@@ -0,0 +1,75 @@
+package blade
+
+import (
+	"bytes"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPaginator(t *testing.T) {
+	p := NewPaginator(5, 10, 95)
+	if p.Pages() != 10 || p.Offset() != 40 || p.From() != 41 || p.To() != 50 || !p.HasPrev() || !p.HasNext() {
+		t.Errorf("Unexpected paginator: %+v", p)
+	}
+	if window := p.Window(1); !reflect.DeepEqual(window, []int{1, 0, 4, 5, 6, 0, 10}) {
+		t.Errorf("Unexpected window: %v", window)
+	}
+	if p := NewPaginator(20, 10, 95); p.Page != 10 || p.To() != 95 || p.HasNext() {
+		t.Errorf("Page not clamped: %+v", p)
+	}
+	if p := NewPaginator(1, 10, 0); p.Pages() != 1 || p.From() != 0 || p.To() != 0 {
+		t.Errorf("Unexpected empty paginator: %+v", p)
+	}
+}
+
+func TestTableComponent(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"orders.blade": `@include('blade/table', .Table)`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	type customer struct{ Name string }
+	table := &Table{
+		Columns: []TableColumn{{Key: "ID", Label: "#"}, {Key: "Customer.Name", Label: "Customer", Sortable: true}, {Key: "Total", Sortable: true, Class: "numeric"}},
+		Rows: []map[string]any{
+			{"ID": 1, "Customer": customer{"Ann"}, "Total": 30},
+			{"ID": 2, "Customer": customer{"Bob"}, "Total": 20},
+		},
+		Sort:      "Total",
+		Paginator: NewPaginator(2, 2, 6),
+	}
+	u, _ := url.Parse("/orders?q=x&page=2&sort=Total")
+	var buf bytes.Buffer
+	if err := engine.RenderContext(WithRequestURL(t.Context(), u), &buf, "orders", map[string]any{"Table": table}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, want := range []string{
+		`<th>#</th>`,
+		`<th><a href="/orders?order=asc&amp;q=x&amp;sort=Customer.Name">Customer</a></th>`,
+		`<th class="numeric" aria-sort="ascending"><a href="/orders?order=desc&amp;q=x&amp;sort=Total">Total</a></th>`,
+		`<td>1</td>`,
+		`<td>Ann</td>`,
+		`<td class="numeric">20</td>`,
+		`<li><a href="/orders?page=1&amp;q=x&amp;sort=Total" rel="prev">Previous</a></li>`,
+		`<li><span aria-current="page">2</span></li>`,
+		`<li><a href="/orders?page=3&amp;q=x&amp;sort=Total" rel="next">Next</a></li>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Missing %s in:\n%s", want, buf.String())
+		}
+	}
+
+	table.Rows, table.Paginator, table.Empty = nil, nil, "No orders"
+	buf.Reset()
+	if err := engine.Render(&buf, "orders", map[string]any{"Table": table}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `<tr><td colspan="3">No orders</td></tr>`) || strings.Contains(buf.String(), "pagination") {
+		t.Errorf("Unexpected empty table:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,209 @@
+package blade
+
+import (
+	"encoding/xml"
+	"fmt"
+	"hash/maphash"
+	"html/template"
+	"io"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"text/template/parse"
+	"time"
+)
+
+// XML encapsulates a known safe XML fragment, it is not escaped in XML templates.
+type XML string
+
+// compiledSet is a snapshot of the loaded templates, Load replaces it at once so renders never see a partial compile.
+type compiledSet struct {
+	parsedFiles map[string]*ParsedFile
+	// brokenFiles are the files that failed to parse in DevMode
+	brokenFiles    map[string]brokenFile
+	templates      map[string]*compiledTemplate
+	debugTemplates map[string]string
+	warnings       []Diagnostic
+	// version is the hash of the sources of the set, see Engine.Generations
+	version  string
+	loadedAt time.Time
+	// builtins are the built-in components rendered as views, by name
+	builtins sync.Map
+}
+
+func newCompiledSet() *compiledSet {
+	return &compiledSet{
+		parsedFiles:    map[string]*ParsedFile{},
+		brokenFiles:    map[string]brokenFile{},
+		templates:      map[string]*compiledTemplate{},
+		debugTemplates: map[string]string{},
+	}
+}
+
+// brokenFile is a file that failed to parse in DevMode.
+type brokenFile struct {
+	file *ParsedFile
+	err  error
+}
+
+// compiledTemplate holds a compiled entry.
+type compiledTemplate struct {
+	// proto is never executed, so it can always be cloned
+	proto templateSet
+	// exec is executed directly when a render does not need its own funcs
+	exec templateSet
+	// stateful reports whether the template calls render scoped funcs
+	stateful bool
+	// clones pools the renderClones of a stateful template, escaped by their first render
+	clones sync.Pool
+	// xml reports whether the template is compiled with XML escaping
+	xml bool
+	// textSize is the size of the compiled template text
+	textSize int
+	// textHash identifies the compiled template text, a Load keeps the template when the text is unchanged
+	textHash uint64
+	// err is the parse or compile error of a broken view in DevMode, it renders the error overlay
+	err error
+}
+
+// renderClone is a clone of the prototype of a stateful template with the render scoped funcs bound to its own
+// state, reset by each render using it. A clone is used by one render at a time.
+type renderClone struct {
+	tmpl  templateSet
+	state *renderState
+}
+
+// templateTextSeed seeds the hashes of the compiled template texts.
+var templateTextSeed = maphash.MakeSeed()
+
+// templateSet is a parsed html/template or text/template with its associated templates.
+type templateSet interface {
+	Execute(w io.Writer, data any) error
+	ExecuteTemplate(w io.Writer, name string, data any) error
+	// trees returns the parse trees of all associated templates
+	trees() []*parse.Tree
+	// clone returns a copy of the set with funcs bound, it fails once the set has been executed
+	clone(funcs ...template.FuncMap) (templateSet, error)
+}
+
+type htmlTemplateSet struct {
+	*template.Template
+}
+
+// parseHTMLTemplate parses text with contextual HTML escaping.
+func parseHTMLTemplate(name string, text string, funcs ...template.FuncMap) (templateSet, error) {
+	tmpl := template.New(name)
+	for _, f := range funcs {
+		tmpl.Funcs(f)
+	}
+	if _, err := tmpl.Parse(text); err != nil {
+		return nil, err
+	}
+	return htmlTemplateSet{tmpl}, nil
+}
+
+func (t htmlTemplateSet) trees() []*parse.Tree {
+	var trees []*parse.Tree
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree != nil {
+			trees = append(trees, tmpl.Tree)
+		}
+	}
+	return trees
+}
+
+func (t htmlTemplateSet) clone(funcs ...template.FuncMap) (templateSet, error) {
+	cloneTmpl, err := t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range funcs {
+		cloneTmpl.Funcs(f)
+	}
+	return htmlTemplateSet{cloneTmpl}, nil
+}
+
+type textTemplateSet struct {
+	*texttemplate.Template
+}
+
+// parseXMLTemplate parses text with text/template and escapes the output of every action for XML.
+func parseXMLTemplate(name string, text string, funcs ...template.FuncMap) (templateSet, error) {
+	tmpl := texttemplate.New(name).Funcs(texttemplate.FuncMap{"__xmlEscape": xmlEscape})
+	for _, f := range funcs {
+		tmpl.Funcs(texttemplate.FuncMap(f))
+	}
+	if _, err := tmpl.Parse(text); err != nil {
+		return nil, err
+	}
+	set := textTemplateSet{tmpl}
+	for _, tree := range set.trees() {
+		walkTree(tree.Root, func(node parse.Node) bool {
+			if action, ok := node.(*parse.ActionNode); ok && len(action.Pipe.Decl) == 0 {
+				escaper := parse.NewIdentifier("__xmlEscape").SetTree(tree).SetPos(action.Pos)
+				action.Pipe.Cmds = append(action.Pipe.Cmds, &parse.CommandNode{
+					NodeType: parse.NodeCommand,
+					Pos:      action.Pos,
+					Args:     []parse.Node{escaper},
+				})
+			}
+			return true
+		})
+	}
+	return set, nil
+}
+
+func (t textTemplateSet) trees() []*parse.Tree {
+	var trees []*parse.Tree
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree != nil {
+			trees = append(trees, tmpl.Tree)
+		}
+	}
+	return trees
+}
+
+func (t textTemplateSet) clone(funcs ...template.FuncMap) (templateSet, error) {
+	cloneTmpl, err := t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range funcs {
+		cloneTmpl.Funcs(texttemplate.FuncMap(f))
+	}
+	return textTemplateSet{cloneTmpl}, nil
+}
+
+// xmlEscape escapes the printed value of an action in XML templates.
+func xmlEscape(args ...any) string {
+	if len(args) == 1 {
+		switch v := args[0].(type) {
+		case XML:
+			return string(v)
+		case nil:
+			return ""
+		}
+	}
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(fmt.Sprint(args...)))
+	return b.String()
+}
+
+// callsFuncs reports whether any template associated with t calls one of funcs.
+func callsFuncs(t templateSet, funcs template.FuncMap) bool {
+	found := false
+	for _, tree := range t.trees() {
+		walkTree(tree.Root, func(node parse.Node) bool {
+			if ident, ok := node.(*parse.IdentifierNode); ok {
+				if _, ok := funcs[ident.Ident]; ok {
+					found = true
+				}
+			}
+			return !found
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package blade
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sync"
+)
+
+// DefaultMaxTenants is the default number of tenant resolvers kept by Engine.Tenant.
+const DefaultMaxTenants = 1000
+
+// TenantFSFunc returns the templates of a tenant, overlaid over the engine templates by Engine.Tenant.
+type TenantFSFunc func(tenant string) (fs.FS, error)
+
+// TenantDirs returns a TenantFSFunc serving the templates of each tenant from dir/<tenant> in fsys. Tenants
+// without directory have no overrides and render the shared templates.
+func TenantDirs(fsys fs.FS, dir string) TenantFSFunc {
+	return func(tenant string) (fs.FS, error) {
+		if !fs.ValidPath(tenant) || path.Base(tenant) != tenant {
+			return nil, fmt.Errorf("invalid tenant name %q", tenant)
+		}
+		return fs.Sub(fsys, path.Join(dir, tenant))
+	}
+}
+
+// Tenant renders views with the templates of a tenant overlaid over the shared templates of the engine.
+// Only the views depending on an overridden file are compiled for the tenant, the others are rendered
+// from the shared set, so many tenants do not multiply the memory of the engine.
+type Tenant struct {
+	name      string
+	overrides overrideSet
+}
+
+// Tenant returns the view resolver of tenant, whose templates are provided by Engine.TenantFS. Tenants without
+// templates, when TenantFS returns an fs.FS or an error matching fs.ErrNotExist, render the shared templates.
+// Resolvers are cached by name, up to Engine.MaxTenants evicting the least recently used ones, and recompile when
+// the engine reloads.
+func (e *Engine) Tenant(name string) *Tenant {
+	return e.tenants.get(e.MaxTenants, name, func() *Tenant {
+		t := &Tenant{name: name}
+		t.overrides = overrideSet{e: e, label: "tenant " + name, overrides: func() (map[string]*ParsedFile, error) {
+			if e.TenantFS == nil {
+				return nil, fmt.Errorf("Engine.TenantFS is not set")
+			}
+			fsys, err := e.TenantFS(name)
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if _, err := fs.Stat(fsys, "."); errors.Is(err, fs.ErrNotExist) {
+				return nil, nil
+			}
+			return e.parseOverrides(fsys)
+		}}
+		return t
+	})
+}
+
+// tenantCache keeps the tenant resolvers of Engine.Tenant, evicting the least recently used ones since the
+// names usually come from requests.
+type tenantCache struct {
+	mu    sync.Mutex
+	order list.List
+	items map[string]*list.Element
+}
+
+// get returns the tenant name, created by create when it is not cached, keeping at most size tenants,
+// DefaultMaxTenants when size is zero.
+func (c *tenantCache) get(size int, name string, create func() *Tenant) *Tenant {
+	if size <= 0 {
+		size = DefaultMaxTenants
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[name]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*Tenant)
+	}
+	if c.items == nil {
+		c.items = map[string]*list.Element{}
+	}
+	t := create()
+	c.items[name] = c.order.PushFront(t)
+	for c.order.Len() > size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*Tenant).name)
+	}
+	return t
+}
+
+// Name returns the name of the tenant.
+func (t *Tenant) Name() string {
+	return t.name
+}
+
+// Load reads the templates of the tenant and compiles the views depending on them.
+func (t *Tenant) Load() error {
+	return t.overrides.Load()
+}
+
+// Render executes the view entry of the tenant into w with data.
+func (t *Tenant) Render(w io.Writer, entry string, data any) error {
+	return t.RenderContext(context.Background(), w, entry, data)
+}
+
+// RenderContext is like Render with a render context.
+func (t *Tenant) RenderContext(ctx context.Context, w io.Writer, entry string, data any) error {
+	set, err := t.overrides.resolve(entry)
+	if err != nil {
+		return err
+	}
+	return t.overrides.e.executeIn(ctx, set, w, entry, data)
+}
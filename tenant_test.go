@@ -0,0 +1,111 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTenant(t *testing.T) {
+	baseFS := createMockFS(map[string]string{
+		"layout.blade":        `<header>@include('partials/logo')</header>@yield('content')`,
+		"partials/logo.blade": `Shared logo`,
+		"home.blade":          `@extends('layout')@section('content')Home@endsection`,
+		"plain.blade":         `Plain`,
+	})
+	tenantsFS := createMockFS(map[string]string{
+		"tenants/acme/partials/logo.blade": `ACME logo`,
+		"tenants/acme/promo.blade":         `@extends('layout')@section('content')Promo@endsection`,
+	})
+	engine := NewEngineFS(baseFS)
+	engine.TenantFS = TenantDirs(tenantsFS, "tenants")
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	acme := engine.Tenant("acme")
+	if engine.Tenant("acme") != acme {
+		t.Errorf("expected tenants to be cached")
+	}
+
+	tests := []struct {
+		render   func(*bytes.Buffer, string) error
+		entry    string
+		expected string
+	}{
+		{func(b *bytes.Buffer, entry string) error { return acme.Render(b, entry, nil) }, "home", "<header>ACME logo</header>Home"},
+		{func(b *bytes.Buffer, entry string) error { return acme.Render(b, entry, nil) }, "promo", "<header>ACME logo</header>Promo"},
+		{func(b *bytes.Buffer, entry string) error { return acme.Render(b, entry, nil) }, "plain", "Plain"},
+		{func(b *bytes.Buffer, entry string) error {
+			return engine.RenderContext(WithTenant(context.Background(), "acme"), b, entry, nil)
+		}, "home", "<header>ACME logo</header>Home"},
+		{func(b *bytes.Buffer, entry string) error { return engine.Render(b, entry, nil) }, "home", "<header>Shared logo</header>Home"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := tt.render(&buf, tt.entry); err != nil {
+			t.Fatalf("Render %s failed: %v", tt.entry, err)
+		}
+		if buf.String() != tt.expected {
+			t.Errorf("Render %s mismatch.\nExp: %s\nGot: %s", tt.entry, tt.expected, buf.String())
+		}
+	}
+
+	// only the views depending on tenant files are compiled for the tenant
+	state := acme.overrides.state.Load()
+	if _, ok := state.set.templates["plain"]; ok || len(state.set.templates) != 4 {
+		t.Errorf("expected home, layout, promo and partials/logo compiled for the tenant, got %d templates", len(state.set.templates))
+	}
+
+	// the tenant recompiles against the reloaded engine templates
+	baseFS["home.blade"].Data = []byte(`@extends('layout')@section('content')New home@endsection`)
+	baseFS["home.blade"].ModTime = time.Now().Add(time.Second)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := acme.Render(&buf, "home", nil); err != nil || buf.String() != "<header>ACME logo</header>New home" {
+		t.Errorf("expected the reloaded view, got %q, %v", buf.String(), err)
+	}
+
+	if err := engine.Tenant("../acme").Render(&buf, "home", nil); err == nil {
+		t.Errorf("expected an error for an invalid tenant name")
+	}
+}
+
+func TestTenantWithoutTemplates(t *testing.T) {
+	baseFS := createMockFS(map[string]string{
+		"home.blade": `Home`,
+	})
+	tenantsFS := createMockFS(map[string]string{
+		"tenants/acme/home.blade": `ACME home`,
+	})
+	engine := NewEngineFS(baseFS)
+	engine.TenantFS = TenantDirs(tenantsFS, "tenants")
+	engine.MaxTenants = 2
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	nobody := engine.Tenant("nobody")
+	for range 2 {
+		var buf bytes.Buffer
+		if err := nobody.Render(&buf, "home", nil); err != nil || buf.String() != "Home" {
+			t.Fatalf("expected the shared view, got %q, %v", buf.String(), err)
+		}
+	}
+	if state := nobody.overrides.state.Load(); state == nil || len(state.set.templates) != 0 {
+		t.Errorf("expected an empty override set to be kept, got %+v", state)
+	}
+
+	// the least recently used tenants are evicted
+	acme := engine.Tenant("acme")
+	engine.Tenant("other")
+	if engine.Tenant("acme") != acme || engine.Tenant("nobody") == nobody {
+		t.Errorf("expected nobody to be evicted and acme to be kept")
+	}
+	if len(engine.tenants.items) != 2 {
+		t.Errorf("expected 2 cached tenants, got %d", len(engine.tenants.items))
+	}
+}
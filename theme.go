@@ -0,0 +1,98 @@
+package blade
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+)
+
+// ThemeSource is a theme of Engine.Themes, its templates override the templates of its parent theme,
+// then the engine templates, so a skin only ships the files it changes.
+type ThemeSource struct {
+	// FS holds the templates of the theme
+	FS fs.FS
+	// Parent is the theme searched after this one, empty for the engine templates
+	Parent string
+}
+
+// Theme renders views through the search path of a theme, see Engine.Theme.
+type Theme struct {
+	name      string
+	overrides overrideSet
+}
+
+// Theme returns the view resolver of the theme name of Engine.Themes, whose templates are looked up in the
+// theme, then in its parents and finally in the engine templates. Resolvers are cached by name and compile
+// only the views depending on theme files, so one binary serves many storefront skins:
+//
+//	eng.Themes = map[string]blade.ThemeSource{
+//		"shop":      {FS: os.DirFS("./themes/shop")},
+//		"dark-shop": {FS: os.DirFS("./themes/dark-shop"), Parent: "shop"},
+//	}
+//	eng.Theme("dark-shop").Render(w, "pages/home", data)
+func (e *Engine) Theme(name string) *Theme {
+	if t, ok := e.themes.Load(name); ok {
+		return t.(*Theme)
+	}
+	t := &Theme{name: name}
+	t.overrides = overrideSet{e: e, label: "theme " + name, overrides: func() (map[string]*ParsedFile, error) {
+		return e.parseTheme(name)
+	}}
+	actual, _ := e.themes.LoadOrStore(name, t)
+	return actual.(*Theme)
+}
+
+// parseTheme parses the templates of the search path of the theme name, a theme file overrides the
+// files of the same name in its parents.
+func (e *Engine) parseTheme(name string) (map[string]*ParsedFile, error) {
+	var path []ThemeSource
+	seen := map[string]struct{}{}
+	for current := name; current != ""; {
+		if _, ok := seen[current]; ok {
+			return nil, fmt.Errorf("theme %q extends itself", current)
+		}
+		seen[current] = struct{}{}
+		source, ok := e.Themes[current]
+		if !ok {
+			return nil, fmt.Errorf("theme %q not found", current)
+		}
+		path = append(path, source)
+		current = source.Parent
+	}
+
+	files := map[string]*ParsedFile{}
+	for i := len(path) - 1; i >= 0; i-- {
+		parsed, err := e.parseOverrides(path[i].FS)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(files, parsed)
+	}
+	return files, nil
+}
+
+// Name returns the name of the theme.
+func (t *Theme) Name() string {
+	return t.name
+}
+
+// Load reads the templates of the theme search path and compiles the views depending on them.
+func (t *Theme) Load() error {
+	return t.overrides.Load()
+}
+
+// Render executes the view entry of the theme into w with data.
+func (t *Theme) Render(w io.Writer, entry string, data any) error {
+	return t.RenderContext(context.Background(), w, entry, data)
+}
+
+// RenderContext is like Render with a render context.
+func (t *Theme) RenderContext(ctx context.Context, w io.Writer, entry string, data any) error {
+	set, err := t.overrides.resolve(entry)
+	if err != nil {
+		return err
+	}
+	return t.overrides.e.executeIn(ctx, set, w, entry, data)
+}
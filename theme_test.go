@@ -0,0 +1,60 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestTheme(t *testing.T) {
+	engine := NewEngineFS(createMockFS(map[string]string{
+		"layout.blade":        `<body class="@include('partials/skin')">@yield('content')</body>`,
+		"partials/skin.blade": `default`,
+		"partials/logo.blade": `Logo`,
+		"home.blade":          `@extends('layout')@section('content')@include('partials/logo')@endsection`,
+	}))
+	engine.Themes["shop"] = ThemeSource{FS: createMockFS(map[string]string{
+		"partials/skin.blade": `shop`,
+		"partials/logo.blade": `Shop logo`,
+	})}
+	engine.Themes["dark-shop"] = ThemeSource{FS: createMockFS(map[string]string{
+		"partials/skin.blade": `dark`,
+	}), Parent: "shop"}
+	engine.Themes["loop"] = ThemeSource{FS: createMockFS(nil), Parent: "loop"}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		theme    string
+		expected string
+	}{
+		{"", `<body class="default">Logo</body>`},
+		{"shop", `<body class="shop">Shop logo</body>`},
+		{"dark-shop", `<body class="dark">Shop logo</body>`},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		ctx := context.Background()
+		if tt.theme != "" {
+			ctx = WithTheme(ctx, tt.theme)
+		}
+		if err := engine.RenderContext(ctx, &buf, "home", nil); err != nil {
+			t.Fatalf("Render with theme %q failed: %v", tt.theme, err)
+		}
+		if buf.String() != tt.expected {
+			t.Errorf("Render with theme %q mismatch.\nExp: %s\nGot: %s", tt.theme, tt.expected, buf.String())
+		}
+	}
+
+	if engine.Theme("dark-shop") != engine.Theme("dark-shop") {
+		t.Errorf("expected themes to be cached")
+	}
+	var buf bytes.Buffer
+	if err := engine.Theme("missing").Render(&buf, "home", nil); err == nil {
+		t.Errorf("expected an error for an unknown theme")
+	}
+	if err := engine.Theme("loop").Render(&buf, "home", nil); err == nil {
+		t.Errorf("expected an error for a theme extending itself")
+	}
+}
@@ -0,0 +1,167 @@
+package blade
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RelativeTimeLocale holds the words used by the timeAgo and dateDiff helpers for a language.
+type RelativeTimeLocale struct {
+	// Now is used for differences under a minute
+	Now string
+	// Past formats a duration in the past, like "%s ago"
+	Past string
+	// Future formats a duration in the future, like "in %s"
+	Future string
+	// Units maps second, minute, hour, day, month and year to their singular and plural forms, like "%d hour"
+	Units map[string][2]string
+}
+
+// DefaultRelativeTimeLocales are the built-in languages of the timeAgo and dateDiff helpers.
+var DefaultRelativeTimeLocales = map[string]RelativeTimeLocale{
+	"en": {
+		Now: "just now", Past: "%s ago", Future: "in %s",
+		Units: map[string][2]string{
+			"second": {"%d second", "%d seconds"}, "minute": {"%d minute", "%d minutes"}, "hour": {"%d hour", "%d hours"},
+			"day": {"%d day", "%d days"}, "month": {"%d month", "%d months"}, "year": {"%d year", "%d years"},
+		},
+	},
+	"vi": {
+		Now: "vừa xong", Past: "%s trước", Future: "%s nữa",
+		Units: map[string][2]string{
+			"second": {"%d giây", "%d giây"}, "minute": {"%d phút", "%d phút"}, "hour": {"%d giờ", "%d giờ"},
+			"day": {"%d ngày", "%d ngày"}, "month": {"%d tháng", "%d tháng"}, "year": {"%d năm", "%d năm"},
+		},
+	},
+	"de": {
+		Now: "gerade eben", Past: "vor %s", Future: "in %s",
+		Units: map[string][2]string{
+			"second": {"%d Sekunde", "%d Sekunden"}, "minute": {"%d Minute", "%d Minuten"}, "hour": {"%d Stunde", "%d Stunden"},
+			"day": {"%d Tag", "%d Tagen"}, "month": {"%d Monat", "%d Monaten"}, "year": {"%d Jahr", "%d Jahren"},
+		},
+	},
+	"fr": {
+		Now: "à l'instant", Past: "il y a %s", Future: "dans %s",
+		Units: map[string][2]string{
+			"second": {"%d seconde", "%d secondes"}, "minute": {"%d minute", "%d minutes"}, "hour": {"%d heure", "%d heures"},
+			"day": {"%d jour", "%d jours"}, "month": {"%d mois", "%d mois"}, "year": {"%d an", "%d ans"},
+		},
+	},
+}
+
+// namedTimeLayouts are the layout names accepted by the formatDate helper.
+var namedTimeLayouts = map[string]string{
+	"ISO8601":     time.RFC3339,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC850":      time.RFC850,
+	"date":        time.DateOnly,
+	"datetime":    time.DateTime,
+	"time":        time.TimeOnly,
+}
+
+// timeAgo returns how long ago (or in how long) t is from now, like "3 hours ago".
+func (s *renderState) timeAgo(v any) (string, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return "", fmt.Errorf("timeAgo: %w", err)
+	}
+	locale := s.relativeTimeLocale()
+	d := time.Since(t)
+	if d < time.Minute && d > -time.Minute {
+		return locale.Now, nil
+	}
+	if d < 0 {
+		return fmt.Sprintf(locale.Future, formatRelativeDuration(locale, -d)), nil
+	}
+	return fmt.Sprintf(locale.Past, formatRelativeDuration(locale, d)), nil
+}
+
+// dateDiff returns the difference between two times in words, like "2 days".
+func (s *renderState) dateDiff(from any, to any) (string, error) {
+	start, err := toTime(from)
+	if err != nil {
+		return "", fmt.Errorf("dateDiff: %w", err)
+	}
+	end, err := toTime(to)
+	if err != nil {
+		return "", fmt.Errorf("dateDiff: %w", err)
+	}
+	d := end.Sub(start)
+	if d < 0 {
+		d = -d
+	}
+	return formatRelativeDuration(s.relativeTimeLocale(), d), nil
+}
+
+// relativeTimeLocale returns the words of the render locale, falling back to its base language and English.
+func (s *renderState) relativeTimeLocale() RelativeTimeLocale {
+	locale := s.locale()
+	if l, ok := s.e.RelativeTimeLocales[locale]; ok {
+		return l
+	}
+	base, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	if l, ok := s.e.RelativeTimeLocales[base]; ok {
+		return l
+	}
+	return DefaultRelativeTimeLocales["en"]
+}
+
+// formatDate formats a time with a named layout (ISO8601, RFC3339, RFC1123, date, datetime...) or a Go layout.
+func formatDate(v any, layout string) (string, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return "", fmt.Errorf("formatDate: %w", err)
+	}
+	if named, ok := namedTimeLayouts[layout]; ok {
+		layout = named
+	}
+	return t.Format(layout), nil
+}
+
+// formatRelativeDuration formats d with the largest fitting unit.
+func formatRelativeDuration(locale RelativeTimeLocale, d time.Duration) string {
+	const day = 24 * time.Hour
+	unit, count := "second", int(d/time.Second)
+	switch {
+	case d >= 365*day:
+		unit, count = "year", int(d/(365*day))
+	case d >= 30*day:
+		unit, count = "month", int(d/(30*day))
+	case d >= day:
+		unit, count = "day", int(d/day)
+	case d >= time.Hour:
+		unit, count = "hour", int(d/time.Hour)
+	case d >= time.Minute:
+		unit, count = "minute", int(d/time.Minute)
+	}
+	forms := locale.Units[unit]
+	if count == 1 {
+		return fmt.Sprintf(forms[0], count)
+	}
+	return fmt.Sprintf(forms[1], count)
+}
+
+// toTime converts time.Time, *time.Time, unix seconds and RFC 3339 strings to time.Time.
+func toTime(v any) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, fmt.Errorf("cannot convert nil to time")
+		}
+		return *t, nil
+	case string:
+		return time.Parse(time.RFC3339, t)
+	}
+	unix, err := toInt(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot convert %v (%T) to time", v, v)
+	}
+	return time.Unix(int64(unix), 0), nil
+}
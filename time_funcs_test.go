@@ -0,0 +1,41 @@
+package blade
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeFuncs(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"post.blade": `{{ timeAgo .Created }}|{{ timeAgo .Scheduled }}|{{ dateDiff .Created .Scheduled }}|{{ formatDate .Fixed "ISO8601" }}|{{ formatDate .Fixed "date" }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	now := time.Now()
+	data := map[string]any{
+		"Created":   now.Add(-3*time.Hour - time.Minute),
+		"Scheduled": now.Add(49 * time.Hour),
+		"Fixed":     time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{"en-US", "3 hours ago|in 2 days|2 days|2025-01-02T03:04:05Z|2025-01-02"},
+		{"vi", "3 giờ trước|2 ngày nữa|2 ngày|2025-01-02T03:04:05Z|2025-01-02"},
+	}
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := engine.RenderContext(WithLocale(context.Background(), tc.locale), &buf, "post", data); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("Time helpers mismatch for %s.\nExp: %s\nGot: %s", tc.locale, tc.expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package blade
+
+import "text/template/parse"
+
+// walkTree calls fn for node and its descendants in depth-first order.
+// Walking stops as soon as fn returns false.
+func walkTree(node parse.Node, fn func(parse.Node) bool) bool {
+	if node == nil {
+		return true
+	}
+	if !fn(node) {
+		return false
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return true
+		}
+		for _, child := range n.Nodes {
+			if !walkTree(child, fn) {
+				return false
+			}
+		}
+	case *parse.ActionNode:
+		return walkPipe(n.Pipe, fn)
+	case *parse.PipeNode:
+		for _, cmd := range n.Cmds {
+			if !walkTree(cmd, fn) {
+				return false
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if !walkTree(arg, fn) {
+				return false
+			}
+		}
+	case *parse.ChainNode:
+		return walkTree(n.Node, fn)
+	case *parse.IfNode:
+		return walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		return walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		return walkBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		return walkPipe(n.Pipe, fn)
+	}
+	return true
+}
+
+func walkPipe(pipe *parse.PipeNode, fn func(parse.Node) bool) bool {
+	if pipe == nil {
+		return true
+	}
+	return walkTree(pipe, fn)
+}
+
+func walkBranch(branch *parse.BranchNode, fn func(parse.Node) bool) bool {
+	if !walkPipe(branch.Pipe, fn) {
+		return false
+	}
+	if branch.List != nil && !walkTree(branch.List, fn) {
+		return false
+	}
+	if branch.ElseList != nil && !walkTree(branch.ElseList, fn) {
+		return false
+	}
+	return true
+}
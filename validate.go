@@ -0,0 +1,137 @@
+package blade
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Validator checks the output of a render.
+type Validator interface {
+	Validate(entry string, output []byte) error
+}
+
+// ValidatorFunc adapts a function to a Validator.
+type ValidatorFunc func(entry string, output []byte) error
+
+// Validate calls f(entry, output).
+func (f ValidatorFunc) Validate(entry string, output []byte) error {
+	return f(entry, output)
+}
+
+// HTMLValidationError is returned by HTMLValidator when the output is not well-formed.
+type HTMLValidationError struct {
+	Entry   string
+	Line    int
+	Message string
+}
+
+func (e *HTMLValidationError) Error() string {
+	return fmt.Sprintf("[%s] invalid HTML at line %d: %s", e.Entry, e.Line, e.Message)
+}
+
+// HTMLValidator is a basic well-formedness checker, it reports unbalanced tags,
+// like a closing tag swallowed by an {{ if }} branch.
+type HTMLValidator struct{}
+
+// voidElements never have a closing tag.
+var voidElements = map[string]struct{}{
+	"area": {}, "base": {}, "br": {}, "col": {}, "embed": {}, "hr": {}, "img": {}, "input": {},
+	"link": {}, "meta": {}, "param": {}, "source": {}, "track": {}, "wbr": {},
+}
+
+// optionalEndElements can be closed implicitly by a sibling or their parent.
+var optionalEndElements = map[string]struct{}{
+	"html": {}, "head": {}, "body": {}, "p": {}, "li": {}, "dt": {}, "dd": {}, "option": {}, "optgroup": {},
+	"colgroup": {}, "thead": {}, "tbody": {}, "tfoot": {}, "tr": {}, "td": {}, "th": {}, "rb": {}, "rt": {}, "rp": {},
+}
+
+type openElement struct {
+	name string
+	line int
+}
+
+// Validate implements Validator.
+func (HTMLValidator) Validate(entry string, output []byte) error {
+	z := html.NewTokenizer(bytes.NewReader(output))
+	var stack []openElement
+	line := 1
+	fail := func(format string, args ...any) error {
+		return &HTMLValidationError{Entry: entry, Line: line, Message: fmt.Sprintf(format, args...)}
+	}
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				return fail("%v", z.Err())
+			}
+			break
+		}
+		tokenLine := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+
+		switch tt {
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if _, ok := voidElements[tag]; ok {
+				continue
+			}
+			if _, ok := optionalEndElements[tag]; ok && len(stack) > 0 && stack[len(stack)-1].name == tag {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, openElement{name: tag, line: tokenLine})
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if _, ok := voidElements[tag]; ok {
+				continue
+			}
+			i := len(stack) - 1
+			for i >= 0 && stack[i].name != tag {
+				if _, ok := optionalEndElements[stack[i].name]; !ok {
+					break
+				}
+				i--
+			}
+			if i < 0 || stack[i].name != tag {
+				line = tokenLine
+				if len(stack) > 0 && containsElement(stack, tag) {
+					top := stack[len(stack)-1]
+					return fail("</%s> closes <%s> opened at line %d while <%s> (line %d) is still open", tag, tag, elementLine(stack, tag), top.name, top.line)
+				}
+				return fail("unexpected closing tag </%s>", tag)
+			}
+			stack = stack[:i]
+		}
+	}
+
+	var unclosed []string
+	for _, el := range stack {
+		if _, ok := optionalEndElements[el.name]; !ok {
+			unclosed = append(unclosed, fmt.Sprintf("<%s> (line %d)", el.name, el.line))
+		}
+	}
+	if len(unclosed) > 0 {
+		return fail("unclosed %s", strings.Join(unclosed, ", "))
+	}
+	return nil
+}
+
+func containsElement(stack []openElement, tag string) bool {
+	return elementLine(stack, tag) > 0
+}
+
+// elementLine returns the line of the innermost open tag element, or 0.
+func elementLine(stack []openElement, tag string) int {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].name == tag {
+			return stack[i].line
+		}
+	}
+	return 0
+}
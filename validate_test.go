@@ -0,0 +1,104 @@
+package blade
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHTMLValidator(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		valid  bool
+	}{
+		{"balanced", "<div><p>Hello<br><img src=x></p></div>", true},
+		{"implicit ends", "<ul><li>One<li>Two</ul><table><tr><td>1<td>2</table>", true},
+		{"self closing and comments", "<!-- <div> --><div/><script>if (a < b) {}</script>", true},
+		{"unclosed", "<div>\n<span>Hi</span>", false},
+		{"unexpected close", "<div></div></div>", false},
+		{"crossed", "<div><span></div></span>", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := HTMLValidator{}.Validate("page", []byte(tt.output))
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("expected an error for %q", tt.output)
+			}
+		})
+	}
+}
+
+func TestEngineValidators(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"page.blade": "<div>\n{{ if .Open }}<section>{{ end }}</section>\n</div>",
+	})
+	engine := NewEngineFS(mockFS)
+	engine.Validators = []Validator{HTMLValidator{}}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", map[string]any{"Open": true}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	buf.Reset()
+	err := engine.Render(&buf, "page", map[string]any{"Open": false})
+	var validationErr *HTMLValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected HTMLValidationError, got %v", err)
+	}
+	if validationErr.Line != 2 {
+		t.Errorf("expected error at line 2, got %d", validationErr.Line)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("invalid output should not be written, got %q", buf.String())
+	}
+}
+
+func TestAccessibilityValidator(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		issues []string
+	}{
+		{"accessible", `<img src="a.png" alt=""><label for="email">Email</label><input id="email"><label>Name <input name="name"></label>` +
+			`<input type="hidden" name="token"><textarea aria-label="Comment"></textarea><button type="submit">Send</button>`, nil},
+		{"label after input", `<input id="q"><label for="q">Search</label>`, nil},
+		{"missing alt", "<p>\n<img src=\"logo.png\"></p>", []string{`line 2: <img src="logo.png"> has no alt attribute`}},
+		{"unlabeled controls", "<input name=\"q\">\n<select id=\"sort\"></select>", []string{
+			"line 1: <input> has no id or label",
+			`line 2: <select id="sort"> has no label`,
+		}},
+		{"duplicate id", "<div id=\"main\"></div>\n<div id=\"main\"></div>", []string{`line 2: duplicate id "main", first used at line 1`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AccessibilityValidator{}.Validate("page", []byte(tt.output))
+			var a11yErr *AccessibilityError
+			if tt.issues == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &a11yErr) {
+				t.Fatalf("expected an AccessibilityError, got %v", err)
+			}
+			var got []string
+			for _, issue := range a11yErr.Issues {
+				got = append(got, fmt.Sprintf("line %d: %s", issue.Line, issue.Message))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.issues, "\n") {
+				t.Errorf("unexpected issues\nExp: %q\nGot: %q", tt.issues, got)
+			}
+		})
+	}
+}
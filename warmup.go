@@ -0,0 +1,30 @@
+package blade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Warmup executes entries, or all loaded entries when none is given, with empty data into io.Discard.
+// It runs the html/template escaping of each entry ahead of the first request and reports runtime errors,
+// like calls to funcs with bad signatures or templates ending in an invalid context, at startup.
+// Entries requiring data for their control flow may report errors that would not happen with real data.
+func (e *Engine) Warmup(entries ...string) error {
+	set := e.set.Load()
+	if len(entries) == 0 {
+		entries = sortedKeys(set.templates)
+	}
+	var errs []error
+	for _, entry := range entries {
+		if tmpl, ok := set.templates[normalizeName(entry)]; ok && tmpl.err != nil {
+			errs = append(errs, fmt.Errorf("[%s] %w", normalizeName(entry), tmpl.err))
+			continue
+		}
+		if err := e.execute(context.Background(), io.Discard, entry, map[string]any{}); err != nil {
+			errs = append(errs, fmt.Errorf("[%s] %w", normalizeName(entry), err))
+		}
+	}
+	return errors.Join(errs...)
+}
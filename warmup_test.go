@@ -0,0 +1,29 @@
+package blade
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWarmup(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"home.blade":   `<h1>{{ .User.Name }}</h1>{{ range .Items }}{{ .Title }}{{ end }}`,
+		"broken.blade": `{{ greet 1 }}`,
+	})
+	engine := NewEngineFS(mockFS)
+	engine.FuncMap["greet"] = func(name string) string { return "Hello " + name }
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if err := engine.Warmup("home"); err != nil {
+		t.Errorf("unexpected warmup error: %v", err)
+	}
+	err := engine.Warmup()
+	if err == nil || !strings.HasPrefix(err.Error(), "[broken] ") {
+		t.Errorf("expected a warmup error for broken, got %v", err)
+	}
+	if err := engine.Warmup("missing"); err == nil {
+		t.Errorf("expected an error for a missing template")
+	}
+}
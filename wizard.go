@@ -0,0 +1,199 @@
+package blade
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"strings"
+)
+
+// wizardSessionPrefix prefixes the session keys of the input of the wizards.
+const wizardSessionPrefix = "wizard:"
+
+// SessionWriter is a SessionReader storing values, like the adapters of the session package, to persist the
+// input of the steps of a Wizard.
+type SessionWriter interface {
+	SessionReader
+	Set(key string, value any)
+}
+
+// WizardStep is a step of a Wizard.
+type WizardStep struct {
+	// Name identifies the step, like "address"
+	Name string
+	// Title is shown by the blade/wizard component, the name when empty
+	Title string
+	// URL is the page of the step, linked by the component once the step is done
+	URL string
+}
+
+// WizardItem is a step of Wizard.Items with its state.
+type WizardItem struct {
+	WizardStep
+	// Number is the one based position of the step
+	Number int
+	// Done reports whether the step is before the current one, Current whether it is the current one
+	Done    bool
+	Current bool
+}
+
+// Wizard is the state of a multi-step form, the data of the built-in blade/wizard component rendered by
+// @wizard. The input of the steps is kept in the session between the requests of the steps, see Load and Save.
+type Wizard struct {
+	// Name identifies the wizard in the session
+	Name  string
+	Steps []WizardStep
+	// Current is the index of the current step
+	Current int
+	// Input is the input of the steps saved so far
+	Input url.Values
+}
+
+// NewWizard returns a wizard at its first step.
+func NewWizard(name string, steps ...WizardStep) *Wizard {
+	w := &Wizard{Name: name, Steps: steps, Input: url.Values{}}
+	for i := range w.Steps {
+		if w.Steps[i].Title == "" {
+			w.Steps[i].Title = w.Steps[i].Name
+		}
+	}
+	return w
+}
+
+// Goto makes step the current step, it returns false when the wizard has no such step.
+func (w *Wizard) Goto(step string) bool {
+	for i, s := range w.Steps {
+		if s.Name == step {
+			w.Current = i
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads the input saved by Save from the session. Input which cannot be read, like input of another
+// version of the wizard, is ignored.
+func (w *Wizard) Load(s SessionReader) *Wizard {
+	raw, _ := s.Get(wizardSessionPrefix + w.Name).(string)
+	input := url.Values{}
+	if raw != "" && json.Unmarshal([]byte(raw), &input) == nil {
+		w.Input = input
+	}
+	return w
+}
+
+// Save merges the input of the current step, like the form of its request, into the input of the wizard and
+// stores it in the session. The input is stored as a JSON string, which every session store can encode.
+func (w *Wizard) Save(s SessionWriter, input url.Values) error {
+	if w.Input == nil {
+		w.Input = url.Values{}
+	}
+	maps.Copy(w.Input, input)
+	raw, err := json.Marshal(w.Input)
+	if err != nil {
+		return fmt.Errorf("wizard %s: %w", w.Name, err)
+	}
+	s.Set(wizardSessionPrefix+w.Name, string(raw))
+	return nil
+}
+
+// Clear removes the input of the wizard from the session, once its form is submitted.
+func (w *Wizard) Clear(s SessionWriter) {
+	w.Input = url.Values{}
+	s.Set(wizardSessionPrefix+w.Name, nil)
+}
+
+// Step returns the current step.
+func (w *Wizard) Step() WizardStep {
+	if w.Current < 0 || w.Current >= len(w.Steps) {
+		return WizardStep{}
+	}
+	return w.Steps[w.Current]
+}
+
+// Is reports whether step is the current step, for @step.
+func (w *Wizard) Is(step string) bool {
+	return w.Step().Name == step
+}
+
+// Number returns the one based position of the current step.
+func (w *Wizard) Number() int {
+	return w.Current + 1
+}
+
+// Count returns the number of steps.
+func (w *Wizard) Count() int {
+	return len(w.Steps)
+}
+
+// Progress returns the percentage of the steps done before the current one.
+func (w *Wizard) Progress() int {
+	if len(w.Steps) == 0 {
+		return 0
+	}
+	return w.Current * 100 / len(w.Steps)
+}
+
+// IsFirst reports whether the current step is the first one.
+func (w *Wizard) IsFirst() bool {
+	return w.Current == 0
+}
+
+// IsLast reports whether the current step is the last one.
+func (w *Wizard) IsLast() bool {
+	return w.Current == len(w.Steps)-1
+}
+
+// Prev returns the step before the current one, or nil.
+func (w *Wizard) Prev() *WizardStep {
+	if w.Current <= 0 || w.Current > len(w.Steps) {
+		return nil
+	}
+	return &w.Steps[w.Current-1]
+}
+
+// Next returns the step after the current one, or nil.
+func (w *Wizard) Next() *WizardStep {
+	if w.Current < 0 || w.Current+1 >= len(w.Steps) {
+		return nil
+	}
+	return &w.Steps[w.Current+1]
+}
+
+// Items returns the steps with their state, for the blade/wizard component.
+func (w *Wizard) Items() []WizardItem {
+	items := make([]WizardItem, len(w.Steps))
+	for i, s := range w.Steps {
+		items[i] = WizardItem{WizardStep: s, Number: i + 1, Done: i < w.Current, Current: i == w.Current}
+	}
+	return items
+}
+
+// Value returns the saved input of field, to refill the fields of the steps visited again:
+// value="{{ old "email" (.Wizard.Value "email") }}".
+func (w *Wizard) Value(field string) string {
+	return w.Input.Get(field)
+}
+
+// parseWizardDirective converts @wizard to an include of the built-in blade/wizard component:
+// @wizard(.Wizard) => @include('blade/wizard', (.Wizard))
+func parseWizardDirective(args []string) (string, bool) {
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		return "", false
+	}
+	return fmt.Sprintf(`@include('%swizard', (%s))`, componentNamePrefix, strings.TrimSpace(args[0])), true
+}
+
+// parseStepDirective converts @step blocks rendered at a step of a wizard:
+// @step(.Wizard, 'address') ... @endstep => {{ if (.Wizard).Is "address" }} ... {{ end }}
+func parseStepDirective(args []string) (string, bool) {
+	if len(args) != 2 || strings.TrimSpace(args[0]) == "" {
+		return "", false
+	}
+	step, ok := unquoteDirectiveString(strings.TrimSpace(args[1]))
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf(`{{ if (%s).Is %q }}`, strings.TrimSpace(args[0]), step), true
+}
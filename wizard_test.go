@@ -0,0 +1,69 @@
+package blade
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// testSession is a SessionWriter keeping its values in a map.
+type testSession map[string]any
+
+func (s testSession) Get(key string) any {
+	return s[key]
+}
+
+func (s testSession) Set(key string, value any) {
+	s[key] = value
+}
+
+func TestWizard(t *testing.T) {
+	mockFS := createMockFS(map[string]string{
+		"signup.blade": `@wizard(.Wizard)@step(.Wizard, 'account')<input name="email">@endstep` +
+			`@step(.Wizard, 'address')<input name="city" value="{{ .Wizard.Value "city" }}">{{ with .Wizard.Prev }}<a href="{{ .URL }}">Back</a>{{ end }}@endstep`,
+	})
+	engine := NewEngineFS(mockFS)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	steps := []WizardStep{{Name: "account", Title: "Account", URL: "/signup/account"}, {Name: "address", URL: "/signup/address"}, {Name: "confirm"}}
+	sess := testSession{}
+	w := NewWizard("signup", steps...)
+	if err := w.Save(sess, url.Values{"email": {"ann@example.com"}, "city": {"Hanoi"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	w = NewWizard("signup", steps...).Load(sess)
+	if !w.Goto("address") || w.Goto("missing") {
+		t.Fatal("Unexpected Goto result")
+	}
+	if w.Number() != 2 || w.Count() != 3 || w.Progress() != 33 || w.IsFirst() || w.IsLast() || w.Next().Name != "confirm" {
+		t.Errorf("Unexpected state: %+v", w)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "signup", map[string]any{"Wizard": w}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, want := range []string{
+		`<li class="wizard-step is-done"><a href="/signup/account">Account</a></li>`,
+		`<li class="wizard-step is-current" aria-current="step"><span>address</span></li>`,
+		`<li class="wizard-step"><span>confirm</span></li>`,
+		`<progress max="3" value="2">2/3</progress>`,
+		`<input name="city" value="Hanoi"><a href="/signup/account">Back</a>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Missing %s in:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), `name="email"`) {
+		t.Errorf("Rendered the step of another step:\n%s", buf.String())
+	}
+
+	w.Clear(sess)
+	if NewWizard("signup", steps...).Load(sess).Value("email") != "" {
+		t.Error("Input kept after Clear")
+	}
+}